@@ -8,16 +8,18 @@ import (
 	"github.com/eannchen/leetsolv/handler"
 )
 
+// Command executes a user command.
+// It returns quit=true to end the interactive session, and a non-nil error when the command failed.
 type Command interface {
-	Execute(scanner *bufio.Scanner, args []string) bool
+	Execute(scanner *bufio.Scanner, args []string) (bool, error)
 }
 
 type CommandRegistry struct {
 	commands              map[string]Command
-	unknownCommandHandler func(command string)
+	unknownCommandHandler func(command string) error
 }
 
-func NewCommandRegistry(unknownCommandHandler func(command string)) *CommandRegistry {
+func NewCommandRegistry(unknownCommandHandler func(command string) error) *CommandRegistry {
 	return &CommandRegistry{
 		commands:              make(map[string]Command),
 		unknownCommandHandler: unknownCommandHandler,
@@ -29,17 +31,13 @@ func (r *CommandRegistry) Register(name string, cmd Command) {
 	r.commands[strings.ToLower(name)] = cmd
 }
 
-func (r *CommandRegistry) Execute(scanner *bufio.Scanner, name string, args []string) bool {
+func (r *CommandRegistry) Execute(scanner *bufio.Scanner, name string, args []string) (bool, error) {
 	// Convert command name to lowercase for case-insensitive lookup
 	lowerName := strings.ToLower(name)
 	if cmd, exists := r.commands[lowerName]; exists {
-		if quit := cmd.Execute(scanner, args); quit {
-			return true
-		}
-	} else {
-		r.unknownCommandHandler(name)
+		return cmd.Execute(scanner, args)
 	}
-	return false
+	return false, r.unknownCommandHandler(name)
 }
 
 // command implementations
@@ -50,145 +48,290 @@ type ListCommand struct {
 	Handler handler.Handler
 }
 
-func (c *ListCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	c.Handler.HandleList(scanner)
-	return false
+func (c *ListCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleList(scanner)
 }
 
 type SearchCommand struct {
 	Handler handler.Handler
 }
 
-func (c *SearchCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	c.Handler.HandleSearch(scanner, args)
-	return false
+func (c *SearchCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleSearch(scanner, args)
 }
 
 type GetCommand struct {
 	Handler handler.Handler
 }
 
-func (c *GetCommand) Execute(scanner *bufio.Scanner, args []string) bool {
+func (c *GetCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
 	var target string
 	if len(args) > 0 {
 		target = args[0]
 	}
-	c.Handler.HandleGet(scanner, target)
-	return false
+	return false, c.Handler.HandleGet(scanner, target)
 }
 
 type StatusCommand struct {
 	Handler handler.Handler
 }
 
-func (c *StatusCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	c.Handler.HandleStatus()
-	return false
+func (c *StatusCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleStatus()
 }
 
 type UpsertCommand struct {
 	Handler handler.Handler
 }
 
-func (c *UpsertCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	var rawURL string
-	if len(args) > 0 {
-		rawURL = args[0]
-	}
-	c.Handler.HandleUpsert(scanner, rawURL)
-	return false
+func (c *UpsertCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleUpsert(scanner, args)
+}
+
+type ReviewCommand struct {
+	Handler handler.Handler
+}
+
+func (c *ReviewCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleReview(scanner)
 }
 
 type DeleteCommand struct {
 	Handler handler.Handler
 }
 
-func (c *DeleteCommand) Execute(scanner *bufio.Scanner, args []string) bool {
+func (c *DeleteCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
 	var target string
 	if len(args) > 0 {
 		target = args[0]
 	}
-	c.Handler.HandleDelete(scanner, target)
-	return false
+	return false, c.Handler.HandleDelete(scanner, target)
 }
 
 type UndoCommand struct {
 	Handler handler.Handler
 }
 
-func (c *UndoCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	c.Handler.HandleUndo(scanner)
-	return false
+func (c *UndoCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleUndo(scanner, args)
+}
+
+type RedoCommand struct {
+	Handler handler.Handler
+}
+
+func (c *RedoCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleRedo(scanner, args)
 }
 
 type HelpCommand struct {
 	Handler handler.Handler
 }
 
-func (c *HelpCommand) Execute(scanner *bufio.Scanner, args []string) bool {
+func (c *HelpCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
 	c.Handler.HandleHelp()
-	return false
+	return false, nil
 }
 
 type ClearCommand struct {
 	Handler handler.Handler
 }
 
-func (c *ClearCommand) Execute(scanner *bufio.Scanner, args []string) bool {
+func (c *ClearCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
 	c.Handler.HandleClear()
-	return false
+	return false, nil
 }
 
 type QuitCommand struct {
 	Handler handler.Handler
 }
 
-func (c *QuitCommand) Execute(scanner *bufio.Scanner, args []string) bool {
+func (c *QuitCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
 	c.Handler.HandleQuit()
-	return true
+	return true, nil
 }
 
 type HistoryCommand struct {
 	Handler handler.Handler
 }
 
-func (c *HistoryCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	c.Handler.HandleHistory()
-	return false
+func (c *HistoryCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleHistory()
+}
+
+type TagsCommand struct {
+	Handler handler.Handler
+}
+
+func (c *TagsCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleTags()
+}
+
+type ExportCommand struct {
+	Handler handler.Handler
+}
+
+func (c *ExportCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleExport(scanner, args)
+}
+
+type ImportCommand struct {
+	Handler handler.Handler
+}
+
+func (c *ImportCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleImport(scanner, args)
 }
 
 type SettingCommand struct {
 	Handler handler.Handler
 }
 
-func (c *SettingCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	c.Handler.HandleSetting(scanner, args)
-	return false
+func (c *SettingCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleSetting(scanner, args)
 }
 
 type VersionCommand struct {
 	Handler handler.Handler
 }
 
-func (c *VersionCommand) Execute(scanner *bufio.Scanner, args []string) bool {
+func (c *VersionCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
 	c.Handler.HandleVersion()
-	return false
+	return false, nil
 }
 
 type MigrateCommand struct {
 	Handler handler.Handler
 }
 
-func (c *MigrateCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	c.Handler.HandleMigrate(scanner)
-	return false
+func (c *MigrateCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleMigrate(scanner)
+}
+
+type DoctorCommand struct {
+	Handler handler.Handler
+}
+
+func (c *DoctorCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleDoctor(args)
+}
+
+type SimulateCommand struct {
+	Handler handler.Handler
+}
+
+func (c *SimulateCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleSimulate(args)
+}
+
+type ForecastCommand struct {
+	Handler handler.Handler
+}
+
+func (c *ForecastCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleForecast(args)
+}
+
+type OptimizeCommand struct {
+	Handler handler.Handler
+}
+
+func (c *OptimizeCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleOptimize(scanner, args)
+}
+
+type StatsCommand struct {
+	Handler handler.Handler
+}
+
+func (c *StatsCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleStats()
+}
+
+type LeechesCommand struct {
+	Handler handler.Handler
+}
+
+func (c *LeechesCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleLeeches(args)
+}
+
+type SuspendCommand struct {
+	Handler handler.Handler
+}
+
+func (c *SuspendCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleSuspend(args)
+}
+
+type UnsuspendCommand struct {
+	Handler handler.Handler
+}
+
+func (c *UnsuspendCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleUnsuspend(args)
+}
+
+type BuryCommand struct {
+	Handler handler.Handler
+}
+
+func (c *BuryCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleBury(args)
+}
+
+type ArchiveCommand struct {
+	Handler handler.Handler
+}
+
+func (c *ArchiveCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleArchive(args)
+}
+
+type StartCommand struct {
+	Handler handler.Handler
+}
+
+func (c *StartCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleStart(args)
+}
+
+type DoneCommand struct {
+	Handler handler.Handler
+}
+
+func (c *DoneCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleDone(scanner, args)
+}
+
+type PauseCommand struct {
+	Handler handler.Handler
+}
+
+func (c *PauseCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandlePause()
+}
+
+type ResumeCommand struct {
+	Handler handler.Handler
+}
+
+func (c *ResumeCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleResume()
+}
+
+type BackupCommand struct {
+	Handler handler.Handler
+}
+
+func (c *BackupCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleBackup(scanner, args)
 }
 
 type ResetCommand struct {
 	Handler handler.Handler
 }
 
-func (c *ResetCommand) Execute(scanner *bufio.Scanner, args []string) bool {
-	c.Handler.HandleReset(scanner)
-	return false
+func (c *ResetCommand) Execute(scanner *bufio.Scanner, args []string) (bool, error) {
+	return false, c.Handler.HandleReset(scanner)
 }
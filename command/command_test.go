@@ -2,65 +2,120 @@ package command
 
 import (
 	"bufio"
+	"errors"
 	"strings"
 	"testing"
 )
 
 // MockHandler implements handler.Handler for testing
 type MockHandler struct {
-	listCalled    bool
-	searchCalled  bool
-	getCalled     bool
-	statusCalled  bool
-	upsertCalled  bool
-	deleteCalled  bool
-	undoCalled    bool
-	helpCalled    bool
-	clearCalled   bool
-	quitCalled    bool
-	historyCalled bool
-	settingCalled bool
-	versionCalled bool
-	migrateCalled bool
-	resetCalled   bool
-
-	searchArgs  []string
-	getArgs     string
-	upsertArgs  string
-	deleteArgs  string
-	settingArgs []string
-}
-
-func (m *MockHandler) HandleList(scanner *bufio.Scanner) {
+	listCalled      bool
+	searchCalled    bool
+	getCalled       bool
+	statusCalled    bool
+	upsertCalled    bool
+	reviewCalled    bool
+	deleteCalled    bool
+	undoCalled      bool
+	redoCalled      bool
+	helpCalled      bool
+	clearCalled     bool
+	quitCalled      bool
+	historyCalled   bool
+	tagsCalled      bool
+	exportCalled    bool
+	importCalled    bool
+	settingCalled   bool
+	versionCalled   bool
+	migrateCalled   bool
+	doctorCalled    bool
+	backupCalled    bool
+	simulateCalled  bool
+	forecastCalled  bool
+	optimizeCalled  bool
+	statsCalled     bool
+	leechesCalled   bool
+	suspendCalled   bool
+	unsuspendCalled bool
+	buryCalled      bool
+	archiveCalled   bool
+	startCalled     bool
+	doneCalled      bool
+	pauseCalled     bool
+	resumeCalled    bool
+	resetCalled     bool
+
+	// err is returned by every handler method that can fail
+	err error
+
+	searchArgs   []string
+	getArgs      string
+	upsertArgs   []string
+	deleteArgs   string
+	undoArgs     []string
+	redoArgs     []string
+	settingArgs  []string
+	importArgs   []string
+	doctorArgs   []string
+	backupArgs   []string
+	simulateArgs []string
+	forecastArgs []string
+	optimizeArgs []string
+	leechesArgs  []string
+	stateArgs    []string
+	startArgs    []string
+	doneArgs     []string
+}
+
+func (m *MockHandler) HandleList(scanner *bufio.Scanner) error {
 	m.listCalled = true
+	return m.err
 }
 
-func (m *MockHandler) HandleSearch(scanner *bufio.Scanner, args []string) {
+func (m *MockHandler) HandleSearch(scanner *bufio.Scanner, args []string) error {
 	m.searchCalled = true
 	m.searchArgs = args
+	return m.err
 }
 
-func (m *MockHandler) HandleGet(scanner *bufio.Scanner, target string) {
+func (m *MockHandler) HandleGet(scanner *bufio.Scanner, target string) error {
 	m.getCalled = true
 	m.getArgs = target
+	return m.err
 }
 
-func (m *MockHandler) HandleStatus() {
+func (m *MockHandler) HandleStatus() error {
 	m.statusCalled = true
+	return m.err
 }
 
-func (m *MockHandler) HandleUpsert(scanner *bufio.Scanner, rawURL string) {
+func (m *MockHandler) HandleUpsert(scanner *bufio.Scanner, args []string) error {
 	m.upsertCalled = true
-	m.upsertArgs = rawURL
+	m.upsertArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleReview(scanner *bufio.Scanner) error {
+	m.reviewCalled = true
+	return m.err
 }
 
-func (m *MockHandler) HandleDelete(scanner *bufio.Scanner, target string) {
+func (m *MockHandler) HandleDelete(scanner *bufio.Scanner, target string) error {
 	m.deleteCalled = true
 	m.deleteArgs = target
+	return m.err
 }
 
-func (m *MockHandler) HandleUndo(scanner *bufio.Scanner) {
+func (m *MockHandler) HandleUndo(scanner *bufio.Scanner, args []string) error {
 	m.undoCalled = true
+	m.undoArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleRedo(scanner *bufio.Scanner, args []string) error {
+	m.redoCalled = true
+	m.redoArgs = args
+	return m.err
 }
 
 func (m *MockHandler) HandleHelp() {
@@ -75,34 +130,143 @@ func (m *MockHandler) HandleQuit() {
 	m.quitCalled = true
 }
 
-func (m *MockHandler) HandleHistory() {
+func (m *MockHandler) HandleHistory() error {
 	m.historyCalled = true
+	return m.err
 }
 
-func (m *MockHandler) HandleSetting(scanner *bufio.Scanner, args []string) {
+func (m *MockHandler) HandleTags() error {
+	m.tagsCalled = true
+	return m.err
+}
+
+func (m *MockHandler) HandleExport(scanner *bufio.Scanner, args []string) error {
+	m.exportCalled = true
+	return m.err
+}
+
+func (m *MockHandler) HandleImport(scanner *bufio.Scanner, args []string) error {
+	m.importCalled = true
+	m.importArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleSetting(scanner *bufio.Scanner, args []string) error {
 	m.settingCalled = true
 	m.settingArgs = args
+	return m.err
 }
 
-func (m *MockHandler) HandleUnknown(command string) {
+func (m *MockHandler) HandleUnknown(command string) error {
 	// Not used in command tests
+	return nil
 }
 
 func (m *MockHandler) HandleVersion() {
 	m.versionCalled = true
 }
 
-func (m *MockHandler) HandleMigrate(scanner *bufio.Scanner) {
+func (m *MockHandler) HandleMigrate(scanner *bufio.Scanner) error {
 	m.migrateCalled = true
+	return m.err
+}
+
+func (m *MockHandler) HandleSimulate(args []string) error {
+	m.simulateCalled = true
+	m.simulateArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleForecast(args []string) error {
+	m.forecastCalled = true
+	m.forecastArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleOptimize(scanner *bufio.Scanner, args []string) error {
+	m.optimizeCalled = true
+	m.optimizeArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleStats() error {
+	m.statsCalled = true
+	return m.err
+}
+
+func (m *MockHandler) HandleLeeches(args []string) error {
+	m.leechesCalled = true
+	m.leechesArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleSuspend(args []string) error {
+	m.suspendCalled = true
+	m.stateArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleUnsuspend(args []string) error {
+	m.unsuspendCalled = true
+	m.stateArgs = args
+	return m.err
 }
 
-func (m *MockHandler) HandleReset(scanner *bufio.Scanner) {
+func (m *MockHandler) HandleBury(args []string) error {
+	m.buryCalled = true
+	m.stateArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleArchive(args []string) error {
+	m.archiveCalled = true
+	m.stateArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleStart(args []string) error {
+	m.startCalled = true
+	m.startArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleDone(scanner *bufio.Scanner, args []string) error {
+	m.doneCalled = true
+	m.doneArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandlePause() error {
+	m.pauseCalled = true
+	return m.err
+}
+
+func (m *MockHandler) HandleResume() error {
+	m.resumeCalled = true
+	return m.err
+}
+
+func (m *MockHandler) HandleDoctor(args []string) error {
+	m.doctorCalled = true
+	m.doctorArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleBackup(scanner *bufio.Scanner, args []string) error {
+	m.backupCalled = true
+	m.backupArgs = args
+	return m.err
+}
+
+func (m *MockHandler) HandleReset(scanner *bufio.Scanner) error {
 	m.resetCalled = true
+	return m.err
 }
 
 func TestNewCommandRegistry(t *testing.T) {
-	unknownHandler := func(command string) {
+	unknownHandler := func(command string) error {
 		// This handler is just for testing the constructor
+		return nil
 	}
 
 	registry := NewCommandRegistry(unknownHandler)
@@ -121,7 +285,7 @@ func TestNewCommandRegistry(t *testing.T) {
 }
 
 func TestCommandRegistry_Register(t *testing.T) {
-	registry := NewCommandRegistry(func(command string) {})
+	registry := NewCommandRegistry(func(command string) error { return nil })
 	mockHandler := &MockHandler{}
 
 	// Test case-sensitive registration
@@ -138,7 +302,7 @@ func TestCommandRegistry_Register(t *testing.T) {
 }
 
 func TestCommandRegistry_Execute_ExistingCommand(t *testing.T) {
-	registry := NewCommandRegistry(func(command string) {})
+	registry := NewCommandRegistry(func(command string) error { return nil })
 	mockHandler := &MockHandler{}
 
 	// Register a command
@@ -146,7 +310,7 @@ func TestCommandRegistry_Execute_ExistingCommand(t *testing.T) {
 
 	// Execute the command
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := registry.Execute(scanner, "test", []string{})
+	quit, _ := registry.Execute(scanner, "test", []string{})
 
 	if quit {
 		t.Error("ListCommand should not return quit=true")
@@ -159,15 +323,17 @@ func TestCommandRegistry_Execute_ExistingCommand(t *testing.T) {
 
 func TestCommandRegistry_Execute_NonExistentCommand(t *testing.T) {
 	unknownHandlerCalled := false
-	unknownHandler := func(command string) {
+	unknownErr := errors.New("unknown command")
+	unknownHandler := func(command string) error {
 		unknownHandlerCalled = true
+		return unknownErr
 	}
 
 	registry := NewCommandRegistry(unknownHandler)
 
 	// Execute non-existent command
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := registry.Execute(scanner, "nonexistent", []string{})
+	quit, err := registry.Execute(scanner, "nonexistent", []string{})
 
 	if quit {
 		t.Error("Non-existent command should not return quit=true")
@@ -176,10 +342,14 @@ func TestCommandRegistry_Execute_NonExistentCommand(t *testing.T) {
 	if !unknownHandlerCalled {
 		t.Error("Unknown command handler should have been called")
 	}
+
+	if err != unknownErr {
+		t.Errorf("Expected the unknown command error, got %v", err)
+	}
 }
 
 func TestCommandRegistry_Execute_CaseInsensitive(t *testing.T) {
-	registry := NewCommandRegistry(func(command string) {})
+	registry := NewCommandRegistry(func(command string) error { return nil })
 	mockHandler := &MockHandler{}
 
 	// Register command in lowercase
@@ -209,7 +379,7 @@ func TestListCommand_Execute(t *testing.T) {
 	command := &ListCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if quit {
 		t.Error("ListCommand should not return quit=true")
@@ -226,7 +396,7 @@ func TestSearchCommand_Execute(t *testing.T) {
 
 	args := []string{"query1", "query2"}
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, args)
+	quit, _ := command.Execute(scanner, args)
 
 	if quit {
 		t.Error("SearchCommand should not return quit=true")
@@ -251,7 +421,7 @@ func TestGetCommand_Execute_WithArgs(t *testing.T) {
 
 	args := []string{"123"}
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, args)
+	quit, _ := command.Execute(scanner, args)
 
 	if quit {
 		t.Error("GetCommand should not return quit=true")
@@ -272,7 +442,7 @@ func TestGetCommand_Execute_WithoutArgs(t *testing.T) {
 
 	args := []string{}
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, args)
+	quit, _ := command.Execute(scanner, args)
 
 	if quit {
 		t.Error("GetCommand should not return quit=true")
@@ -292,7 +462,7 @@ func TestStatusCommand_Execute(t *testing.T) {
 	command := &StatusCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if quit {
 		t.Error("StatusCommand should not return quit=true")
@@ -309,7 +479,7 @@ func TestUpsertCommand_Execute_WithArgs(t *testing.T) {
 
 	args := []string{"https://leetcode.com/problems/test"}
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, args)
+	quit, _ := command.Execute(scanner, args)
 
 	if quit {
 		t.Error("UpsertCommand should not return quit=true")
@@ -319,8 +489,8 @@ func TestUpsertCommand_Execute_WithArgs(t *testing.T) {
 		t.Error("Handler.HandleUpsert should have been called")
 	}
 
-	if mockHandler.upsertArgs != "https://leetcode.com/problems/test" {
-		t.Errorf("Expected URL 'https://leetcode.com/problems/test', got '%s'", mockHandler.upsertArgs)
+	if len(mockHandler.upsertArgs) != 1 || mockHandler.upsertArgs[0] != "https://leetcode.com/problems/test" {
+		t.Errorf("Expected args [https://leetcode.com/problems/test], got %v", mockHandler.upsertArgs)
 	}
 }
 
@@ -330,7 +500,7 @@ func TestUpsertCommand_Execute_WithoutArgs(t *testing.T) {
 
 	args := []string{}
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, args)
+	quit, _ := command.Execute(scanner, args)
 
 	if quit {
 		t.Error("UpsertCommand should not return quit=true")
@@ -340,8 +510,85 @@ func TestUpsertCommand_Execute_WithoutArgs(t *testing.T) {
 		t.Error("Handler.HandleUpsert should have been called")
 	}
 
-	if mockHandler.upsertArgs != "" {
-		t.Errorf("Expected empty URL, got '%s'", mockHandler.upsertArgs)
+	if len(mockHandler.upsertArgs) != 0 {
+		t.Errorf("Expected no args, got %v", mockHandler.upsertArgs)
+	}
+}
+
+func TestUpsertCommand_Execute_ReturnsHandlerError(t *testing.T) {
+	mockHandler := &MockHandler{err: errors.New("invalid familiarity")}
+	registry := NewCommandRegistry(func(command string) error { return nil })
+	registry.Register("add", &UpsertCommand{Handler: mockHandler})
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, err := registry.Execute(scanner, "add", []string{"--familiarity=9"})
+
+	if quit {
+		t.Error("UpsertCommand should not return quit=true")
+	}
+	if err == nil {
+		t.Error("Expected the handler error to be returned")
+	}
+}
+
+func TestCommands_Execute_ReturnHandlerErrors(t *testing.T) {
+	handlerErr := errors.New("handler failed")
+	mockHandler := &MockHandler{err: handlerErr}
+
+	commands := map[string]Command{
+		"list":     &ListCommand{Handler: mockHandler},
+		"search":   &SearchCommand{Handler: mockHandler},
+		"detail":   &GetCommand{Handler: mockHandler},
+		"status":   &StatusCommand{Handler: mockHandler},
+		"review":   &ReviewCommand{Handler: mockHandler},
+		"delete":   &DeleteCommand{Handler: mockHandler},
+		"undo":     &UndoCommand{Handler: mockHandler},
+		"redo":     &RedoCommand{Handler: mockHandler},
+		"history":  &HistoryCommand{Handler: mockHandler},
+		"tags":     &TagsCommand{Handler: mockHandler},
+		"export":   &ExportCommand{Handler: mockHandler},
+		"import":   &ImportCommand{Handler: mockHandler},
+		"setting":  &SettingCommand{Handler: mockHandler},
+		"migrate":  &MigrateCommand{Handler: mockHandler},
+		"doctor":   &DoctorCommand{Handler: mockHandler},
+		"backup":   &BackupCommand{Handler: mockHandler},
+		"simulate": &SimulateCommand{Handler: mockHandler},
+		"forecast": &ForecastCommand{Handler: mockHandler},
+		"optimize": &OptimizeCommand{Handler: mockHandler},
+		"stats":    &StatsCommand{Handler: mockHandler},
+		"leeches":  &LeechesCommand{Handler: mockHandler},
+		"suspend":  &SuspendCommand{Handler: mockHandler},
+		"bury":     &BuryCommand{Handler: mockHandler},
+		"start":    &StartCommand{Handler: mockHandler},
+		"done":     &DoneCommand{Handler: mockHandler},
+		"pause":    &PauseCommand{Handler: mockHandler},
+		"resume":   &ResumeCommand{Handler: mockHandler},
+		"reset":    &ResetCommand{Handler: mockHandler},
+	}
+
+	for name, command := range commands {
+		t.Run(name, func(t *testing.T) {
+			scanner := bufio.NewScanner(strings.NewReader(""))
+			if _, err := command.Execute(scanner, []string{}); err != handlerErr {
+				t.Errorf("Expected the handler error to be returned, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReviewCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &ReviewCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{})
+
+	if quit {
+		t.Error("ReviewCommand should not return quit=true")
+	}
+
+	if !mockHandler.reviewCalled {
+		t.Error("Handler.HandleReview should have been called")
 	}
 }
 
@@ -351,7 +598,7 @@ func TestDeleteCommand_Execute_WithArgs(t *testing.T) {
 
 	args := []string{"123"}
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, args)
+	quit, _ := command.Execute(scanner, args)
 
 	if quit {
 		t.Error("DeleteCommand should not return quit=true")
@@ -372,7 +619,7 @@ func TestDeleteCommand_Execute_WithoutArgs(t *testing.T) {
 
 	args := []string{}
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, args)
+	quit, _ := command.Execute(scanner, args)
 
 	if quit {
 		t.Error("DeleteCommand should not return quit=true")
@@ -392,7 +639,7 @@ func TestUndoCommand_Execute(t *testing.T) {
 	command := &UndoCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{"--to", "3"})
 
 	if quit {
 		t.Error("UndoCommand should not return quit=true")
@@ -401,6 +648,28 @@ func TestUndoCommand_Execute(t *testing.T) {
 	if !mockHandler.undoCalled {
 		t.Error("Handler.HandleUndo should have been called")
 	}
+	if len(mockHandler.undoArgs) != 2 || mockHandler.undoArgs[1] != "3" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.undoArgs)
+	}
+}
+
+func TestRedoCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &RedoCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{"2"})
+
+	if quit {
+		t.Error("RedoCommand should not return quit=true")
+	}
+
+	if !mockHandler.redoCalled {
+		t.Error("Handler.HandleRedo should have been called")
+	}
+	if len(mockHandler.redoArgs) != 1 || mockHandler.redoArgs[0] != "2" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.redoArgs)
+	}
 }
 
 func TestHelpCommand_Execute(t *testing.T) {
@@ -408,7 +677,7 @@ func TestHelpCommand_Execute(t *testing.T) {
 	command := &HelpCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if quit {
 		t.Error("HelpCommand should not return quit=true")
@@ -424,7 +693,7 @@ func TestClearCommand_Execute(t *testing.T) {
 	command := &ClearCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if quit {
 		t.Error("ClearCommand should not return quit=true")
@@ -440,7 +709,7 @@ func TestQuitCommand_Execute(t *testing.T) {
 	command := &QuitCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if !quit {
 		t.Error("QuitCommand should return quit=true")
@@ -456,7 +725,7 @@ func TestHistoryCommand_Execute(t *testing.T) {
 	command := &HistoryCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if quit {
 		t.Error("HistoryCommand should not return quit=true")
@@ -467,8 +736,24 @@ func TestHistoryCommand_Execute(t *testing.T) {
 	}
 }
 
+func TestTagsCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &TagsCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{})
+
+	if quit {
+		t.Error("TagsCommand should not return quit=true")
+	}
+
+	if !mockHandler.tagsCalled {
+		t.Error("Handler.HandleTags should have been called")
+	}
+}
+
 func TestCommandRegistry_RegisterMultipleCommands(t *testing.T) {
-	registry := NewCommandRegistry(func(command string) {})
+	registry := NewCommandRegistry(func(command string) error { return nil })
 	mockHandler := &MockHandler{}
 
 	// Register multiple commands
@@ -503,7 +788,7 @@ func TestCommandRegistry_RegisterMultipleCommands(t *testing.T) {
 }
 
 func TestCommandRegistry_ExecuteWithScanner(t *testing.T) {
-	registry := NewCommandRegistry(func(command string) {})
+	registry := NewCommandRegistry(func(command string) error { return nil })
 	mockHandler := &MockHandler{}
 
 	// Register a command that uses the scanner
@@ -529,15 +814,31 @@ func TestCommandInterfaceCompliance(t *testing.T) {
 	var _ Command = &GetCommand{}
 	var _ Command = &StatusCommand{}
 	var _ Command = &UpsertCommand{}
+	var _ Command = &ReviewCommand{}
 	var _ Command = &DeleteCommand{}
 	var _ Command = &UndoCommand{}
+	var _ Command = &RedoCommand{}
 	var _ Command = &HelpCommand{}
 	var _ Command = &ClearCommand{}
 	var _ Command = &QuitCommand{}
 	var _ Command = &HistoryCommand{}
+	var _ Command = &TagsCommand{}
 	var _ Command = &SettingCommand{}
 	var _ Command = &VersionCommand{}
 	var _ Command = &MigrateCommand{}
+	var _ Command = &DoctorCommand{}
+	var _ Command = &BackupCommand{}
+	var _ Command = &SimulateCommand{}
+	var _ Command = &ForecastCommand{}
+	var _ Command = &OptimizeCommand{}
+	var _ Command = &LeechesCommand{}
+	var _ Command = &SuspendCommand{}
+	var _ Command = &UnsuspendCommand{}
+	var _ Command = &BuryCommand{}
+	var _ Command = &ArchiveCommand{}
+	var _ Command = &StatsCommand{}
+	var _ Command = &StartCommand{}
+	var _ Command = &DoneCommand{}
 	var _ Command = &ResetCommand{}
 }
 
@@ -546,7 +847,7 @@ func TestSettingCommand_Execute(t *testing.T) {
 	command := &SettingCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{"randomizeinterval", "true"})
+	quit, _ := command.Execute(scanner, []string{"randomizeinterval", "true"})
 
 	if quit {
 		t.Error("SettingCommand should not return quit=true")
@@ -562,7 +863,7 @@ func TestVersionCommand_Execute(t *testing.T) {
 	command := &VersionCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if quit {
 		t.Error("VersionCommand should not return quit=true")
@@ -578,7 +879,7 @@ func TestMigrateCommand_Execute(t *testing.T) {
 	command := &MigrateCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if quit {
 		t.Error("MigrateCommand should not return quit=true")
@@ -589,12 +890,217 @@ func TestMigrateCommand_Execute(t *testing.T) {
 	}
 }
 
+func TestDoctorCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &DoctorCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{"--repair"})
+
+	if quit {
+		t.Error("DoctorCommand should not return quit=true")
+	}
+
+	if !mockHandler.doctorCalled {
+		t.Error("Handler.HandleDoctor should have been called")
+	}
+	if len(mockHandler.doctorArgs) != 1 || mockHandler.doctorArgs[0] != "--repair" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.doctorArgs)
+	}
+}
+
+func TestBackupCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &BackupCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{"restore", "20240615-120000"})
+
+	if quit {
+		t.Error("BackupCommand should not return quit=true")
+	}
+
+	if !mockHandler.backupCalled {
+		t.Error("Handler.HandleBackup should have been called")
+	}
+	if len(mockHandler.backupArgs) != 2 || mockHandler.backupArgs[1] != "20240615-120000" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.backupArgs)
+	}
+}
+
+func TestSimulateCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &SimulateCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{"--days=60", "--add=20"})
+
+	if quit {
+		t.Error("SimulateCommand should not return quit=true")
+	}
+
+	if !mockHandler.simulateCalled {
+		t.Error("Handler.HandleSimulate should have been called")
+	}
+	if len(mockHandler.simulateArgs) != 2 || mockHandler.simulateArgs[1] != "--add=20" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.simulateArgs)
+	}
+}
+
+func TestForecastCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &ForecastCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{"--weeks", "--tag=graph"})
+
+	if quit {
+		t.Error("ForecastCommand should not return quit=true")
+	}
+
+	if !mockHandler.forecastCalled {
+		t.Error("Handler.HandleForecast should have been called")
+	}
+	if len(mockHandler.forecastArgs) != 2 || mockHandler.forecastArgs[1] != "--tag=graph" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.forecastArgs)
+	}
+}
+
+func TestOptimizeCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &OptimizeCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{"--seed=7"})
+
+	if quit {
+		t.Error("OptimizeCommand should not return quit=true")
+	}
+
+	if !mockHandler.optimizeCalled {
+		t.Error("Handler.HandleOptimize should have been called")
+	}
+	if len(mockHandler.optimizeArgs) != 1 || mockHandler.optimizeArgs[0] != "--seed=7" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.optimizeArgs)
+	}
+}
+
+func TestLeechesCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &LeechesCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{"clear", "3"})
+
+	if quit {
+		t.Error("LeechesCommand should not return quit=true")
+	}
+
+	if !mockHandler.leechesCalled {
+		t.Error("Handler.HandleLeeches should have been called")
+	}
+	if len(mockHandler.leechesArgs) != 2 || mockHandler.leechesArgs[1] != "3" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.leechesArgs)
+	}
+}
+
+func TestStateCommands_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	commands := map[string]Command{
+		"suspend":   &SuspendCommand{Handler: mockHandler},
+		"unsuspend": &UnsuspendCommand{Handler: mockHandler},
+		"bury":      &BuryCommand{Handler: mockHandler},
+		"archive":   &ArchiveCommand{Handler: mockHandler},
+	}
+
+	for name, command := range commands {
+		scanner := bufio.NewScanner(strings.NewReader(""))
+		if quit, _ := command.Execute(scanner, []string{"3", "2"}); quit {
+			t.Errorf("%s should not return quit=true", name)
+		}
+	}
+
+	if !mockHandler.suspendCalled || !mockHandler.unsuspendCalled || !mockHandler.buryCalled || !mockHandler.archiveCalled {
+		t.Errorf("Expected every state handler to be called, got %+v", mockHandler)
+	}
+	if len(mockHandler.stateArgs) != 2 || mockHandler.stateArgs[0] != "3" {
+		t.Errorf("Expected args to be passed through, got %v", mockHandler.stateArgs)
+	}
+}
+
+func TestStatsCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &StatsCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{})
+
+	if quit {
+		t.Error("StatsCommand should not return quit=true")
+	}
+
+	if !mockHandler.statsCalled {
+		t.Error("Handler.HandleStats should have been called")
+	}
+}
+
+func TestTimerCommands_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	scanner := bufio.NewScanner(strings.NewReader(""))
+
+	if quit, _ := (&StartCommand{Handler: mockHandler}).Execute(scanner, []string{"3"}); quit {
+		t.Error("StartCommand should not return quit=true")
+	}
+	if !mockHandler.startCalled || len(mockHandler.startArgs) != 1 || mockHandler.startArgs[0] != "3" {
+		t.Errorf("Expected Handler.HandleStart to be called with the target, got %v", mockHandler.startArgs)
+	}
+
+	if quit, _ := (&DoneCommand{Handler: mockHandler}).Execute(scanner, []string{"--familiarity=4"}); quit {
+		t.Error("DoneCommand should not return quit=true")
+	}
+	if !mockHandler.doneCalled || len(mockHandler.doneArgs) != 1 || mockHandler.doneArgs[0] != "--familiarity=4" {
+		t.Errorf("Expected Handler.HandleDone to be called with the flags, got %v", mockHandler.doneArgs)
+	}
+}
+
+func TestPauseCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &PauseCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{})
+
+	if quit {
+		t.Error("PauseCommand should not return quit=true")
+	}
+
+	if !mockHandler.pauseCalled {
+		t.Error("Handler.HandlePause should have been called")
+	}
+}
+
+func TestResumeCommand_Execute(t *testing.T) {
+	mockHandler := &MockHandler{}
+	command := &ResumeCommand{Handler: mockHandler}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	quit, _ := command.Execute(scanner, []string{})
+
+	if quit {
+		t.Error("ResumeCommand should not return quit=true")
+	}
+
+	if !mockHandler.resumeCalled {
+		t.Error("Handler.HandleResume should have been called")
+	}
+}
+
 func TestResetCommand_Execute(t *testing.T) {
 	mockHandler := &MockHandler{}
 	command := &ResetCommand{Handler: mockHandler}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	quit := command.Execute(scanner, []string{})
+	quit, _ := command.Execute(scanner, []string{})
 
 	if quit {
 		t.Error("ResetCommand should not return quit=true")
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -16,6 +17,9 @@ import (
 var (
 	defaultConfig *Config
 
+	// ErrUnknownSetting is returned for a setting name that is not in the registry
+	ErrUnknownSetting = errors.New("unknown setting")
+
 	// Environment variable loaders
 	envLoaders = []struct {
 		Key   string
@@ -23,9 +27,73 @@ var (
 	}{
 		{"LEETSOLV_QUESTIONS_FILE", func(e *Config, v string) { e.QuestionsFile = v }},
 		{"LEETSOLV_DELTAS_FILE", func(e *Config, v string) { e.DeltasFile = v }},
+		{"LEETSOLV_REDO_FILE", func(e *Config, v string) { e.RedoFile = v }},
+		{"LEETSOLV_REVIEWS_FILE", func(e *Config, v string) { e.ReviewsFile = v }},
 		{"LEETSOLV_INFO_LOG_FILE", func(e *Config, v string) { e.InfoLogFile = v }},
 		{"LEETSOLV_ERROR_LOG_FILE", func(e *Config, v string) { e.ErrorLogFile = v }},
 		{"LEETSOLV_SETTINGS_FILE", func(e *Config, v string) { e.SettingsFile = v }},
+		{"LEETSOLV_PAGE_SIZE", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.PageSize = i
+			}
+		}},
+		{"LEETSOLV_MAX_DELTA", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.MaxDelta = i
+			}
+		}},
+		{"LEETSOLV_TOP_K_DUE", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.TopKDue = i
+			}
+		}},
+		{"LEETSOLV_TOP_K_UPCOMING", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.TopKUpcoming = i
+			}
+		}},
+		{"LEETSOLV_IMPORTANCE_WEIGHT", func(e *Config, v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				e.ImportanceWeight = f
+			}
+		}},
+		{"LEETSOLV_OVERDUE_WEIGHT", func(e *Config, v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				e.OverdueWeight = f
+			}
+		}},
+		{"LEETSOLV_FAMILIARITY_WEIGHT", func(e *Config, v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				e.FamiliarityWeight = f
+			}
+		}},
+		{"LEETSOLV_REVIEW_PENALTY_WEIGHT", func(e *Config, v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				e.ReviewPenaltyWeight = f
+			}
+		}},
+		{"LEETSOLV_EASE_PENALTY_WEIGHT", func(e *Config, v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				e.EasePenaltyWeight = f
+			}
+		}},
+		{"LEETSOLV_LEECH_THRESHOLD", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.LeechThreshold = i
+			}
+		}},
+		{"LEETSOLV_LEECH_ACTION", func(e *Config, v string) { e.LeechAction = strings.ToLower(v) }},
+		{"LEETSOLV_BACKUP_DIR", func(e *Config, v string) { e.BackupDir = v }},
+		{"LEETSOLV_BACKUP_KEEP", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.BackupKeep = i
+			}
+		}},
+		{"LEETSOLV_BACKUP_MAX_AGE_DAYS", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.BackupMaxAgeDays = i
+			}
+		}},
 		{"LEETSOLV_RANDOMIZE_INTERVAL", func(e *Config, v string) {
 			if b, err := strconv.ParseBool(v); err == nil {
 				e.RandomizeInterval = b
@@ -41,77 +109,160 @@ var (
 				e.OverdueLimit = i
 			}
 		}},
+		{"LEETSOLV_LOAD_BALANCE", func(e *Config, v string) {
+			if b, err := strconv.ParseBool(v); err == nil {
+				e.LoadBalance = b
+			}
+		}},
+		{"LEETSOLV_DAILY_REVIEW_CAP", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.DailyReviewCap = i
+			}
+		}},
+		{"LEETSOLV_MAX_INTERVAL", func(e *Config, v string) {
+			if i, err := strconv.Atoi(v); err == nil {
+				e.MaxInterval = i
+			}
+		}},
+		{"LEETSOLV_SLOWER_SOLVE_PENALTY", func(e *Config, v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				e.SlowerSolvePenalty = f
+			}
+		}},
+		{"LEETSOLV_RETRY_PENALTY", func(e *Config, v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				e.RetryPenalty = f
+			}
+		}},
+		{"LEETSOLV_ALGORITHM", func(e *Config, v string) { e.Algorithm = strings.ToLower(v) }},
+		{"LEETSOLV_DESIRED_RETENTION", func(e *Config, v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				e.DesiredRetention = f
+			}
+		}},
 	}
 
 	// Settings registry (for configurable settings)
 	settingsRegistry = map[string]SettingDefinition{
-		"randomizeinterval": {
-			Name:        "RandomizeInterval",
-			Type:        "bool",
-			Description: "Enable/disable randomized interval",
-			Validator: func(valueStr string) (any, error) {
-				if boolValue, err := strconv.ParseBool(valueStr); err == nil {
-					return boolValue, nil
-				}
-				return nil, errors.New("RandomizeInterval must be a boolean value")
-			},
-			Getter: func(e *Config) any {
-				return e.RandomizeInterval
-			},
-			Setter: func(e *Config, value any) error {
-				if boolValue, ok := value.(bool); ok {
-					e.RandomizeInterval = boolValue
-					return nil
-				}
-				return errors.New("RandomizeInterval must be a boolean value")
-			},
-		},
-		"overduepenalty": {
-			Name:        "OverduePenalty",
-			Type:        "bool",
-			Description: "Enable/disable overdue penalty",
+		"randomizeinterval": boolSetting("RandomizeInterval", "Enable/disable randomized interval",
+			func(e *Config) *bool { return &e.RandomizeInterval }),
+		"overduepenalty": boolSetting("OverduePenalty", "Enable/disable overdue penalty",
+			func(e *Config) *bool { return &e.OverduePenalty }),
+		"overduelimit": intSetting("OverdueLimit", "days", "Days after which overdue questions are at risk of penalty",
+			func(e *Config) *int { return &e.OverdueLimit }),
+		"loadbalance": boolSetting("LoadBalance", "Move each next review, within a few days, to the day with the fewest reviews",
+			func(e *Config) *bool { return &e.LoadBalance }),
+		"dailyreviewcap": intSetting("DailyReviewCap", "reviews", "Most reviews to schedule on one day (0 for no cap)",
+			func(e *Config) *int { return &e.DailyReviewCap }),
+		"algorithm": {
+			Name:        "Algorithm",
+			Type:        "string",
+			Description: "Scheduling algorithm: sm2 or fsrs (takes effect on next start)",
 			Validator: func(valueStr string) (any, error) {
-				if boolValue, err := strconv.ParseBool(valueStr); err == nil {
-					return boolValue, nil
+				algorithm := strings.ToLower(valueStr)
+				if algorithm != AlgorithmSM2 && algorithm != AlgorithmFSRS {
+					return nil, errors.New("Algorithm must be sm2 or fsrs")
 				}
-				return nil, errors.New("OverduePenalty must be a boolean value")
+				return algorithm, nil
 			},
 			Getter: func(e *Config) any {
-				return e.OverduePenalty
+				return e.Algorithm
 			},
 			Setter: func(e *Config, value any) error {
-				if boolValue, ok := value.(bool); ok {
-					e.OverduePenalty = boolValue
-					return nil
+				if strValue, ok := value.(string); ok {
+					return setValidated(e, &e.Algorithm, strValue)
 				}
-				return errors.New("OverduePenalty must be a boolean value")
+				return errors.New("Algorithm must be a string value")
 			},
 		},
-		"overduelimit": {
-			Name:        "OverdueLimit",
-			Type:        "int",
-			Unit:        "days",
-			Description: "Days after which overdue questions are at risk of penalty",
+		"desiredretention": floatSetting("DesiredRetention", "", "Target recall probability for the FSRS algorithm (0.70-0.97)",
+			func(e *Config) *float64 { return &e.DesiredRetention }),
+		"maxinterval": intSetting("MaxInterval", "days", "Longest interval to schedule",
+			func(e *Config) *int { return &e.MaxInterval }),
+		"baseintervals":          levelMapSetting("BaseIntervals", "days", "First interval of a question, and the interval after a very hard review, by importance", ImportanceLevels, strconv.Atoi, func(e *Config) *map[string]int { return &e.BaseIntervals }),
+		"newquestionbonus":       levelMapSetting("NewQuestionBonus", "days", "Days added to the first interval of a question, by familiarity", FamiliarityLevels, strconv.Atoi, func(e *Config) *map[string]int { return &e.NewQuestionBonus }),
+		"memorymultipliers":      levelMapSetting("MemoryMultipliers", "", "Factor applied to every interval, by memory use", MemoryLevels, parseFloat, func(e *Config) *map[string]float64 { return &e.MemoryMultipliers }),
+		"starteasefactors":       levelMapSetting("StartEaseFactors", "", "Ease factor of a new question, by importance", ImportanceLevels, parseFloat, func(e *Config) *map[string]float64 { return &e.StartEaseFactors }),
+		"importanceeasebonus":    levelMapSetting("ImportanceEaseBonus", "", "Ease factor change after each review, by importance", ImportanceLevels, parseFloat, func(e *Config) *map[string]float64 { return &e.ImportanceEaseBonus }),
+		"familiarityeasepenalty": levelMapSetting("FamiliarityEasePenalty", "", "Ease factor change after each review, by familiarity", FamiliarityLevels, parseFloat, func(e *Config) *map[string]float64 { return &e.FamiliarityEasePenalty }),
+		"memoryeasepenalty":      levelMapSetting("MemoryEasePenalty", "", "Ease factor change after each review, by memory use", MemoryLevels, parseFloat, func(e *Config) *map[string]float64 { return &e.MemoryEasePenalty }),
+		"slowersolvepenalty": floatSetting("SlowerSolvePenalty", "", "Ease factor lost when a solve takes longer than the last timed solve (0 turns it off)",
+			func(e *Config) *float64 { return &e.SlowerSolvePenalty }),
+		"retrypenalty": floatSetting("RetryPenalty", "", "Ease factor lost when a solve does not pass on the first submission (0 turns it off)",
+			func(e *Config) *float64 { return &e.RetryPenalty }),
+		"importanceweight": inRange[float64](floatSetting("ImportanceWeight", "", "Priority score weight of importance (-10 to 10)",
+			func(e *Config) *float64 { return &e.ImportanceWeight }), -maxWeight, maxWeight),
+		"overdueweight": inRange[float64](floatSetting("OverdueWeight", "", "Priority score weight of overdue days (-10 to 10)",
+			func(e *Config) *float64 { return &e.OverdueWeight }), -maxWeight, maxWeight),
+		"familiarityweight": inRange[float64](floatSetting("FamiliarityWeight", "", "Priority score weight of difficulty (-10 to 10)",
+			func(e *Config) *float64 { return &e.FamiliarityWeight }), -maxWeight, maxWeight),
+		"reviewpenaltyweight": inRange[float64](floatSetting("ReviewPenaltyWeight", "", "Priority score weight of the review count (-10 to 10)",
+			func(e *Config) *float64 { return &e.ReviewPenaltyWeight }), -maxWeight, maxWeight),
+		"easepenaltyweight": inRange[float64](floatSetting("EasePenaltyWeight", "", "Priority score weight of the ease factor (-10 to 10)",
+			func(e *Config) *float64 { return &e.EasePenaltyWeight }), -maxWeight, maxWeight),
+		"topkdue": inRange(intSetting("TopKDue", "questions", "Due questions to show in status (1-100)",
+			func(e *Config) *int { return &e.TopKDue }), 1, maxTopK),
+		"topkupcoming": inRange(intSetting("TopKUpcoming", "questions", "Upcoming questions to show in status (1-100)",
+			func(e *Config) *int { return &e.TopKUpcoming }), 1, maxTopK),
+		"pagesize": inRange(intSetting("PageSize", "questions", "Questions per page when listing (1-100)",
+			func(e *Config) *int { return &e.PageSize }), 1, maxPageSize),
+		"maxdelta": inRange(intSetting("MaxDelta", "actions", "Actions kept in the history for undo, and in the redo history (1-1000)",
+			func(e *Config) *int { return &e.MaxDelta }), 1, maxMaxDelta),
+		"leechthreshold": intSetting("LeechThreshold", "lapses", "Hard or very hard reviews after which a question is a leech (0 turns detection off)",
+			func(e *Config) *int { return &e.LeechThreshold }),
+		"leechaction": {
+			Name:        "LeechAction",
+			Type:        "string",
+			Description: "What happens to a new leech: tag, suspend or top (of the due questions)",
 			Validator: func(valueStr string) (any, error) {
-				if intValue, err := strconv.Atoi(valueStr); err == nil {
-					return intValue, nil
+				action := strings.ToLower(valueStr)
+				if !slices.Contains(LeechActions, action) {
+					return nil, errors.New("LeechAction must be tag, suspend or top")
 				}
-				return nil, errors.New("OverdueLimit must be an integer value")
+				return action, nil
 			},
 			Getter: func(e *Config) any {
-				return e.OverdueLimit
+				return e.LeechAction
 			},
 			Setter: func(e *Config, value any) error {
-				if intValue, ok := value.(int); ok {
-					e.OverdueLimit = intValue
-					return e.validate()
+				if strValue, ok := value.(string); ok {
+					return setValidated(e, &e.LeechAction, strValue)
 				}
-				return errors.New("OverdueLimit must be an integer value")
+				return errors.New("LeechAction must be a string value")
 			},
 		},
+		"backupkeep": intSetting("BackupKeep", "", "Number of data backups to keep",
+			func(e *Config) *int { return &e.BackupKeep }),
+		"backupmaxagedays": intSetting("BackupMaxAgeDays", "days", "Days after which data backups are deleted (0 keeps them regardless of age)",
+			func(e *Config) *int { return &e.BackupMaxAgeDays }),
 	}
 )
 
+// Supported scheduling algorithms
+const (
+	AlgorithmSM2  = "sm2"
+	AlgorithmFSRS = "fsrs"
+)
+
+// Actions taken on a question when it becomes a leech
+const (
+	LeechActionTag     = "tag"     // Tag it with "leech"
+	LeechActionSuspend = "suspend" // Leave it out of the due lists until it is cleared
+	LeechActionTop     = "top"     // Put it ahead of every other due question
+)
+
+// LeechActions lists the valid leech actions
+var LeechActions = []string{LeechActionTag, LeechActionSuspend, LeechActionTop}
+
+// Upper bounds of the list and history sizes, and the bound of the priority score weights either
+// way. They apply when a setting is changed, not to a settings file written before they existed.
+const (
+	maxPageSize = 100
+	maxMaxDelta = 1000
+	maxTopK     = 100
+	maxWeight   = 10
+)
+
 // initDefaultConfig initializes the default configuration with proper file paths
 func initDefaultConfig() error {
 	homeDir, err := os.UserHomeDir()
@@ -130,6 +281,8 @@ func initDefaultConfig() error {
 		// Default data files with absolute paths
 		QuestionsFile: filepath.Join(configDir, "questions.json"),
 		DeltasFile:    filepath.Join(configDir, "deltas.json"),
+		RedoFile:      filepath.Join(configDir, "redo.json"),
+		ReviewsFile:   filepath.Join(configDir, "reviews.json"),
 		InfoLogFile:   filepath.Join(configDir, "info.log"),
 		ErrorLogFile:  filepath.Join(configDir, "error.log"),
 		SettingsFile:  filepath.Join(configDir, "settings.json"),
@@ -156,6 +309,69 @@ func initDefaultConfig() error {
 			RandomizeInterval: true,  // Enable/disable randomized interval
 			OverduePenalty:    false, // Enable/disable overdue penalty
 			OverdueLimit:      7,     // Days after which overdue questions are at risk of penalty
+			LoadBalance:       false, // Enable/disable balancing reviews across days
+			DailyReviewCap:    0,     // Most reviews scheduled on one day; 0 for no cap
+			Algorithm:         AlgorithmSM2,
+			DesiredRetention:  0.9, // Target recall probability for FSRS
+		},
+		// SM-2 settings
+		SM2: SM2{
+			MaxInterval: 90,
+			BaseIntervals: map[string]int{
+				"low":      8,
+				"medium":   6,
+				"high":     5,
+				"critical": 4,
+			},
+			NewQuestionBonus: map[string]int{
+				"veryHard": 0,
+				"hard":     0,
+				"medium":   2,
+				"easy":     5,
+				"veryEasy": 7,
+			},
+			MemoryMultipliers: map[string]float64{
+				"reasoned": 1.00, // don't change
+				"partial":  1.10, // give more forgetting time
+				"full":     1.25, // give even more forgetting time
+			},
+			StartEaseFactors: map[string]float64{
+				"low":      2.0,
+				"medium":   1.9,
+				"high":     1.8,
+				"critical": 1.7,
+			},
+			ImportanceEaseBonus: map[string]float64{
+				"low":      0.15,
+				"medium":   0.10,
+				"high":     0.05,
+				"critical": 0.03,
+			},
+			FamiliarityEasePenalty: map[string]float64{
+				"veryHard": -0.40,
+				"hard":     -0.25,
+				"medium":   -0.10,
+				"easy":     0.05,
+				"veryEasy": 0.15,
+			},
+			MemoryEasePenalty: map[string]float64{
+				"reasoned": 0.00,
+				"partial":  -0.02,
+				"full":     -0.05,
+			},
+			SlowerSolvePenalty: 0.05,
+			RetryPenalty:       0.05,
+		},
+		// Leech settings
+		Leech: Leech{
+			LeechThreshold: 4, // Hard or very hard reviews that make a question a leech
+			LeechAction:    LeechActionTag,
+		},
+		// Backup settings
+		Backup: Backup{
+			BackupDir:        filepath.Join(configDir, "backups"),
+			BackupKeep:       20, // Snapshots to keep
+			BackupMaxAgeDays: 30, // Days after which snapshots are deleted
 		},
 	}
 
@@ -165,9 +381,10 @@ func initDefaultConfig() error {
 // SettingDefinition defines a configurable setting
 type SettingDefinition struct {
 	Name        string
-	Type        string // "bool", "int", "float64", "string"
+	Type        string // "bool", "int", "float64", "string", "map[string]int", "map[string]float64"
 	Unit        string
 	Description string
+	Keys        []string // Keys of a map setting, in level order
 	Validator   func(string) (any, error)
 	Getter      func(*Config) any
 	Setter      func(*Config, any) error
@@ -199,6 +416,53 @@ type SRS struct {
 	RandomizeInterval bool `json:"randomizeInterval"`
 	OverduePenalty    bool `json:"overduePenalty"`
 	OverdueLimit      int  `json:"overdueLimit"`
+	// Move next reviews to the least loaded day of a window around the interval
+	LoadBalance bool `json:"loadBalance"`
+	// Most reviews to schedule on one day; 0 for no cap
+	DailyReviewCap int `json:"dailyReviewCap"`
+	// Scheduling algorithm ("sm2" or "fsrs")
+	Algorithm string `json:"algorithm"`
+	// Target recall probability used by the FSRS algorithm
+	DesiredRetention float64 `json:"desiredRetention"`
+}
+
+// SM2 holds the constants of the SM-2 algorithm. Its maps are keyed by level name, see
+// ImportanceLevels, FamiliarityLevels and MemoryLevels.
+type SM2 struct {
+	// Longest interval to schedule, in days
+	MaxInterval int `json:"maxInterval"`
+	// First interval of a question by importance, also used after a very hard review
+	BaseIntervals map[string]int `json:"baseIntervals"`
+	// Days added to the first interval by familiarity
+	NewQuestionBonus map[string]int `json:"newQuestionBonus"`
+	// Factor applied to every interval by memory use
+	MemoryMultipliers map[string]float64 `json:"memoryMultipliers"`
+	// Ease factor of a new question by importance
+	StartEaseFactors map[string]float64 `json:"startEaseFactors"`
+	// Ease factor changes after each review
+	ImportanceEaseBonus    map[string]float64 `json:"importanceEaseBonus"`
+	FamiliarityEasePenalty map[string]float64 `json:"familiarityEasePenalty"`
+	MemoryEasePenalty      map[string]float64 `json:"memoryEasePenalty"`
+	// Ease factor lost when a solve takes longer than the last timed solve; 0 turns it off
+	SlowerSolvePenalty float64 `json:"slowerSolvePenalty"`
+	// Ease factor lost when a solve does not pass on the first submission; 0 turns it off
+	RetryPenalty float64 `json:"retryPenalty"`
+}
+
+type Leech struct {
+	// Hard or very hard reviews after which a question is a leech; 0 turns detection off
+	LeechThreshold int `json:"leechThreshold"`
+	// What happens to a new leech ("tag", "suspend" or "top")
+	LeechAction string `json:"leechAction"`
+}
+
+type Backup struct {
+	// Directory holding the data file snapshots
+	BackupDir string `json:"backupDir"`
+	// Number of snapshots to keep
+	BackupKeep int `json:"backupKeep"`
+	// Days after which snapshots are deleted; 0 keeps them regardless of age
+	BackupMaxAgeDays int `json:"backupMaxAgeDays"`
 }
 
 type Config struct {
@@ -207,6 +471,8 @@ type Config struct {
 	// Default data files
 	QuestionsFile string `json:"questionsFile"`
 	DeltasFile    string `json:"deltasFile"`
+	RedoFile      string `json:"redoFile"`
+	ReviewsFile   string `json:"reviewsFile"`
 	InfoLogFile   string `json:"infoLogFile"`
 	ErrorLogFile  string `json:"errorLogFile"`
 	SettingsFile  string `json:"settingsFile"`
@@ -218,6 +484,12 @@ type Config struct {
 	DuePriority
 	// SRS settings
 	SRS
+	// SM-2 settings
+	SM2
+	// Leech settings
+	Leech
+	// Backup settings
+	Backup
 }
 
 func NewConfig(file fileutil.FileUtil) (*Config, error) {
@@ -227,23 +499,41 @@ func NewConfig(file fileutil.FileUtil) (*Config, error) {
 	}
 
 	config := &Config{file: file}
+	if err := config.load(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Reload reads the configuration again, e.g. after the settings file was restored from a backup.
+// The current values are kept when the new configuration fails to load.
+func (e *Config) Reload() error {
+	config := &Config{file: e.file}
+	if err := config.load(); err != nil {
+		return err
+	}
+	*e = *config
+	return nil
+}
 
+// load fills the configuration from the defaults, the environment and the settings file
+func (e *Config) load() error {
 	// Load default configuration first
-	if err := config.loadFromDefault(); err != nil {
-		return nil, fmt.Errorf("failed to load default configuration: %v", err)
+	if err := e.loadFromDefault(); err != nil {
+		return fmt.Errorf("failed to load default configuration: %v", err)
 	}
 
 	// Load environment variable overrides first
-	if err := config.loadFromEnvironment(); err != nil {
-		return nil, fmt.Errorf("failed to load environment variables: %v", err)
+	if err := e.loadFromEnvironment(); err != nil {
+		return fmt.Errorf("failed to load environment variables: %v", err)
 	}
 
 	// Then load user settings file (which can override env vars)
-	if err := config.loadFromFile(); err != nil {
-		return nil, fmt.Errorf("failed to load settings file: %w", err)
+	if err := e.loadFromFile(); err != nil {
+		return fmt.Errorf("failed to load settings file: %w", err)
 	}
 
-	return config, nil
+	return nil
 }
 
 func (e *Config) Save() error {
@@ -291,6 +581,30 @@ func (e *Config) validate() error {
 	if e.OverdueLimit <= 0 {
 		return errors.New("OverdueLimit must be positive")
 	}
+	if e.DailyReviewCap < 0 {
+		return errors.New("DailyReviewCap must not be negative")
+	}
+	if e.Algorithm != AlgorithmSM2 && e.Algorithm != AlgorithmFSRS {
+		return fmt.Errorf("Algorithm must be %q or %q", AlgorithmSM2, AlgorithmFSRS)
+	}
+	if e.DesiredRetention < 0.7 || e.DesiredRetention > 0.97 {
+		return errors.New("DesiredRetention must be between 0.70 and 0.97")
+	}
+	if err := e.validateSM2(); err != nil {
+		return err
+	}
+	if e.LeechThreshold < 0 {
+		return errors.New("LeechThreshold must not be negative")
+	}
+	if !slices.Contains(LeechActions, e.LeechAction) {
+		return fmt.Errorf("LeechAction must be %q, %q or %q", LeechActionTag, LeechActionSuspend, LeechActionTop)
+	}
+	if e.BackupKeep <= 0 {
+		return errors.New("BackupKeep must be positive")
+	}
+	if e.BackupMaxAgeDays < 0 {
+		return errors.New("BackupMaxAgeDays must not be negative")
+	}
 	return nil
 }
 
@@ -303,7 +617,7 @@ func (e *Config) GetSettingsRegistry() map[string]SettingDefinition {
 func (e *Config) GetSettingValue(settingName string) (any, error) {
 	setting, exists := settingsRegistry[strings.ToLower(settingName)]
 	if !exists {
-		return nil, fmt.Errorf("unknown setting: %s", settingName)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSetting, settingName)
 	}
 	return setting.Getter(e), nil
 }
@@ -312,7 +626,7 @@ func (e *Config) GetSettingValue(settingName string) (any, error) {
 func (e *Config) SetSettingValue(settingName string, value any) error {
 	setting, exists := settingsRegistry[strings.ToLower(settingName)]
 	if !exists {
-		return fmt.Errorf("unknown setting: %s", settingName)
+		return fmt.Errorf("%w: %s", ErrUnknownSetting, settingName)
 	}
 	return setting.Setter(e, value)
 }
@@ -321,7 +635,7 @@ func (e *Config) SetSettingValue(settingName string, value any) error {
 func (e *Config) GetSettingInfo(settingName string) (*SettingDefinition, error) {
 	setting, exists := settingsRegistry[strings.ToLower(settingName)]
 	if !exists {
-		return nil, fmt.Errorf("unknown setting: %s", settingName)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSetting, settingName)
 	}
 	return &setting, nil
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -77,6 +78,52 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestReload(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "test_settings_*.json")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+	tempFile.Close()
+
+	os.Setenv("LEETSOLV_SETTINGS_FILE", tempFile.Name())
+	defer os.Unsetenv("LEETSOLV_SETTINGS_FILE")
+
+	fileUtil := fileutil.NewJSONFileUtil()
+	config, err := NewConfig(fileUtil)
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	// Another instance writes the settings file, as a backup restore does
+	other, err := NewConfig(fileUtil)
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	other.PageSize = 42
+	if err := other.Save(); err != nil {
+		t.Fatalf("Failed to save configuration: %v", err)
+	}
+
+	if err := config.Reload(); err != nil {
+		t.Fatalf("Failed to reload configuration: %v", err)
+	}
+	if config.PageSize != 42 {
+		t.Errorf("Expected PageSize to be 42 after reload, got %d", config.PageSize)
+	}
+
+	// A settings file that fails validation leaves the current values in place
+	if err := os.WriteFile(tempFile.Name(), []byte(`{"pageSize": -1}`), 0644); err != nil {
+		t.Fatalf("Failed to write settings file: %v", err)
+	}
+	if err := config.Reload(); err == nil {
+		t.Error("Expected an error when reloading an invalid settings file")
+	}
+	if config.PageSize != 42 {
+		t.Errorf("Expected PageSize to stay 42 after a failed reload, got %d", config.PageSize)
+	}
+}
+
 func TestValidation(t *testing.T) {
 	fileUtil := &MockFileUtil{}
 	config, err := NewConfig(fileUtil)
@@ -247,3 +294,291 @@ func TestGetSettingInfo(t *testing.T) {
 		t.Error("Expected error for unknown setting")
 	}
 }
+
+func TestAlgorithmSettings(t *testing.T) {
+	fileUtil := &MockFileUtil{}
+	config, err := NewConfig(fileUtil)
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	if config.Algorithm != AlgorithmSM2 {
+		t.Errorf("Expected default Algorithm to be %q, got %q", AlgorithmSM2, config.Algorithm)
+	}
+
+	// Validator normalizes case and rejects unknown algorithms
+	info, err := config.GetSettingInfo("algorithm")
+	if err != nil {
+		t.Fatalf("Failed to get setting info: %v", err)
+	}
+	value, err := info.Validator("FSRS")
+	if err != nil {
+		t.Fatalf("Expected FSRS to be valid: %v", err)
+	}
+	if err := config.SetSettingValue("algorithm", value); err != nil {
+		t.Fatalf("Failed to set algorithm: %v", err)
+	}
+	if config.Algorithm != AlgorithmFSRS {
+		t.Errorf("Expected Algorithm to be %q, got %q", AlgorithmFSRS, config.Algorithm)
+	}
+	if _, err := info.Validator("leitner"); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+
+	// Desired retention must stay within the supported range
+	if err := config.SetSettingValue("desiredretention", 0.85); err != nil {
+		t.Fatalf("Failed to set desired retention: %v", err)
+	}
+	if config.DesiredRetention != 0.85 {
+		t.Errorf("Expected DesiredRetention to be 0.85, got %v", config.DesiredRetention)
+	}
+	config.DesiredRetention = 0.5
+	if err := config.validate(); err == nil {
+		t.Error("Expected error for desired retention below range")
+	}
+}
+
+func TestLoadBalanceSettings(t *testing.T) {
+	t.Setenv("LEETSOLV_LOAD_BALANCE", "true")
+	t.Setenv("LEETSOLV_DAILY_REVIEW_CAP", "15")
+
+	config, err := NewConfig(&MockFileUtil{})
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if !config.LoadBalance || config.DailyReviewCap != 15 {
+		t.Errorf("Expected load balancing with a cap of 15 from the environment, got %t and %d", config.LoadBalance, config.DailyReviewCap)
+	}
+
+	if err := config.SetSettingValue("dailyreviewcap", 0); err != nil {
+		t.Errorf("Expected no cap to be valid: %v", err)
+	}
+	if err := config.SetSettingValue("dailyreviewcap", -1); err == nil {
+		t.Error("Expected error for a negative daily review cap")
+	}
+	if err := config.SetSettingValue("loadbalance", false); err != nil || config.LoadBalance {
+		t.Errorf("Expected load balancing to be turned off, got %t and %v", config.LoadBalance, err)
+	}
+}
+
+func TestLeechSettings(t *testing.T) {
+	t.Setenv("LEETSOLV_LEECH_THRESHOLD", "6")
+	t.Setenv("LEETSOLV_LEECH_ACTION", "Suspend")
+
+	config, err := NewConfig(&MockFileUtil{})
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if config.LeechThreshold != 6 || config.LeechAction != LeechActionSuspend {
+		t.Errorf("Expected a threshold of 6 and the suspend action from the environment, got %d and %s", config.LeechThreshold, config.LeechAction)
+	}
+
+	info, _ := config.GetSettingInfo("leechaction")
+	value, err := info.Validator("TOP")
+	if err != nil || config.SetSettingValue("leechaction", value) != nil || config.LeechAction != LeechActionTop {
+		t.Errorf("Expected the top action, got %s and %v", config.LeechAction, err)
+	}
+	if _, err := info.Validator("delete"); err == nil {
+		t.Error("Expected error parsing an unknown leech action")
+	}
+	if err := config.SetSettingValue("leechaction", "delete"); err == nil || config.LeechAction != LeechActionTop {
+		t.Errorf("Expected an unknown leech action to be refused, got %s", config.LeechAction)
+	}
+
+	if err := config.SetSettingValue("leechthreshold", 0); err != nil {
+		t.Errorf("Expected a threshold of 0 to turn detection off: %v", err)
+	}
+	if err := config.SetSettingValue("leechthreshold", -1); err == nil {
+		t.Error("Expected error for a negative leech threshold")
+	}
+}
+
+func TestSM2Settings(t *testing.T) {
+	t.Setenv("LEETSOLV_MAX_INTERVAL", "60")
+
+	config, err := NewConfig(&MockFileUtil{})
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if config.MaxInterval != 60 || config.BaseIntervals["medium"] != 6 || config.NewQuestionBonus["veryEasy"] != 7 {
+		t.Errorf("Expected MaxInterval 60 from the environment and default maps, got %d, %v and %v",
+			config.MaxInterval, config.BaseIntervals, config.NewQuestionBonus)
+	}
+
+	info, _ := config.GetSettingInfo("baseintervals")
+	value, err := info.Validator("Medium=7, critical=3")
+	if err != nil {
+		t.Fatalf("Failed to parse level pairs: %v", err)
+	}
+	if err := config.SetSettingValue("baseintervals", value); err != nil {
+		t.Fatalf("Failed to set BaseIntervals: %v", err)
+	}
+	if config.BaseIntervals["medium"] != 7 || config.BaseIntervals["critical"] != 3 || config.BaseIntervals["low"] != 8 {
+		t.Errorf("Expected only the given levels to change, got %v", config.BaseIntervals)
+	}
+
+	// An interval past MaxInterval is refused and leaves the map as it was
+	if err := config.SetSettingValue("baseintervals", map[string]int{"low": 61}); err == nil {
+		t.Error("Expected error for a base interval longer than MaxInterval")
+	}
+	if config.BaseIntervals["low"] != 8 {
+		t.Errorf("Expected the refused value not to be kept, got %v", config.BaseIntervals)
+	}
+
+	for _, input := range []string{"", "medium", "urgent=2", "medium=fast"} {
+		if _, err := info.Validator(input); err == nil {
+			t.Errorf("Expected error parsing %q", input)
+		}
+	}
+
+	info, _ = config.GetSettingInfo("starteasefactors")
+	if info.Type != "map[string]float64" || len(info.Keys) != 4 {
+		t.Errorf("Expected a float map keyed by importance, got %s with %v", info.Type, info.Keys)
+	}
+	if err := config.SetSettingValue("starteasefactors", map[string]float64{"low": 3.0}); err == nil {
+		t.Error("Expected error for a start ease factor above the maximum")
+	}
+
+	config.FamiliarityEasePenalty["medum"] = 0.1
+	if err := config.validate(); err == nil {
+		t.Error("Expected error for an unknown level")
+	}
+}
+
+func TestPriorityAndListSettings(t *testing.T) {
+	t.Setenv("LEETSOLV_IMPORTANCE_WEIGHT", "2.5")
+	t.Setenv("LEETSOLV_PAGE_SIZE", "10")
+
+	config, err := NewConfig(&MockFileUtil{})
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if config.ImportanceWeight != 2.5 || config.PageSize != 10 {
+		t.Errorf("Expected ImportanceWeight 2.5 and PageSize 10 from the environment, got %v and %d", config.ImportanceWeight, config.PageSize)
+	}
+
+	info, _ := config.GetSettingInfo("overdueweight")
+	if info.Type != "float64" {
+		t.Errorf("Expected a float64 setting, got %s", info.Type)
+	}
+	value, err := info.Validator("-1.5")
+	if err != nil {
+		t.Fatalf("Failed to parse a weight: %v", err)
+	}
+	if err := config.SetSettingValue("overdueweight", value); err != nil || config.OverdueWeight != -1.5 {
+		t.Errorf("Expected OverdueWeight to be -1.5, got %v and %v", config.OverdueWeight, err)
+	}
+	for _, input := range []string{"heavy", "NaN", "Inf"} {
+		if _, err := info.Validator(input); err == nil {
+			t.Errorf("Expected error parsing %q", input)
+		}
+	}
+
+	// Values out of range are refused and leave the setting as it was
+	for name, value := range map[string]any{
+		"easepenaltyweight": 10.5,
+		"topkdue":           0,
+		"topkupcoming":      101,
+		"pagesize":          0,
+		"maxdelta":          1001,
+	} {
+		previous, _ := config.GetSettingValue(name)
+		if err := config.SetSettingValue(name, value); err == nil {
+			t.Errorf("Expected error setting %s to %v", name, value)
+		}
+		if current, _ := config.GetSettingValue(name); current != previous {
+			t.Errorf("Expected %s to stay %v, got %v", name, previous, current)
+		}
+	}
+
+	if err := config.SetSettingValue("pagesize", "10"); err == nil {
+		t.Error("Expected error for a value of the wrong type")
+	}
+}
+
+func TestLegacySettingsOutOfRange(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "test_settings_*.json")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+	tempFile.Close()
+
+	// Values past the setting bounds, as hand-edited before the bounds existed
+	legacy := `{"pageSize": 200, "maxDelta": 5000, "topKDue": 500, "importanceWeight": 20}`
+	if err := os.WriteFile(tempFile.Name(), []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write settings file: %v", err)
+	}
+	t.Setenv("LEETSOLV_SETTINGS_FILE", tempFile.Name())
+
+	config, err := NewConfig(fileutil.NewJSONFileUtil())
+	if err != nil {
+		t.Fatalf("Expected a legacy settings file to load, got %v", err)
+	}
+	if config.PageSize != 200 || config.MaxDelta != 5000 || config.TopKDue != 500 || config.ImportanceWeight != 20 {
+		t.Errorf("Expected the legacy values to be kept, got %d, %d, %d and %v",
+			config.PageSize, config.MaxDelta, config.TopKDue, config.ImportanceWeight)
+	}
+
+	// Other settings can still be changed, while the bounds apply to new values
+	if err := config.SetSettingValue("overduelimit", 10); err != nil {
+		t.Errorf("Expected another setting to change, got %v", err)
+	}
+	if err := config.SetSettingValue("pagesize", 300); err == nil {
+		t.Error("Expected error setting PageSize past its bound")
+	}
+}
+
+func TestResetSettingValue(t *testing.T) {
+	config, err := NewConfig(&MockFileUtil{})
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	if diffs := config.SettingDiffs(); len(diffs) != 0 {
+		t.Errorf("Expected no settings to differ from the defaults, got %+v", diffs)
+	}
+
+	config.SetSettingValue("pagesize", 20)
+	config.SetSettingValue("baseintervals", map[string]int{"medium": 4})
+	diffs := config.SettingDiffs()
+	if len(diffs) != 2 || diffs[0].Setting.Name != "BaseIntervals" || diffs[1].Value != 20 || diffs[1].Default != 5 {
+		t.Fatalf("Expected BaseIntervals and PageSize to differ, got %+v", diffs)
+	}
+
+	if err := config.ResetSettingValue("PageSize"); err != nil || config.PageSize != 5 {
+		t.Errorf("Expected PageSize back to 5, got %d and %v", config.PageSize, err)
+	}
+	if err := config.ResetSettingValue("baseintervals"); err != nil || config.BaseIntervals["medium"] != 6 {
+		t.Errorf("Expected BaseIntervals back to the defaults, got %v and %v", config.BaseIntervals, err)
+	}
+	if diffs := config.SettingDiffs(); len(diffs) != 0 {
+		t.Errorf("Expected no settings to differ after the resets, got %+v", diffs)
+	}
+
+	if err := config.ResetSettingValue("unknownsetting"); !errors.Is(err, ErrUnknownSetting) {
+		t.Errorf("Expected ErrUnknownSetting, got %v", err)
+	}
+}
+
+func TestSolvePenaltySettings(t *testing.T) {
+	t.Setenv("LEETSOLV_SLOWER_SOLVE_PENALTY", "0.2")
+
+	config, err := NewConfig(&MockFileUtil{})
+	if err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+	if config.SlowerSolvePenalty != 0.2 || config.RetryPenalty != 0.05 {
+		t.Errorf("Expected a slower solve penalty of 0.2 from the environment and the default retry penalty, got %v and %v",
+			config.SlowerSolvePenalty, config.RetryPenalty)
+	}
+
+	if err := config.SetSettingValue("retrypenalty", 0.0); err != nil {
+		t.Errorf("Expected a retry penalty of 0 to turn it off: %v", err)
+	}
+	for _, value := range []float64{-0.1, 1.5} {
+		if err := config.SetSettingValue("slowersolvepenalty", value); err == nil {
+			t.Errorf("Expected error for a slower solve penalty of %v", value)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SettingDiff is a setting whose value differs from its default
+type SettingDiff struct {
+	Setting SettingDefinition
+	Value   any
+	Default any
+}
+
+// setValidated sets a setting field and validates the configuration, putting the previous
+// value back when the new one is invalid
+func setValidated[V any](e *Config, field *V, value V) error {
+	previous := *field
+	*field = value
+	if err := e.validate(); err != nil {
+		*field = previous
+		return err
+	}
+	return nil
+}
+
+// inRange limits the values a setting can be changed to. Unlike validate, it does not run on load.
+func inRange[V int | float64](definition SettingDefinition, min, max V) SettingDefinition {
+	setter := definition.Setter
+	definition.Setter = func(e *Config, value any) error {
+		if typed, ok := value.(V); ok && (typed < min || typed > max) {
+			return fmt.Errorf("%s must be between %v and %v", definition.Name, min, max)
+		}
+		return setter(e, value)
+	}
+	return definition
+}
+
+func boolSetting(name, description string, field func(*Config) *bool) SettingDefinition {
+	return scalarSetting(name, "", description, strconv.ParseBool, "a boolean value", field)
+}
+
+func intSetting(name, unit, description string, field func(*Config) *int) SettingDefinition {
+	return scalarSetting(name, unit, description, strconv.Atoi, "an integer value", field)
+}
+
+func floatSetting(name, unit, description string, field func(*Config) *float64) SettingDefinition {
+	return scalarSetting(name, unit, description, parseFloat, "a number", field)
+}
+
+// scalarSetting defines a setting holding a single value of a Go type, described by kind in errors
+func scalarSetting[V bool | int | float64](name, unit, description string, parse func(string) (V, error), kind string, field func(*Config) *V) SettingDefinition {
+	var zero V
+	return SettingDefinition{
+		Name:        name,
+		Type:        fmt.Sprintf("%T", zero),
+		Unit:        unit,
+		Description: description,
+		Validator: func(valueStr string) (any, error) {
+			if value, err := parse(valueStr); err == nil {
+				return value, nil
+			}
+			return nil, fmt.Errorf("%s must be %s", name, kind)
+		},
+		Getter: func(e *Config) any {
+			return *field(e)
+		},
+		Setter: func(e *Config, value any) error {
+			if typed, ok := value.(V); ok {
+				return setValidated(e, field(e), typed)
+			}
+			return fmt.Errorf("%s must be %s", name, kind)
+		},
+	}
+}
+
+// levelMapSetting defines a map setting keyed by levels. Its value is given as level=value
+// pairs separated by commas, and only the given levels change.
+func levelMapSetting[V int | float64](name, unit, description string, levels []string, parse func(string) (V, error), field func(*Config) *map[string]V) SettingDefinition {
+	var zero V
+	return SettingDefinition{
+		Name:        name,
+		Type:        fmt.Sprintf("map[string]%T", zero),
+		Unit:        unit,
+		Description: description,
+		Keys:        levels,
+		Validator: func(valueStr string) (any, error) {
+			return parseLevelMap(name, valueStr, levels, parse)
+		},
+		Getter: func(e *Config) any {
+			return maps.Clone(*field(e))
+		},
+		Setter: func(e *Config, value any) error {
+			update, ok := value.(map[string]V)
+			if !ok {
+				return fmt.Errorf("%s must be given as level=value pairs", name)
+			}
+			merged := maps.Clone(*field(e))
+			maps.Copy(merged, update)
+			return setValidated(e, field(e), merged)
+		},
+	}
+}
+
+// parseLevelMap parses level=value pairs, separated by commas or spaces. Level names are
+// matched regardless of case.
+func parseLevelMap[V int | float64](name, valueStr string, levels []string, parse func(string) (V, error)) (map[string]V, error) {
+	pairs := strings.FieldsFunc(valueStr, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("%s must be given as level=value pairs, e.g. %s=1", name, levels[1])
+	}
+
+	values := make(map[string]V, len(pairs))
+	for _, pair := range pairs {
+		key, valueText, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("%s must be given as level=value pairs, e.g. %s=1", name, levels[1])
+		}
+		index := slices.IndexFunc(levels, func(level string) bool { return strings.EqualFold(level, key) })
+		if index < 0 {
+			return nil, fmt.Errorf("%s has no level %q; the levels are %s", name, key, strings.Join(levels, ", "))
+		}
+		value, err := parse(valueText)
+		if err != nil {
+			return nil, fmt.Errorf("%s of %s must be a number", name, levels[index])
+		}
+		values[levels[index]] = value
+	}
+	return values, nil
+}
+
+// parseFloat parses a finite number
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("%q is not a finite number", s)
+	}
+	return f, nil
+}
+
+// GetDefaultSettingValue retrieves the built-in default of a configurable setting by name
+func (e *Config) GetDefaultSettingValue(settingName string) (any, error) {
+	setting, exists := settingsRegistry[strings.ToLower(settingName)]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSetting, settingName)
+	}
+	if defaultConfig == nil {
+		return nil, errors.New("default configuration is not initialized")
+	}
+	return setting.Getter(defaultConfig), nil
+}
+
+// ResetSettingValue sets a configurable setting back to its built-in default
+func (e *Config) ResetSettingValue(settingName string) error {
+	value, err := e.GetDefaultSettingValue(settingName)
+	if err != nil {
+		return err
+	}
+	return settingsRegistry[strings.ToLower(settingName)].Setter(e, value)
+}
+
+// SettingDiffs returns the configurable settings whose values differ from their built-in
+// defaults, ordered by name
+func (e *Config) SettingDiffs() []SettingDiff {
+	var diffs []SettingDiff
+	for _, key := range slices.Sorted(maps.Keys(settingsRegistry)) {
+		setting := settingsRegistry[key]
+		value, err := e.GetDefaultSettingValue(key)
+		if err != nil {
+			continue
+		}
+		if current := setting.Getter(e); !reflect.DeepEqual(current, value) {
+			diffs = append(diffs, SettingDiff{Setting: setting, Value: current, Default: value})
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Level names keying the maps of the SM-2 settings, in level order
+var (
+	ImportanceLevels  = []string{"low", "medium", "high", "critical"}
+	FamiliarityLevels = []string{"veryHard", "hard", "medium", "easy", "veryEasy"}
+	MemoryLevels      = []string{"reasoned", "partial", "full"}
+)
+
+// Ease factor bounds shared by the schedulers
+const (
+	MinEaseFactor = 1.3
+	MaxEaseFactor = 2.6
+)
+
+// validateSM2 checks that every map of the SM-2 settings has a valid value for each of its levels,
+// and that the solve penalties are ease factor changes
+func (e *Config) validateSM2() error {
+	if e.MaxInterval <= 0 {
+		return errors.New("MaxInterval must be positive")
+	}
+	checks := []error{
+		validateLevels("BaseIntervals", e.BaseIntervals, ImportanceLevels,
+			func(v int) bool { return v >= 1 && v <= e.MaxInterval }, "between 1 and MaxInterval"),
+		validateLevels("NewQuestionBonus", e.NewQuestionBonus, FamiliarityLevels,
+			func(v int) bool { return v >= 0 }, "0 or more"),
+		validateLevels("MemoryMultipliers", e.MemoryMultipliers, MemoryLevels,
+			func(v float64) bool { return v > 0 }, "positive"),
+		validateLevels("StartEaseFactors", e.StartEaseFactors, ImportanceLevels,
+			func(v float64) bool { return v >= MinEaseFactor && v <= MaxEaseFactor }, fmt.Sprintf("between %.1f and %.1f", MinEaseFactor, MaxEaseFactor)),
+		validateLevels("ImportanceEaseBonus", e.ImportanceEaseBonus, ImportanceLevels, isEaseChange, "between -1 and 1"),
+		validateLevels("FamiliarityEasePenalty", e.FamiliarityEasePenalty, FamiliarityLevels, isEaseChange, "between -1 and 1"),
+		validateLevels("MemoryEasePenalty", e.MemoryEasePenalty, MemoryLevels, isEaseChange, "between -1 and 1"),
+	}
+	if e.SlowerSolvePenalty < 0 || e.SlowerSolvePenalty > 1 {
+		checks = append(checks, errors.New("SlowerSolvePenalty must be between 0 and 1"))
+	}
+	if e.RetryPenalty < 0 || e.RetryPenalty > 1 {
+		checks = append(checks, errors.New("RetryPenalty must be between 0 and 1"))
+	}
+	for _, err := range checks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isEaseChange(v float64) bool {
+	return v >= -1 && v <= 1
+}
+
+// validateLevels checks that a map setting has exactly the given levels, each with a valid value
+func validateLevels[V int | float64](name string, values map[string]V, levels []string, valid func(V) bool, rule string) error {
+	for _, level := range slices.Sorted(maps.Keys(values)) {
+		if !slices.Contains(levels, level) {
+			return fmt.Errorf("%s has unknown level %q; the levels are %s", name, level, strings.Join(levels, ", "))
+		}
+	}
+	for _, level := range levels {
+		value, ok := values[level]
+		if !ok {
+			return fmt.Errorf("%s is missing level %q", name, level)
+		}
+		if !valid(value) {
+			return fmt.Errorf("%s of %s must be %s", name, level, rule)
+		}
+	}
+	return nil
+}
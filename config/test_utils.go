@@ -28,9 +28,12 @@ func (m *MockFileUtil) Delete(filename string) error {
 type TestConfig struct {
 	QuestionsFile     string
 	DeltasFile        string
+	RedoFile          string
+	ReviewsFile       string
 	InfoLogFile       string
 	ErrorLogFile      string
 	SettingsFile      string
+	BackupDir         string
 	PageSize          int
 	MaxDelta          int
 	TopKDue           int
@@ -61,6 +64,18 @@ func MockEnv(t *testing.T) (*TestConfig, *Config) {
 	}
 	deltasFile.Close()
 
+	redoFile, err := os.CreateTemp("", "test_redo_*.json")
+	if err != nil {
+		t.Fatalf("Failed to create temp redo file: %v", err)
+	}
+	redoFile.Close()
+
+	reviewsFile, err := os.CreateTemp("", "test_reviews_*.json")
+	if err != nil {
+		t.Fatalf("Failed to create temp reviews file: %v", err)
+	}
+	reviewsFile.Close()
+
 	infoLogFile, err := os.CreateTemp("", "test_info_*.log")
 	if err != nil {
 		t.Fatalf("Failed to create temp info log file: %v", err)
@@ -83,6 +98,8 @@ func MockEnv(t *testing.T) (*TestConfig, *Config) {
 	t.Cleanup(func() {
 		os.Remove(questionsFile.Name())
 		os.Remove(deltasFile.Name())
+		os.Remove(redoFile.Name())
+		os.Remove(reviewsFile.Name())
 		os.Remove(infoLogFile.Name())
 		os.Remove(errorLogFile.Name())
 		os.Remove(settingsFile.Name())
@@ -91,9 +108,12 @@ func MockEnv(t *testing.T) (*TestConfig, *Config) {
 	testConfig := &TestConfig{
 		QuestionsFile:       questionsFile.Name(),
 		DeltasFile:          deltasFile.Name(),
+		RedoFile:            redoFile.Name(),
+		ReviewsFile:         reviewsFile.Name(),
 		InfoLogFile:         infoLogFile.Name(),
 		ErrorLogFile:        errorLogFile.Name(),
 		SettingsFile:        settingsFile.Name(),
+		BackupDir:           t.TempDir(),
 		PageSize:            3,     // Smaller for testing
 		MaxDelta:            20,    // Smaller for testing
 		TopKDue:             5,     // Smaller for testing
@@ -118,9 +138,12 @@ func MockEnv(t *testing.T) (*TestConfig, *Config) {
 	// Override with test values
 	config.QuestionsFile = testConfig.QuestionsFile
 	config.DeltasFile = testConfig.DeltasFile
+	config.RedoFile = testConfig.RedoFile
+	config.ReviewsFile = testConfig.ReviewsFile
 	config.InfoLogFile = testConfig.InfoLogFile
 	config.ErrorLogFile = testConfig.ErrorLogFile
 	config.SettingsFile = testConfig.SettingsFile
+	config.BackupDir = testConfig.BackupDir
 	config.PageSize = testConfig.PageSize
 	config.MaxDelta = testConfig.MaxDelta
 	config.TopKDue = testConfig.TopKDue
@@ -141,6 +164,8 @@ func MockEnv(t *testing.T) (*TestConfig, *Config) {
 func (tc *TestConfig) SetTestEnvironment() {
 	os.Setenv("LEETSOLV_QUESTIONS_FILE", tc.QuestionsFile)
 	os.Setenv("LEETSOLV_DELTAS_FILE", tc.DeltasFile)
+	os.Setenv("LEETSOLV_REDO_FILE", tc.RedoFile)
+	os.Setenv("LEETSOLV_REVIEWS_FILE", tc.ReviewsFile)
 	os.Setenv("LEETSOLV_INFO_LOG_FILE", tc.InfoLogFile)
 	os.Setenv("LEETSOLV_ERROR_LOG_FILE", tc.ErrorLogFile)
 	os.Setenv("LEETSOLV_SETTINGS_FILE", tc.SettingsFile)
@@ -154,6 +179,8 @@ func (tc *TestConfig) SetTestEnvironment() {
 func (tc *TestConfig) ClearTestEnvironment() {
 	os.Unsetenv("LEETSOLV_QUESTIONS_FILE")
 	os.Unsetenv("LEETSOLV_DELTAS_FILE")
+	os.Unsetenv("LEETSOLV_REDO_FILE")
+	os.Unsetenv("LEETSOLV_REVIEWS_FILE")
 	os.Unsetenv("LEETSOLV_INFO_LOG_FILE")
 	os.Unsetenv("LEETSOLV_ERROR_LOG_FILE")
 	os.Setenv("LEETSOLV_SETTINGS_FILE", "")
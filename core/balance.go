@@ -0,0 +1,76 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/eannchen/leetsolv/config"
+	"github.com/eannchen/leetsolv/internal/clock"
+)
+
+// loadBalanceFuzz is the share of an interval that load balancing may move it by, either way
+const loadBalanceFuzz = 0.1
+
+// spreadInterval keeps reviews from piling up on the same days. With load balancing on, the
+// interval moves within a fuzz window proportional to it, to the day with the fewest reviews
+// already scheduled; otherwise it is randomized by -1 to +2 days when RandomizeInterval is set.
+// Days at the daily review cap are skipped; when the whole window is full, the first later day
+// with room is taken. The result lies between 1 and maxInterval.
+func spreadInterval(cfg *config.Config, clk clock.Clock, rand Rand, state ScheduleState, q *Question, date time.Time, intervalDays, maxInterval int) int {
+	balance := cfg.LoadBalance && state != nil
+
+	// Randomize interval to avoid over-fitting to a specific date
+	if cfg.RandomizeInterval && !balance {
+		// Randomize between -1 and +2 days
+		// IntN(4) produces 0,1,2,3; subtracting 1 gives -1,0,1,2
+		intervalDays += rand.IntN(4) - 1
+	}
+
+	// Secure bounds
+	intervalDays = min(max(intervalDays, 1), maxInterval)
+
+	capped := cfg.DailyReviewCap > 0 && state != nil
+	if !balance && !capped {
+		return intervalDays
+	}
+
+	first, last := intervalDays, intervalDays
+	if balance {
+		fuzz := max(1, int(math.Round(float64(intervalDays)*loadBalanceFuzz)))
+		first, last = max(1, intervalDays-fuzz), min(maxInterval, intervalDays+fuzz)
+	}
+	counts := state.DueCounts(clk.AddDays(date, first), maxInterval-first+1, q.ID)
+	hasRoom := func(day int) bool {
+		return cfg.DailyReviewCap <= 0 || counts[day-first] < cfg.DailyReviewCap
+	}
+
+	// The least loaded day with room, the nearest to the interval among equals, the earlier among those
+	best := 0
+	for day := first; day <= last; day++ {
+		if !hasRoom(day) {
+			continue
+		}
+		if best == 0 || counts[day-first] < counts[best-first] ||
+			counts[day-first] == counts[best-first] && distance(day, intervalDays) < distance(best, intervalDays) {
+			best = day
+		}
+	}
+	if best > 0 {
+		return best
+	}
+
+	// Every day of the window is at the cap
+	for day := last + 1; day <= maxInterval; day++ {
+		if hasRoom(day) {
+			return day
+		}
+	}
+	return intervalDays
+}
+
+func distance(a, b int) int {
+	if a < b {
+		return b - a
+	}
+	return a - b
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/config"
+)
+
+// mockState is a schedule state with the given counts per day, counted from today
+type mockState struct {
+	today       time.Time
+	counts      map[int]int
+	excludeID   int // Excluded ID of the last DueCounts call
+	pausedSince time.Time
+	paused      bool
+}
+
+func (m *mockState) DueCounts(start time.Time, days int, excludeID int) []int {
+	m.excludeID = excludeID
+	offset := int(start.Sub(m.today).Hours() / 24)
+	counts := make([]int, days)
+	for i := range counts {
+		counts[i] = m.counts[offset+i]
+	}
+	return counts
+}
+
+func (m *mockState) PausedSince() (time.Time, bool) {
+	return m.pausedSince, m.paused
+}
+
+// balancedNextReview returns the days until the next review that the SM2 scheduler picks for the interval
+func balancedNextReview(t *testing.T, cfg *config.Config, load *mockState, intervalDays int) int {
+	t.Helper()
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	load.today = mockClock.Today()
+	// FixedRand{Value: 3} would randomize by +2 days
+	scheduler := NewSchedulerWithState(cfg, mockClock, FixedRand{Value: 3}, load).(*SM2Scheduler)
+
+	question := &Question{ID: 7}
+	scheduler.setNextReview(question, mockClock.Today(), intervalDays)
+	return int(question.NextReview.Sub(mockClock.Today()).Hours() / 24)
+}
+
+func TestSpreadInterval_LoadBalance(t *testing.T) {
+	_, cfg := config.MockEnv(t)
+	cfg.LoadBalance = true
+
+	tests := []struct {
+		name     string
+		counts   map[int]int
+		interval int
+		expected int
+	}{
+		{name: "Least loaded day of the window", counts: map[int]int{18: 3, 19: 1, 20: 4, 21: 1, 22: 0}, interval: 20, expected: 22},
+		{name: "Interval kept when the window is even", counts: map[int]int{}, interval: 20, expected: 20},
+		{name: "Nearest and then earlier day among equals", counts: map[int]int{18: 1, 19: 1, 20: 4, 21: 1, 22: 1}, interval: 20, expected: 19},
+		{name: "Window of at least a day", counts: map[int]int{3: 5, 4: 2, 5: 5}, interval: 4, expected: 4},
+		{name: "Window kept above zero days", counts: map[int]int{1: 2, 2: 1}, interval: 1, expected: 2},
+		{name: "Window kept below the maximum interval", counts: map[int]int{87: 1, 88: 1, 89: 1, 90: 5}, interval: 90, expected: 86},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load := &mockState{counts: tt.counts}
+			if got := balancedNextReview(t, cfg, load, tt.interval); got != tt.expected {
+				t.Errorf("Expected the review in %d days, got %d", tt.expected, got)
+			}
+			if load.excludeID != 7 {
+				t.Errorf("Expected the scheduled question to be left out of the load, got ID %d", load.excludeID)
+			}
+		})
+	}
+}
+
+func TestSpreadInterval_DailyReviewCap(t *testing.T) {
+	_, cfg := config.MockEnv(t)
+	cfg.RandomizeInterval = false
+	cfg.DailyReviewCap = 2
+
+	// Without load balancing only a full day moves the review, to the next day with room
+	if got := balancedNextReview(t, cfg, &mockState{counts: map[int]int{10: 2, 11: 2, 12: 1}}, 10); got != 12 {
+		t.Errorf("Expected the review moved to day 12, got %d", got)
+	}
+	if got := balancedNextReview(t, cfg, &mockState{counts: map[int]int{9: 0, 10: 1}}, 10); got != 10 {
+		t.Errorf("Expected the review kept on day 10, got %d", got)
+	}
+
+	// With load balancing the full days of the window are skipped
+	cfg.LoadBalance = true
+	if got := balancedNextReview(t, cfg, &mockState{counts: map[int]int{9: 2, 10: 1, 11: 2}}, 10); got != 10 {
+		t.Errorf("Expected the review kept on day 10, got %d", got)
+	}
+	if got := balancedNextReview(t, cfg, &mockState{counts: map[int]int{9: 2, 10: 2, 11: 2, 12: 3}}, 10); got != 13 {
+		t.Errorf("Expected the review moved after the full window to day 13, got %d", got)
+	}
+
+	// When no day has room the interval is kept
+	full := map[int]int{}
+	for day := 1; day <= 90; day++ {
+		full[day] = 2
+	}
+	if got := balancedNextReview(t, cfg, &mockState{counts: full}, 10); got != 10 {
+		t.Errorf("Expected the review kept on day 10, got %d", got)
+	}
+}
+
+func TestSpreadInterval_Off(t *testing.T) {
+	_, cfg := config.MockEnv(t)
+	cfg.RandomizeInterval = true
+
+	// Neither load balancing nor a cap: randomized as before
+	if got := balancedNextReview(t, cfg, &mockState{counts: map[int]int{12: 9}}, 10); got != 12 {
+		t.Errorf("Expected the randomized review on day 12, got %d", got)
+	}
+
+	// Load balancing without a load to read falls back to randomization
+	cfg.LoadBalance = true
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	question := &Question{}
+	NewFSRSSchedulerWithRand(cfg, mockClock, FixedRand{Value: 3}).setNextReview(question, mockClock.Today(), 10)
+	if expected := mockClock.AddDays(mockClock.Today(), 12); !question.NextReview.Equal(expected) {
+		t.Errorf("Expected the randomized review on %v, got %v", expected, question.NextReview)
+	}
+}
+
+func TestNewSchedulerWithState_FSRS(t *testing.T) {
+	_, cfg := config.MockEnv(t)
+	cfg.Algorithm = config.AlgorithmFSRS
+	cfg.LoadBalance = true
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	load := &mockState{today: mockClock.Today(), counts: map[int]int{20: 3, 21: 1, 22: 3}}
+
+	scheduler, ok := NewSchedulerWithState(cfg, mockClock, FixedRand{Value: 1}, load).(*FSRSScheduler)
+	if !ok {
+		t.Fatal("Expected an FSRS scheduler")
+	}
+	question := &Question{ID: 3}
+	scheduler.setNextReview(question, mockClock.Today(), 20)
+	if expected := mockClock.AddDays(mockClock.Today(), 19); !question.NextReview.Equal(expected) {
+		t.Errorf("Expected the review on the least loaded day %v, got %v", expected, question.NextReview)
+	}
+}
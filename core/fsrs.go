@@ -0,0 +1,240 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/eannchen/leetsolv/config"
+	"github.com/eannchen/leetsolv/internal/clock"
+)
+
+// FSRS model constants (FSRS-4.5 forgetting curve)
+const (
+	fsrsDecay     = -0.5
+	fsrsFactor    = 19.0 / 81.0
+	minDifficulty = 1.0
+	maxDifficulty = 10.0
+	minRetention  = 0.70
+	maxRetention  = 0.97
+)
+
+// defaultFSRSWeights are the published FSRS-4.5 default parameters.
+var defaultFSRSWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+	0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// FSRSScheduler implements the scheduling logic of the Free Spaced Repetition Scheduler.
+// Each question's memory is modeled by its stability (days until recall drops to 90%)
+// and difficulty (1-10); the next review is set when the predicted recall probability
+// reaches the desired retention. MaxInterval and MemoryMultipliers apply as with SM-2, and
+// StartEaseFactors is kept so switching back to SM-2 stays well-defined; like the desired
+// retention, they are read from cfg each time a question is scheduled.
+type FSRSScheduler struct {
+	cfg   *config.Config
+	Clock clock.Clock
+	Rand  Rand
+	State ScheduleState // Read to balance reviews and leave out paused days; nil for neither
+
+	// Model settings
+	weights                   [17]float64
+	importanceRetentionOffset map[Importance]float64
+}
+
+func NewFSRSScheduler(cfg *config.Config, clock clock.Clock) *FSRSScheduler {
+	return NewFSRSSchedulerWithRand(cfg, clock, DefaultRand{})
+}
+
+func NewFSRSSchedulerWithRand(cfg *config.Config, clock clock.Clock, rand Rand) *FSRSScheduler {
+	return &FSRSScheduler{
+		cfg:   cfg,
+		Clock: clock,
+		Rand:  rand,
+
+		// Model settings
+		weights: defaultFSRSWeights,
+		importanceRetentionOffset: map[Importance]float64{
+			LowImportance:      -0.05, // accept more forgetting
+			MediumImportance:   0.00,
+			HighImportance:     0.02,
+			CriticalImportance: 0.04, // review before recall degrades
+		},
+	}
+}
+
+func (s FSRSScheduler) maxInterval() int { return s.cfg.MaxInterval }
+
+func (s FSRSScheduler) memoryMultiplier(memory MemoryUse) float64 {
+	return levelSetting(s.cfg.MemoryMultipliers, config.MemoryLevels, memory)
+}
+
+func (s FSRSScheduler) startEaseFactor(importance Importance) float64 {
+	return levelSetting(s.cfg.StartEaseFactors, config.ImportanceLevels, importance)
+}
+
+// fsrsGrade maps the five familiarity levels onto the FSRS rating scale
+// (1 = Again, 2 = Hard, 3 = Good, 4 = Easy).
+func fsrsGrade(f Familiarity) float64 {
+	switch f {
+	case VeryHard:
+		return 1
+	case Hard:
+		return 2
+	case Medium:
+		return 3
+	case Easy:
+		return 3.5
+	default:
+		return 4
+	}
+}
+
+func (s FSRSScheduler) ScheduleNewQuestion(q *Question, memory MemoryUse) *Question {
+	today := s.Clock.Today()
+	grade := fsrsGrade(q.Familiarity)
+
+	q.EaseFactor = s.startEaseFactor(q.Importance)
+	q.ReviewCount = 1
+	q.LastReviewed = today
+	q.Stability = s.initialStability(grade)
+	q.Difficulty = s.initialDifficulty(grade)
+
+	s.setNextReview(q, today, s.nextInterval(q, memory))
+	return q
+}
+
+func (s FSRSScheduler) Schedule(q *Question, memory MemoryUse) {
+	q.ReviewCount++
+	today := s.Clock.Today()
+	grade := fsrsGrade(q.Familiarity)
+
+	// Questions previously scheduled by SM-2 have no memory state yet
+	if q.Stability <= 0 {
+		s.bootstrapMemoryState(q)
+	}
+
+	elapsedDays := math.Max(today.Sub(s.Clock.ToDate(q.LastReviewed)).Hours()/24, 0)
+	retrievability := s.retrievability(elapsedDays, q.Stability)
+
+	// Stability is updated with the difficulty from before this review
+	if q.Familiarity == VeryHard {
+		q.Stability = s.forgetStability(q.Difficulty, q.Stability, retrievability)
+	} else {
+		q.Stability = s.recallStability(q.Difficulty, q.Stability, retrievability, grade)
+	}
+	q.Difficulty = s.nextDifficulty(q.Difficulty, grade)
+	q.LastReviewed = today
+
+	s.setNextReview(q, today, s.nextInterval(q, memory))
+}
+
+// bootstrapMemoryState derives an initial memory state from SM-2 scheduling data.
+// The last interval approximates stability, and the ease factor maps inversely onto difficulty.
+func (s FSRSScheduler) bootstrapMemoryState(q *Question) {
+	prevIntervalDays := q.NextReview.Sub(q.LastReviewed).Hours() / 24
+	q.Stability = math.Max(prevIntervalDays, 1)
+
+	easeRange := defaultMaxEaseFactor - defaultMinEaseFactor
+	q.Difficulty = clampDifficulty(maxDifficulty - (maxDifficulty-minDifficulty)*(q.EaseFactor-defaultMinEaseFactor)/easeRange)
+}
+
+// retrievability returns the predicted recall probability after elapsedDays.
+func (s FSRSScheduler) retrievability(elapsedDays, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+func (s FSRSScheduler) initialStability(grade float64) float64 {
+	lower := math.Floor(grade)
+	upper := math.Ceil(grade)
+	lowerStability := s.weights[int(lower)-1]
+	upperStability := s.weights[int(upper)-1]
+
+	// Interpolate geometrically between ratings for in-between grades
+	return math.Exp(math.Log(lowerStability) + (grade-lower)*(math.Log(upperStability)-math.Log(lowerStability)))
+}
+
+func (s FSRSScheduler) initialDifficulty(grade float64) float64 {
+	return clampDifficulty(s.weights[4] - (grade-3)*s.weights[5])
+}
+
+func (s FSRSScheduler) nextDifficulty(difficulty, grade float64) float64 {
+	next := difficulty - s.weights[6]*(grade-3)
+	// Mean reversion towards the difficulty of an "Easy" first review
+	return clampDifficulty(s.weights[7]*s.initialDifficulty(4) + (1-s.weights[7])*next)
+}
+
+func (s FSRSScheduler) recallStability(difficulty, stability, retrievability, grade float64) float64 {
+	hardPenalty := 1.0
+	if grade < 3 {
+		hardPenalty = s.weights[15]
+	}
+	easyBonus := 1.0
+	if grade > 3 {
+		easyBonus = 1 + (s.weights[16]-1)*(grade-3)
+	}
+
+	growth := math.Exp(s.weights[8]) *
+		(11 - difficulty) *
+		math.Pow(stability, -s.weights[9]) *
+		(math.Exp(s.weights[10]*(1-retrievability)) - 1) *
+		hardPenalty * easyBonus
+
+	return stability * (growth + 1)
+}
+
+func (s FSRSScheduler) forgetStability(difficulty, stability, retrievability float64) float64 {
+	next := s.weights[11] *
+		math.Pow(difficulty, -s.weights[12]) *
+		(math.Pow(stability+1, s.weights[13]) - 1) *
+		math.Exp(s.weights[14]*(1-retrievability))
+
+	// Forgetting never increases stability
+	return math.Min(next, stability)
+}
+
+// nextInterval returns the number of days until recall drops to the desired retention.
+func (s FSRSScheduler) nextInterval(q *Question, memory MemoryUse) int {
+	retention := s.cfg.DesiredRetention + s.importanceRetentionOffset[q.Importance]
+	retention = math.Min(math.Max(retention, minRetention), maxRetention)
+
+	intervalDays := q.Stability / fsrsFactor * (math.Pow(retention, 1/fsrsDecay) - 1)
+	return int(math.Round(intervalDays * s.memoryMultiplier(memory)))
+}
+
+func (s FSRSScheduler) setNextReview(q *Question, date time.Time, intervalDays int) {
+	intervalDays = spreadInterval(s.cfg, s.Clock, s.Rand, s.State, q, date, intervalDays, s.maxInterval())
+	q.NextReview = s.Clock.AddDays(date, intervalDays)
+}
+
+func (s FSRSScheduler) CalculatePriorityScore(q *Question, pausedSince time.Time) float64 {
+	today := s.Clock.Today()
+
+	// Compute overdue days (at least 0)
+	overdueDays := overdueDays(q, today, pausedSince)
+	if overdueDays < 0 {
+		overdueDays = 0
+	}
+
+	// Invert Familiarity (VeryEasy = 0, VeryHard = 4)
+	famScore := 4 - int(q.Familiarity)
+
+	// Map difficulty back onto the ease factor scale so both algorithms share the same weights
+	easeFactor := q.EaseFactor
+	if q.Stability > 0 {
+		easeRange := defaultMaxEaseFactor - defaultMinEaseFactor
+		easeFactor = defaultMinEaseFactor + easeRange*(maxDifficulty-q.Difficulty)/(maxDifficulty-minDifficulty)
+	}
+
+	score := s.cfg.ImportanceWeight*float64(q.Importance) +
+		s.cfg.OverdueWeight*float64(overdueDays) +
+		s.cfg.FamiliarityWeight*float64(famScore) +
+		s.cfg.ReviewPenaltyWeight*float64(q.ReviewCount) +
+		s.cfg.EasePenaltyWeight*easeFactor +
+		leechPriority(s.cfg, q)
+
+	return score
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Min(math.Max(d, minDifficulty), maxDifficulty)
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/config"
+)
+
+func TestNewScheduler_SelectsAlgorithm(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+
+	cfg.Algorithm = config.AlgorithmSM2
+	if _, ok := NewScheduler(cfg, mockClock).(*SM2Scheduler); !ok {
+		t.Error("Expected SM2Scheduler for sm2 algorithm")
+	}
+
+	cfg.Algorithm = config.AlgorithmFSRS
+	if _, ok := NewScheduler(cfg, mockClock).(*FSRSScheduler); !ok {
+		t.Error("Expected FSRSScheduler for fsrs algorithm")
+	}
+
+	scheduler, ok := NewSchedulerWithRand(cfg, mockClock, FixedRand{Value: 1}).(*FSRSScheduler)
+	if !ok || scheduler.Rand != (FixedRand{Value: 1}) {
+		t.Error("Expected FSRSScheduler with the given Rand")
+	}
+}
+
+func TestFSRSScheduleNewQuestion(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+	cfg.DesiredRetention = 0.9
+	scheduler := NewFSRSSchedulerWithRand(cfg, mockClock, FixedRand{Value: 1}) // +0 days randomization
+
+	tests := []struct {
+		name             string
+		familiarity      Familiarity
+		importance       Importance
+		memory           MemoryUse
+		expectedInterval int
+	}{
+		// At 90% retention the interval equals the stability
+		{"VeryHard is reviewed tomorrow", VeryHard, MediumImportance, MemoryReasoned, 1},
+		{"Medium uses Good stability", Medium, MediumImportance, MemoryReasoned, 4},
+		{"VeryEasy uses Easy stability", VeryEasy, MediumImportance, MemoryReasoned, 14},
+		{"High importance raises retention", Medium, HighImportance, MemoryReasoned, 3},
+		{"Full memory use extends interval", Medium, MediumImportance, MemoryFull, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &Question{Familiarity: tt.familiarity, Importance: tt.importance}
+			scheduler.ScheduleNewQuestion(q, tt.memory)
+
+			if q.ReviewCount != 1 {
+				t.Errorf("Expected ReviewCount 1, got %d", q.ReviewCount)
+			}
+			if expected := cfg.StartEaseFactors[config.ImportanceLevels[tt.importance]]; q.EaseFactor != expected {
+				t.Errorf("Expected EaseFactor %.2f, got %.2f", expected, q.EaseFactor)
+			}
+			if q.Stability <= 0 {
+				t.Errorf("Expected positive Stability, got %.4f", q.Stability)
+			}
+			if q.Difficulty < minDifficulty || q.Difficulty > maxDifficulty {
+				t.Errorf("Expected Difficulty within [1, 10], got %.4f", q.Difficulty)
+			}
+			interval := int(q.NextReview.Sub(q.LastReviewed).Hours() / 24)
+			if interval != tt.expectedInterval {
+				t.Errorf("Expected interval %d days, got %d", tt.expectedInterval, interval)
+			}
+		})
+	}
+}
+
+func TestFSRSScheduler_ConfiguredIntervals(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+	cfg.DesiredRetention = 0.9
+	scheduler := NewFSRSSchedulerWithRand(cfg, mockClock, FixedRand{Value: 1})
+
+	// MaxInterval and MemoryMultipliers changed after the scheduler is created apply as well
+	if err := cfg.SetSettingValue("memorymultipliers", map[string]float64{"full": 2}); err != nil {
+		t.Fatalf("Failed to set MemoryMultipliers: %v", err)
+	}
+	q := &Question{Familiarity: Medium, Importance: MediumImportance}
+	scheduler.ScheduleNewQuestion(q, MemoryFull)
+	if interval := int(q.NextReview.Sub(q.LastReviewed).Hours() / 24); interval != 7 {
+		t.Errorf("Expected the doubled 7 day interval, got %d", interval)
+	}
+
+	if err := cfg.SetSettingValue("maxinterval", 10); err != nil {
+		t.Fatalf("Failed to set MaxInterval: %v", err)
+	}
+	q = &Question{Familiarity: VeryEasy, Importance: MediumImportance, Stability: 60, Difficulty: 3}
+	q.LastReviewed = mockClock.Today().AddDate(0, 0, -60)
+	q.NextReview = mockClock.Today()
+	scheduler.Schedule(q, MemoryReasoned)
+	if interval := int(q.NextReview.Sub(mockClock.Today()).Hours() / 24); interval != 10 {
+		t.Errorf("Expected the interval capped at 10 days, got %d", interval)
+	}
+}
+
+func TestFSRSSchedule(t *testing.T) {
+	today := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	mockClock := NewMockClock(today)
+	_, cfg := config.MockEnv(t)
+	cfg.DesiredRetention = 0.9
+	scheduler := NewFSRSSchedulerWithRand(cfg, mockClock, FixedRand{Value: 1})
+
+	newReviewedQuestion := func(f Familiarity) *Question {
+		return &Question{
+			Familiarity:  f,
+			Importance:   MediumImportance,
+			EaseFactor:   1.9,
+			ReviewCount:  1,
+			Stability:    4,
+			Difficulty:   5,
+			LastReviewed: time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC),
+			NextReview:   time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	t.Run("Successful recall increases stability", func(t *testing.T) {
+		q := newReviewedQuestion(Medium)
+		scheduler.Schedule(q, MemoryReasoned)
+
+		if q.Stability <= 4 {
+			t.Errorf("Expected Stability to grow beyond 4, got %.4f", q.Stability)
+		}
+		if q.ReviewCount != 2 {
+			t.Errorf("Expected ReviewCount 2, got %d", q.ReviewCount)
+		}
+		if !q.LastReviewed.Equal(mockClock.Today()) {
+			t.Errorf("Expected LastReviewed to be today, got %v", q.LastReviewed)
+		}
+		interval := int(q.NextReview.Sub(q.LastReviewed).Hours() / 24)
+		if interval <= 4 {
+			t.Errorf("Expected interval longer than previous 4 days, got %d", interval)
+		}
+	})
+
+	t.Run("Easier grades grow stability faster", func(t *testing.T) {
+		hard := newReviewedQuestion(Hard)
+		medium := newReviewedQuestion(Medium)
+		easy := newReviewedQuestion(VeryEasy)
+		scheduler.Schedule(hard, MemoryReasoned)
+		scheduler.Schedule(medium, MemoryReasoned)
+		scheduler.Schedule(easy, MemoryReasoned)
+
+		if !(hard.Stability < medium.Stability && medium.Stability < easy.Stability) {
+			t.Errorf("Expected Hard < Medium < VeryEasy stability, got %.4f, %.4f, %.4f",
+				hard.Stability, medium.Stability, easy.Stability)
+		}
+		if !(hard.Difficulty > medium.Difficulty && medium.Difficulty > easy.Difficulty) {
+			t.Errorf("Expected Hard > Medium > VeryEasy difficulty, got %.4f, %.4f, %.4f",
+				hard.Difficulty, medium.Difficulty, easy.Difficulty)
+		}
+	})
+
+	t.Run("Forgetting decreases stability", func(t *testing.T) {
+		q := newReviewedQuestion(VeryHard)
+		scheduler.Schedule(q, MemoryReasoned)
+
+		if q.Stability >= 4 {
+			t.Errorf("Expected Stability to drop below 4, got %.4f", q.Stability)
+		}
+		if q.Difficulty <= 5 {
+			t.Errorf("Expected Difficulty to rise above 5, got %.4f", q.Difficulty)
+		}
+	})
+
+	t.Run("Bootstraps memory state from SM-2 data", func(t *testing.T) {
+		q := newReviewedQuestion(Medium)
+		q.Stability = 0
+		q.Difficulty = 0
+		q.EaseFactor = defaultMaxEaseFactor
+
+		scheduler.Schedule(q, MemoryReasoned)
+
+		if q.Stability <= 4 {
+			t.Errorf("Expected Stability to grow from the 4-day SM-2 interval, got %.4f", q.Stability)
+		}
+		if q.Difficulty < minDifficulty || q.Difficulty > maxDifficulty {
+			t.Errorf("Expected Difficulty within [1, 10], got %.4f", q.Difficulty)
+		}
+	})
+}
+
+func TestFSRSModelFunctions(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+	scheduler := NewFSRSSchedulerWithRand(cfg, mockClock, FixedRand{Value: 1})
+
+	t.Run("Retrievability is 90% after stability days", func(t *testing.T) {
+		r := scheduler.retrievability(10, 10)
+		if math.Abs(r-0.9) > 1e-9 {
+			t.Errorf("Expected retrievability 0.9, got %.6f", r)
+		}
+	})
+
+	t.Run("Initial stability interpolates between ratings", func(t *testing.T) {
+		good := scheduler.initialStability(3)
+		easy := scheduler.initialStability(4)
+		between := scheduler.initialStability(3.5)
+		if !(good < between && between < easy) {
+			t.Errorf("Expected %.4f < %.4f < %.4f", good, between, easy)
+		}
+	})
+
+	t.Run("Difficulty stays within bounds", func(t *testing.T) {
+		d := 10.0
+		for i := 0; i < 20; i++ {
+			d = scheduler.nextDifficulty(d, 1)
+		}
+		if d > maxDifficulty {
+			t.Errorf("Expected Difficulty at most 10, got %.4f", d)
+		}
+
+		d = 1.0
+		for i := 0; i < 20; i++ {
+			d = scheduler.nextDifficulty(d, 4)
+		}
+		if d < minDifficulty {
+			t.Errorf("Expected Difficulty at least 1, got %.4f", d)
+		}
+	})
+}
+
+func TestFSRSCalculatePriorityScore(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+	scheduler := NewFSRSSchedulerWithRand(cfg, mockClock, FixedRand{Value: 1})
+
+	base := Question{
+		Familiarity: Medium,
+		Importance:  MediumImportance,
+		ReviewCount: 2,
+		EaseFactor:  1.9,
+		NextReview:  mockClock.Today(),
+	}
+
+	// Without FSRS memory state the score matches SM-2
+	sm2 := NewSM2SchedulerWithRand(cfg, mockClock, FixedRand{Value: 1})
+	if got, want := scheduler.CalculatePriorityScore(&base, time.Time{}), sm2.CalculatePriorityScore(&base, time.Time{}); got != want {
+		t.Errorf("Expected score %.4f without memory state, got %.4f", want, got)
+	}
+
+	// Harder questions get a lower ease equivalent, raising their priority
+	easy := base
+	easy.Stability, easy.Difficulty = 5, 2
+	hard := base
+	hard.Stability, hard.Difficulty = 5, 9
+	if scheduler.CalculatePriorityScore(&hard, time.Time{}) <= scheduler.CalculatePriorityScore(&easy, time.Time{}) {
+		t.Error("Expected higher difficulty to yield a higher priority score")
+	}
+}
@@ -0,0 +1,43 @@
+package core
+
+import "github.com/eannchen/leetsolv/config"
+
+// LeechTag is the tag given to a leech when the leech action is "tag"
+const LeechTag = "leech"
+
+// leechPriorityBoost is added to the priority score of a leech when the leech action is "top".
+// It outweighs any score the weights can give, so leeches come before every other due question.
+const leechPriorityBoost = 1e6
+
+// IsLapse reports whether a review with the familiarity counts as a failed review
+func IsLapse(familiarity Familiarity) bool {
+	return familiarity <= Hard
+}
+
+// TrackLapse counts a failed review of an existing question, after it has been scheduled, and
+// reports whether the review made the question a leech. A question becomes a leech once its
+// lapses reach LeechThreshold, or when a failed review leaves its ease factor pinned at the
+// minimum it already had. easeBefore is the ease factor before the review.
+func TrackLapse(cfg *config.Config, q *Question, easeBefore float64) bool {
+	if !IsLapse(q.Familiarity) {
+		return false
+	}
+	q.Lapses++
+	if q.Leech || cfg.LeechThreshold <= 0 {
+		return false
+	}
+	pinned := easeBefore <= config.MinEaseFactor && q.EaseFactor <= config.MinEaseFactor
+	if q.Lapses >= cfg.LeechThreshold || pinned {
+		q.Leech = true
+		return true
+	}
+	return false
+}
+
+// leechPriority is the part of the priority score that forces leeches to the top of the due questions
+func leechPriority(cfg *config.Config, q *Question) float64 {
+	if q.Leech && cfg.LeechAction == config.LeechActionTop {
+		return leechPriorityBoost
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/config"
+)
+
+func TestTrackLapse(t *testing.T) {
+	_, cfg := config.MockEnv(t)
+	cfg.LeechThreshold = 3
+
+	tests := []struct {
+		name       string
+		question   Question
+		easeBefore float64
+		leech      bool // Whether the review makes the question a leech
+		lapses     int
+	}{
+		{name: "Passed review", question: Question{Familiarity: Medium, Lapses: 2, EaseFactor: 1.8}, easeBefore: 1.8, lapses: 2},
+		{name: "Lapse below the threshold", question: Question{Familiarity: Hard, Lapses: 1, EaseFactor: 1.6}, easeBefore: 1.8, lapses: 2},
+		{name: "Lapse reaching the threshold", question: Question{Familiarity: VeryHard, Lapses: 2, EaseFactor: 1.6}, easeBefore: 1.8, leech: true, lapses: 3},
+		{name: "Ease factor reaching the minimum", question: Question{Familiarity: VeryHard, EaseFactor: 1.3}, easeBefore: 1.5, lapses: 1},
+		{name: "Ease factor pinned at the minimum", question: Question{Familiarity: Hard, Lapses: 1, EaseFactor: 1.3}, easeBefore: 1.3, leech: true, lapses: 2},
+		{name: "Already a leech", question: Question{Familiarity: Hard, Lapses: 5, Leech: true, EaseFactor: 1.3}, easeBefore: 1.3, lapses: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.question
+			if leech := TrackLapse(cfg, &q, tt.easeBefore); leech != tt.leech {
+				t.Errorf("Expected TrackLapse to return %t, got %t", tt.leech, leech)
+			}
+			if q.Lapses != tt.lapses {
+				t.Errorf("Expected %d lapses, got %d", tt.lapses, q.Lapses)
+			}
+			if q.Leech != (tt.leech || tt.question.Leech) {
+				t.Errorf("Expected Leech to be %t, got %t", tt.leech || tt.question.Leech, q.Leech)
+			}
+		})
+	}
+
+	t.Run("Detection off", func(t *testing.T) {
+		cfg.LeechThreshold = 0
+		q := Question{Familiarity: VeryHard, Lapses: 9, EaseFactor: 1.3}
+		if TrackLapse(cfg, &q, 1.3) || q.Leech || q.Lapses != 10 {
+			t.Errorf("Expected lapses to be counted without a leech, got %+v", q)
+		}
+	})
+}
+
+func TestCalculatePriorityScore_LeechOnTop(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+
+	overdue := &Question{Importance: CriticalImportance, Familiarity: VeryHard, NextReview: mockClock.Now().AddDate(0, 0, -30)}
+	leech := &Question{Importance: LowImportance, Familiarity: VeryEasy, ReviewCount: 20, EaseFactor: 2.6, Leech: true, NextReview: mockClock.Now()}
+
+	for _, scheduler := range []Scheduler{NewSM2SchedulerWithRand(cfg, mockClock, FixedRand{Value: 1}), NewFSRSSchedulerWithRand(cfg, mockClock, FixedRand{Value: 1})} {
+		cfg.LeechAction = config.LeechActionTag
+		if scheduler.CalculatePriorityScore(leech, time.Time{}) >= scheduler.CalculatePriorityScore(overdue, time.Time{}) {
+			t.Errorf("%T: expected a tagged leech to keep its usual priority", scheduler)
+		}
+		cfg.LeechAction = config.LeechActionTop
+		if scheduler.CalculatePriorityScore(leech, time.Time{}) <= scheduler.CalculatePriorityScore(overdue, time.Time{}) {
+			t.Errorf("%T: expected the leech to come first", scheduler)
+		}
+	}
+}
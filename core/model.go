@@ -1,7 +1,12 @@
 // Package core implements the core models for the leetsolv application.
 package core
 
-import "time"
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
 
 const MaxImportance = int(CriticalImportance) + 1
 const MaxFamiliarity = int(VeryEasy) + 1
@@ -60,18 +65,134 @@ type ParsedURL struct {
 
 type QuestionMap map[int]*Question
 
+// QuestionState is the lifecycle state of a question
+type QuestionState string
+
+const (
+	StateActive    QuestionState = ""          // Scheduled and listed as usual
+	StateSuspended QuestionState = "suspended" // Kept, but left out of the due lists until unsuspended
+	StateBuried    QuestionState = "buried"    // Left out of the due lists until the day in BuriedUntil
+	StateArchived  QuestionState = "archived"  // Mastered, and no longer reviewed
+)
+
+// QuestionStates lists the lifecycle states
+var QuestionStates = []QuestionState{StateActive, StateSuspended, StateBuried, StateArchived}
+
+func (s QuestionState) String() string {
+	if s == StateActive {
+		return "active"
+	}
+	return string(s)
+}
+
+// ParseQuestionState parses the name of a lifecycle state, as given by String; "mastered" is
+// taken as archived
+func ParseQuestionState(name string) (QuestionState, bool) {
+	switch state := QuestionState(strings.ToLower(strings.TrimSpace(name))); state {
+	case "active", StateActive:
+		return StateActive, true
+	case "mastered":
+		return StateArchived, true
+	case StateSuspended, StateBuried, StateArchived:
+		return state, true
+	}
+	return StateActive, false
+}
+
 type Question struct {
 	ID           int         `json:"id"`
 	URL          string      `json:"url"`
 	Note         string      `json:"note"`
+	Tags         []string    `json:"tags,omitempty"`
 	Familiarity  Familiarity `json:"familiarity"`
 	Importance   Importance  `json:"importance"`
 	LastReviewed time.Time   `json:"last_reviewed"`
 	NextReview   time.Time   `json:"next_review"`
 	ReviewCount  int         `json:"review_count"`
 	EaseFactor   float64     `json:"ease_factor"`
-	UpdatedAt    time.Time   `json:"updated_at"`
-	CreatedAt    time.Time   `json:"created_at"`
+	// FSRS memory state; zero until the question is reviewed with the FSRS scheduler
+	Stability  float64 `json:"stability,omitempty"`
+	Difficulty float64 `json:"difficulty,omitempty"`
+	// Reviews of the question rated hard or very hard, and whether they made it a leech
+	Lapses int           `json:"lapses,omitempty"`
+	Leech  bool          `json:"leech,omitempty"`
+	State  QuestionState `json:"state,omitempty"`
+	// Whether the leech action suspended the question, so that clearing the leech unsuspends it
+	LeechSuspended bool `json:"leech_suspended,omitempty"`
+	// Day a burial ends on; zero unless the question is buried
+	BuriedUntil time.Time `json:"buried_until,omitzero"`
+	// Latest solve told when the question was added or reviewed
+	LastSolve Solve     `json:"last_solve,omitzero"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StateOn returns the lifecycle state of the question on the day. A burial is over on the day it
+// lasts until, so the question is active again from then on.
+func (q *Question) StateOn(today time.Time) QuestionState {
+	if q.State == StateBuried && !q.BuriedUntil.After(today) {
+		return StateActive
+	}
+	return q.State
+}
+
+// IsScheduled reports whether the question comes up for review at all; suspended and archived
+// questions do not
+func (q *Question) IsScheduled() bool {
+	return q.State == StateActive || q.State == StateBuried
+}
+
+// ReviewDate returns when the question comes up for review: its next review, or the day its
+// burial ends when that is later
+func (q *Question) ReviewDate() time.Time {
+	if q.State == StateBuried && q.BuriedUntil.After(q.NextReview) {
+		return q.BuriedUntil
+	}
+	return q.NextReview
+}
+
+// Equal reports whether both questions hold the same values; times are compared as instants
+func (q *Question) Equal(other *Question) bool {
+	return q.ID == other.ID && q.URL == other.URL && q.Note == other.Note &&
+		slices.Equal(q.Tags, other.Tags) &&
+		q.Familiarity == other.Familiarity && q.Importance == other.Importance &&
+		q.LastReviewed.Equal(other.LastReviewed) && q.NextReview.Equal(other.NextReview) &&
+		q.ReviewCount == other.ReviewCount && q.EaseFactor == other.EaseFactor &&
+		q.Stability == other.Stability && q.Difficulty == other.Difficulty &&
+		q.Lapses == other.Lapses && q.Leech == other.Leech && q.State == other.State &&
+		q.LeechSuspended == other.LeechSuspended && q.BuriedUntil.Equal(other.BuriedUntil) &&
+		q.LastSolve.Equal(other.LastSolve) &&
+		q.UpdatedAt.Equal(other.UpdatedAt) && q.CreatedAt.Equal(other.CreatedAt)
+}
+
+// HasTag reports whether the question is tagged with the given normalized tag.
+func (q *Question) HasTag(tag string) bool {
+	for _, t := range q.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags lowercases and trims tags, strips a leading '#', and removes empty and duplicate tags.
+// The result is sorted so that equal tag sets compare equal.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(tag)), "#")
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
 }
 
 // ActionType defines the type of action performed.
@@ -81,8 +202,15 @@ const (
 	ActionAdd    ActionType = "add"
 	ActionUpdate ActionType = "update"
 	ActionDelete ActionType = "delete"
+	ActionImport ActionType = "import" // A group of add and update deltas undone together
+	ActionResume ActionType = "resume" // A group of update deltas that moved next reviews past a pause
 )
 
+// IsBatch reports whether deltas of the action hold their changes in Batch
+func (a ActionType) IsBatch() bool {
+	return a == ActionImport || a == ActionResume
+}
+
 func (a ActionType) String() string {
 	switch a {
 	case ActionAdd:
@@ -91,6 +219,10 @@ func (a ActionType) String() string {
 		return "Update"
 	case ActionDelete:
 		return "Delete"
+	case ActionImport:
+		return "Import"
+	case ActionResume:
+		return "Resume"
 	}
 	return ""
 }
@@ -103,6 +235,10 @@ func (a ActionType) PastTenseString() string {
 		return "Updated"
 	case ActionDelete:
 		return "Deleted"
+	case ActionImport:
+		return "Imported"
+	case ActionResume:
+		return "Resumed"
 	}
 	return ""
 }
@@ -112,13 +248,46 @@ type Delta struct {
 	QuestionID int        `json:"question_id"`
 	OldState   *Question  `json:"old_state"`
 	NewState   *Question  `json:"new_state"`
+	Batch      []Delta    `json:"batch,omitempty"` // Deltas of an import or resume, in the order they were applied
 	CreatedAt  time.Time  `json:"created_at"`
 }
 
+// Pause is a period during which reviews were put on hold, from the day Start up to the day End
+type Pause struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitzero"` // Zero while the pause lasts
+}
+
+// ReviewEvent records a single review of a question.
+// Unlike Delta, review events are append-only and never truncated, so they form the long-term review history.
+// Undoing the review marks its event reverted instead of removing it, so that a redo can restore it.
+type ReviewEvent struct {
+	QuestionID       int         `json:"question_id"`
+	Familiarity      Familiarity `json:"familiarity"`
+	MemoryUse        MemoryUse   `json:"memory_use"`
+	Importance       Importance  `json:"importance"`
+	EaseFactorBefore float64     `json:"ease_factor_before"` // Zero for the first review of a new question
+	EaseFactorAfter  float64     `json:"ease_factor_after"`
+	IntervalDays     int         `json:"interval_days"` // Interval chosen by the scheduler
+	Solve            Solve       `json:"solve,omitzero"`
+	ReviewedAt       time.Time   `json:"reviewed_at"` // CreatedAt of the delta that recorded the review
+	Reverted         bool        `json:"reverted,omitempty"`
+}
+
+// RecordedBy reports whether the event is the review the delta recorded. A delta is identified
+// by its question and the time it was created, which its review event is logged with.
+func (e ReviewEvent) RecordedBy(delta Delta) bool {
+	return (delta.Action == ActionAdd || delta.Action == ActionUpdate) &&
+		e.QuestionID == delta.QuestionID && e.ReviewedAt.Equal(delta.CreatedAt)
+}
+
 // SearchFilter defines filtering criteria for question search
 type SearchFilter struct {
-	Familiarity *Familiarity `json:"familiarity,omitempty"`
-	Importance  *Importance  `json:"importance,omitempty"`
-	ReviewCount *int         `json:"review_count,omitempty"`
-	DueOnly     bool         `json:"due_only,omitempty"`
+	Familiarity *Familiarity   `json:"familiarity,omitempty"`
+	Importance  *Importance    `json:"importance,omitempty"`
+	ReviewCount *int           `json:"review_count,omitempty"`
+	DueOnly     bool           `json:"due_only,omitempty"`
+	State       *QuestionState `json:"state,omitempty"`
+	Tags        []string       `json:"tags,omitempty"`         // Questions must have all of these tags
+	ExcludeTags []string       `json:"exclude_tags,omitempty"` // Questions must have none of these tags
 }
@@ -1,6 +1,10 @@
 package core
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestPlatformString(t *testing.T) {
 	tests := []struct {
@@ -29,6 +33,7 @@ func TestActionTypeString(t *testing.T) {
 		{ActionAdd, "Add"},
 		{ActionUpdate, "Update"},
 		{ActionDelete, "Delete"},
+		{ActionImport, "Import"},
 		{ActionType("unknown"), ""},
 	}
 
@@ -49,6 +54,7 @@ func TestActionTypePastTenseString(t *testing.T) {
 		{ActionAdd, "Added"},
 		{ActionUpdate, "Updated"},
 		{ActionDelete, "Deleted"},
+		{ActionImport, "Imported"},
 		{ActionType("unknown"), ""},
 	}
 
@@ -60,3 +66,99 @@ func TestActionTypePastTenseString(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{"lowercases and sorts", []string{"Graph", "DP"}, []string{"dp", "graph"}},
+		{"strips hash and spaces", []string{" #bfs ", "#Two-Pointers"}, []string{"bfs", "two-pointers"}},
+		{"removes duplicates and empties", []string{"dp", "", "DP", "#"}, []string{"dp"}},
+		{"nil input", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTags(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("NormalizeTags(%v) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuestionHasTag(t *testing.T) {
+	q := &Question{Tags: []string{"dp", "graph"}}
+	if !q.HasTag("dp") {
+		t.Error("Expected question to have tag dp")
+	}
+	if q.HasTag("bfs") {
+		t.Error("Expected question not to have tag bfs")
+	}
+}
+
+func TestQuestionEqual(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	q := &Question{ID: 1, URL: "https://leetcode.com/problems/two-sum/", Tags: []string{"array"}, NextReview: at}
+
+	// The same instant in another location is the same time
+	local := *q
+	local.NextReview = at.In(time.FixedZone("UTC+8", 8*60*60))
+	if !q.Equal(&local) {
+		t.Error("Expected questions with the same instant to be equal")
+	}
+
+	tagged := *q
+	tagged.Tags = []string{"array", "hash-table"}
+	if q.Equal(&tagged) {
+		t.Error("Expected questions with different tags to differ")
+	}
+}
+
+func TestParseQuestionState(t *testing.T) {
+	tests := []struct {
+		input string
+		want  QuestionState
+		ok    bool
+	}{
+		{"active", StateActive, true},
+		{"", StateActive, true},
+		{" Suspended ", StateSuspended, true},
+		{"buried", StateBuried, true},
+		{"mastered", StateArchived, true},
+		{"frozen", StateActive, false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := ParseQuestionState(tt.input); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseQuestionState(%q) = %q, %t, want %q, %t", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestQuestionBurial(t *testing.T) {
+	today := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	q := &Question{State: StateBuried, NextReview: today, BuriedUntil: today.AddDate(0, 0, 2)}
+
+	if !q.IsScheduled() || q.StateOn(today) != StateBuried {
+		t.Errorf("Expected a scheduled, buried question, got %q", q.StateOn(today))
+	}
+	if !q.ReviewDate().Equal(q.BuriedUntil) {
+		t.Errorf("Expected the question to come due as the burial ends, got %v", q.ReviewDate())
+	}
+	if q.StateOn(q.BuriedUntil) != StateActive {
+		t.Errorf("Expected the burial to be over on its last day, got %q", q.StateOn(q.BuriedUntil))
+	}
+
+	// A burial that ends before the next review does not move it
+	q.NextReview = today.AddDate(0, 0, 5)
+	if !q.ReviewDate().Equal(q.NextReview) {
+		t.Errorf("Expected the next review, got %v", q.ReviewDate())
+	}
+
+	q.State = StateSuspended
+	if q.IsScheduled() || q.StateOn(today) != StateSuspended || StateActive.String() != "active" {
+		t.Errorf("Expected a suspended question not to be scheduled, got %q", q.StateOn(today))
+	}
+}
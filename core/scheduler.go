@@ -12,7 +12,67 @@ import (
 type Scheduler interface {
 	ScheduleNewQuestion(q *Question, memory MemoryUse) *Question
 	Schedule(q *Question, memory MemoryUse)
-	CalculatePriorityScore(q *Question) float64
+	// CalculatePriorityScore scores a due question; pausedSince is the day reviews were paused on,
+	// zero when they are not, so that scoring a whole list reads the pause once
+	CalculatePriorityScore(q *Question, pausedSince time.Time) float64
+}
+
+// NewScheduler creates the scheduler selected by the Algorithm setting.
+func NewScheduler(cfg *config.Config, clock clock.Clock) Scheduler {
+	return NewSchedulerWithRand(cfg, clock, DefaultRand{})
+}
+
+// NewSchedulerWithRand creates the scheduler selected by the Algorithm setting with the given Rand.
+func NewSchedulerWithRand(cfg *config.Config, clock clock.Clock, rand Rand) Scheduler {
+	return NewSchedulerWithState(cfg, clock, rand, nil)
+}
+
+// NewSchedulerWithState creates the scheduler selected by the Algorithm setting that reads the
+// schedule state to balance reviews across days and to leave paused days out of overdue days.
+// A nil state does neither.
+func NewSchedulerWithState(cfg *config.Config, clock clock.Clock, rand Rand, state ScheduleState) Scheduler {
+	if cfg.Algorithm == config.AlgorithmFSRS {
+		scheduler := NewFSRSSchedulerWithRand(cfg, clock, rand)
+		scheduler.State = state
+		return scheduler
+	}
+	scheduler := NewSM2SchedulerWithRand(cfg, clock, rand)
+	scheduler.State = state
+	return scheduler
+}
+
+// ScheduleState gives a scheduler read access to the schedule of all questions
+type ScheduleState interface {
+	// DueCounts returns how many questions are due on each of the days from start on, one count
+	// per day, leaving out the question with excludeID. Questions due before start are not counted.
+	DueCounts(start time.Time, days int, excludeID int) []int
+	// PausedSince returns the day reviews were paused on, if they are paused
+	PausedSince() (time.Time, bool)
+}
+
+// overdueDays returns the number of days since the question came due, negative when it is not
+// due yet. The days of a pause ongoing since pausedSince do not count; resuming moves the next
+// reviews instead. A zero pausedSince means reviews are not paused.
+func overdueDays(q *Question, today, pausedSince time.Time) int {
+	days := int(today.Sub(q.NextReview).Hours() / 24)
+	if !pausedSince.IsZero() && days > 0 {
+		since := pausedSince
+		if since.Before(q.NextReview) {
+			since = q.NextReview
+		}
+		days -= max(0, int(today.Sub(since).Hours()/24))
+	}
+	return days
+}
+
+// pausedSince returns the day reviews were paused on according to the state, or zero when they
+// are not paused or there is no state
+func pausedSince(state ScheduleState) time.Time {
+	if state == nil {
+		return time.Time{}
+	}
+	since, _ := state.PausedSince()
+	return since
 }
 
 // Rand abstracts random number generation for testability.
@@ -33,31 +93,38 @@ type FixedRand struct {
 
 func (f FixedRand) IntN(_ int) int { return f.Value }
 
-// SM2Scheduler implements the spaced repetition scheduling logic
+// Ease factor bounds shared by the schedulers
+const (
+	defaultMinEaseFactor = config.MinEaseFactor
+	defaultMaxEaseFactor = config.MaxEaseFactor
+)
+
+// ClampEaseFactor limits an ease factor to the bounds shared by the schedulers
+func ClampEaseFactor(easeFactor float64) float64 {
+	return math.Min(math.Max(easeFactor, defaultMinEaseFactor), defaultMaxEaseFactor)
+}
+
+// levelSetting returns the value of a map setting for a level; the level of a key is its index in
+// levels. A level out of range has the zero value.
+func levelSetting[L ~int, V any](values map[string]V, levels []string, level L) V {
+	if level < 0 || int(level) >= len(levels) {
+		var zero V
+		return zero
+	}
+	return values[levels[level]]
+}
+
+// SM2Scheduler implements the spaced repetition scheduling logic. The settings are read from cfg
+// each time a question is scheduled, so a changed setting applies to the next review.
 type SM2Scheduler struct {
 	cfg   *config.Config
 	Clock clock.Clock
 	Rand  Rand
+	State ScheduleState // Read to balance reviews and leave out paused days; nil for neither
 
-	// Interval settings (in days)
-	maxInterval       int
-	baseIntervals     map[Importance]int
-	memoryMultipliers map[MemoryUse]float64
-
-	// Ease Factor settings
-	minEaseFactor          float64
-	maxEaseFactor          float64
-	startEaseFactors       map[Importance]float64
-	importanceEaseBonus    map[Importance]float64
-	familiarityEasePenalty map[Familiarity]float64
-	memoryEasePenalty      map[MemoryUse]float64
-
-	// Due Priority List settings
-	importanceWeight    float64
-	overdueWeight       float64
-	familiarityWeight   float64
-	reviewPenaltyWeight float64
-	easePenaltyWeight   float64
+	// Ease Factor bounds
+	minEaseFactor float64
+	maxEaseFactor float64
 }
 
 func NewSM2Scheduler(cfg *config.Config, clock clock.Clock) *SM2Scheduler {
@@ -70,76 +137,58 @@ func NewSM2SchedulerWithRand(cfg *config.Config, clock clock.Clock, rand Rand) *
 		Clock: clock,
 		Rand:  rand,
 
-		// Interval settings (in days)
-		maxInterval: 90,
-		baseIntervals: map[Importance]int{
-			LowImportance:      8,
-			MediumImportance:   6,
-			HighImportance:     5,
-			CriticalImportance: 4,
-		},
-		memoryMultipliers: map[MemoryUse]float64{
-			MemoryReasoned: 1.00, // don't change
-			MemoryPartial:  1.10, // give more forgetting time
-			MemoryFull:     1.25, // give even more forgetting time
-		},
-
-		// Ease Factor settings
-		minEaseFactor: 1.3,
-		maxEaseFactor: 2.6,
-		startEaseFactors: map[Importance]float64{
-			LowImportance:      2.0,
-			MediumImportance:   1.9,
-			HighImportance:     1.8,
-			CriticalImportance: 1.7,
-		},
-		importanceEaseBonus: map[Importance]float64{
-			LowImportance:      0.15,
-			MediumImportance:   0.10,
-			HighImportance:     0.05,
-			CriticalImportance: 0.03,
-		},
-		familiarityEasePenalty: map[Familiarity]float64{
-			VeryHard: -0.40,
-			Hard:     -0.25,
-			Medium:   -0.10,
-			Easy:     0.05,
-			VeryEasy: 0.15,
-		},
-		memoryEasePenalty: map[MemoryUse]float64{
-			MemoryReasoned: 0.00,
-			MemoryPartial:  -0.02,
-			MemoryFull:     -0.05,
-		},
-
-		// Due Priority List settings
-		importanceWeight:    cfg.ImportanceWeight,    // Prioritizes designated importance
-		overdueWeight:       cfg.OverdueWeight,       // Prioritizes items past their due date
-		familiarityWeight:   cfg.FamiliarityWeight,   // Prioritizes historically difficult items
-		reviewPenaltyWeight: cfg.ReviewPenaltyWeight, // De-prioritizes questions seen many times (prevents leeching)
-		easePenaltyWeight:   cfg.EasePenaltyWeight,   // De-prioritizes "easier" questions to focus on struggles
+		// Ease Factor bounds
+		minEaseFactor: defaultMinEaseFactor,
+		maxEaseFactor: defaultMaxEaseFactor,
 	}
 }
 
+// Interval settings (in days)
+
+func (s SM2Scheduler) maxInterval() int { return s.cfg.MaxInterval }
+
+func (s SM2Scheduler) baseInterval(importance Importance) int {
+	return levelSetting(s.cfg.BaseIntervals, config.ImportanceLevels, importance)
+}
+
+func (s SM2Scheduler) newQuestionBonus(familiarity Familiarity) int {
+	return levelSetting(s.cfg.NewQuestionBonus, config.FamiliarityLevels, familiarity)
+}
+
+func (s SM2Scheduler) memoryMultiplier(memory MemoryUse) float64 {
+	return levelSetting(s.cfg.MemoryMultipliers, config.MemoryLevels, memory)
+}
+
+// Ease Factor settings
+
+func (s SM2Scheduler) startEaseFactor(importance Importance) float64 {
+	return levelSetting(s.cfg.StartEaseFactors, config.ImportanceLevels, importance)
+}
+
+func (s SM2Scheduler) importanceEaseBonus(importance Importance) float64 {
+	return levelSetting(s.cfg.ImportanceEaseBonus, config.ImportanceLevels, importance)
+}
+
+func (s SM2Scheduler) familiarityEasePenalty(familiarity Familiarity) float64 {
+	return levelSetting(s.cfg.FamiliarityEasePenalty, config.FamiliarityLevels, familiarity)
+}
+
+func (s SM2Scheduler) memoryEasePenalty(memory MemoryUse) float64 {
+	return levelSetting(s.cfg.MemoryEasePenalty, config.MemoryLevels, memory)
+}
+
 func (s SM2Scheduler) ScheduleNewQuestion(q *Question, memory MemoryUse) *Question {
 	today := s.Clock.Today()
 
-	q.EaseFactor = s.startEaseFactors[q.Importance]
+	q.EaseFactor = s.startEaseFactor(q.Importance)
 	q.ReviewCount = 1
 	q.LastReviewed = today
 
-	intervalDays := s.baseIntervals[q.Importance]
+	intervalDays := s.baseInterval(q.Importance)
 
 	// Small tweaks to interval for early grading signal
-	switch q.Familiarity {
-	case VeryEasy:
-		intervalDays += 7
-	case Easy:
-		intervalDays += 5
-	case Medium:
-		intervalDays += 2
-	}
-	intervalDays = int(math.Round(float64(intervalDays) * s.memoryMultipliers[memory]))
+	intervalDays += s.newQuestionBonus(q.Familiarity)
+	intervalDays = int(math.Round(float64(intervalDays) * s.memoryMultiplier(memory)))
 
 	s.setNextReview(q, today, intervalDays)
 	return q
@@ -149,7 +198,7 @@ func (s SM2Scheduler) Schedule(q *Question, memory MemoryUse) {
 	q.ReviewCount++
 	today := s.Clock.Today()
 
-	baseInterval := s.baseIntervals[q.Importance]
+	baseInterval := s.baseInterval(q.Importance)
 
 	// Reset if still struggling
 	if q.Familiarity == VeryHard {
@@ -169,7 +218,7 @@ func (s SM2Scheduler) Schedule(q *Question, memory MemoryUse) {
 	if prevIntervalDays < 1 {
 		prevIntervalDays = baseInterval // fallback
 	}
-	intervalDays := int(math.Round(float64(prevIntervalDays) * q.EaseFactor * s.memoryMultipliers[memory]))
+	intervalDays := int(math.Round(float64(prevIntervalDays) * q.EaseFactor * s.memoryMultiplier(memory)))
 
 	s.setNextReview(q, today, intervalDays)
 	s.setEaseFactor(q, memory)
@@ -177,28 +226,14 @@ func (s SM2Scheduler) Schedule(q *Question, memory MemoryUse) {
 }
 
 func (s SM2Scheduler) setNextReview(q *Question, date time.Time, intervalDays int) {
-
-	// Randomize interval to avoid over-fitting to a specific date
-	if s.cfg.RandomizeInterval {
-		// Randomize between -1 and +2 days
-		// IntN(4) produces 0,1,2,3; subtracting 1 gives -1,0,1,2
-		intervalDays += s.Rand.IntN(4) - 1
-	}
-
-	// Secure bounds
-	if intervalDays < 1 {
-		intervalDays = 1
-	} else if intervalDays > s.maxInterval {
-		intervalDays = s.maxInterval
-	}
-
+	intervalDays = spreadInterval(s.cfg, s.Clock, s.Rand, s.State, q, date, intervalDays, s.maxInterval())
 	q.NextReview = s.Clock.AddDays(date, intervalDays)
 }
 
 func (s SM2Scheduler) setEaseFactor(q *Question, memory MemoryUse) {
-	bonus := s.importanceEaseBonus[q.Importance]
-	penalty := s.familiarityEasePenalty[q.Familiarity]
-	memoryPenalty := s.memoryEasePenalty[memory]
+	bonus := s.importanceEaseBonus(q.Importance)
+	penalty := s.familiarityEasePenalty(q.Familiarity)
+	memoryPenalty := s.memoryEasePenalty(memory)
 
 	// Apply core adjustments
 	q.EaseFactor += bonus
@@ -222,18 +257,18 @@ func (s SM2Scheduler) setEaseFactorOverduePenalty(q *Question) {
 	today := s.Clock.Today()
 
 	overdueLimit := s.cfg.OverdueLimit
-	overdueDays := int(today.Sub(q.NextReview).Hours() / 24)
+	overdueDays := overdueDays(q, today, pausedSince(s.State))
 	if overdueDays > overdueLimit && q.Importance > LowImportance && q.Familiarity < VeryEasy {
 		penaltyFactor := math.Min(float64(overdueDays-overdueLimit)*0.01, 0.1)
 		q.EaseFactor -= penaltyFactor
 	}
 }
 
-func (s SM2Scheduler) CalculatePriorityScore(q *Question) float64 {
+func (s SM2Scheduler) CalculatePriorityScore(q *Question, pausedSince time.Time) float64 {
 	today := s.Clock.Today()
 
 	// Compute overdue days (at least 0)
-	overdueDays := int(today.Sub(q.NextReview).Hours() / 24)
+	overdueDays := overdueDays(q, today, pausedSince)
 	if overdueDays < 0 {
 		overdueDays = 0
 	}
@@ -241,11 +276,12 @@ func (s SM2Scheduler) CalculatePriorityScore(q *Question) float64 {
 	// Invert Familiarity (VeryEasy = 0, VeryHard = 4)
 	famScore := 4 - int(q.Familiarity)
 
-	score := s.importanceWeight*float64(q.Importance) +
-		s.overdueWeight*float64(overdueDays) +
-		s.familiarityWeight*float64(famScore) +
-		s.reviewPenaltyWeight*float64(q.ReviewCount) +
-		s.easePenaltyWeight*q.EaseFactor
+	score := s.cfg.ImportanceWeight*float64(q.Importance) +
+		s.cfg.OverdueWeight*float64(overdueDays) +
+		s.cfg.FamiliarityWeight*float64(famScore) +
+		s.cfg.ReviewPenaltyWeight*float64(q.ReviewCount) +
+		s.cfg.EasePenaltyWeight*q.EaseFactor +
+		leechPriority(s.cfg, q)
 
 	return score
 }
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -80,8 +81,8 @@ func TestNewSM2Scheduler(t *testing.T) {
 
 	// Test interval settings
 	expectedMaxInterval := 90
-	if scheduler.maxInterval != expectedMaxInterval {
-		t.Errorf("Expected maxInterval to be %d, got %d", expectedMaxInterval, scheduler.maxInterval)
+	if scheduler.maxInterval() != expectedMaxInterval {
+		t.Errorf("Expected maxInterval to be %d, got %d", expectedMaxInterval, scheduler.maxInterval())
 	}
 
 	// Test base intervals
@@ -92,8 +93,8 @@ func TestNewSM2Scheduler(t *testing.T) {
 		CriticalImportance: 4,
 	}
 	for importance, expected := range expectedBaseIntervals {
-		if scheduler.baseIntervals[importance] != expected {
-			t.Errorf("Expected baseInterval for %v to be %d, got %d", importance, expected, scheduler.baseIntervals[importance])
+		if scheduler.baseInterval(importance) != expected {
+			t.Errorf("Expected baseInterval for %v to be %d, got %d", importance, expected, scheduler.baseInterval(importance))
 		}
 	}
 
@@ -104,8 +105,21 @@ func TestNewSM2Scheduler(t *testing.T) {
 		MemoryFull:     1.25,
 	}
 	for memory, expected := range expectedMemoryMultipliers {
-		if scheduler.memoryMultipliers[memory] != expected {
-			t.Errorf("Expected memoryMultiplier for %v to be %f, got %f", memory, expected, scheduler.memoryMultipliers[memory])
+		if scheduler.memoryMultiplier(memory) != expected {
+			t.Errorf("Expected memoryMultiplier for %v to be %f, got %f", memory, expected, scheduler.memoryMultiplier(memory))
+		}
+	}
+
+	// Test start ease factors
+	expectedStartEaseFactors := map[Importance]float64{
+		LowImportance:      2.0,
+		MediumImportance:   1.9,
+		HighImportance:     1.8,
+		CriticalImportance: 1.7,
+	}
+	for importance, expected := range expectedStartEaseFactors {
+		if scheduler.startEaseFactor(importance) != expected {
+			t.Errorf("Expected startEaseFactor for %v to be %f, got %f", importance, expected, scheduler.startEaseFactor(importance))
 		}
 	}
 
@@ -118,6 +132,42 @@ func TestNewSM2Scheduler(t *testing.T) {
 	}
 }
 
+func TestNewSM2Scheduler_ConfiguredConstants(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+	// Settings changed after the scheduler is created apply to the next schedule
+	scheduler := NewSM2SchedulerWithRand(cfg, mockClock, FixedRand{Value: 1})
+	if err := cfg.SetSettingValue("maxinterval", 30); err != nil {
+		t.Fatalf("Failed to set MaxInterval: %v", err)
+	}
+	if err := cfg.SetSettingValue("baseintervals", map[string]int{"medium": 3}); err != nil {
+		t.Fatalf("Failed to set BaseIntervals: %v", err)
+	}
+	if err := cfg.SetSettingValue("newquestionbonus", map[string]int{"medium": 0}); err != nil {
+		t.Fatalf("Failed to set NewQuestionBonus: %v", err)
+	}
+
+	if scheduler.maxInterval() != 30 || scheduler.baseInterval(LowImportance) != 8 {
+		t.Errorf("Expected MaxInterval 30 and the other base intervals kept, got %d and %d", scheduler.maxInterval(), scheduler.baseInterval(LowImportance))
+	}
+
+	question := &Question{Importance: MediumImportance, Familiarity: Medium}
+	scheduler.ScheduleNewQuestion(question, MemoryReasoned)
+	if days := int(question.NextReview.Sub(mockClock.Today()).Hours() / 24); days != 3 {
+		t.Errorf("Expected the configured 3 day first interval, got %d", days)
+	}
+
+	// Long intervals stop at the configured maximum
+	question.Familiarity = VeryEasy
+	question.EaseFactor = 2.6
+	question.LastReviewed = mockClock.Today().AddDate(0, 0, -25)
+	question.NextReview = mockClock.Today()
+	scheduler.Schedule(question, MemoryReasoned)
+	if days := int(question.NextReview.Sub(mockClock.Today()).Hours() / 24); days != 30 {
+		t.Errorf("Expected the interval capped at 30 days, got %d", days)
+	}
+}
+
 func TestScheduleNewQuestion(t *testing.T) {
 	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
 	_, cfg := config.MockEnv(t)
@@ -440,6 +490,69 @@ func TestSetEaseFactorOverduePenalty(t *testing.T) {
 	}
 }
 
+func TestOverdueDays_Paused(t *testing.T) {
+	today := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	dueOn := func(day int) *Question {
+		return &Question{NextReview: time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC)}
+	}
+	pausedSince := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		pausedSince time.Time
+		question    *Question
+		expected    int
+	}{
+		{"not paused", time.Time{}, dueOn(10), 9},
+		{"due before the pause", pausedSince, dueOn(10), 4},
+		{"due during the pause", pausedSince, dueOn(17), 0},
+		{"not due yet", pausedSince, dueOn(25), -5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overdueDays(tt.question, today, tt.pausedSince); got != tt.expected {
+				t.Errorf("Expected %d overdue days, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCalculatePriorityScore_PausedSince(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+	// The score reads the pause it is given, not the state, so a list reads the pause only once
+	state := &mockState{pausedSince: time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC), paused: true}
+	scheduler := NewSchedulerWithState(cfg, mockClock, FixedRand{Value: 1}, state)
+
+	question := &Question{Importance: HighImportance, Familiarity: Medium, NextReview: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)}
+	notPaused := scheduler.CalculatePriorityScore(question, time.Time{})
+	paused := scheduler.CalculatePriorityScore(question, state.pausedSince)
+	if want := notPaused - cfg.OverdueWeight*8; math.Abs(paused-want) > 1e-9 {
+		t.Errorf("Expected the 8 paused days to leave the score, got %v, want %v", paused, want)
+	}
+}
+
+func TestSetEaseFactorOverduePenalty_Paused(t *testing.T) {
+	mockClock := NewMockClock(time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC))
+	_, cfg := config.MockEnv(t)
+	cfg.OverduePenalty = true
+	state := &mockState{pausedSince: time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC), paused: true}
+	scheduler := NewSchedulerWithState(cfg, mockClock, FixedRand{Value: 1}, state).(*SM2Scheduler)
+
+	// 10 days overdue, but only 2 of them before the pause
+	question := &Question{
+		Importance:  HighImportance,
+		Familiarity: Hard,
+		EaseFactor:  1.8,
+		NextReview:  time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC),
+	}
+	scheduler.setEaseFactorOverduePenalty(question)
+	if question.EaseFactor != 1.8 {
+		t.Errorf("Expected no penalty for the paused days, got EaseFactor %f", question.EaseFactor)
+	}
+}
+
 func TestSetNextReview(t *testing.T) {
 	mockClock := NewMockClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
 	_, cfg := config.MockEnv(t)
@@ -551,7 +664,7 @@ func TestCalculatePriorityScore(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			score := scheduler.CalculatePriorityScore(tt.question)
+			score := scheduler.CalculatePriorityScore(tt.question, time.Time{})
 			if err := tt.check(score); err != nil {
 				t.Errorf("Check failed: %v", err)
 			}
@@ -581,7 +694,7 @@ func TestSchedulerInterface(t *testing.T) {
 	scheduler.Schedule(question, MemoryPartial)
 
 	// Test CalculatePriorityScore
-	score := scheduler.CalculatePriorityScore(question)
+	score := scheduler.CalculatePriorityScore(question, time.Time{})
 	if score < 0 {
 		t.Error("Expected priority score to be non-negative")
 	}
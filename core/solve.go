@@ -0,0 +1,87 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/eannchen/leetsolv/config"
+)
+
+// Solve is how solving a question went, as told when it is added or reviewed. Every part is
+// optional; a zero value was not recorded.
+type Solve struct {
+	Minutes   int   `json:"minutes,omitempty"`    // Time to solve
+	FirstPass *bool `json:"first_pass,omitempty"` // Whether the first submission passed
+	Attempts  int   `json:"attempts,omitempty"`   // Submissions until one passed
+}
+
+// IsZero reports whether nothing about the solve was recorded
+func (s Solve) IsZero() bool {
+	return s.Minutes == 0 && s.FirstPass == nil && s.Attempts == 0
+}
+
+// Equal reports whether both solves recorded the same parts
+func (s Solve) Equal(other Solve) bool {
+	samePass := (s.FirstPass == nil) == (other.FirstPass == nil) && (s.FirstPass == nil || *s.FirstPass == *other.FirstPass)
+	return s.Minutes == other.Minutes && s.Attempts == other.Attempts && samePass
+}
+
+// Retried reports whether the solve needed more than one submission
+func (s Solve) Retried() bool {
+	return (s.FirstPass != nil && !*s.FirstPass) || s.Attempts > 1
+}
+
+// Valid reports whether the parts of the solve agree with each other
+func (s Solve) Valid() bool {
+	if s.Minutes < 0 || s.Attempts < 0 {
+		return false
+	}
+	return s.FirstPass == nil || s.Attempts == 0 || *s.FirstPass == (s.Attempts == 1)
+}
+
+// Normalized fills in the part of the solve that the others tell: a first pass takes one
+// attempt, and the number of attempts tells whether the first submission passed
+func (s Solve) Normalized() Solve {
+	switch {
+	case s.FirstPass == nil && s.Attempts > 0:
+		firstPass := s.Attempts == 1
+		s.FirstPass = &firstPass
+	case s.FirstPass != nil && *s.FirstPass && s.Attempts == 0:
+		s.Attempts = 1
+	}
+	return s
+}
+
+// Timer is a running solve timer. It keeps the URL rather than the question, as the question
+// being solved may not have been added yet.
+type Timer struct {
+	URL   string    `json:"url"`
+	Start time.Time `json:"start"`
+}
+
+// Minutes is the time since the timer started, rounded to whole minutes and at least one
+func (t Timer) Minutes(now time.Time) int {
+	return max(1, int(math.Round(now.Sub(t.Start).Minutes())))
+}
+
+// ApplySolve records the solve of a reviewed question, after it has been scheduled, and lowers
+// its SM-2 ease factor when the solve took longer than the last timed solve or needed more than
+// one submission. A review without a recorded solve keeps the last one.
+func ApplySolve(cfg *config.Config, q *Question, solve Solve) {
+	if solve.IsZero() {
+		return
+	}
+	previous := q.LastSolve
+	q.LastSolve = solve
+	if cfg.Algorithm != config.AlgorithmSM2 {
+		return
+	}
+
+	if solve.Minutes > 0 && previous.Minutes > 0 && solve.Minutes > previous.Minutes {
+		q.EaseFactor -= cfg.SlowerSolvePenalty
+	}
+	if solve.Retried() {
+		q.EaseFactor -= cfg.RetryPenalty
+	}
+	q.EaseFactor = ClampEaseFactor(q.EaseFactor)
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/config"
+)
+
+func TestApplySolve(t *testing.T) {
+	_, cfg := config.MockEnv(t)
+	cfg.SlowerSolvePenalty = 0.1
+	cfg.RetryPenalty = 0.05
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		previous Solve
+		solve    Solve
+		ease     float64
+	}{
+		{name: "Nothing recorded", previous: Solve{Minutes: 20}, ease: 2.0},
+		{name: "First timed solve", solve: Solve{Minutes: 30}, ease: 2.0},
+		{name: "Faster than the last solve", previous: Solve{Minutes: 20}, solve: Solve{Minutes: 15, FirstPass: &yes}, ease: 2.0},
+		{name: "Slower than the last solve", previous: Solve{Minutes: 20}, solve: Solve{Minutes: 25}, ease: 1.9},
+		{name: "Failed first submission", solve: Solve{FirstPass: &no}, ease: 1.95},
+		{name: "Slower and retried", previous: Solve{Minutes: 20}, solve: Solve{Minutes: 40, Attempts: 3}, ease: 1.85},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Question{EaseFactor: 2.0, LastSolve: tt.previous}
+			ApplySolve(cfg, &q, tt.solve)
+			if math.Abs(q.EaseFactor-tt.ease) > 1e-9 {
+				t.Errorf("Expected ease factor %.2f, got %.2f", tt.ease, q.EaseFactor)
+			}
+			want := tt.solve
+			if want.IsZero() {
+				want = tt.previous
+			}
+			if !q.LastSolve.Equal(want) {
+				t.Errorf("Expected last solve %+v, got %+v", want, q.LastSolve)
+			}
+		})
+	}
+}
+
+func TestApplySolve_Bounds(t *testing.T) {
+	_, cfg := config.MockEnv(t)
+	cfg.RetryPenalty = 0.5
+
+	q := Question{EaseFactor: 1.4}
+	ApplySolve(cfg, &q, Solve{Attempts: 2})
+	if q.EaseFactor != config.MinEaseFactor {
+		t.Errorf("Expected the ease factor to stop at the minimum, got %.2f", q.EaseFactor)
+	}
+
+	// FSRS does not use the ease factor, so the solve is only recorded
+	cfg.Algorithm = config.AlgorithmFSRS
+	q = Question{EaseFactor: 2.0}
+	ApplySolve(cfg, &q, Solve{Attempts: 2})
+	if q.EaseFactor != 2.0 || q.LastSolve.Attempts != 2 {
+		t.Errorf("Expected the solve to be recorded without an ease change, got %+v", q)
+	}
+}
+
+func TestSolveValid(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		solve Solve
+		valid bool
+	}{
+		{Solve{}, true},
+		{Solve{Minutes: 25, FirstPass: &yes, Attempts: 1}, true},
+		{Solve{FirstPass: &no, Attempts: 3}, true},
+		{Solve{FirstPass: &yes, Attempts: 2}, false},
+		{Solve{FirstPass: &no, Attempts: 1}, false},
+		{Solve{Minutes: -5}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.solve.Valid(); got != tt.valid {
+			t.Errorf("Expected %+v to be valid: %t, got %t", tt.solve, tt.valid, got)
+		}
+	}
+}
+
+func TestSolveNormalized(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		solve Solve
+		want  Solve
+	}{
+		{Solve{Minutes: 10}, Solve{Minutes: 10}},
+		{Solve{Attempts: 1}, Solve{Attempts: 1, FirstPass: &yes}},
+		{Solve{Attempts: 3}, Solve{Attempts: 3, FirstPass: &no}},
+		{Solve{FirstPass: &yes}, Solve{Attempts: 1, FirstPass: &yes}},
+		{Solve{FirstPass: &no}, Solve{FirstPass: &no}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.solve.Normalized(); !got.Equal(tt.want) {
+			t.Errorf("Expected %+v to normalize to %+v, got %+v", tt.solve, tt.want, got)
+		}
+	}
+}
+
+func TestTimerMinutes(t *testing.T) {
+	start := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	timer := Timer{URL: "https://leetcode.com/problems/two-sum", Start: start}
+
+	tests := []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, 1},
+		{20 * time.Second, 1},
+		{29*time.Minute + 29*time.Second, 29},
+		{29*time.Minute + 30*time.Second, 30},
+		{26 * time.Hour, 26 * 60},
+	}
+
+	for _, tt := range tests {
+		if got := timer.Minutes(start.Add(tt.elapsed)); got != tt.want {
+			t.Errorf("Expected %v to be %d minutes, got %d", tt.elapsed, tt.want, got)
+		}
+	}
+}
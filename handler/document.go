@@ -0,0 +1,721 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/backup"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/usecase"
+)
+
+// OutputFormat selects how command results are rendered
+type OutputFormat string
+
+const (
+	FormatText OutputFormat = "text"
+	FormatJSON OutputFormat = "json"
+	FormatTSV  OutputFormat = "tsv"
+)
+
+// ParseOutputFormat validates a --format value
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	switch format := OutputFormat(strings.ToLower(value)); format {
+	case FormatText, FormatJSON, FormatTSV:
+		return format, nil
+	}
+	return "", errs.WrapValidationError(fmt.Errorf("unknown output format %q", value), "Output format must be text, json or tsv")
+}
+
+// ExtractOutputFormat removes the global --json and --format=<format> flags from the arguments
+// and returns the selected format with the remaining arguments.
+func ExtractOutputFormat(args []string) (OutputFormat, []string, error) {
+	format := FormatText
+	remaining := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		switch {
+		case arg == "--json":
+			format = FormatJSON
+		case strings.HasPrefix(arg, "--format="):
+			parsed, err := ParseOutputFormat(strings.TrimPrefix(arg, "--format="))
+			if err != nil {
+				return "", nil, err
+			}
+			format = parsed
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+
+	return format, remaining, nil
+}
+
+// Document is a command result rendered by the structured output formats.
+// JSON output encodes the document itself; TSV output writes Header followed by Rows.
+type Document interface {
+	Header() []string
+	Rows() [][]string
+}
+
+// QuestionView is the stable machine-readable form of a question.
+// Levels are 1-based to match the values accepted on the command line.
+type QuestionView struct {
+	ID           int        `json:"id"`
+	URL          string     `json:"url"`
+	Note         string     `json:"note"`
+	Tags         []string   `json:"tags"`
+	Familiarity  int        `json:"familiarity"`
+	Importance   int        `json:"importance"`
+	LastReviewed time.Time  `json:"last_reviewed"`
+	NextReview   time.Time  `json:"next_review"`
+	ReviewCount  int        `json:"review_count"`
+	EaseFactor   float64    `json:"ease_factor"`
+	Stability    float64    `json:"stability,omitempty"`
+	Difficulty   float64    `json:"difficulty,omitempty"`
+	Lapses       int        `json:"lapses,omitempty"`
+	Leech        bool       `json:"leech,omitempty"`
+	State        string     `json:"state,omitempty"` // Empty for an active question
+	BuriedUntil  time.Time  `json:"buried_until,omitzero"`
+	LastSolve    core.Solve `json:"last_solve,omitzero"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func newQuestionView(q *core.Question) QuestionView {
+	tags := q.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return QuestionView{
+		ID:           q.ID,
+		URL:          q.URL,
+		Note:         q.Note,
+		Tags:         tags,
+		Familiarity:  int(q.Familiarity) + 1,
+		Importance:   int(q.Importance) + 1,
+		LastReviewed: q.LastReviewed.UTC(),
+		NextReview:   q.NextReview.UTC(),
+		ReviewCount:  q.ReviewCount,
+		EaseFactor:   q.EaseFactor,
+		Stability:    q.Stability,
+		Difficulty:   q.Difficulty,
+		Lapses:       q.Lapses,
+		Leech:        q.Leech,
+		State:        string(q.State),
+		BuriedUntil:  q.BuriedUntil.UTC(),
+		LastSolve:    q.LastSolve,
+		CreatedAt:    q.CreatedAt.UTC(),
+	}
+}
+
+func newQuestionViews(questions []core.Question) []QuestionView {
+	views := make([]QuestionView, 0, len(questions))
+	for i := range questions {
+		views = append(views, newQuestionView(&questions[i]))
+	}
+	return views
+}
+
+var questionHeader = []string{"id", "url", "note", "tags", "familiarity", "importance", "last_reviewed", "next_review", "review_count", "ease_factor"}
+
+func (v QuestionView) row() []string {
+	return []string{
+		strconv.Itoa(v.ID),
+		v.URL,
+		v.Note,
+		strings.Join(v.Tags, ","),
+		strconv.Itoa(v.Familiarity),
+		strconv.Itoa(v.Importance),
+		v.LastReviewed.Format(time.RFC3339),
+		v.NextReview.Format(time.RFC3339),
+		strconv.Itoa(v.ReviewCount),
+		strconv.FormatFloat(v.EaseFactor, 'f', 2, 64),
+	}
+}
+
+// QuestionDocument is the result of the detail command
+type QuestionDocument struct {
+	Question QuestionView `json:"question"`
+}
+
+func (d QuestionDocument) Header() []string { return questionHeader }
+func (d QuestionDocument) Rows() [][]string { return [][]string{d.Question.row()} }
+
+// QuestionListDocument is the result of the list and search commands
+type QuestionListDocument struct {
+	Total     int            `json:"total"`
+	Questions []QuestionView `json:"questions"`
+}
+
+func newQuestionListDocument(questions []core.Question) QuestionListDocument {
+	return QuestionListDocument{Total: len(questions), Questions: newQuestionViews(questions)}
+}
+
+func (d QuestionListDocument) Header() []string { return questionHeader }
+func (d QuestionListDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Questions))
+	for _, q := range d.Questions {
+		rows = append(rows, q.row())
+	}
+	return rows
+}
+
+// LeechesDocument is the result of the leeches command
+type LeechesDocument struct {
+	Leeches []QuestionView `json:"leeches"`
+}
+
+func newLeechesDocument(leeches []core.Question) LeechesDocument {
+	return LeechesDocument{Leeches: newQuestionViews(leeches)}
+}
+
+// Header follows the question columns with the lapses and the lifecycle state
+func (d LeechesDocument) Header() []string {
+	return append(slices.Clone(questionHeader), "lapses", "state")
+}
+func (d LeechesDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Leeches))
+	for _, q := range d.Leeches {
+		rows = append(rows, append(q.row(), strconv.Itoa(q.Lapses), core.QuestionState(q.State).String()))
+	}
+	return rows
+}
+
+// StatusDocument is the result of the status command
+type StatusDocument struct {
+	Total         int            `json:"total"`
+	TotalDue      int            `json:"total_due"`
+	TotalUpcoming int            `json:"total_upcoming"`
+	PausedSince   string         `json:"paused_since,omitempty"` // Empty when reviews are not paused
+	Due           []QuestionView `json:"due"`
+	Upcoming      []QuestionView `json:"upcoming"`
+}
+
+func newStatusDocument(summary usecase.QuestionsSummary) StatusDocument {
+	doc := StatusDocument{
+		Total:         summary.Total,
+		TotalDue:      summary.TotalDue,
+		TotalUpcoming: summary.TotalUpcoming,
+		Due:           newQuestionViews(summary.TopDue),
+		Upcoming:      newQuestionViews(summary.TopUpcoming),
+	}
+	if !summary.PausedSince.IsZero() {
+		doc.PausedSince = summary.PausedSince.Format(time.DateOnly)
+	}
+	return doc
+}
+
+// Header prefixes the question columns with the list the question belongs to
+func (d StatusDocument) Header() []string { return append([]string{"list"}, questionHeader...) }
+func (d StatusDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Due)+len(d.Upcoming))
+	for _, q := range d.Due {
+		rows = append(rows, append([]string{"due"}, q.row()...))
+	}
+	for _, q := range d.Upcoming {
+		rows = append(rows, append([]string{"upcoming"}, q.row()...))
+	}
+	return rows
+}
+
+// DeltaView is the stable machine-readable form of a history entry
+type DeltaView struct {
+	Index      int           `json:"index,omitempty"` // Position in the history, for 'undo --to'; 0 within an import
+	QuestionID int           `json:"question_id"`
+	Action     string        `json:"action"`
+	URL        string        `json:"url"`
+	OldState   *QuestionView `json:"old_state"`
+	NewState   *QuestionView `json:"new_state"`
+	Batch      []DeltaView   `json:"batch,omitempty"` // Changes made by an import
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// HistoryDocument is the result of the history command, most recent first
+type HistoryDocument struct {
+	History []DeltaView `json:"history"`
+}
+
+func newDeltaView(delta core.Delta) DeltaView {
+	view := DeltaView{
+		QuestionID: delta.QuestionID,
+		Action:     string(delta.Action),
+		CreatedAt:  delta.CreatedAt.UTC(),
+	}
+	if delta.OldState != nil {
+		oldState := newQuestionView(delta.OldState)
+		view.OldState = &oldState
+		view.URL = delta.OldState.URL
+	}
+	if delta.NewState != nil {
+		newState := newQuestionView(delta.NewState)
+		view.NewState = &newState
+		view.URL = delta.NewState.URL
+	}
+	for _, child := range delta.Batch {
+		view.Batch = append(view.Batch, newDeltaView(child))
+	}
+	return view
+}
+
+func newHistoryDocument(deltas []core.Delta) HistoryDocument {
+	views := make([]DeltaView, 0, len(deltas))
+	for i, delta := range deltas {
+		view := newDeltaView(delta)
+		view.Index = i + 1
+		views = append(views, view)
+	}
+	return HistoryDocument{History: views}
+}
+
+func (d HistoryDocument) Header() []string {
+	return []string{"index", "question_id", "action", "url", "created_at"}
+}
+func (d HistoryDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.History))
+	for _, delta := range d.History {
+		rows = append(rows, []string{strconv.Itoa(delta.Index), strconv.Itoa(delta.QuestionID), delta.Action, delta.URL, delta.CreatedAt.Format(time.RFC3339)})
+	}
+	return rows
+}
+
+// TagView is the stable machine-readable form of a tag summary
+type TagView struct {
+	Name     string `json:"name"`
+	Total    int    `json:"total"`
+	TotalDue int    `json:"total_due"`
+}
+
+// TagsDocument is the result of the tags command
+type TagsDocument struct {
+	Tags []TagView `json:"tags"`
+}
+
+func newTagsDocument(tags []usecase.TagSummary) TagsDocument {
+	views := make([]TagView, 0, len(tags))
+	for _, tag := range tags {
+		views = append(views, TagView(tag))
+	}
+	return TagsDocument{Tags: views}
+}
+
+func (d TagsDocument) Header() []string { return []string{"name", "total", "total_due"} }
+func (d TagsDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Tags))
+	for _, tag := range d.Tags {
+		rows = append(rows, []string{tag.Name, strconv.Itoa(tag.Total), strconv.Itoa(tag.TotalDue)})
+	}
+	return rows
+}
+
+// IssueView is the stable machine-readable form of a data integrity problem
+type IssueView struct {
+	Kind       string `json:"kind"`
+	QuestionID int    `json:"question_id,omitempty"`
+	Message    string `json:"message"`
+	Repairable bool   `json:"repairable"`
+}
+
+// DoctorDocument is the result of the doctor command
+type DoctorDocument struct {
+	Issues   []IssueView `json:"issues"`
+	Repaired int         `json:"repaired"`
+	Backup   string      `json:"backup,omitempty"`
+}
+
+func newDoctorDocument(result *usecase.CheckResult) DoctorDocument {
+	views := make([]IssueView, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		views = append(views, IssueView{
+			Kind:       string(issue.Kind),
+			QuestionID: issue.QuestionID,
+			Message:    issue.Message,
+			Repairable: issue.Repairable,
+		})
+	}
+	return DoctorDocument{Issues: views, Repaired: result.Repaired, Backup: result.Backup}
+}
+
+func (d DoctorDocument) Header() []string {
+	return []string{"kind", "question_id", "repairable", "message"}
+}
+func (d DoctorDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Issues))
+	for _, issue := range d.Issues {
+		rows = append(rows, []string{issue.Kind, strconv.Itoa(issue.QuestionID), strconv.FormatBool(issue.Repairable), issue.Message})
+	}
+	return rows
+}
+
+// SimulatedDayView is the stable machine-readable form of a simulated day
+type SimulatedDayView struct {
+	Date    string `json:"date"` // YYYY-MM-DD
+	Reviews int    `json:"reviews"`
+	Added   int    `json:"added"`
+}
+
+// SimulatedQuestionView is the stable machine-readable form of a question's simulated intervals
+type SimulatedQuestionView struct {
+	ID        int    `json:"id"` // Numbered among the hypothetical questions when new is set
+	URL       string `json:"url,omitempty"`
+	New       bool   `json:"new,omitempty"`
+	Intervals []int  `json:"intervals"`
+}
+
+// SimulationDocument is the result of the simulate command
+type SimulationDocument struct {
+	Days      []SimulatedDayView      `json:"days"`
+	Peaks     []SimulatedDayView      `json:"peaks"`
+	Questions []SimulatedQuestionView `json:"questions"`
+}
+
+func newSimulatedDayViews(days []usecase.SimulatedDay) []SimulatedDayView {
+	views := make([]SimulatedDayView, 0, len(days))
+	for _, day := range days {
+		views = append(views, SimulatedDayView{Date: day.Date.Format(time.DateOnly), Reviews: day.Reviews, Added: day.Added})
+	}
+	return views
+}
+
+func newSimulationDocument(result *usecase.SimulationResult) SimulationDocument {
+	questions := make([]SimulatedQuestionView, 0, len(result.Questions))
+	for _, q := range result.Questions {
+		questions = append(questions, SimulatedQuestionView(q))
+	}
+	return SimulationDocument{
+		Days:      newSimulatedDayViews(result.Days),
+		Peaks:     newSimulatedDayViews(result.Peaks),
+		Questions: questions,
+	}
+}
+
+func (d SimulationDocument) Header() []string { return []string{"date", "reviews", "added"} }
+func (d SimulationDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Days))
+	for _, day := range d.Days {
+		rows = append(rows, []string{day.Date, strconv.Itoa(day.Reviews), strconv.Itoa(day.Added)})
+	}
+	return rows
+}
+
+// ParameterChangeView is the stable machine-readable form of a proposed setting change
+type ParameterChangeView struct {
+	Setting  string  `json:"setting"`
+	Level    string  `json:"level"`
+	Current  float64 `json:"current"`
+	Proposed float64 `json:"proposed"`
+}
+
+// OptimizeDocument is the result of the optimize command
+type OptimizeDocument struct {
+	Seed          uint64                `json:"seed"`
+	Questions     int                   `json:"questions"`
+	Reviews       int                   `json:"reviews"`
+	Recalled      int                   `json:"recalled"`
+	CurrentError  float64               `json:"current_error"`
+	ProposedError float64               `json:"proposed_error"`
+	Changes       []ParameterChangeView `json:"changes"`
+}
+
+func newOptimizeDocument(result *usecase.OptimizeResult) OptimizeDocument {
+	changes := make([]ParameterChangeView, 0, len(result.Changes))
+	for _, change := range result.Changes {
+		changes = append(changes, ParameterChangeView(change))
+	}
+	return OptimizeDocument{
+		Seed:          result.Options.Seed,
+		Questions:     result.Questions,
+		Reviews:       result.Reviews,
+		Recalled:      result.Recalled,
+		CurrentError:  result.CurrentError,
+		ProposedError: result.ProposedError,
+		Changes:       changes,
+	}
+}
+
+func (d OptimizeDocument) Header() []string {
+	return []string{"setting", "level", "current", "proposed"}
+}
+func (d OptimizeDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Changes))
+	for _, change := range d.Changes {
+		rows = append(rows, []string{change.Setting, change.Level,
+			strconv.FormatFloat(change.Current, 'f', -1, 64), strconv.FormatFloat(change.Proposed, 'f', -1, 64)})
+	}
+	return rows
+}
+
+// ForecastDayView is the stable machine-readable form of a forecast day
+type ForecastDayView struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Due  int    `json:"due"`
+}
+
+// ForecastWeekView is the stable machine-readable form of a forecast week
+type ForecastWeekView struct {
+	Start string `json:"start"` // YYYY-MM-DD
+	End   string `json:"end"`   // YYYY-MM-DD
+	Due   int    `json:"due"`
+}
+
+// ForecastDocument is the result of the forecast command; TSV output has a row per week
+// when weekly is set and a row per day otherwise
+type ForecastDocument struct {
+	Days    []ForecastDayView  `json:"days"`
+	Weeks   []ForecastWeekView `json:"weeks"`
+	Peaks   []ForecastDayView  `json:"peaks"`
+	Overdue int                `json:"overdue"`
+	Later   int                `json:"later"`
+	Total   int                `json:"total"`
+	weekly  bool
+}
+
+func newForecastDayViews(days []usecase.ForecastDay) []ForecastDayView {
+	views := make([]ForecastDayView, 0, len(days))
+	for _, day := range days {
+		views = append(views, ForecastDayView{Date: day.Date.Format(time.DateOnly), Due: day.Due})
+	}
+	return views
+}
+
+func newForecastDocument(forecast *usecase.Forecast, weekly bool) ForecastDocument {
+	weeks := forecast.Weeks()
+	weekViews := make([]ForecastWeekView, 0, len(weeks))
+	for _, week := range weeks {
+		weekViews = append(weekViews, ForecastWeekView{Start: week.Start.Format(time.DateOnly), End: week.End.Format(time.DateOnly), Due: week.Due})
+	}
+	return ForecastDocument{
+		Days:    newForecastDayViews(forecast.Days),
+		Weeks:   weekViews,
+		Peaks:   newForecastDayViews(forecast.Peaks),
+		Overdue: forecast.Overdue,
+		Later:   forecast.Later,
+		Total:   forecast.Total,
+		weekly:  weekly,
+	}
+}
+
+func (d ForecastDocument) Header() []string {
+	if d.weekly {
+		return []string{"start", "end", "due"}
+	}
+	return []string{"date", "due"}
+}
+func (d ForecastDocument) Rows() [][]string {
+	if d.weekly {
+		rows := make([][]string, 0, len(d.Weeks))
+		for _, week := range d.Weeks {
+			rows = append(rows, []string{week.Start, week.End, strconv.Itoa(week.Due)})
+		}
+		return rows
+	}
+	rows := make([][]string, 0, len(d.Days))
+	for _, day := range d.Days {
+		rows = append(rows, []string{day.Date, strconv.Itoa(day.Due)})
+	}
+	return rows
+}
+
+// LevelCountView is the number of questions at one familiarity or importance level
+type LevelCountView struct {
+	Level     int `json:"level"` // 1-based, as entered
+	Questions int `json:"questions"`
+}
+
+// PlatformCountView is the number of questions from one platform
+type PlatformCountView struct {
+	Platform  string `json:"platform"`
+	Questions int    `json:"questions"`
+}
+
+// MonthCountView is the number of questions added in one month
+type MonthCountView struct {
+	Month string `json:"month"` // YYYY-MM
+	Added int    `json:"added"`
+}
+
+// StatsDocument is the result of the stats command; TSV output has a row per figure
+type StatsDocument struct {
+	Questions        int                 `json:"questions"`
+	Familiarity      []LevelCountView    `json:"familiarity"`
+	Importance       []LevelCountView    `json:"importance"`
+	Platforms        []PlatformCountView `json:"platforms"`
+	AverageEase      float64             `json:"average_ease_factor"`
+	AverageInterval  float64             `json:"average_interval_days"`
+	Reviews          int                 `json:"reviews"`
+	ReviewsPerDay    float64             `json:"reviews_per_day"`
+	ReviewsPerWeek   float64             `json:"reviews_per_week"`
+	Retention        float64             `json:"retention"`
+	RetentionReviews int                 `json:"retention_reviews"` // Reviews the retention is taken over
+	CurrentStreak    int                 `json:"current_streak"`
+	LongestStreak    int                 `json:"longest_streak"`
+	AddedPerMonth    []MonthCountView    `json:"added_per_month"`
+}
+
+func newLevelCountViews(counts []int) []LevelCountView {
+	views := make([]LevelCountView, 0, len(counts))
+	for level, count := range counts {
+		views = append(views, LevelCountView{Level: level + 1, Questions: count})
+	}
+	return views
+}
+
+func newStatsDocument(stats *usecase.Stats) StatsDocument {
+	platforms := make([]PlatformCountView, 0, len(stats.ByPlatform))
+	for _, platform := range stats.ByPlatform {
+		platforms = append(platforms, PlatformCountView{Platform: string(platform.Platform), Questions: platform.Questions})
+	}
+	months := make([]MonthCountView, 0, len(stats.AddedPerMonth))
+	for _, month := range stats.AddedPerMonth {
+		months = append(months, MonthCountView{Month: month.Month.Format("2006-01"), Added: month.Added})
+	}
+	return StatsDocument{
+		Questions:        stats.Questions,
+		Familiarity:      newLevelCountViews(stats.ByFamiliarity[:]),
+		Importance:       newLevelCountViews(stats.ByImportance[:]),
+		Platforms:        platforms,
+		AverageEase:      stats.AverageEase,
+		AverageInterval:  stats.AverageInterval,
+		Reviews:          stats.Reviews,
+		ReviewsPerDay:    stats.ReviewsPerDay(),
+		ReviewsPerWeek:   stats.ReviewsPerWeek(),
+		Retention:        stats.Retention(),
+		RetentionReviews: stats.Repeated,
+		CurrentStreak:    stats.CurrentStreak,
+		LongestStreak:    stats.LongestStreak,
+		AddedPerMonth:    months,
+	}
+}
+
+func (d StatsDocument) Header() []string { return []string{"group", "key", "value"} }
+func (d StatsDocument) Rows() [][]string {
+	formatFloat := func(value float64) string { return strconv.FormatFloat(value, 'f', -1, 64) }
+	rows := [][]string{{"questions", "total", strconv.Itoa(d.Questions)}}
+	for _, level := range d.Familiarity {
+		rows = append(rows, []string{"familiarity", strconv.Itoa(level.Level), strconv.Itoa(level.Questions)})
+	}
+	for _, level := range d.Importance {
+		rows = append(rows, []string{"importance", strconv.Itoa(level.Level), strconv.Itoa(level.Questions)})
+	}
+	for _, platform := range d.Platforms {
+		rows = append(rows, []string{"platform", platform.Platform, strconv.Itoa(platform.Questions)})
+	}
+	rows = append(rows,
+		[]string{"schedule", "average_ease_factor", formatFloat(d.AverageEase)},
+		[]string{"schedule", "average_interval_days", formatFloat(d.AverageInterval)},
+		[]string{"reviews", "total", strconv.Itoa(d.Reviews)},
+		[]string{"reviews", "per_day", formatFloat(d.ReviewsPerDay)},
+		[]string{"reviews", "per_week", formatFloat(d.ReviewsPerWeek)},
+		[]string{"reviews", "retention", formatFloat(d.Retention)},
+		[]string{"reviews", "retention_reviews", strconv.Itoa(d.RetentionReviews)},
+		[]string{"reviews", "current_streak", strconv.Itoa(d.CurrentStreak)},
+		[]string{"reviews", "longest_streak", strconv.Itoa(d.LongestStreak)},
+	)
+	for _, month := range d.AddedPerMonth {
+		rows = append(rows, []string{"added", month.Month, strconv.Itoa(month.Added)})
+	}
+	return rows
+}
+
+// BackupView is the stable machine-readable form of a backup
+type BackupView struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Reason    string    `json:"reason"`
+	Size      int64     `json:"size"`
+}
+
+// BackupsDocument is the result of the backup list command
+type BackupsDocument struct {
+	Backups []BackupView `json:"backups"`
+}
+
+func newBackupsDocument(snapshots []backup.Snapshot) BackupsDocument {
+	views := make([]BackupView, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		views = append(views, BackupView{ID: snapshot.ID, CreatedAt: snapshot.CreatedAt, Reason: snapshot.Reason, Size: snapshot.Size()})
+	}
+	return BackupsDocument{Backups: views}
+}
+
+func (d BackupsDocument) Header() []string { return []string{"id", "created_at", "size", "reason"} }
+func (d BackupsDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Backups))
+	for _, b := range d.Backups {
+		rows = append(rows, []string{b.ID, b.CreatedAt.Format(time.RFC3339), strconv.FormatInt(b.Size, 10), b.Reason})
+	}
+	return rows
+}
+
+// SettingView is the stable machine-readable form of a setting
+type SettingView struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Value  any      `json:"value"`
+	Unit   string   `json:"unit,omitempty"`
+	levels []string // Keys of a map setting, in level order
+}
+
+// SettingsDocument is the result of the setting command without arguments
+type SettingsDocument struct {
+	Settings []SettingView `json:"settings"`
+}
+
+func (d SettingsDocument) Header() []string { return []string{"name", "type", "value", "unit"} }
+func (d SettingsDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Settings))
+	for _, setting := range d.Settings {
+		rows = append(rows, []string{setting.Name, setting.Type, formatSettingValue(setting.Value, setting.levels), setting.Unit})
+	}
+	return rows
+}
+
+// SettingDiffView is the stable machine-readable form of a setting that differs from its default
+type SettingDiffView struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Value   any      `json:"value"`
+	Default any      `json:"default"`
+	Unit    string   `json:"unit,omitempty"`
+	levels  []string // Keys of a map setting, in level order
+}
+
+// SettingDiffsDocument is the result of the setting diff command
+type SettingDiffsDocument struct {
+	Settings []SettingDiffView `json:"settings"`
+}
+
+func (d SettingDiffsDocument) Header() []string {
+	return []string{"name", "type", "value", "default", "unit"}
+}
+func (d SettingDiffsDocument) Rows() [][]string {
+	rows := make([][]string, 0, len(d.Settings))
+	for _, setting := range d.Settings {
+		rows = append(rows, []string{setting.Name, setting.Type, formatSettingValue(setting.Value, setting.levels),
+			formatSettingValue(setting.Default, setting.levels), setting.Unit})
+	}
+	return rows
+}
+
+// formatSettingValue formats a setting value the way the setting command takes it; map settings
+// become level=value pairs in level order
+func formatSettingValue(value any, levels []string) string {
+	switch values := value.(type) {
+	case map[string]int:
+		return formatLevels(values, levels)
+	case map[string]float64:
+		return formatLevels(values, levels)
+	}
+	return fmt.Sprint(value)
+}
+
+func formatLevels[V int | float64](values map[string]V, levels []string) string {
+	pairs := make([]string, 0, len(levels))
+	for _, level := range levels {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", level, values[level]))
+	}
+	return strings.Join(pairs, ",")
+}
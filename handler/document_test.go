@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/usecase"
+)
+
+func TestExtractOutputFormat(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expected     OutputFormat
+		expectedArgs []string
+		hasError     bool
+	}{
+		{"defaults to text", []string{"status"}, FormatText, []string{"status"}, false},
+		{"json flag before command", []string{"--json", "status"}, FormatJSON, []string{"status"}, false},
+		{"format flag after args", []string{"search", "dp", "--format=TSV"}, FormatTSV, []string{"search", "dp"}, false},
+		{"unknown format", []string{"--format=xml", "status"}, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, args, err := ExtractOutputFormat(tt.args)
+			if tt.hasError {
+				if err == nil {
+					t.Error("Expected error for unknown format")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if format != tt.expected {
+				t.Errorf("Expected format %q, got %q", tt.expected, format)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("Expected args %v, got %v", tt.expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestQuestionView_UsesOneBasedLevels(t *testing.T) {
+	q := &core.Question{
+		ID:          7,
+		URL:         "https://leetcode.com/problems/two-sum/",
+		Familiarity: core.VeryHard,
+		Importance:  core.CriticalImportance,
+		NextReview:  time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	view := newQuestionView(q)
+	if view.Familiarity != 1 || view.Importance != 4 {
+		t.Errorf("Expected familiarity 1 and importance 4, got %d and %d", view.Familiarity, view.Importance)
+	}
+	if view.Tags == nil {
+		t.Error("Expected tags to encode as an empty array rather than null")
+	}
+
+	row := view.row()
+	if len(row) != len(questionHeader) {
+		t.Errorf("Expected %d fields, got %d", len(questionHeader), len(row))
+	}
+}
+
+func TestStatusDocument_JSONShape(t *testing.T) {
+	doc := newStatusDocument(usecase.QuestionsSummary{
+		TopDue:   []core.Question{{ID: 1, URL: "https://leetcode.com/problems/a/"}},
+		TotalDue: 1,
+		Total:    1,
+	})
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	for _, key := range []string{"total", "total_due", "total_upcoming", "due", "upcoming"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("Expected key %q in status document", key)
+		}
+	}
+
+	rows := doc.Rows()
+	if len(rows) != 1 || rows[0][0] != "due" {
+		t.Errorf("Expected one row in the due list, got %v", rows)
+	}
+}
+
+func TestHistoryDocument_TakesURLFromEitherState(t *testing.T) {
+	doc := newHistoryDocument([]core.Delta{
+		{Action: core.ActionDelete, QuestionID: 1, OldState: &core.Question{ID: 1, URL: "https://leetcode.com/problems/a/"}},
+		{Action: core.ActionAdd, QuestionID: 2, NewState: &core.Question{ID: 2, URL: "https://leetcode.com/problems/b/"}},
+	})
+
+	if doc.History[0].URL != "https://leetcode.com/problems/a/" || doc.History[0].NewState != nil {
+		t.Errorf("Unexpected delete entry: %+v", doc.History[0])
+	}
+	if doc.History[1].URL != "https://leetcode.com/problems/b/" || doc.History[1].OldState != nil {
+		t.Errorf("Unexpected add entry: %+v", doc.History[1])
+	}
+	if doc.History[0].Index != 1 || doc.History[1].Index != 2 {
+		t.Errorf("Expected the entries numbered as 'undo --to' takes them, got %d and %d", doc.History[0].Index, doc.History[1].Index)
+	}
+}
@@ -3,13 +3,21 @@ package handler
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/eannchen/leetsolv/config"
 	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/backup"
 	"github.com/eannchen/leetsolv/internal/errs"
 	"github.com/eannchen/leetsolv/internal/tokenizer"
 	"github.com/eannchen/leetsolv/internal/urlparser"
@@ -17,22 +25,42 @@ import (
 )
 
 type Handler interface {
-	HandleList(scanner *bufio.Scanner)
-	HandleSearch(scanner *bufio.Scanner, args []string)
-	HandleGet(scanner *bufio.Scanner, target string)
-	HandleStatus()
-	HandleUpsert(scanner *bufio.Scanner, rawURL string)
-	HandleDelete(scanner *bufio.Scanner, target string)
-	HandleUndo(scanner *bufio.Scanner)
-	HandleHistory()
-	HandleUnknown(command string)
+	HandleList(scanner *bufio.Scanner) error
+	HandleSearch(scanner *bufio.Scanner, args []string) error
+	HandleGet(scanner *bufio.Scanner, target string) error
+	HandleStatus() error
+	HandleUpsert(scanner *bufio.Scanner, args []string) error
+	HandleReview(scanner *bufio.Scanner) error
+	HandleDelete(scanner *bufio.Scanner, target string) error
+	HandleUndo(scanner *bufio.Scanner, args []string) error
+	HandleRedo(scanner *bufio.Scanner, args []string) error
+	HandleHistory() error
+	HandleTags() error
+	HandleExport(scanner *bufio.Scanner, args []string) error
+	HandleImport(scanner *bufio.Scanner, args []string) error
+	HandleUnknown(command string) error
 	HandleHelp()
 	HandleClear()
 	HandleQuit()
-	HandleSetting(scanner *bufio.Scanner, args []string)
+	HandleSetting(scanner *bufio.Scanner, args []string) error
 	HandleVersion()
-	HandleMigrate(scanner *bufio.Scanner)
-	HandleReset(scanner *bufio.Scanner)
+	HandleMigrate(scanner *bufio.Scanner) error
+	HandleDoctor(args []string) error
+	HandleSimulate(args []string) error
+	HandleForecast(args []string) error
+	HandleOptimize(scanner *bufio.Scanner, args []string) error
+	HandleStats() error
+	HandleLeeches(args []string) error
+	HandleSuspend(args []string) error
+	HandleUnsuspend(args []string) error
+	HandleBury(args []string) error
+	HandleArchive(args []string) error
+	HandleStart(args []string) error
+	HandleDone(scanner *bufio.Scanner, args []string) error
+	HandlePause() error
+	HandleResume() error
+	HandleBackup(scanner *bufio.Scanner, args []string) error
+	HandleReset(scanner *bufio.Scanner) error
 }
 
 type HandlerImpl struct {
@@ -51,22 +79,32 @@ func NewHandler(cfg *config.Config, questionUseCase usecase.QuestionUseCase, IOH
 	}
 }
 
-func (h *HandlerImpl) HandleList(scanner *bufio.Scanner) {
+// structured reports whether results should be rendered as documents instead of text
+func (h *HandlerImpl) structured() bool {
+	return h.IO.Format() != FormatText
+}
+
+func (h *HandlerImpl) HandleList(scanner *bufio.Scanner) error {
 
 	questions, err := h.QuestionUseCase.ListQuestionsOrderByDesc()
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newQuestionListDocument(questions))
+		return nil
 	}
 	if len(questions) == 0 {
 		h.IO.PrintError(errs.ErrNoQuestionsAvailable)
-		return
+		return errs.ErrNoQuestionsAvailable
 	}
 
 	h.paginateQuestions(scanner, questions)
+	return nil
 }
 
-func (h *HandlerImpl) HandleSearch(scanner *bufio.Scanner, args []string) {
+func (h *HandlerImpl) HandleSearch(scanner *bufio.Scanner, args []string) error {
 	if len(args) == 0 {
 		args = strings.Fields(h.IO.ReadLine(scanner, "Enter search query (or press Enter to search all): "))
 	}
@@ -76,20 +114,25 @@ func (h *HandlerImpl) HandleSearch(scanner *bufio.Scanner, args []string) {
 	filter, err := h.parseFilterArgs(filterArgs)
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
 	}
 
 	questions, err := h.QuestionUseCase.SearchQuestions(targets, filter)
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newQuestionListDocument(questions))
+		return nil
 	}
 	if len(questions) == 0 {
 		h.IO.PrintError(errs.ErrNoQuestionsAvailable)
-		return
+		return errs.ErrNoQuestionsAvailable
 	}
 
 	h.paginateQuestions(scanner, questions)
+	return nil
 }
 
 func (h *HandlerImpl) parseSearchQueries(args []string) ([]string, []string) {
@@ -140,6 +183,28 @@ func (h *HandlerImpl) parseFilterArgs(args []string) (*core.SearchFilter, error)
 		case arg == "--due-only":
 			filter.DueOnly = true
 
+		case strings.HasPrefix(arg, "--state="):
+			val := strings.TrimPrefix(arg, "--state=")
+			state, ok := core.ParseQuestionState(val)
+			if !ok || val == "" {
+				return nil, errs.ErrInvalidState
+			}
+			filter.State = &state
+
+		case strings.HasPrefix(arg, "--tag="):
+			tags, err := h.parseTags(strings.TrimPrefix(arg, "--tag="))
+			if err != nil {
+				return nil, err
+			}
+			filter.Tags = append(filter.Tags, tags...)
+
+		case strings.HasPrefix(arg, "--no-tag="):
+			tags, err := h.parseTags(strings.TrimPrefix(arg, "--no-tag="))
+			if err != nil {
+				return nil, err
+			}
+			filter.ExcludeTags = append(filter.ExcludeTags, tags...)
+
 		default:
 			// Skip unknown arguments
 			continue
@@ -207,12 +272,12 @@ func (h *HandlerImpl) getQuestionsPage(questions []core.Question, page int) ([]c
 	return questions[start:end], totalPages, nil
 }
 
-func (h *HandlerImpl) HandleGet(scanner *bufio.Scanner, target string) {
+func (h *HandlerImpl) HandleGet(scanner *bufio.Scanner, target string) error {
 	if target == "" {
 		target = h.IO.ReadLine(scanner, "Enter ID or URL to get the question details: ")
 		if target == "" {
 			h.IO.PrintError(errs.ErrInvalidEmptyInput)
-			return
+			return errs.ErrInvalidEmptyInput
 		}
 	}
 	_, err := strconv.Atoi(target)
@@ -221,7 +286,7 @@ func (h *HandlerImpl) HandleGet(scanner *bufio.Scanner, target string) {
 		parsed, err := urlparser.Parse(target)
 		if err != nil {
 			h.IO.PrintError(err)
-			return
+			return err
 		}
 		target = parsed.NormalizedURL
 	}
@@ -229,21 +294,33 @@ func (h *HandlerImpl) HandleGet(scanner *bufio.Scanner, target string) {
 	question, err := h.QuestionUseCase.GetQuestion(target)
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
 	}
 
+	if h.structured() {
+		h.IO.PrintDocument(QuestionDocument{Question: newQuestionView(question)})
+		return nil
+	}
 	h.IO.PrintQuestionDetail(question)
+	return nil
 }
 
-func (h *HandlerImpl) HandleStatus() {
+func (h *HandlerImpl) HandleStatus() error {
 	summary, err := h.QuestionUseCase.ListQuestionsSummary()
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newStatusDocument(summary))
+		return nil
 	}
 
 	h.IO.PrintlnColored(ColorHeader, "───────────── Question Status ─────────────")
 	h.IO.PrintfColored(ColorStatTotal, "Total Questions: %d\n", summary.Total)
+	if !summary.PausedSince.IsZero() {
+		h.IO.PrintfColored(ColorWarning, "Reviews paused since %s. Run 'resume' when you are back.\n", summary.PausedSince.Format(time.DateOnly))
+	}
 	h.IO.Printf("\n")
 
 	h.IO.PrintlnColored(ColorHeader, "-- Due Questions --")
@@ -276,12 +353,131 @@ func (h *HandlerImpl) HandleStatus() {
 		h.IO.Printf("\n")
 		h.IO.PrintfColored(ColorAnnotation, "* Priority Scoring Formula = (%.1f×Importance)+(%.1f×Overdue Days)+(%.1f×Difficulty)+(%.1f×Review Count)+(%.1f×Ease Factor)\n",
 			h.cfg.ImportanceWeight, h.cfg.OverdueWeight, h.cfg.FamiliarityWeight, h.cfg.ReviewPenaltyWeight, h.cfg.EasePenaltyWeight)
+		if h.cfg.LeechAction == config.LeechActionTop {
+			h.IO.PrintlnColored(ColorAnnotation, "* Leeches come before every other due question")
+		}
 	}
 
 	h.IO.Printf("\n")
+	return nil
+}
+
+// upsertInput holds the values given as flags to the upsert command; nil fields are prompted for
+type upsertInput struct {
+	rawURL      string
+	note        *string
+	tags        []string
+	tagsSet     bool
+	familiarity *core.Familiarity
+	memory      *core.MemoryUse
+	importance  *core.Importance
+	solve       core.Solve // Parts of the solve given as flags; not prompted for when any is given
+	elapsed     int        // Minutes measured by the solve timer, unless given as a flag
+}
+
+// parseUpsertArgs parses the URL and the --note, --tags, --familiarity, --memory, --importance,
+// --minutes, --first-pass and --attempts flags
+func (h *HandlerImpl) parseUpsertArgs(args []string) (*upsertInput, error) {
+	input := &upsertInput{}
+
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--note="):
+			note := strings.TrimSpace(strings.TrimPrefix(arg, "--note="))
+			input.note = &note
+
+		case strings.HasPrefix(arg, "--tags="):
+			tags, err := h.parseTags(strings.TrimPrefix(arg, "--tags="))
+			if err != nil {
+				return nil, err
+			}
+			input.tags = tags
+			input.tagsSet = true
+
+		case strings.HasPrefix(arg, "--familiarity="):
+			familiarity, err := h.validateFamiliarity(strings.TrimPrefix(arg, "--familiarity="))
+			if err != nil {
+				return nil, err
+			}
+			input.familiarity = &familiarity
+
+		case strings.HasPrefix(arg, "--memory="):
+			memory, err := h.validateMemoryUse(strings.TrimPrefix(arg, "--memory="))
+			if err != nil {
+				return nil, err
+			}
+			input.memory = &memory
+
+		case strings.HasPrefix(arg, "--importance="):
+			importance, err := h.validateImportance(strings.TrimPrefix(arg, "--importance="))
+			if err != nil {
+				return nil, err
+			}
+			input.importance = &importance
+
+		case strings.HasPrefix(arg, "--minutes="):
+			minutes, err := strconv.Atoi(strings.TrimPrefix(arg, "--minutes="))
+			if err != nil || minutes <= 0 {
+				return nil, errs.ErrInvalidSolve
+			}
+			input.solve.Minutes = minutes
+
+		case strings.HasPrefix(arg, "--attempts="):
+			attempts, err := strconv.Atoi(strings.TrimPrefix(arg, "--attempts="))
+			if err != nil || attempts <= 0 {
+				return nil, errs.ErrInvalidSolve
+			}
+			input.solve.Attempts = attempts
+
+		case strings.HasPrefix(arg, "--first-pass="):
+			firstPass, ok := parseYesNo(strings.TrimPrefix(arg, "--first-pass="))
+			if !ok {
+				return nil, errs.ErrInvalidSolve
+			}
+			input.solve.FirstPass = &firstPass
+
+		case strings.HasPrefix(arg, "--"):
+			return nil, errs.WrapValidationError(fmt.Errorf("unknown flag %s", arg),
+				fmt.Sprintf("Unknown flag: %s. Supported: --note, --tags, --familiarity, --memory, --importance, --minutes, --first-pass, --attempts", arg))
+
+		case input.rawURL == "":
+			input.rawURL = arg
+
+		default:
+			return nil, errs.WrapValidationError(fmt.Errorf("unexpected argument %s", arg), "Usage: add <url> [--note=...] [--tags=...] [--familiarity=1-5] [--memory=1-3] [--importance=1-4] [--minutes=N] [--first-pass=yes|no] [--attempts=N]")
+		}
+	}
+
+	if !input.solve.Valid() {
+		return nil, errs.ErrInvalidSolve
+	}
+	return input, nil
+}
+
+// parseYesNo parses a yes or no answer
+func parseYesNo(input string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes", "true":
+		return true, true
+	case "n", "no", "false":
+		return false, true
+	}
+	return false, false
+}
+
+func (h *HandlerImpl) HandleUpsert(scanner *bufio.Scanner, args []string) error {
+	// Validate all flags before prompting for anything
+	input, err := h.parseUpsertArgs(args)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	return h.upsert(scanner, input)
 }
 
-func (h *HandlerImpl) HandleUpsert(scanner *bufio.Scanner, rawURL string) {
+// upsert adds or reviews a question, prompting for whatever the input leaves out
+func (h *HandlerImpl) upsert(scanner *bufio.Scanner, input *upsertInput) error {
+	rawURL := input.rawURL
 	if rawURL == "" {
 		h.IO.Println("Provided URL will be normalized to a canonical form to match existing data.")
 		h.IO.Println("Supported platforms: LeetCode, HackerRank")
@@ -292,72 +488,330 @@ func (h *HandlerImpl) HandleUpsert(scanner *bufio.Scanner, rawURL string) {
 	parsed, err := urlparser.Parse(rawURL)
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
 	}
 	h.IO.PrintfColored(ColorGreen, "[%s] Using normalized URL: %s\n", parsed.Platform.String(), parsed.NormalizedURL)
 
 	h.IO.Printf("\n")
 
-	note := h.IO.ReadLine(scanner, "Note: ")
+	var note string
+	if input.note != nil {
+		note = *input.note
+	} else {
+		note = h.IO.ReadLine(scanner, "Note: ")
+	}
+
+	tags := input.tags
+	if !input.tagsSet && input.familiarity != nil {
+		// Driven by flags: keep the tags a question already added has instead of prompting
+		if existing, err := h.QuestionUseCase.GetQuestion(parsed.NormalizedURL); err == nil {
+			tags = existing.Tags
+		}
+	} else if !input.tagsSet {
+		tags, err = h.promptTags(scanner, parsed.NormalizedURL)
+		if err != nil {
+			h.IO.PrintError(err)
+			return err
+		}
+	}
+
+	var familiarity core.Familiarity
+	if input.familiarity != nil {
+		familiarity = *input.familiarity
+	} else {
+		h.IO.Printf("\n")
+		h.printFamiliarityOptions()
+		famInput := h.IO.ReadLine(scanner, "\nEnter a number (1-5): ")
+		familiarity, err = h.validateFamiliarity(famInput)
+		if err != nil {
+			h.IO.PrintError(err)
+			return err
+		}
+	}
+
+	// Memory use only matters once the question was solved reasonably well
+	memory := core.MemoryReasoned
+	if familiarity >= core.Medium {
+		if input.memory != nil {
+			memory = *input.memory
+		} else {
+			h.IO.Printf("\n")
+			h.printMemoryUseOptions()
+			memoryInput := h.IO.ReadLine(scanner, "\nEnter a number (1-3): ")
+			memory, err = h.validateMemoryUse(memoryInput)
+			if err != nil {
+				h.IO.PrintError(err)
+				return err
+			}
+		}
+	}
+
+	var importance core.Importance
+	if input.importance != nil {
+		importance = *input.importance
+	} else {
+		h.IO.Printf("\n")
+		h.IO.Println("Importance:")
+		h.IO.Println("1. Low Importance")
+		h.IO.Println("2. Medium Importance")
+		h.IO.Println("3. High Importance")
+		h.IO.Println("4. Critical Importance")
+		impInput := h.IO.ReadLine(scanner, "\nEnter a number (1-4): ")
+		importance, err = h.validateImportance(impInput)
+		if err != nil {
+			h.IO.PrintError(err)
+			return err
+		}
+	}
+
+	// The solve is optional, so it is only asked for while prompting for the review itself
+	solve := input.solve
+	if solve.IsZero() && input.familiarity == nil {
+		solve, err = h.promptSolve(scanner, input.elapsed)
+		if err != nil {
+			h.IO.PrintError(err)
+			return err
+		}
+	}
+	if solve.Minutes == 0 {
+		solve.Minutes = input.elapsed
+	}
+
+	// Call the updated UpsertQuestion function
+	delta, err := h.QuestionUseCase.UpsertQuestion(parsed.NormalizedURL, note, tags, familiarity, importance, memory, solve)
+	if err != nil {
+		h.IO.PrintError(err)
+		h.IO.Printf("\n")
+		return err
+	}
+
+	// Display the upserted question
+	h.IO.Printf("\n")
+	h.IO.PrintSuccess(fmt.Sprintf("Question %s", delta.Action.PastTenseString()))
+	h.IO.PrintQuestionUpsertDetail(delta)
+	h.IO.Printf("\n")
+	return nil
+}
 
+// promptSolve asks for the minutes and submissions a solve took; pressing Enter skips either.
+// The minutes are not asked for when the solve timer measured them.
+func (h *HandlerImpl) promptSolve(scanner *bufio.Scanner, elapsed int) (core.Solve, error) {
+	solve := core.Solve{Minutes: elapsed}
 	h.IO.Printf("\n")
+	if elapsed > 0 {
+		h.IO.Printf("Minutes to solve: %d\n", elapsed)
+	} else if input := h.IO.ReadLine(scanner, "Minutes to solve (optional): "); input != "" {
+		minutes, err := strconv.Atoi(input)
+		if err != nil || minutes <= 0 {
+			return core.Solve{}, errs.ErrInvalidSolve
+		}
+		solve.Minutes = minutes
+	}
+	if input := h.IO.ReadLine(scanner, "Submissions until one passed (optional): "); input != "" {
+		attempts, err := strconv.Atoi(input)
+		if err != nil || attempts <= 0 {
+			return core.Solve{}, errs.ErrInvalidSolve
+		}
+		solve.Attempts = attempts
+	}
+	return solve, nil
+}
 
+func (h *HandlerImpl) printFamiliarityOptions() {
 	h.IO.Println("Familiarity:")
 	h.IO.Println("1. Struggled - Solved, but barely; needed heavy effort or help.")
 	h.IO.Println("2. Clumsy    - Solved with major guidance or recurring mistakes.")
 	h.IO.Println("3. Decent    - Solved mostly right, but with uncertainty or slow spots.")
 	h.IO.Println("4. Smooth    - Solved cleanly with clear reasoning, minor pauses, and no real confusion.")
 	h.IO.Println("5. Fluent    - Solved confidently with no hesitation.")
-	famInput := h.IO.ReadLine(scanner, "\nEnter a number (1-5): ")
-	familiarity, err := h.validateFamiliarity(famInput)
+}
+
+func (h *HandlerImpl) printMemoryUseOptions() {
+	h.IO.Println("Memory Use:")
+	h.IO.Println("1. Reasoned - Solved purely from reasoning.")
+	h.IO.Println("2. Partial  - Recalled some solution fragments, but still reasoned through the rest.")
+	h.IO.Println("3. Full     - Solved mainly from memory of the full approach or exact steps.")
+	h.IO.PrintlnColored(ColorAnnotation, "When you report that you solved the problem from memory, the scheduler interprets that as weaker learning.")
+}
+
+func (h *HandlerImpl) HandleReview(scanner *bufio.Scanner) error {
+	queue, err := h.QuestionUseCase.ListDueQuestions()
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
+	}
+	if len(queue) == 0 {
+		h.IO.Println("No questions are due. Nice work!")
+		h.IO.Printf("\n")
+		return nil
 	}
 
+	h.IO.PrintlnColored(ColorHeader, "───────────── Review Session ─────────────")
+	h.IO.PrintfColored(ColorStatDueTotal, "Due: %d  (in priority order)\n", len(queue))
+	h.IO.Printf("\n")
+	h.printFamiliarityOptions()
+	h.IO.PrintlnColored(ColorAnnotation, "Note and importance are kept as they are. Press Enter to end the session.")
 	h.IO.Printf("\n")
 
-	memory := core.MemoryReasoned
-	if familiarity >= core.Medium {
-		h.IO.Println("Memory Use:")
-		h.IO.Println("1. Reasoned - Solved purely from reasoning.")
-		h.IO.Println("2. Partial  - Recalled some solution fragments, but still reasoned through the rest.")
-		h.IO.Println("3. Full     - Solved mainly from memory of the full approach or exact steps.")
-		h.IO.PrintlnColored(ColorAnnotation, "When you report that you solved the problem from memory, the scheduler interprets that as weaker learning.")
-		memoryInput := h.IO.ReadLine(scanner, "\nEnter a number (1-3): ")
-		memory, err = h.validateMemoryUse(memoryInput)
+	var reviewed, buried int
+	total := len(queue)
+
+	for len(queue) > 0 {
+		q := queue[0]
+		h.IO.PrintfColored(ColorHeader, "-- %d/%d --\n", reviewed+buried+1, total)
+		h.IO.PrintQuestionDetail(&q)
+
+		familiarity, action := h.promptReviewFamiliarity(scanner)
+		switch action {
+		case reviewActionQuit:
+			h.printReviewSummary(reviewed, buried, len(queue))
+			return nil
+		case reviewActionSkip:
+			// Requeue at the end so it comes back later in the session
+			queue = append(queue[1:], q)
+			h.IO.PrintCancel("Skipped")
+			h.IO.Printf("\n")
+			continue
+		case reviewActionBury:
+			// Leave it out of reviews until tomorrow, as 'bury' does
+			delta, err := h.QuestionUseCase.BuryQuestion(strconv.Itoa(q.ID), 1, time.Time{})
+			if err != nil {
+				h.IO.PrintError(err)
+				h.printReviewSummary(reviewed, buried, len(queue))
+				return err
+			}
+			queue = queue[1:]
+			buried++
+			h.IO.PrintCancel(fmt.Sprintf("Buried until %s", delta.NewState.BuriedUntil.Format(time.DateOnly)))
+			h.IO.Printf("\n")
+			continue
+		}
+
+		memory := core.MemoryReasoned
+		if familiarity >= core.Medium {
+			h.IO.Printf("\n")
+			h.printMemoryUseOptions()
+			var ok bool
+			if memory, ok = h.promptReviewMemoryUse(scanner); !ok {
+				h.printReviewSummary(reviewed, buried, len(queue))
+				return nil
+			}
+		}
+
+		delta, err := h.QuestionUseCase.UpsertQuestion(q.URL, q.Note, q.Tags, familiarity, q.Importance, memory, core.Solve{})
 		if err != nil {
 			h.IO.PrintError(err)
-			return
+			h.printReviewSummary(reviewed, buried, len(queue))
+			return err
 		}
+		queue = queue[1:]
+		reviewed++
+		h.IO.PrintSuccess(fmt.Sprintf("Reviewed. Next review: %s", delta.NewState.NextReview.Local().Format("2006-01-02")))
 		h.IO.Printf("\n")
 	}
 
-	h.IO.Println("Importance:")
-	h.IO.Println("1. Low Importance")
-	h.IO.Println("2. Medium Importance")
-	h.IO.Println("3. High Importance")
-	h.IO.Println("4. Critical Importance")
-	impInput := h.IO.ReadLine(scanner, "\nEnter a number (1-4): ")
-	importance, err := h.validateImportance(impInput)
-	if err != nil {
-		h.IO.PrintError(err)
-		return
+	h.printReviewSummary(reviewed, buried, 0)
+	return nil
+}
+
+type reviewAction int
+
+const (
+	reviewActionGrade reviewAction = iota
+	reviewActionSkip
+	reviewActionBury
+	reviewActionQuit
+)
+
+// promptReviewFamiliarity asks for a familiarity level or a session action, re-asking on invalid input.
+// Empty input ends the session so that a closed input stream cannot loop forever.
+func (h *HandlerImpl) promptReviewFamiliarity(scanner *bufio.Scanner) (core.Familiarity, reviewAction) {
+	for {
+		input := strings.ToLower(h.IO.ReadLine(scanner, "Familiarity (1-5), [s] Skip, [b] Bury, [q] Quit: "))
+		switch input {
+		case "", "q":
+			return 0, reviewActionQuit
+		case "s":
+			return 0, reviewActionSkip
+		case "b":
+			return 0, reviewActionBury
+		}
+
+		familiarity, err := h.validateFamiliarity(input)
+		if err != nil {
+			h.IO.PrintError(err)
+			continue
+		}
+		return familiarity, reviewActionGrade
 	}
+}
 
-	// Call the updated UpsertQuestion function
-	delta, err := h.QuestionUseCase.UpsertQuestion(parsed.NormalizedURL, note, familiarity, importance, memory)
-	if err != nil {
-		h.IO.PrintError(err)
+// promptReviewMemoryUse asks for a memory use level, re-asking on invalid input.
+// It returns false when the input is empty, which ends the session.
+func (h *HandlerImpl) promptReviewMemoryUse(scanner *bufio.Scanner) (core.MemoryUse, bool) {
+	for {
+		input := h.IO.ReadLine(scanner, "\nEnter a number (1-3): ")
+		if input == "" {
+			return 0, false
+		}
+
+		memory, err := h.validateMemoryUse(input)
+		if err != nil {
+			h.IO.PrintError(err)
+			continue
+		}
+		return memory, true
+	}
+}
+
+func (h *HandlerImpl) printReviewSummary(reviewed, buried, remaining int) {
+	h.IO.Printf("\n")
+	h.IO.PrintlnColored(ColorHeader, "───────────── Session Summary ─────────────")
+	h.IO.PrintfColored(ColorStatTotal, "Reviewed: %d\n", reviewed)
+	h.IO.Printf("Buried: %d\n", buried)
+	if remaining > 0 {
+		h.IO.PrintfColored(ColorStatDueTotal, "Still due: %d\n", remaining)
 	} else {
-		// Display the upserted question
-		h.IO.Printf("\n")
-		h.IO.PrintSuccess(fmt.Sprintf("Question %s", delta.Action.PastTenseString()))
-		h.IO.PrintQuestionUpsertDetail(delta)
+		h.IO.Printf("Still due: 0\n")
 	}
 	h.IO.Printf("\n")
 }
 
+// promptTags asks for the tags of a question. Empty input keeps the tags a question already
+// added has, and "-" removes them.
+func (h *HandlerImpl) promptTags(scanner *bufio.Scanner, url string) ([]string, error) {
+	existing, err := h.QuestionUseCase.GetQuestion(url)
+	if err != nil || len(existing.Tags) == 0 {
+		// A new question, or one without tags, has none to keep
+		return h.parseTags(h.IO.ReadLine(scanner, "Tags (comma-separated, optional): "))
+	}
+
+	input := h.IO.ReadLine(scanner, fmt.Sprintf("Tags (comma-separated, Enter keeps %s, - removes them): ", strings.Join(existing.Tags, ", ")))
+	switch input {
+	case "":
+		return existing.Tags, nil
+	case "-":
+		return nil, nil
+	}
+	return h.parseTags(input)
+}
+
+// parseTags splits a comma- or space-separated tag list and normalizes it
+func (h *HandlerImpl) parseTags(input string) ([]string, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	tags := core.NormalizeTags(fields)
+	for _, tag := range tags {
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_+.", r) {
+				return nil, errs.ErrInvalidTag
+			}
+		}
+	}
+	return tags, nil
+}
+
 func (h *HandlerImpl) validateFamiliarity(input string) (core.Familiarity, error) {
 	fam, err := strconv.Atoi(input)
 	if err != nil || fam < 1 || fam > 5 {
@@ -382,12 +836,12 @@ func (h *HandlerImpl) validateMemoryUse(input string) (core.MemoryUse, error) {
 	return core.MemoryUse(memory - 1), nil
 }
 
-func (h *HandlerImpl) HandleDelete(scanner *bufio.Scanner, target string) {
+func (h *HandlerImpl) HandleDelete(scanner *bufio.Scanner, target string) error {
 	if target == "" {
 		target = h.IO.ReadLine(scanner, "Enter ID or URL to delete the question: ")
 		if target == "" {
 			h.IO.PrintError(errs.ErrInvalidEmptyInput)
-			return
+			return errs.ErrInvalidEmptyInput
 		}
 	}
 	_, err := strconv.Atoi(target)
@@ -396,7 +850,7 @@ func (h *HandlerImpl) HandleDelete(scanner *bufio.Scanner, target string) {
 		parsed, err := urlparser.Parse(target)
 		if err != nil {
 			h.IO.PrintError(err)
-			return
+			return err
 		}
 		target = parsed.NormalizedURL
 	}
@@ -406,7 +860,7 @@ func (h *HandlerImpl) HandleDelete(scanner *bufio.Scanner, target string) {
 	if confirm != "y" && confirm != "yes" {
 		h.IO.PrintCancel("Cancelled")
 		h.IO.Printf("\n")
-		return
+		return nil
 	}
 
 	_, err = h.QuestionUseCase.DeleteQuestion(target)
@@ -416,48 +870,205 @@ func (h *HandlerImpl) HandleDelete(scanner *bufio.Scanner, target string) {
 		h.IO.PrintSuccess("Question Deleted")
 	}
 	h.IO.Printf("\n")
+	return err
+}
+
+// parseUndoArgs parses the optional count, or --to with the history entry to go back to
+func parseUndoArgs(args []string) (count int, to int, err error) {
+	usage := errs.WrapValidationError(fmt.Errorf("invalid undo arguments %v", args), "Usage: undo [count | --to <history #>]")
+
+	switch {
+	case len(args) == 0:
+		return 1, 0, nil
+	case len(args) == 1 && strings.HasPrefix(args[0], "--to="):
+		to, err = strconv.Atoi(strings.TrimPrefix(args[0], "--to="))
+	case len(args) == 2 && args[0] == "--to":
+		to, err = strconv.Atoi(args[1])
+	case len(args) == 1:
+		count, err = strconv.Atoi(args[0])
+		if err != nil || count < 1 {
+			return 0, 0, usage
+		}
+		return count, 0, nil
+	default:
+		return 0, 0, usage
+	}
+	if err != nil || to < 1 {
+		return 0, 0, usage
+	}
+	return 0, to, nil
+}
+
+// parseRedoArgs parses the optional count
+func parseRedoArgs(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	count, err := strconv.Atoi(args[0])
+	if len(args) > 1 || err != nil || count < 1 {
+		return 0, errs.WrapValidationError(fmt.Errorf("invalid redo arguments %v", args), "Usage: redo [count]")
+	}
+	return count, nil
+}
+
+func (h *HandlerImpl) HandleUndo(scanner *bufio.Scanner, args []string) error {
+	count, to, err := parseUndoArgs(args)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	history, err := h.QuestionUseCase.GetHistory()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if len(history) == 0 {
+		h.IO.PrintError(errs.ErrNoActionsToUndo)
+		return errs.ErrNoActionsToUndo
+	}
+
+	if to > 0 {
+		if to > len(history) {
+			err := errs.WrapValidationError(fmt.Errorf("history entry %d out of range", to),
+				fmt.Sprintf("History entry %d does not exist. Run 'history' to see the entries", to))
+			h.IO.PrintError(err)
+			return err
+		}
+		if to == 1 {
+			h.IO.Println("Entry 1 is the latest action, so there is nothing to undo.")
+			h.IO.Printf("\n")
+			return nil
+		}
+		// Everything newer than the entry is undone
+		count = to - 1
+	}
+	if count > len(history) {
+		err := errs.WrapValidationError(fmt.Errorf("cannot undo %d of %d actions", count, len(history)),
+			fmt.Sprintf("Only %s can be undone", pluralize(len(history), "action")))
+		h.IO.PrintError(err)
+		return err
+	}
+
+	// Preview the actions and what reverting them does before confirming
+	h.IO.PrintlnColored(ColorWarning, "Undo reverts these actions, newest first:")
+	h.printDeltas(history[:count])
+	h.IO.Println(replaySummary(history[:count], true))
+	prompt := "Do you want to undo the previous action? [y/N]: "
+	if count > 1 {
+		prompt = fmt.Sprintf("Do you want to undo these %d actions? [y/N]: ", count)
+	}
+	confirm := strings.ToLower(h.IO.ReadLine(scanner, prompt))
+	if confirm != "y" && confirm != "yes" {
+		h.IO.PrintCancel("Cancelled")
+		h.IO.Printf("\n")
+		return nil
+	}
+
+	undone, err := h.QuestionUseCase.Undo(count)
+	if err != nil {
+		h.IO.PrintError(err)
+	} else {
+		pronoun := "them"
+		if len(undone) == 1 {
+			pronoun = "it"
+		}
+		h.IO.PrintSuccess(fmt.Sprintf("Undid %s. Run 'redo' to apply %s again.", pluralize(len(undone), "action"), pronoun))
+	}
+	h.IO.Printf("\n")
+	return err
 }
 
-func (h *HandlerImpl) HandleUndo(scanner *bufio.Scanner) {
-	// Confirm before undo
-	confirm := strings.ToLower(h.IO.ReadLine(scanner, "Do you want to undo the previous action? [y/N]: "))
+func (h *HandlerImpl) HandleRedo(scanner *bufio.Scanner, args []string) error {
+	count, err := parseRedoArgs(args)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	redoHistory, err := h.QuestionUseCase.GetRedoHistory()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if len(redoHistory) == 0 {
+		h.IO.PrintError(errs.ErrNoActionsToRedo)
+		return errs.ErrNoActionsToRedo
+	}
+	if count > len(redoHistory) {
+		err := errs.WrapValidationError(fmt.Errorf("cannot redo %d of %d actions", count, len(redoHistory)),
+			fmt.Sprintf("Only %s can be redone", pluralize(len(redoHistory), "action")))
+		h.IO.PrintError(err)
+		return err
+	}
+
+	// Preview the actions and what reapplying them does before confirming
+	h.IO.PrintlnColored(ColorWarning, "Redo applies these undone actions again, oldest first:")
+	h.printDeltas(redoHistory[:count])
+	h.IO.Println(replaySummary(redoHistory[:count], false))
+	prompt := "Do you want to redo the last undone action? [y/N]: "
+	if count > 1 {
+		prompt = fmt.Sprintf("Do you want to redo these %d actions? [y/N]: ", count)
+	}
+	confirm := strings.ToLower(h.IO.ReadLine(scanner, prompt))
 	if confirm != "y" && confirm != "yes" {
 		h.IO.PrintCancel("Cancelled")
 		h.IO.Printf("\n")
-		return
+		return nil
 	}
 
-	err := h.QuestionUseCase.Undo()
+	redone, err := h.QuestionUseCase.Redo(count)
 	if err != nil {
 		h.IO.PrintError(err)
 	} else {
-		h.IO.PrintSuccess("Undo successful")
+		h.IO.PrintSuccess(fmt.Sprintf("Redid %s", pluralize(len(redone), "action")))
 	}
 	h.IO.Printf("\n")
+	return err
 }
 
-func (h *HandlerImpl) HandleHistory() {
+func (h *HandlerImpl) HandleHistory() error {
 	deltas, err := h.QuestionUseCase.GetHistory()
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newHistoryDocument(deltas))
+		return nil
 	}
 
 	if len(deltas) == 0 {
 		h.IO.Println("No history available.")
-		return
+	} else {
+		h.IO.PrintlnColored(ColorHeader, "──────────────────────────────────────────── Action History (Order by time desc) ────────────────────────────────────────────")
+		h.printDeltas(deltas)
+		if len(deltas) > 1 {
+			h.IO.PrintlnColored(ColorAnnotation, "Run 'undo --to <#>' to go back to an entry.")
+		}
 	}
 
-	formatWithStrID := "%-6s %-9s %-60s %-22s %s"
-	formatWithIntID := "%-6d %-9s %-60s %-22s %s"
+	// The redo history is only a hint here, so failing to load it does not fail the command
+	if redoHistory, err := h.QuestionUseCase.GetRedoHistory(); err == nil && len(redoHistory) > 0 {
+		h.IO.PrintlnColored(ColorAnnotation, fmt.Sprintf("%s undone; run 'redo' to apply again.", pluralize(len(redoHistory), "action")))
+	}
+	h.IO.Printf("\n")
+	return nil
+}
+
+// printDeltas prints history entries as a table, numbering them from 1
+func (h *HandlerImpl) printDeltas(deltas []core.Delta) {
+	formatWithStrID := "%-4s %-6s %-9s %-60s %-22s %s"
+	formatWithIntID := "%-4d %-6d %-9s %-60s %-22s %s"
 
-	h.IO.PrintlnColored(ColorHeader, "─────────────────────────────────────── Action History (Order by time desc) ────────────────────────────────────────")
-	h.IO.PrintfColored(ColorHeader, formatWithStrID, "# ID", "Action", "Question", "Changes", "When")
+	h.IO.PrintfColored(ColorHeader, formatWithStrID, "#", "ID", "Action", "Question", "Changes", "When")
 	h.IO.Printf("\n")
-	for _, delta := range deltas {
+	for i, delta := range deltas {
 		// Extract question name from URL
 		var questionName string
-		if delta.NewState != nil {
+		if delta.Action.IsBatch() {
+			questionName = pluralize(len(delta.Batch), "question")
+		} else if delta.NewState != nil {
 			questionName = h.extractQuestionNameFromURL(delta.NewState.URL)
 		} else if delta.OldState != nil {
 			questionName = h.extractQuestionNameFromURL(delta.OldState.URL)
@@ -476,83 +1087,423 @@ func (h *HandlerImpl) HandleHistory() {
 
 		// Print entry. If multiple changes, print them on separate aligned lines.
 		if len(changeList) == 0 {
-			entry := fmt.Sprintf(formatWithIntID, delta.QuestionID, delta.Action.String(), questionName, "", timeDesc)
+			entry := fmt.Sprintf(formatWithIntID, i+1, delta.QuestionID, delta.Action.String(), questionName, "", timeDesc)
 			h.IO.Println(entry)
 		} else {
 			// First line with the first change and time
-			first := fmt.Sprintf(formatWithIntID, delta.QuestionID, delta.Action.String(), questionName, changeList[0], timeDesc)
+			first := fmt.Sprintf(formatWithIntID, i+1, delta.QuestionID, delta.Action.String(), questionName, changeList[0], timeDesc)
 			h.IO.Println(first)
 			// Continuation lines: only the Change column filled
-			for i := 1; i < len(changeList); i++ {
-				cont := fmt.Sprintf(formatWithStrID, "", "", "", changeList[i], "")
+			for j := 1; j < len(changeList); j++ {
+				cont := fmt.Sprintf(formatWithStrID, "", "", "", "", changeList[j], "")
 				h.IO.Println(cont)
 			}
 		}
 	}
-	h.IO.Printf("\n")
 }
 
-func (h *HandlerImpl) HandleSetting(scanner *bufio.Scanner, args []string) {
-	if len(args) == 0 {
-		// Show current configurable settings
-		registry := h.cfg.GetSettingsRegistry()
-		h.IO.PrintfColored(ColorHeader, "Current Settings:\n")
+// replaySummary describes what undoing, or redoing, the history entries does to the questions
+func replaySummary(deltas []core.Delta, undo bool) string {
+	counts := make(map[core.ActionType]int)
+	for _, delta := range deltas {
+		if !delta.Action.IsBatch() {
+			counts[delta.Action]++
+			continue
+		}
+		for _, step := range delta.Batch {
+			counts[step.Action]++
+		}
+		if delta.Action == core.ActionResume {
+			counts[core.ActionResume]++
+		}
+	}
+
+	var effects []string
+	if n := counts[core.ActionAdd]; n > 0 {
+		if undo {
+			effects = append(effects, "removes "+pluralize(n, "added question"))
+		} else {
+			effects = append(effects, "adds back "+pluralize(n, "question"))
+		}
+	}
+	if n := counts[core.ActionUpdate]; n > 0 {
+		if undo {
+			effects = append(effects, "reverts "+pluralize(n, "update"))
+		} else {
+			effects = append(effects, "reapplies "+pluralize(n, "update"))
+		}
+	}
+	if n := counts[core.ActionDelete]; n > 0 {
+		if undo {
+			effects = append(effects, "restores "+pluralize(n, "deleted question"))
+		} else {
+			effects = append(effects, "deletes "+pluralize(n, "question")+" again")
+		}
+	}
+	if counts[core.ActionResume] > 0 {
+		if undo {
+			effects = append(effects, "pauses reviews again")
+		} else {
+			effects = append(effects, "resumes reviews again")
+		}
+	}
+
+	if len(effects) > 1 {
+		effects = []string{strings.Join(effects[:len(effects)-1], ", "), effects[len(effects)-1]}
+	}
+	return "This " + strings.Join(effects, " and ") + "."
+}
+
+// pluralize formats a count with a noun that takes a plural "s"
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func (h *HandlerImpl) HandleTags() error {
+	tags, err := h.QuestionUseCase.ListTags()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newTagsDocument(tags))
+		return nil
+	}
 
-		for _, setting := range registry {
-			value, err := h.cfg.GetSettingValue(setting.Name)
+	if len(tags) == 0 {
+		h.IO.Println("No tags yet. Add tags when adding or updating a question.")
+		return nil
+	}
+
+	format := "%-24s %-10s %s\n"
+
+	h.IO.PrintlnColored(ColorHeader, "───────────── Tags ─────────────")
+	h.IO.PrintfColored(ColorHeader, format, "Tag", "Questions", "Due")
+	for _, tag := range tags {
+		if tag.TotalDue > 0 {
+			h.IO.PrintfColored(ColorStatDueTotal, format, tag.Name, strconv.Itoa(tag.Total), strconv.Itoa(tag.TotalDue))
+		} else {
+			h.IO.Printf(format, tag.Name, strconv.Itoa(tag.Total), "0")
+		}
+	}
+	h.IO.Printf("\n")
+	return nil
+}
+
+// transferArgs holds the arguments of the export and import commands
+type transferArgs struct {
+	path   string
+	as     string
+	policy usecase.ImportPolicy
+	dryRun bool
+}
+
+// parseTransferArgs parses the file path and the --as flag, plus --on-conflict and --dry-run when importing
+func (h *HandlerImpl) parseTransferArgs(args []string, importing bool) (*transferArgs, error) {
+	parsed := &transferArgs{policy: usecase.ImportSkip}
+	usage := "Usage: export <file> [--as=csv|json]"
+	if importing {
+		usage = "Usage: import <file> [--as=csv|json] [--on-conflict=skip|overwrite|merge] [--dry-run]"
+	}
+
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--as="):
+			parsed.as = strings.TrimPrefix(arg, "--as=")
+		case importing && strings.HasPrefix(arg, "--on-conflict="):
+			policy, err := usecase.ParseImportPolicy(strings.TrimPrefix(arg, "--on-conflict="))
 			if err != nil {
-				continue
+				return nil, err
 			}
-			h.IO.Printf("  %s: %v %s\n", setting.Name, value, setting.Unit)
+			parsed.policy = policy
+		case importing && arg == "--dry-run":
+			parsed.dryRun = true
+		case strings.HasPrefix(arg, "--"):
+			return nil, errs.WrapValidationError(fmt.Errorf("unknown flag %s", arg), fmt.Sprintf("Unknown flag: %s. %s", arg, usage))
+		case parsed.path == "":
+			parsed.path = arg
+		default:
+			return nil, errs.WrapValidationError(fmt.Errorf("unexpected argument %s", arg), usage)
+		}
+	}
+	return parsed, nil
+}
+
+func (h *HandlerImpl) HandleExport(scanner *bufio.Scanner, args []string) error {
+	input, err := h.parseTransferArgs(args, false)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if input.path == "" {
+		input.path = h.IO.ReadLine(scanner, "Enter the file to export to (.csv or .json): ")
+		if input.path == "" {
+			h.IO.PrintError(errs.ErrInvalidEmptyInput)
+			return errs.ErrInvalidEmptyInput
+		}
+	}
+	format, err := detectFileFormat(input.path, input.as)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	questions, err := h.QuestionUseCase.ExportQuestions()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := encodeExport(&buf, format, questions); err != nil {
+		err = errs.WrapInternalError(err, "Failed to encode export")
+		h.IO.PrintError(err)
+		return err
+	}
+	if err := os.WriteFile(input.path, buf.Bytes(), 0644); err != nil {
+		err = errs.WrapInternalError(err, fmt.Sprintf("Failed to write %s", input.path))
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Exported %d questions to %s", len(questions), input.path))
+	h.IO.Printf("\n")
+	return nil
+}
+
+func (h *HandlerImpl) HandleImport(scanner *bufio.Scanner, args []string) error {
+	input, err := h.parseTransferArgs(args, true)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if input.path == "" {
+		input.path = h.IO.ReadLine(scanner, "Enter the file to import (.csv or .json): ")
+		if input.path == "" {
+			h.IO.PrintError(errs.ErrInvalidEmptyInput)
+			return errs.ErrInvalidEmptyInput
+		}
+	}
+	format, err := detectFileFormat(input.path, input.as)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	data, err := os.ReadFile(input.path)
+	if err != nil {
+		err = errs.WrapValidationError(err, fmt.Sprintf("Cannot read %s", input.path))
+		h.IO.PrintError(err)
+		return err
+	}
+	views, err := decodeImport(data, format)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	questions := make([]core.Question, 0, len(views))
+	for i, view := range views {
+		q, err := h.questionFromView(view, i+1)
+		if err != nil {
+			h.IO.PrintError(err)
+			return err
+		}
+		questions = append(questions, q)
+	}
+
+	result, err := h.QuestionUseCase.ImportQuestions(questions, input.policy, input.dryRun)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	for _, item := range result.Items {
+		switch item.Outcome {
+		case usecase.ImportAdded:
+			h.IO.PrintfColored(ColorGreen, "  %-7s %s\n", item.Outcome, item.URL)
+		case usecase.ImportUpdated:
+			h.IO.PrintfColored(ColorYellow, "  %-7s %s\n", item.Outcome, item.URL)
+		default:
+			h.IO.Printf("  %-7s %s\n", item.Outcome, item.URL)
+		}
+	}
+	h.IO.Printf("\n")
+
+	summary := fmt.Sprintf("Added: %d  Updated: %d  Skipped: %d", result.Added, result.Updated, result.Skipped)
+	if result.DryRun {
+		h.IO.PrintCancel("Dry run, nothing was saved. " + summary)
+	} else {
+		h.IO.PrintSuccess("Import completed. " + summary)
+		if result.Added+result.Updated > 0 {
+			h.IO.PrintlnColored(ColorAnnotation, "Run 'undo' to revert the whole import.")
+		}
+	}
+	h.IO.Printf("\n")
+	return nil
+}
+
+func (h *HandlerImpl) HandleSetting(scanner *bufio.Scanner, args []string) error {
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "reset":
+			return h.resetSetting(args[1:])
+		case "diff":
+			return h.diffSettings()
+		}
+	}
+
+	if len(args) == 0 && h.structured() {
+		h.IO.PrintDocument(h.newSettingsDocument())
+		return nil
+	}
+
+	if len(args) == 0 {
+		// Show current configurable settings
+		settings := h.newSettingsDocument().Settings
+		h.IO.PrintfColored(ColorHeader, "Current Settings:\n")
+
+		for _, setting := range settings {
+			h.IO.Printf("  %s: %s %s\n", setting.Name, formatSettingValue(setting.Value, setting.levels), setting.Unit)
 		}
 
 		h.IO.Println()
 		h.IO.Println("To change a setting, use:")
 		h.IO.PrintlnColored(ColorYellow, "setting <setting_name> <value>")
+		h.IO.Println("To restore a default, or list the settings that differ from their defaults, use:")
+		h.IO.PrintlnColored(ColorYellow, "setting reset <setting_name>")
+		h.IO.PrintlnColored(ColorYellow, "setting diff")
 		h.IO.Println()
 		h.IO.PrintlnColored(ColorHeader, "Available settings:")
-		for _, setting := range registry {
-			h.IO.Printf("  %s (%s): %s\n", setting.Name, setting.Type, setting.Description)
+		registry := h.cfg.GetSettingsRegistry()
+		for _, setting := range settings {
+			h.IO.Printf("  %s (%s): %s\n", setting.Name, setting.Type, registry[strings.ToLower(setting.Name)].Description)
 		}
 
 		h.IO.PrintlnColored(ColorAnnotation, "Examples:")
 		h.IO.PrintlnColored(ColorAnnotation, "  setting RandomizeInterval false")
-		h.IO.PrintlnColored(ColorAnnotation, "  setting OverduePenalty true")
 		h.IO.PrintlnColored(ColorAnnotation, "  setting OverdueLimit 14")
-		return
+		h.IO.PrintlnColored(ColorAnnotation, "  setting ImportanceWeight 2.5")
+		h.IO.PrintlnColored(ColorAnnotation, "  setting BaseIntervals medium=4,critical=3")
+		h.IO.PrintlnColored(ColorAnnotation, "  setting reset PageSize")
+		return nil
 	}
 
 	if len(args) < 2 {
-		h.IO.PrintError(errs.WrapValidationError(errors.New("invalid usage"), "Usage: setting <setting_name> <value>"))
-		return
+		err := errs.WrapValidationError(errors.New("invalid usage"), "Usage: setting <setting_name> <value>")
+		h.IO.PrintError(err)
+		return err
 	}
 
 	settingName := args[0]
-	valueStr := args[1]
+	// Map settings may be given as several level=value arguments
+	valueStr := strings.Join(args[1:], " ")
 
 	settingInfo, err := h.cfg.GetSettingInfo(settingName)
 	if err != nil {
-		h.IO.PrintError(errs.WrapValidationError(err, ""))
-		return
+		err = errs.WrapValidationError(err, "")
+		h.IO.PrintError(err)
+		return err
 	}
 	value, err := settingInfo.Validator(valueStr)
 	if err != nil {
-		h.IO.PrintError(errs.WrapValidationError(err, ""))
-		return
+		err = errs.WrapValidationError(err, "")
+		h.IO.PrintError(err)
+		return err
 	}
 
 	if err := h.QuestionUseCase.UpdateSetting(settingName, value); err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
+	}
+
+	// Show the whole value, as a map setting changes only the given levels
+	current, _ := h.cfg.GetSettingValue(settingInfo.Name)
+	h.IO.PrintSuccess(fmt.Sprintf("%s set to %s %s", settingInfo.Name, formatSettingValue(current, settingInfo.Keys), settingInfo.Unit))
+	h.IO.Printf("\n")
+	return nil
+}
+
+// resetSetting puts the named setting back to its default value
+func (h *HandlerImpl) resetSetting(args []string) error {
+	if len(args) != 1 {
+		err := errs.WrapValidationError(errors.New("invalid usage"), "Usage: setting reset <setting_name>")
+		h.IO.PrintError(err)
+		return err
+	}
+
+	if err := h.QuestionUseCase.ResetSetting(args[0]); err != nil {
+		h.IO.PrintError(err)
+		return err
 	}
 
-	h.IO.PrintSuccess(fmt.Sprintf("%s set to %v %s", settingInfo.Name, value, settingInfo.Unit))
+	settingInfo, _ := h.cfg.GetSettingInfo(args[0])
+	current, _ := h.cfg.GetSettingValue(settingInfo.Name)
+	h.IO.PrintSuccess(fmt.Sprintf("%s reset to %s %s", settingInfo.Name, formatSettingValue(current, settingInfo.Keys), settingInfo.Unit))
 	h.IO.Printf("\n")
+	return nil
+}
+
+// diffSettings lists the settings whose values differ from their defaults
+func (h *HandlerImpl) diffSettings() error {
+	diffs := h.cfg.SettingDiffs()
+	doc := SettingDiffsDocument{Settings: make([]SettingDiffView, 0, len(diffs))}
+	for _, diff := range diffs {
+		doc.Settings = append(doc.Settings, SettingDiffView{
+			Name:    diff.Setting.Name,
+			Type:    diff.Setting.Type,
+			Value:   diff.Value,
+			Default: diff.Default,
+			Unit:    diff.Setting.Unit,
+			levels:  diff.Setting.Keys,
+		})
+	}
+
+	if h.structured() {
+		h.IO.PrintDocument(doc)
+		return nil
+	}
+
+	if len(doc.Settings) == 0 {
+		h.IO.Println("All settings are at their default values.")
+		return nil
+	}
+
+	h.IO.PrintfColored(ColorHeader, "Settings Changed from Defaults (%d):\n", len(doc.Settings))
+	for _, setting := range doc.Settings {
+		h.IO.Printf("  %s: %s %s\n", setting.Name, formatSettingValue(setting.Value, setting.levels), setting.Unit)
+		h.IO.PrintfColored(ColorAnnotation, "    default: %s %s\n", formatSettingValue(setting.Default, setting.levels), setting.Unit)
+	}
+	h.IO.Println()
+	h.IO.PrintlnColored(ColorAnnotation, "Run 'setting reset <setting_name>' to restore a default.")
+	return nil
+}
+
+func (h *HandlerImpl) newSettingsDocument() SettingsDocument {
+	registry := h.cfg.GetSettingsRegistry()
+
+	settings := make([]SettingView, 0, len(registry))
+	for _, setting := range registry {
+		value, err := h.cfg.GetSettingValue(setting.Name)
+		if err != nil {
+			continue
+		}
+		settings = append(settings, SettingView{Name: setting.Name, Type: setting.Type, Value: value, Unit: setting.Unit, levels: setting.Keys})
+	}
+
+	// Registry iteration order is random; sort for stable output
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Name < settings[j].Name
+	})
+	return SettingsDocument{Settings: settings}
 }
 
-func (h *HandlerImpl) HandleUnknown(command string) {
+func (h *HandlerImpl) HandleUnknown(command string) error {
 	h.IO.PrintfColored(ColorWarning, "Unknown command: '%s'\n", command)
 	h.IO.PrintfColored(ColorWarning, "Type 'help' or 'h' for more information\n")
+	return errs.ErrUnknownCommand
 }
 
 func (h *HandlerImpl) HandleHelp() {
@@ -562,18 +1513,56 @@ func (h *HandlerImpl) HandleHelp() {
 	h.IO.Println("  status/stat                   - Show question status (total, due, upcoming)")
 	h.IO.Println("  list/ls                       - List all questions with pagination")
 	h.IO.Println("  search/s [queries] [filters]  - Search questions on URL or note with optional filters")
-	h.IO.Println("                                   Filters: --familiarity=1-5, --importance=1-4, --review-count=N, --due-only")
+	h.IO.Println("                                   Filters: --familiarity=1-5, --importance=1-4, --review-count=N, --due-only,")
+	h.IO.Println("                                            --tag=TAG, --no-tag=TAG, --state=active|suspended|buried|archived")
 	h.IO.Println("  detail/get [id|url]           - Get details of a question by ID or URL")
-	h.IO.Println("  upsert/add                    - Add or update a question")
+	h.IO.Println("  upsert/add [url] [flags]      - Add or update a question")
+	h.IO.Println("                                   Flags: --note=TEXT, --tags=A,B, --familiarity=1-5, --memory=1-3, --importance=1-4,")
+	h.IO.Println("                                          --minutes=N, --first-pass=yes|no, --attempts=N")
+	h.IO.Println("  review/rev                    - Review due questions one by one in priority order")
 	h.IO.Println("  remove/rm/delete/del [id|url] - Delete a question by ID or URL")
-	h.IO.Println("  undo/back                     - Undo the last action")
+	h.IO.Println("  undo/back [n]                 - Undo the last action, or the last n actions")
+	h.IO.Println("  undo --to <#>                 - Undo every action after history entry #")
+	h.IO.Println("  redo [n]                      - Apply the last undone action, or n of them, again")
 	h.IO.Println("  history/hist/log              - Show action history")
+	h.IO.Println("  tags                          - List tags with question and due counts")
+	h.IO.Println("  export [file] [--as=csv|json] - Export all questions to a CSV or JSON file")
+	h.IO.Println("  import [file] [flags]         - Import questions from a CSV or JSON file as one undoable action")
+	h.IO.Println("                                   Flags: --as=csv|json, --on-conflict=skip|overwrite|merge, --dry-run")
 	h.IO.Println("  setting/config/cfg            - View and modify application settings")
+	h.IO.Println("  setting reset <name>          - Restore the default value of a setting")
+	h.IO.Println("  setting diff                  - Show the settings that differ from their defaults")
+	h.IO.Println("  doctor/fsck [--repair]        - Check data files for problems; --repair fixes them after a backup")
+	h.IO.Println("  backup [list]                 - List the backups taken before your data changed")
+	h.IO.Println("  backup create [reason]        - Back up the data files now")
+	h.IO.Println("  backup restore <id>           - Restore the data files from a backup")
+	h.IO.Println("  simulate/sim [flags]          - Simulate the review load of the coming days without saving")
+	h.IO.Println("                                   Flags: --days=1-365, --familiarity=1-5, --memory=1-3, --add=N, --per-day=N")
+	h.IO.Println("  forecast/fc [flags]           - Chart and calendar of the questions coming due")
+	h.IO.Println("                                   Flags: --days=1-90, --weeks, --importance=1-4, --tag=TAG, --no-tag=TAG")
+	h.IO.Println("  optimize/opt [flags]          - Fit the SM-2 settings to your review history and offer to apply them")
+	h.IO.Println("                                   Flags: --seed=N, --rounds=1-500")
+	h.IO.Println("  stats                         - Show statistics on your questions and reviews")
+	h.IO.Println("  leeches/leech                 - List the questions you keep failing, most lapses first")
+	h.IO.Println("  leeches clear <id|url>        - Give a leech a fresh start (undoable)")
+	h.IO.Println("  suspend <id|url>              - Leave a question out of reviews until unsuspended (undoable)")
+	h.IO.Println("  unsuspend <id|url>            - Bring a suspended, buried or archived question back (undoable)")
+	h.IO.Println("  bury <id|url> [days|date]     - Leave a question out of reviews for some days, 1 by default (undoable)")
+	h.IO.Println("  archive/mastered <id|url>     - Retire a mastered question from reviews (undoable)")
+	h.IO.Println("  start <id|url>                - Start a timer on the question you are solving")
+	h.IO.Println("  done [flags]                  - Stop the timer and record the solve, taking the flags of add")
+	h.IO.Println("  pause                         - Pause reviews, e.g. for a trip; paused days do not count as overdue")
+	h.IO.Println("  resume                        - Resume reviews, moving next reviews by the paused days (undoable)")
 	h.IO.Println("  reset                         - Delete all questions and history")
 	h.IO.Println("  version/ver/v                 - Show version information")
 	h.IO.Println("  help/h                        - Show this help message")
 	h.IO.Println("  clear/cls                     - Clear the screen")
 	h.IO.Println("  quit/q/exit                   - Exit the application")
+	h.IO.PrintfColored(ColorHeader, "\nGlobal Flags (apply to the command they are given with):\n")
+	h.IO.Println("  --json                        - Print results of read commands as JSON")
+	h.IO.Println("  --format=text|json|tsv        - Choose the output format of read commands")
+	h.IO.PrintfColored(ColorHeader, "\nExit Codes (command line mode):\n")
+	h.IO.Println("  0 success, 1 system error, 2 validation error, 3 business error (e.g. question not found)")
 	h.IO.PrintfColored(ColorHeader, "\nTips:\n")
 	h.IO.Println("  • Commands are case-insensitive")
 	h.IO.Println("  • Press Enter to continue pagination")
@@ -612,6 +1601,16 @@ func (h *HandlerImpl) getChanges(oldState, newState *core.Question) []string {
 		changes = append(changes, fmt.Sprintf("Familiarity: %d → %d", oldState.Familiarity+1, newState.Familiarity+1))
 	}
 
+	if !slices.Equal(oldState.Tags, newState.Tags) {
+		changes = append(changes, "Tags changed")
+	}
+
+	if newState.State == core.StateBuried && !oldState.BuriedUntil.Equal(newState.BuriedUntil) {
+		changes = append(changes, fmt.Sprintf("Buried → %s", newState.BuriedUntil.Format(time.DateOnly)))
+	} else if oldState.State != newState.State {
+		changes = append(changes, fmt.Sprintf("State → %s", newState.State))
+	}
+
 	return changes
 }
 
@@ -619,7 +1618,7 @@ func (h *HandlerImpl) HandleVersion() {
 	h.IO.Println(h.Version)
 }
 
-func (h *HandlerImpl) HandleMigrate(scanner *bufio.Scanner) {
+func (h *HandlerImpl) HandleMigrate(scanner *bufio.Scanner) error {
 	h.IO.Println("This will convert all timestamps in your data files to UTC format.")
 	h.IO.Println("This is recommended if you upgraded from a version (v1.0.5 or earlier) that used local timezone.")
 	h.IO.Println("")
@@ -627,39 +1626,974 @@ func (h *HandlerImpl) HandleMigrate(scanner *bufio.Scanner) {
 	confirm := h.IO.ReadLine(scanner, "Proceed with migration? [y/N]: ")
 	if confirm != "y" && confirm != "Y" {
 		h.IO.PrintCancel("Migration cancelled.")
-		return
+		return nil
 	}
 
 	questionsCount, deltasCount, err := h.QuestionUseCase.MigrateToUTC()
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
 	}
 
 	h.IO.PrintSuccess(fmt.Sprintf("Migration completed: %d questions, %d deltas converted to UTC.", questionsCount, deltasCount))
+	return nil
 }
 
-func (h *HandlerImpl) HandleReset(scanner *bufio.Scanner) {
-	h.IO.PrintlnColored(ColorWarning, "⚠️  This will permanently delete ALL your data:")
-	h.IO.Println("    • All questions")
-	h.IO.Println("    • All review history (undo history)")
-	h.IO.Println("")
-	h.IO.PrintlnColored(ColorWarning, "This action cannot be undone.")
-	h.IO.Println("")
+func (h *HandlerImpl) HandleDoctor(args []string) error {
+	repair := false
+	for _, arg := range args {
+		if arg != "--repair" {
+			err := errs.WrapValidationError(fmt.Errorf("unexpected argument %s", arg), "Usage: doctor [--repair]")
+			h.IO.PrintError(err)
+			return err
+		}
+		repair = true
+	}
 
-	confirm := h.IO.ReadLine(scanner, "Type 'yes' to confirm (any other input cancels): ")
-	if confirm != "yes" {
-		h.IO.PrintCancel("Reset cancelled.")
-		h.IO.Printf("\n")
+	result, err := h.QuestionUseCase.CheckData(repair)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	// Issues a repair did not fix still need attention
+	remaining := len(result.Issues) - result.Repaired
+
+	if h.structured() {
+		h.IO.PrintDocument(newDoctorDocument(result))
+	} else {
+		h.printDoctorResult(result, repair)
+	}
+
+	// The problems are already listed, so the error only sets the exit code
+	if remaining > 0 {
+		return errs.ErrDataIssuesFound
+	}
+	return nil
+}
+
+func (h *HandlerImpl) printDoctorResult(result *usecase.CheckResult, repair bool) {
+	if len(result.Issues) == 0 {
+		h.IO.PrintSuccess("No problems found.")
 		return
 	}
 
-	questionsCount, deltasCount, err := h.QuestionUseCase.ResetData()
+	h.IO.PrintlnColored(ColorHeader, "───────────── Problems ─────────────")
+	for _, issue := range result.Issues {
+		if issue.Repairable {
+			h.IO.PrintfColored(ColorWarning, "[%s] %s\n", issue.Kind, issue.Message)
+		} else {
+			h.IO.PrintfColored(ColorWarning, "[%s] %s (needs a manual fix)\n", issue.Kind, issue.Message)
+		}
+	}
+	h.IO.Printf("\n")
+
+	if repair {
+		if result.Repaired > 0 {
+			h.IO.PrintSuccess(fmt.Sprintf("Repaired %d of %d problems.", result.Repaired, len(result.Issues)))
+			h.IO.Printf("The previous data is in backup %s; run 'backup restore %s' to bring it back.\n", result.Backup, result.Backup)
+		}
+		return
+	}
+
+	if repairable := result.Repairable(); repairable > 0 {
+		h.IO.Printf("Run 'doctor --repair' to fix %d of %d problems; the data files are backed up first.\n", repairable, len(result.Issues))
+	}
+}
+
+// simulateUsage describes the flags of the simulate command
+const simulateUsage = "Usage: simulate [--days=N] [--familiarity=1-5] [--memory=1-3] [--add=N] [--per-day=N]"
+
+// maxLoadBar is the widest bar of the daily load chart
+const maxLoadBar = 50
+
+// parseSimulateArgs parses the simulate flags; days default to 30 and reviews repeat the current familiarity
+func (h *HandlerImpl) parseSimulateArgs(args []string) (usecase.SimulationOptions, error) {
+	opts := usecase.SimulationOptions{Days: 30, Memory: core.MemoryReasoned}
+
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch name {
+		case "--days":
+			opts.Days, err = strconv.Atoi(value)
+			if err != nil || opts.Days < 1 || opts.Days > usecase.MaxSimulationDays {
+				return opts, errs.WrapValidationError(fmt.Errorf("invalid days %q", value),
+					fmt.Sprintf("Please simulate between 1 and %d days", usecase.MaxSimulationDays))
+			}
+		case "--familiarity":
+			familiarity, err := h.validateFamiliarity(value)
+			if err != nil {
+				return opts, err
+			}
+			opts.Familiarity = &familiarity
+		case "--memory":
+			if opts.Memory, err = h.validateMemoryUse(value); err != nil {
+				return opts, err
+			}
+		case "--add":
+			opts.NewQuestions, err = strconv.Atoi(value)
+			if err != nil || opts.NewQuestions < 0 {
+				return opts, errs.WrapValidationError(fmt.Errorf("invalid count %q", value), "Please enter the number of questions to add")
+			}
+		case "--per-day":
+			opts.NewPerDay, err = strconv.Atoi(value)
+			if err != nil || opts.NewPerDay < 1 {
+				return opts, errs.WrapValidationError(fmt.Errorf("invalid count %q", value), "Please enter how many questions to add per day")
+			}
+		default:
+			return opts, errs.WrapValidationError(fmt.Errorf("unexpected argument %s", arg), simulateUsage)
+		}
+	}
+	return opts, nil
+}
+
+func (h *HandlerImpl) HandleSimulate(args []string) error {
+	opts, err := h.parseSimulateArgs(args)
 	if err != nil {
 		h.IO.PrintError(err)
-		return
+		return err
 	}
 
-	h.IO.PrintSuccess(fmt.Sprintf("Reset completed: %d questions and %d history records deleted.", questionsCount, deltasCount))
+	result, err := h.QuestionUseCase.Simulate(opts)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newSimulationDocument(result))
+		return nil
+	}
+
+	h.IO.PrintlnColored(ColorHeader, fmt.Sprintf("───────────── Simulation: next %d days ─────────────", opts.Days))
+	h.IO.PrintlnColored(ColorAnnotation, describeSimulation(opts))
+	h.IO.Println("Nothing is saved; your questions stay as they are.")
+	h.IO.Printf("\n")
+
+	h.IO.PrintlnColored(ColorHeader, "-- Daily Load --")
+	h.printLoadChart(result.Days)
+	h.IO.Printf("\n")
+
+	if len(result.Peaks) > 0 {
+		peaks := make([]string, 0, len(result.Peaks))
+		for _, day := range result.Peaks {
+			peaks = append(peaks, fmt.Sprintf("%s (%d)", day.Date.Format("Mon 2006-01-02"), day.Load()))
+		}
+		h.IO.PrintfColored(ColorWarning, "Peak days: %s\n", strings.Join(peaks, ", "))
+	}
+	total := result.TotalLoad()
+	h.IO.Printf("Total: %s over %d days, %.1f per day on average\n", pluralize(total, "question"), len(result.Days), float64(total)/float64(len(result.Days)))
+	h.IO.Printf("\n")
+
+	if len(result.Questions) == 0 {
+		return nil
+	}
+	h.IO.PrintlnColored(ColorHeader, "-- Interval Growth (days) --")
+	format := "%-8s %-50s %-8s %s\n"
+	h.IO.PrintfColored(ColorHeader, format, "ID", "Question", "Reviews", "Intervals")
+	for _, q := range result.Questions {
+		id, name := strconv.Itoa(q.ID), h.extractQuestionNameFromURL(q.URL)
+		if q.New {
+			id, name = fmt.Sprintf("new %d", q.ID), "(hypothetical)"
+		}
+		intervals := make([]string, 0, len(q.Intervals))
+		for _, days := range q.Intervals {
+			intervals = append(intervals, strconv.Itoa(days))
+		}
+		h.IO.Printf(format, id, name, strconv.Itoa(len(q.Intervals)), strings.Join(intervals, " → "))
+	}
 	h.IO.Printf("\n")
+	return nil
+}
+
+// describeSimulation states the assumptions a simulation ran under
+func describeSimulation(opts usecase.SimulationOptions) string {
+	outcome := "each question is rated as it was last time"
+	if opts.Familiarity != nil {
+		outcome = fmt.Sprintf("every review is rated familiarity %d", *opts.Familiarity+1)
+	}
+	description := fmt.Sprintf("Assuming %s, with memory use %d", outcome, opts.Memory+1)
+
+	switch {
+	case opts.NewQuestions == 0:
+	case opts.NewPerDay > 0:
+		description += fmt.Sprintf(", and %s added, %d per day", pluralize(opts.NewQuestions, "new question"), opts.NewPerDay)
+	default:
+		description += fmt.Sprintf(", and %s added today", pluralize(opts.NewQuestions, "new question"))
+	}
+	return description + "."
+}
+
+// printLoadChart prints a bar per simulated day
+func (h *HandlerImpl) printLoadChart(days []usecase.SimulatedDay) {
+	busiest := 0
+	for _, day := range days {
+		busiest = max(busiest, day.Load())
+	}
+
+	for _, day := range days {
+		line := fmt.Sprintf("%s │%s %d", day.Date.Format("Mon 2006-01-02"), loadBar(day.Load(), busiest), day.Load())
+		if day.Added > 0 {
+			line += fmt.Sprintf(" (%d new)", day.Added)
+		}
+		h.IO.Println(line)
+	}
+}
+
+// loadBar draws a bar as wide as the load, scaled down when the busiest load is wider than maxLoadBar
+func loadBar(load, busiest int) string {
+	width := load
+	if busiest > maxLoadBar {
+		width = (width*maxLoadBar + busiest - 1) / busiest
+	}
+	return strings.Repeat("█", width)
+}
+
+// forecastUsage describes the flags of the forecast command
+const forecastUsage = "Usage: forecast [--days=N] [--weeks] [--importance=1-4] [--tag=TAG] [--no-tag=TAG]"
+
+// forecastArgs holds the arguments of the forecast command
+type forecastArgs struct {
+	days   int
+	weekly bool
+	filter *core.SearchFilter // nil without filter flags
+}
+
+// parseForecastArgs parses the forecast flags; the forecast covers 30 days by default
+func (h *HandlerImpl) parseForecastArgs(args []string) (*forecastArgs, error) {
+	parsed := &forecastArgs{days: 30}
+	filter := &core.SearchFilter{}
+
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		switch name {
+		case "--days":
+			days, err := strconv.Atoi(value)
+			if err != nil || days < 1 || days > usecase.MaxForecastDays {
+				return nil, errs.WrapValidationError(fmt.Errorf("invalid days %q", value),
+					fmt.Sprintf("Please forecast between 1 and %d days", usecase.MaxForecastDays))
+			}
+			parsed.days = days
+		case "--weeks":
+			parsed.weekly = true
+		case "--importance":
+			importance, err := h.validateImportance(value)
+			if err != nil {
+				return nil, err
+			}
+			filter.Importance = &importance
+		case "--tag", "--no-tag":
+			tags, err := h.parseTags(value)
+			if err != nil {
+				return nil, err
+			}
+			if name == "--tag" {
+				filter.Tags = append(filter.Tags, tags...)
+			} else {
+				filter.ExcludeTags = append(filter.ExcludeTags, tags...)
+			}
+		default:
+			return nil, errs.WrapValidationError(fmt.Errorf("unexpected argument %s", arg), forecastUsage)
+		}
+	}
+
+	if filter.Importance != nil || len(filter.Tags) > 0 || len(filter.ExcludeTags) > 0 {
+		parsed.filter = filter
+	}
+	return parsed, nil
+}
+
+func (h *HandlerImpl) HandleForecast(args []string) error {
+	parsed, err := h.parseForecastArgs(args)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	forecast, err := h.QuestionUseCase.ForecastDue(parsed.days, parsed.filter)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newForecastDocument(forecast, parsed.weekly))
+		return nil
+	}
+
+	h.IO.PrintlnColored(ColorHeader, fmt.Sprintf("───────────── Forecast: next %d days ─────────────", parsed.days))
+	if parsed.filter != nil {
+		h.IO.PrintlnColored(ColorAnnotation, describeForecastFilter(parsed.filter))
+	}
+	if forecast.Total == 0 {
+		h.IO.Println("No questions to forecast.")
+		h.IO.Printf("\n")
+		return nil
+	}
+	h.IO.Printf("\n")
+
+	if parsed.weekly {
+		h.IO.PrintlnColored(ColorHeader, "-- Due per Week --")
+		weeks := forecast.Weeks()
+		busiest := 0
+		for _, week := range weeks {
+			busiest = max(busiest, week.Due)
+		}
+		for _, week := range weeks {
+			h.IO.Printf("%s – %s │%s %d\n", week.Start.Format("Jan 02"), week.End.Format("Jan 02"), loadBar(week.Due, busiest), week.Due)
+		}
+	} else {
+		h.IO.PrintlnColored(ColorHeader, "-- Due per Day --")
+		busiest := 0
+		for _, day := range forecast.Days {
+			busiest = max(busiest, day.Due)
+		}
+		for i, day := range forecast.Days {
+			line := fmt.Sprintf("%s │%s %d", day.Date.Format("Mon 2006-01-02"), loadBar(day.Due, busiest), day.Due)
+			if i == 0 && forecast.Overdue > 0 {
+				line += fmt.Sprintf(" (%d overdue)", forecast.Overdue)
+			}
+			h.IO.Println(line)
+		}
+	}
+	h.IO.Printf("\n")
+
+	h.IO.PrintlnColored(ColorHeader, "-- Calendar --")
+	h.printForecastCalendar(forecast.Days)
+
+	if len(forecast.Peaks) > 0 {
+		peaks := make([]string, 0, len(forecast.Peaks))
+		for _, day := range forecast.Peaks {
+			peaks = append(peaks, fmt.Sprintf("%s (%d)", day.Date.Format("Mon 2006-01-02"), day.Due))
+		}
+		h.IO.PrintfColored(ColorWarning, "Peak days: %s\n", strings.Join(peaks, ", "))
+	}
+	due := forecast.DueInRange()
+	h.IO.Printf("Total: %s due over %d days, %.1f per day on average\n", pluralize(due, "question"), len(forecast.Days), float64(due)/float64(len(forecast.Days)))
+	if forecast.Later > 0 {
+		h.IO.Printf("Later: %s due after %s\n", pluralize(forecast.Later, "question"), forecast.Days[len(forecast.Days)-1].Date.Format("Mon 2006-01-02"))
+	}
+	h.IO.Printf("\n")
+	return nil
+}
+
+// describeForecastFilter states which questions a filtered forecast counts
+func describeForecastFilter(filter *core.SearchFilter) string {
+	var conditions []string
+	if filter.Importance != nil {
+		conditions = append(conditions, fmt.Sprintf("importance %d", *filter.Importance+1))
+	}
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, "tagged "+strings.Join(filter.Tags, " and "))
+	}
+	if len(filter.ExcludeTags) > 0 {
+		conditions = append(conditions, "not tagged "+strings.Join(filter.ExcludeTags, " or "))
+	}
+	return "Counting only questions with " + strings.Join(conditions, ", ") + "."
+}
+
+// printForecastCalendar prints a month grid per month of the forecast, Monday first, with the
+// number of questions due under each day. Days outside the forecast are left blank.
+func (h *HandlerImpl) printForecastCalendar(days []usecase.ForecastDay) {
+	const cell = "%5s"
+
+	for start := 0; start < len(days); {
+		month := days[start].Date
+		end := start
+		for end < len(days) && days[end].Date.Month() == month.Month() {
+			end++
+		}
+
+		h.IO.PrintlnColored(ColorHeader, month.Format("January 2006"))
+		var header strings.Builder
+		for _, name := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+			fmt.Fprintf(&header, cell, name)
+		}
+		h.IO.PrintlnColored(ColorAnnotation, header.String())
+
+		// Pad the first week up to the weekday of the first forecast day of the month
+		var dates, counts strings.Builder
+		column := (int(month.Weekday()) + 6) % 7
+		dates.WriteString(strings.Repeat(" ", 5*column))
+		counts.WriteString(strings.Repeat(" ", 5*column))
+		for _, day := range days[start:end] {
+			fmt.Fprintf(&dates, cell, strconv.Itoa(day.Date.Day()))
+			count := "·"
+			if day.Due > 0 {
+				count = strconv.Itoa(day.Due)
+			}
+			fmt.Fprintf(&counts, cell, count)
+
+			if column++; column == 7 {
+				h.IO.Println(dates.String())
+				h.IO.Println(counts.String())
+				dates.Reset()
+				counts.Reset()
+				column = 0
+			}
+		}
+		if column > 0 {
+			h.IO.Println(dates.String())
+			h.IO.Println(counts.String())
+		}
+		h.IO.Printf("\n")
+		start = end
+	}
+}
+
+func (h *HandlerImpl) HandleBackup(scanner *bufio.Scanner, args []string) error {
+	subcommand := "list"
+	if len(args) > 0 {
+		subcommand = strings.ToLower(args[0])
+	}
+
+	switch {
+	case subcommand == "list" && len(args) <= 1:
+		return h.listBackups()
+	case subcommand == "create":
+		reason := strings.Join(args[1:], " ")
+		if reason == "" {
+			reason = "manual"
+		}
+		return h.createBackup(reason)
+	case subcommand == "restore" && len(args) == 2:
+		return h.restoreBackup(scanner, args[1])
+	}
+
+	err := errs.WrapValidationError(fmt.Errorf("invalid backup arguments %v", args), "Usage: backup [list | create [reason] | restore <id>]")
+	h.IO.PrintError(err)
+	return err
+}
+
+func (h *HandlerImpl) listBackups() error {
+	snapshots, err := h.QuestionUseCase.ListBackups()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	if h.structured() {
+		h.IO.PrintDocument(newBackupsDocument(snapshots))
+		return nil
+	}
+
+	if len(snapshots) == 0 {
+		h.IO.Println("No backups yet. One is taken automatically before your data files change.")
+		return nil
+	}
+
+	h.IO.PrintlnColored(ColorHeader, "───────────── Backups ─────────────")
+	for _, snapshot := range snapshots {
+		h.IO.Printf("%-20s %-16s %9s  %s\n", snapshot.ID, h.IO.FormatTimeAgo(snapshot.CreatedAt), formatSize(snapshot.Size()), snapshot.Reason)
+	}
+	h.IO.Printf("\n")
+	h.IO.Println("Run 'backup restore <id>' to bring back the data of a backup.")
+	return nil
+}
+
+func (h *HandlerImpl) createBackup(reason string) error {
+	snapshot, err := h.QuestionUseCase.CreateBackup(reason)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Backup %s created.", snapshot.ID))
+	return nil
+}
+
+func (h *HandlerImpl) restoreBackup(scanner *bufio.Scanner, id string) error {
+	snapshots, err := h.QuestionUseCase.ListBackups()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	index := slices.IndexFunc(snapshots, func(s backup.Snapshot) bool { return s.ID == id })
+	if index < 0 {
+		h.IO.PrintError(errs.ErrBackupNotFound)
+		return errs.ErrBackupNotFound
+	}
+
+	snapshot := snapshots[index]
+	h.IO.PrintlnColored(ColorWarning, fmt.Sprintf("⚠️  This will replace your questions, history, review log and settings with backup %s", id))
+	h.IO.Printf("    (%s, taken %s).\n", snapshot.Reason, h.IO.FormatTimeAgo(snapshot.CreatedAt))
+	h.IO.Println("The current data is backed up first, so the restore can be undone the same way.")
+	h.IO.Println("")
+
+	confirm := h.IO.ReadLine(scanner, "Proceed with restore? [y/N]: ")
+	if confirm != "y" && confirm != "Y" {
+		h.IO.PrintCancel("Restore cancelled.")
+		return nil
+	}
+
+	restored, before, err := h.QuestionUseCase.RestoreBackup(id)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Restored backup %s.", restored.ID))
+	if before != nil {
+		h.IO.Printf("The data from before the restore is in backup %s.\n", before.ID)
+	}
+	return nil
+}
+
+// formatSize renders a byte count with a binary unit, e.g. 1.5 KiB
+func formatSize(size int64) string {
+	switch {
+	case size < 1<<10:
+		return fmt.Sprintf("%d B", size)
+	case size < 1<<20:
+		return fmt.Sprintf("%.1f KiB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+	}
+}
+
+func (h *HandlerImpl) HandleReset(scanner *bufio.Scanner) error {
+	h.IO.PrintlnColored(ColorWarning, "⚠️  This will permanently delete ALL your data:")
+	h.IO.Println("    • All questions")
+	h.IO.Println("    • All undo history")
+	h.IO.Println("    • All review log entries")
+	h.IO.Println("")
+	h.IO.PrintlnColored(ColorWarning, "This action cannot be undone, but your data is backed up first; see 'backup list'.")
+	h.IO.Println("")
+
+	confirm := h.IO.ReadLine(scanner, "Type 'yes' to confirm (any other input cancels): ")
+	if confirm != "yes" {
+		h.IO.PrintCancel("Reset cancelled.")
+		h.IO.Printf("\n")
+		return nil
+	}
+
+	questionsCount, deltasCount, err := h.QuestionUseCase.ResetData()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Reset completed: %d questions and %d history records deleted.", questionsCount, deltasCount))
+	h.IO.Printf("\n")
+	return nil
+}
+
+// doneUsage describes the arguments of the done command
+const doneUsage = "Usage: done [--note=...] [--tags=...] [--familiarity=1-5] [--memory=1-3] [--importance=1-4] [--minutes=N] [--first-pass=yes|no] [--attempts=N]"
+
+func (h *HandlerImpl) HandleStart(args []string) error {
+	if len(args) != 1 {
+		err := errs.WrapValidationError(fmt.Errorf("invalid start arguments %v", args), "Usage: start <id|url>")
+		h.IO.PrintError(err)
+		return err
+	}
+	target, err := h.normalizeTarget(args[0])
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	timer, err := h.QuestionUseCase.StartTimer(target)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Timer started at %s on %s", timer.Start.Local().Format("15:04"), timer.URL))
+	h.IO.PrintlnColored(ColorAnnotation, "Run 'done' once it is solved, even from another session.")
+	h.IO.Printf("\n")
+	return nil
+}
+
+// HandleDone stops the solve timer and goes on to record the review of the timed question with
+// the minutes the timer ran. A question already added keeps its note, tags and importance unless
+// they are given as flags.
+func (h *HandlerImpl) HandleDone(scanner *bufio.Scanner, args []string) error {
+	input, err := h.parseUpsertArgs(args)
+	if err == nil && input.rawURL != "" {
+		err = errs.WrapValidationError(fmt.Errorf("unexpected argument %s", input.rawURL), doneUsage)
+	}
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	stopped, err := h.QuestionUseCase.StopTimer()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	h.IO.PrintSuccess(fmt.Sprintf("Timer stopped after %s", pluralize(stopped.Minutes, "minute")))
+
+	input.rawURL = stopped.Timer.URL
+	input.elapsed = stopped.Minutes
+	if q := stopped.Question; q != nil {
+		if input.note == nil {
+			input.note = &q.Note
+		}
+		if !input.tagsSet {
+			input.tags, input.tagsSet = q.Tags, true
+		}
+		if input.importance == nil {
+			input.importance = &q.Importance
+		}
+	}
+
+	if err := h.upsert(scanner, input); err != nil {
+		h.IO.PrintlnColored(ColorAnnotation, fmt.Sprintf("The timer is stopped. Run 'add %s --minutes=%d' to record the solve.", stopped.Timer.URL, stopped.Minutes))
+		return err
+	}
+	return nil
+}
+
+func (h *HandlerImpl) HandlePause() error {
+	pause, err := h.QuestionUseCase.PauseReviews()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Reviews paused from %s. Run 'resume' when you are back.", pause.Start.Format(time.DateOnly)))
+	h.IO.Printf("\n")
+	return nil
+}
+
+func (h *HandlerImpl) HandleResume() error {
+	result, err := h.QuestionUseCase.ResumeReviews()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	if result.Days == 0 {
+		h.IO.PrintSuccess("Reviews resumed on the day they were paused, so no review moved")
+	} else {
+		h.IO.PrintSuccess(fmt.Sprintf("Reviews resumed after %s. Moved the next review of %s by %s.",
+			pluralize(result.Days, "day"), pluralize(result.Shifted, "question"), pluralize(result.Days, "day")))
+		h.IO.PrintlnColored(ColorAnnotation, "Run 'undo' to pause reviews again.")
+	}
+	h.IO.Printf("\n")
+	return nil
+}
+
+// optimizeUsage describes the flags of the optimize command
+const optimizeUsage = "Usage: optimize [--seed=N] [--rounds=N]"
+
+// parseOptimizeArgs parses the optimize flags
+func parseOptimizeArgs(args []string) (usecase.OptimizeOptions, error) {
+	opts := usecase.OptimizeOptions{Seed: usecase.DefaultOptimizeSeed, Rounds: usecase.DefaultOptimizeRounds}
+
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		var err error
+		switch name {
+		case "--seed":
+			if opts.Seed, err = strconv.ParseUint(value, 10, 64); err != nil {
+				return opts, errs.WrapValidationError(fmt.Errorf("invalid seed %q", value), "Please enter a seed of 0 or more")
+			}
+		case "--rounds":
+			opts.Rounds, err = strconv.Atoi(value)
+			if err != nil || opts.Rounds < 1 || opts.Rounds > usecase.MaxOptimizeRounds {
+				return opts, errs.WrapValidationError(fmt.Errorf("invalid rounds %q", value),
+					fmt.Sprintf("Please optimize with between 1 and %d rounds", usecase.MaxOptimizeRounds))
+			}
+		default:
+			return opts, errs.WrapValidationError(fmt.Errorf("unexpected argument %s", arg), optimizeUsage)
+		}
+	}
+	return opts, nil
+}
+
+func (h *HandlerImpl) HandleOptimize(scanner *bufio.Scanner, args []string) error {
+	opts, err := parseOptimizeArgs(args)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	result, err := h.QuestionUseCase.Optimize(opts)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newOptimizeDocument(result))
+		return nil
+	}
+
+	h.IO.PrintlnColored(ColorHeader, "───────────── SM-2 Settings vs. Review History ─────────────")
+	h.IO.Printf("Predicted %s of %s; %.0f%% were rated medium or easier.\n",
+		pluralize(result.Reviews, "review"), pluralize(result.Questions, "question"), 100*float64(result.Recalled)/float64(result.Reviews))
+	h.IO.Printf("Prediction error (log loss): %.4f now, %.4f with the proposed settings (%.1f%% better)\n",
+		result.CurrentError, result.ProposedError, 100*result.Improvement())
+	h.IO.PrintlnColored(ColorAnnotation, fmt.Sprintf("Seed %d, up to %d rounds; the same seed and history give the same proposal.", opts.Seed, opts.Rounds))
+	if h.cfg.Algorithm != config.AlgorithmSM2 {
+		h.IO.PrintlnColored(ColorWarning, fmt.Sprintf("The %s algorithm is selected; these settings apply once you switch back to sm2.", h.cfg.Algorithm))
+	}
+	h.IO.Printf("\n")
+
+	if len(result.Changes) == 0 {
+		h.IO.Println("The current settings already predict your reviews best; nothing to change.")
+		return nil
+	}
+
+	format := "%-24s %-10s %10s %10s\n"
+	h.IO.PrintfColored(ColorHeader, format, "Setting", "Level", "Current", "Proposed")
+	for _, change := range result.Changes {
+		h.IO.Printf(format, change.Setting, change.Level, strconv.FormatFloat(change.Current, 'f', -1, 64), strconv.FormatFloat(change.Proposed, 'f', -1, 64))
+	}
+	h.IO.Printf("\n")
+
+	confirm := strings.ToLower(h.IO.ReadLine(scanner, "Apply the proposed settings? [y/N]: "))
+	if confirm != "y" && confirm != "yes" {
+		h.IO.PrintCancel("Settings unchanged.")
+		return nil
+	}
+
+	if err := h.QuestionUseCase.ApplyOptimization(result); err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	h.IO.PrintSuccess(fmt.Sprintf("Applied %s. Run 'setting diff' to review them, or 'setting reset <setting_name>' to restore a default.",
+		pluralize(len(result.Changes), "change")))
+	h.IO.Printf("\n")
+	return nil
+}
+
+// familiarityLabels and importanceLabels name the levels as the prompts do
+var (
+	familiarityLabels = [core.MaxFamiliarity]string{"Struggled", "Clumsy", "Decent", "Smooth", "Fluent"}
+	importanceLabels  = [core.MaxImportance]string{"Low", "Medium", "High", "Critical"}
+)
+
+func (h *HandlerImpl) HandleStats() error {
+	stats, err := h.QuestionUseCase.GetStats()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newStatsDocument(stats))
+		return nil
+	}
+
+	h.IO.PrintlnColored(ColorHeader, "───────────── Statistics ─────────────")
+	h.IO.Printf("Questions: %d\n", stats.Questions)
+	h.IO.Printf("\n")
+
+	h.IO.PrintlnColored(ColorHeader, "-- Familiarity --")
+	var labels []string
+	for level, label := range familiarityLabels {
+		labels = append(labels, fmt.Sprintf("%d %s", level+1, label))
+	}
+	h.printCountChart(labels, stats.ByFamiliarity[:])
+
+	h.IO.PrintlnColored(ColorHeader, "-- Importance --")
+	labels = nil
+	for level, label := range importanceLabels {
+		labels = append(labels, fmt.Sprintf("%d %s", level+1, label))
+	}
+	h.printCountChart(labels, stats.ByImportance[:])
+
+	h.IO.PrintlnColored(ColorHeader, "-- Platform --")
+	labels = nil
+	var counts []int
+	for _, platform := range stats.ByPlatform {
+		labels = append(labels, platform.Platform.String())
+		counts = append(counts, platform.Questions)
+	}
+	h.printCountChart(labels, counts)
+
+	h.IO.PrintlnColored(ColorHeader, "-- Schedule --")
+	h.IO.Printf("Average ease factor: %.2f\n", stats.AverageEase)
+	h.IO.Printf("Average interval: %.1f days\n", stats.AverageInterval)
+	h.IO.Printf("\n")
+
+	h.IO.PrintlnColored(ColorHeader, "-- Reviews --")
+	h.IO.Printf("Reviews logged: %d\n", stats.Reviews)
+	h.IO.Printf("Last %d days: %.1f per day, %.1f per week\n", usecase.StatsWindowDays, stats.ReviewsPerDay(), stats.ReviewsPerWeek())
+	if stats.Repeated > 0 {
+		h.IO.Printf("Retention: %.0f%% (%d of %s rated 3 or higher)\n", stats.Retention()*100, stats.Retained, pluralize(stats.Repeated, "review"))
+	} else {
+		h.IO.Println("Retention: no reviews of questions already added yet")
+	}
+	h.IO.Printf("Streak: %s (longest: %s)\n", pluralize(stats.CurrentStreak, "day"), pluralize(stats.LongestStreak, "day"))
+	h.IO.Printf("\n")
+
+	h.IO.PrintlnColored(ColorHeader, "-- Added per Month --")
+	labels, counts = nil, nil
+	for _, month := range stats.AddedPerMonth {
+		labels = append(labels, month.Month.Format("Jan 2006"))
+		counts = append(counts, month.Added)
+	}
+	h.printCountChart(labels, counts)
+	return nil
+}
+
+// printCountChart draws a bar for each labeled count, followed by an empty line
+func (h *HandlerImpl) printCountChart(labels []string, counts []int) {
+	width, busiest := 0, 0
+	for i, label := range labels {
+		width = max(width, utf8.RuneCountInString(label))
+		busiest = max(busiest, counts[i])
+	}
+	for i, label := range labels {
+		h.IO.Printf("%-*s │%s %d\n", width, label, loadBar(counts[i], busiest), counts[i])
+	}
+	h.IO.Printf("\n")
+}
+
+func (h *HandlerImpl) HandleLeeches(args []string) error {
+	switch {
+	case len(args) == 0:
+		return h.listLeeches()
+	case strings.ToLower(args[0]) == "clear" && len(args) == 2:
+		return h.clearLeech(args[1])
+	}
+
+	err := errs.WrapValidationError(fmt.Errorf("invalid leeches arguments %v", args), "Usage: leeches [clear <id|url>]")
+	h.IO.PrintError(err)
+	return err
+}
+
+func (h *HandlerImpl) listLeeches() error {
+	leeches, err := h.QuestionUseCase.ListLeeches()
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+	if h.structured() {
+		h.IO.PrintDocument(newLeechesDocument(leeches))
+		return nil
+	}
+
+	if len(leeches) == 0 {
+		if h.cfg.LeechThreshold == 0 {
+			h.IO.Println("Leech detection is off. Run 'setting LeechThreshold <lapses>' to turn it on.")
+		} else {
+			h.IO.Printf("No leeches. A question becomes one after %s rated hard or very hard.\n", pluralize(h.cfg.LeechThreshold, "review"))
+		}
+		return nil
+	}
+
+	format := "%-6s %-7s %-6s %-8s %-10s %-12s %s\n"
+
+	h.IO.PrintlnColored(ColorHeader, "───────────── Leeches ─────────────")
+	h.IO.PrintfColored(ColorHeader, format, "ID", "Lapses", "Ease", "Reviews", "State", "Next Review", "URL")
+	for _, q := range leeches {
+		h.IO.Printf(format, strconv.Itoa(q.ID), strconv.Itoa(q.Lapses), strconv.FormatFloat(q.EaseFactor, 'f', 2, 64),
+			strconv.Itoa(q.ReviewCount), q.State.String(), q.NextReview.Local().Format(time.DateOnly), q.URL)
+	}
+	h.IO.Printf("\n")
+	h.IO.Println("Rework a leech from scratch, then run 'leeches clear <id|url>' to give it a fresh start.")
+	return nil
+}
+
+func (h *HandlerImpl) clearLeech(target string) error {
+	target, err := h.normalizeTarget(target)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	delta, err := h.QuestionUseCase.ClearLeech(target)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Question %d is no longer a leech and its lapses are reset.", delta.QuestionID))
+	h.IO.PrintlnColored(ColorAnnotation, "Run 'undo' to mark it as a leech again.")
+	h.IO.Printf("\n")
+	return nil
+}
+
+func (h *HandlerImpl) HandleSuspend(args []string) error {
+	return h.setState(args, core.StateSuspended, "suspend <id|url>",
+		"It is left out of reviews until you run 'unsuspend'.")
+}
+
+func (h *HandlerImpl) HandleUnsuspend(args []string) error {
+	return h.setState(args, core.StateActive, "unsuspend <id|url>",
+		"It is back in the reviews on its next review date.")
+}
+
+func (h *HandlerImpl) HandleArchive(args []string) error {
+	return h.setState(args, core.StateArchived, "archive <id|url>",
+		"It is left out of reviews for good; run 'unsuspend' to bring it back.")
+}
+
+// setState moves the question named by the only argument to a state that lasts until changed
+func (h *HandlerImpl) setState(args []string, state core.QuestionState, usage, annotation string) error {
+	if len(args) != 1 {
+		err := errs.WrapValidationError(fmt.Errorf("invalid state arguments %v", args), "Usage: "+usage)
+		h.IO.PrintError(err)
+		return err
+	}
+	target, err := h.normalizeTarget(args[0])
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	delta, err := h.QuestionUseCase.SetQuestionState(target, state)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Question %d is now %s. %s", delta.QuestionID, state, annotation))
+	h.IO.PrintlnColored(ColorAnnotation, fmt.Sprintf("Run 'undo' to make it %s again.", delta.OldState.State))
+	h.IO.Printf("\n")
+	return nil
+}
+
+// HandleBury leaves a question out of the due lists for a number of days, 1 by default, or until a date
+func (h *HandlerImpl) HandleBury(args []string) error {
+	usageErr := errs.WrapValidationError(fmt.Errorf("invalid bury arguments %v", args), "Usage: bury <id|url> [days|YYYY-MM-DD]")
+	if len(args) < 1 || len(args) > 2 {
+		h.IO.PrintError(usageErr)
+		return usageErr
+	}
+	target, err := h.normalizeTarget(args[0])
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	days := 1
+	var until time.Time
+	if len(args) == 2 {
+		if n, err := strconv.Atoi(args[1]); err == nil {
+			days = n
+		} else if date, err := time.Parse(time.DateOnly, args[1]); err == nil {
+			until = date
+		} else {
+			h.IO.PrintError(usageErr)
+			return usageErr
+		}
+	}
+
+	delta, err := h.QuestionUseCase.BuryQuestion(target, days, until)
+	if err != nil {
+		h.IO.PrintError(err)
+		return err
+	}
+
+	h.IO.PrintSuccess(fmt.Sprintf("Question %d is buried until %s. It comes due again on %s.", delta.QuestionID,
+		delta.NewState.BuriedUntil.Format(time.DateOnly), delta.NewState.ReviewDate().Format(time.DateOnly)))
+	h.IO.PrintlnColored(ColorAnnotation, "Run 'undo' to dig it up.")
+	h.IO.Printf("\n")
+	return nil
+}
+
+// normalizeTarget keeps an ID as given and normalizes a question URL
+func (h *HandlerImpl) normalizeTarget(target string) (string, error) {
+	if _, err := strconv.Atoi(target); err == nil {
+		return target, nil
+	}
+	parsed, err := urlparser.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	return parsed.NormalizedURL, nil
 }
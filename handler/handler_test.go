@@ -5,12 +5,14 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/eannchen/leetsolv/config"
 	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/backup"
 	"github.com/eannchen/leetsolv/internal/errs"
 	"github.com/eannchen/leetsolv/internal/logger"
 	"github.com/eannchen/leetsolv/internal/urlparser"
@@ -28,6 +30,8 @@ type MockIOHandler struct {
 	writeCalls []string
 	lines      []string
 	lineIndex  int
+	format     OutputFormat
+	documents  []Document
 }
 
 func NewMockIOHandler(input string) *MockIOHandler {
@@ -108,6 +112,18 @@ func (m *MockIOHandler) FormatTimeAgo(t time.Time) string {
 	return "just now"
 }
 
+func (m *MockIOHandler) Format() OutputFormat {
+	if m.format == "" {
+		return FormatText
+	}
+	return m.format
+}
+
+func (m *MockIOHandler) PrintDocument(doc Document) {
+	m.writeCalls = append(m.writeCalls, "PrintDocument")
+	m.documents = append(m.documents, doc)
+}
+
 // MockQuestionUseCase implements QuestionUseCase for testing
 type MockQuestionUseCase struct {
 	questions     []core.Question
@@ -117,6 +133,44 @@ type MockQuestionUseCase struct {
 	deleted       *core.Question
 	summary       usecase.QuestionsSummary
 	searchResults []core.Question
+	dueQuestions  []core.Question
+	upsertCalls   []string // URLs passed to UpsertQuestion, in call order
+	upsertTags    []string // Tags passed to the last UpsertQuestion call
+	upsertNote    string
+	upsertImp     core.Importance
+	tags          []usecase.TagSummary
+	imported      []core.Question // Questions passed to the last ImportQuestions call
+	importPolicy  usecase.ImportPolicy
+	checkResult   *usecase.CheckResult
+	checkRepair   bool // Repair flag passed to the last CheckData call
+	backups       []backup.Snapshot
+	restoredID    string       // ID passed to the last RestoreBackup call
+	history       []core.Delta // Returned by GetHistory when set
+	redoHistory   []core.Delta
+	undoCount     int // Count passed to the last Undo call
+	redoCount     int // Count passed to the last Redo call
+	simulation    *usecase.SimulationResult
+	simulateOpts  usecase.SimulationOptions // Options passed to the last Simulate call
+	forecast      *usecase.Forecast
+	forecastDays  int                // Days passed to the last ForecastDue call
+	forecastBy    *core.SearchFilter // Filter passed to the last ForecastDue call
+	optimization  *usecase.OptimizeResult
+	optimizeOpts  usecase.OptimizeOptions // Options passed to the last Optimize call
+	applied       *usecase.OptimizeResult // Result passed to the last ApplyOptimization call
+	stats         *usecase.Stats
+	leeches       []core.Question
+	cleared       string // Target passed to the last ClearLeech call
+	upsertSolve   core.Solve
+	stateTarget   string // Target passed to the last SetQuestionState or BuryQuestion call
+	state         core.QuestionState
+	buryDays      int
+	buryUntil     time.Time
+	timerTarget   string // Target passed to the last StartTimer call
+	stopped       *usecase.StoppedTimer
+	pause         *core.Pause
+	settingName   string // Setting passed to the last UpdateSetting call
+	settingValue  any
+	resume        *usecase.ResumeResult
 	pagination    map[string]interface{} // For testing pagination edge cases
 }
 
@@ -141,6 +195,20 @@ func (m *MockQuestionUseCase) ListQuestionsSummary() (usecase.QuestionsSummary,
 	return m.summary, nil
 }
 
+func (m *MockQuestionUseCase) ListDueQuestions() ([]core.Question, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.dueQuestions, nil
+}
+
+func (m *MockQuestionUseCase) ListTags() ([]usecase.TagSummary, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.tags, nil
+}
+
 func (m *MockQuestionUseCase) ListQuestionsOrderByDesc() ([]core.Question, error) {
 	if m.shouldError {
 		return nil, m.errorToReturn
@@ -193,10 +261,14 @@ func (m *MockQuestionUseCase) SearchQuestions(queries []string, filter *core.Sea
 	return m.searchResults, nil
 }
 
-func (m *MockQuestionUseCase) UpsertQuestion(url, note string, familiarity core.Familiarity, importance core.Importance, memory core.MemoryUse) (*core.Delta, error) {
+func (m *MockQuestionUseCase) UpsertQuestion(url, note string, tags []string, familiarity core.Familiarity, importance core.Importance, memory core.MemoryUse, solve core.Solve) (*core.Delta, error) {
 	if m.shouldError {
 		return nil, m.errorToReturn
 	}
+	m.upsertCalls = append(m.upsertCalls, url)
+	m.upsertTags = tags
+	m.upsertNote, m.upsertImp = note, importance
+	m.upsertSolve = solve
 	return m.upserted, nil
 }
 
@@ -207,17 +279,142 @@ func (m *MockQuestionUseCase) DeleteQuestion(target string) (*core.Question, err
 	return m.deleted, nil
 }
 
-func (m *MockQuestionUseCase) Undo() error {
+func (m *MockQuestionUseCase) Undo(count int) ([]core.Delta, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.undoCount = count
+	return make([]core.Delta, count), nil
+}
+
+func (m *MockQuestionUseCase) Redo(count int) ([]core.Delta, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.redoCount = count
+	return make([]core.Delta, count), nil
+}
+
+func (m *MockQuestionUseCase) Simulate(opts usecase.SimulationOptions) (*usecase.SimulationResult, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.simulateOpts = opts
+	return m.simulation, nil
+}
+
+func (m *MockQuestionUseCase) ForecastDue(days int, filter *core.SearchFilter) (*usecase.Forecast, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.forecastDays = days
+	m.forecastBy = filter
+	return m.forecast, nil
+}
+
+func (m *MockQuestionUseCase) Optimize(opts usecase.OptimizeOptions) (*usecase.OptimizeResult, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.optimizeOpts = opts
+	return m.optimization, nil
+}
+
+func (m *MockQuestionUseCase) ApplyOptimization(result *usecase.OptimizeResult) error {
 	if m.shouldError {
 		return m.errorToReturn
 	}
+	m.applied = result
 	return nil
 }
 
+func (m *MockQuestionUseCase) GetStats() (*usecase.Stats, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.stats, nil
+}
+
+func (m *MockQuestionUseCase) ListLeeches() ([]core.Question, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.leeches, nil
+}
+
+func (m *MockQuestionUseCase) ClearLeech(target string) (*core.Delta, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.cleared = target
+	return m.upserted, nil
+}
+
+func (m *MockQuestionUseCase) SetQuestionState(target string, state core.QuestionState) (*core.Delta, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.stateTarget, m.state = target, state
+	return &core.Delta{Action: core.ActionUpdate, QuestionID: 1, OldState: &core.Question{ID: 1}, NewState: &core.Question{ID: 1, State: state}}, nil
+}
+
+func (m *MockQuestionUseCase) BuryQuestion(target string, days int, until time.Time) (*core.Delta, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.stateTarget, m.state, m.buryDays, m.buryUntil = target, core.StateBuried, days, until
+	buried := &core.Question{ID: 1, State: core.StateBuried, BuriedUntil: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)}
+	return &core.Delta{Action: core.ActionUpdate, QuestionID: 1, OldState: &core.Question{ID: 1}, NewState: buried}, nil
+}
+
+func (m *MockQuestionUseCase) StartTimer(target string) (*core.Timer, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.timerTarget = target
+	return &core.Timer{URL: target, Start: testTime}, nil
+}
+
+func (m *MockQuestionUseCase) StopTimer() (*usecase.StoppedTimer, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	if m.stopped == nil {
+		return nil, errs.ErrNoTimer
+	}
+	stopped := m.stopped
+	m.stopped = nil
+	return stopped, nil
+}
+
+func (m *MockQuestionUseCase) PauseReviews() (*core.Pause, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.pause, nil
+}
+
+func (m *MockQuestionUseCase) ResumeReviews() (*usecase.ResumeResult, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.resume, nil
+}
+
+func (m *MockQuestionUseCase) GetRedoHistory() ([]core.Delta, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.redoHistory, nil
+}
+
 func (m *MockQuestionUseCase) GetHistory() ([]core.Delta, error) {
 	if m.shouldError {
 		return nil, m.errorToReturn
 	}
+	if m.history != nil {
+		return m.history, nil
+	}
 	// Return a sample delta for testing
 	return []core.Delta{
 		{
@@ -232,6 +429,27 @@ func (m *MockQuestionUseCase) GetHistory() ([]core.Delta, error) {
 	}, nil
 }
 
+func (m *MockQuestionUseCase) ExportQuestions() ([]core.Question, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.questions, nil
+}
+
+func (m *MockQuestionUseCase) ImportQuestions(questions []core.Question, policy usecase.ImportPolicy, dryRun bool) (*usecase.ImportResult, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.imported = questions
+	m.importPolicy = policy
+	result := &usecase.ImportResult{DryRun: dryRun}
+	for _, q := range questions {
+		result.Items = append(result.Items, usecase.ImportItem{URL: q.URL, Outcome: usecase.ImportAdded})
+		result.Added++
+	}
+	return result, nil
+}
+
 func (m *MockQuestionUseCase) GetSettings() error {
 	if m.shouldError {
 		return m.errorToReturn
@@ -243,6 +461,16 @@ func (m *MockQuestionUseCase) UpdateSetting(settingName string, value interface{
 	if m.shouldError {
 		return m.errorToReturn
 	}
+	m.settingName = settingName
+	m.settingValue = value
+	return nil
+}
+
+func (m *MockQuestionUseCase) ResetSetting(settingName string) error {
+	if m.shouldError {
+		return m.errorToReturn
+	}
+	m.settingName = settingName
 	return nil
 }
 
@@ -253,6 +481,46 @@ func (m *MockQuestionUseCase) MigrateToUTC() (int, int, error) {
 	return len(m.questions), 0, nil
 }
 
+func (m *MockQuestionUseCase) CheckData(repair bool) (*usecase.CheckResult, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	m.checkRepair = repair
+	if m.checkResult == nil {
+		return &usecase.CheckResult{}, nil
+	}
+	return m.checkResult, nil
+}
+
+func (m *MockQuestionUseCase) CreateBackup(reason string) (*backup.Snapshot, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	snapshot := backup.Snapshot{ID: "20240615-120000", CreatedAt: testTime, Reason: reason}
+	m.backups = append([]backup.Snapshot{snapshot}, m.backups...)
+	return &snapshot, nil
+}
+
+func (m *MockQuestionUseCase) ListBackups() ([]backup.Snapshot, error) {
+	if m.shouldError {
+		return nil, m.errorToReturn
+	}
+	return m.backups, nil
+}
+
+func (m *MockQuestionUseCase) RestoreBackup(id string) (*backup.Snapshot, *backup.Snapshot, error) {
+	if m.shouldError {
+		return nil, nil, m.errorToReturn
+	}
+	m.restoredID = id
+	for i := range m.backups {
+		if m.backups[i].ID == id {
+			return &m.backups[i], nil, nil
+		}
+	}
+	return nil, nil, errs.ErrBackupNotFound
+}
+
 func (m *MockQuestionUseCase) ResetData() (int, int, error) {
 	if m.shouldError {
 		return 0, 0, m.errorToReturn
@@ -438,7 +706,9 @@ func TestHandler_HandleGet_Error(t *testing.T) {
 	mockUseCase.errorToReturn = errs.ErrQuestionNotFound
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	handler.HandleGet(scanner, "999")
+	if err := handler.HandleGet(scanner, "999"); err != errs.ErrQuestionNotFound {
+		t.Errorf("Expected ErrQuestionNotFound to be returned, got %v", err)
+	}
 
 	// Verify that error was printed
 	found := false
@@ -511,6 +781,17 @@ func TestHandler_HandleStatus_Success(t *testing.T) {
 	}
 }
 
+func TestHandler_HandleStatus_Paused(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.summary = usecase.QuestionsSummary{Total: 1, PausedSince: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)}
+
+	handler.HandleStatus()
+
+	if output := mockIO.output.String(); !strings.Contains(output, "Reviews paused since 2024-06-10") {
+		t.Errorf("Expected the pause in the status, got %q", output)
+	}
+}
+
 func TestHandler_HandleStatus_Error(t *testing.T) {
 	handler, mockIO, mockUseCase := setupTestHandler(t)
 
@@ -533,10 +814,52 @@ func TestHandler_HandleStatus_Error(t *testing.T) {
 	}
 }
 
+func TestHandler_StructuredOutput_PrintsDocuments(t *testing.T) {
+	t.Run("status", func(t *testing.T) {
+		handler, mockIO, mockUseCase := setupTestHandler(t)
+		mockIO.format = FormatJSON
+		mockUseCase.summary = usecase.QuestionsSummary{
+			TopDue:   []core.Question{{ID: 1, URL: "https://leetcode.com/problems/test1"}},
+			TotalDue: 1,
+			Total:    1,
+		}
+
+		handler.HandleStatus()
+
+		if len(mockIO.documents) != 1 {
+			t.Fatalf("Expected one document, got %d", len(mockIO.documents))
+		}
+		doc, ok := mockIO.documents[0].(StatusDocument)
+		if !ok || doc.TotalDue != 1 || len(doc.Due) != 1 {
+			t.Errorf("Expected status document with one due question, got %+v", mockIO.documents[0])
+		}
+	})
+
+	t.Run("empty list is a document, not an error", func(t *testing.T) {
+		handler, mockIO, mockUseCase := setupTestHandler(t)
+		mockIO.format = FormatTSV
+		mockUseCase.questions = []core.Question{}
+
+		handler.HandleList(bufio.NewScanner(strings.NewReader("")))
+
+		for _, call := range mockIO.writeCalls {
+			if call == "PrintError" {
+				t.Error("Expected no error for an empty list in structured mode")
+			}
+		}
+		if len(mockIO.documents) != 1 {
+			t.Fatalf("Expected one document, got %d", len(mockIO.documents))
+		}
+		if doc, ok := mockIO.documents[0].(QuestionListDocument); !ok || doc.Total != 0 {
+			t.Errorf("Expected empty question list document, got %+v", mockIO.documents[0])
+		}
+	})
+}
+
 func TestHandler_HandleUpsert_Success(t *testing.T) {
 	// Create mock IO with proper input
-	// Input: URL, note, familiarity (3), memory (1), importance (2)
-	mockIO := NewMockIOHandler("https://leetcode.com/problems/two-sum\nTest question\n3\n1\n2\n")
+	// Input: URL, note, tags, familiarity (3), memory (1), importance (2), minutes (25), submissions (2)
+	mockIO := NewMockIOHandler("https://leetcode.com/problems/two-sum\nTest question\nArray, #Hash-Map\n3\n1\n2\n25\n2\n")
 	mockUseCase := NewMockQuestionUseCase()
 	_, cfg := config.MockEnv(t)
 	logger.InitNop()
@@ -567,7 +890,7 @@ func TestHandler_HandleUpsert_Success(t *testing.T) {
 
 	// Simulate user input: URL, note, familiarity (3), importance (2)
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	handler.HandleUpsert(scanner, "")
+	handler.HandleUpsert(scanner, nil)
 
 	// Verify that success message was printed
 	found := false
@@ -601,6 +924,17 @@ func TestHandler_HandleUpsert_Success(t *testing.T) {
 	if !strings.Contains(output, "Using normalized URL: https://leetcode.com/problems/two-sum/") {
 		t.Error("Expected normalized URL to be printed")
 	}
+
+	// Verify tags were parsed and normalized
+	if len(mockUseCase.upsertTags) != 2 || mockUseCase.upsertTags[0] != "array" || mockUseCase.upsertTags[1] != "hash-map" {
+		t.Errorf("Expected tags [array hash-map], got %v", mockUseCase.upsertTags)
+	}
+
+	// Verify the solve was read
+	solve := mockUseCase.upsertSolve
+	if solve.Minutes != 25 || solve.Attempts != 2 || solve.FirstPass != nil {
+		t.Errorf("Expected a 25 minute solve in 2 submissions, got %+v", solve)
+	}
 }
 
 func TestHandler_HandleUpsert_InvalidURL(t *testing.T) {
@@ -609,7 +943,7 @@ func TestHandler_HandleUpsert_InvalidURL(t *testing.T) {
 	// Simulate invalid URL input
 	input := "invalid-url\n"
 	scanner := bufio.NewScanner(strings.NewReader(input))
-	handler.HandleUpsert(scanner, "")
+	handler.HandleUpsert(scanner, nil)
 
 	// Verify that error was printed
 	found := false
@@ -630,7 +964,7 @@ func TestHandler_HandleUpsert_InvalidFamiliarity(t *testing.T) {
 	// Simulate valid URL but invalid familiarity
 	input := "https://leetcode.com/problems/test\nTest question\n6\n"
 	scanner := bufio.NewScanner(strings.NewReader(input))
-	handler.HandleUpsert(scanner, "")
+	handler.HandleUpsert(scanner, nil)
 
 	// Verify that error was printed
 	found := false
@@ -651,7 +985,7 @@ func TestHandler_HandleUpsert_InvalidImportance(t *testing.T) {
 	// Simulate valid URL and familiarity but invalid importance
 	input := "https://leetcode.com/problems/test\nTest question\n3\n5\n"
 	scanner := bufio.NewScanner(strings.NewReader(input))
-	handler.HandleUpsert(scanner, "")
+	handler.HandleUpsert(scanner, nil)
 
 	// Verify that error was printed
 	found := false
@@ -677,7 +1011,7 @@ func TestHandler_HandleUpsert_UseCaseError(t *testing.T) {
 	// Input: URL, note, familiarity (3), memory (1), importance (2)
 	input := "https://leetcode.com/problems/test\nTest question\n3\n1\n2\n"
 	scanner := bufio.NewScanner(strings.NewReader(input))
-	handler.HandleUpsert(scanner, "")
+	handler.HandleUpsert(scanner, nil)
 
 	// Verify that error was printed
 	found := false
@@ -692,10 +1026,178 @@ func TestHandler_HandleUpsert_UseCaseError(t *testing.T) {
 	}
 }
 
+func TestHandler_HandleUpsert_WithFlags(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.upserted = &core.Delta{
+		Action:   core.ActionAdd,
+		NewState: &core.Question{ID: 1, URL: "https://leetcode.com/problems/two-sum/"},
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	err := handler.HandleUpsert(scanner, []string{
+		"https://leetcode.com/problems/two-sum",
+		"--note=hash map lookup",
+		"--tags=array,hash-map",
+		"--familiarity=3",
+		"--memory=1",
+		"--importance=2",
+		"--minutes=40",
+		"--first-pass=no",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(mockIO.readCalls) != 0 {
+		t.Errorf("Expected no prompts when all values are given, got %v", mockIO.readCalls)
+	}
+	if solve := mockUseCase.upsertSolve; solve.Minutes != 40 || solve.FirstPass == nil || *solve.FirstPass || solve.Attempts != 0 {
+		t.Errorf("Expected a 40 minute solve that failed the first submission, got %+v", solve)
+	}
+	if len(mockUseCase.upsertCalls) != 1 || mockUseCase.upsertCalls[0] != "https://leetcode.com/problems/two-sum/" {
+		t.Errorf("Expected one upsert of the normalized URL, got %v", mockUseCase.upsertCalls)
+	}
+	if len(mockUseCase.upsertTags) != 2 {
+		t.Errorf("Expected 2 tags, got %v", mockUseCase.upsertTags)
+	}
+}
+
+func TestHandler_HandleUpsert_PromptsOnlyForMissingFlags(t *testing.T) {
+	// Input: memory use (2) is the only prompt
+	mockIO := NewMockIOHandler("2\n")
+	mockUseCase := NewMockQuestionUseCase()
+	_, cfg := config.MockEnv(t)
+	logger.InitNop()
+	handler := NewHandler(cfg, mockUseCase, mockIO, "test-version")
+	mockUseCase.upserted = &core.Delta{
+		Action:   core.ActionAdd,
+		NewState: &core.Question{ID: 1, URL: "https://leetcode.com/problems/two-sum/"},
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	err := handler.HandleUpsert(scanner, []string{
+		"https://leetcode.com/problems/two-sum",
+		"--note=",
+		"--tags=",
+		"--familiarity=4",
+		"--importance=1",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(mockIO.readCalls) != 1 {
+		t.Errorf("Expected only the memory use prompt, got %v", mockIO.readCalls)
+	}
+}
+
+func TestHandler_HandleUpsert_KeepsExistingTags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tags     string
+		expected []string
+	}{
+		{"empty input keeps the tags", "", []string{"array", "hash-map"}},
+		{"dash removes the tags", "-", nil},
+		{"new tags replace them", "graph", []string{"graph"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Input: tags, familiarity (4) and memory use (2); the solve prompts are skipped
+			mockIO := NewMockIOHandler(tc.tags + "\n4\n2\n")
+			mockUseCase := NewMockQuestionUseCase()
+			_, cfg := config.MockEnv(t)
+			logger.InitNop()
+			handler := NewHandler(cfg, mockUseCase, mockIO, "test-version")
+			mockUseCase.questions = []core.Question{{ID: 1, URL: "https://leetcode.com/problems/two-sum/", Tags: []string{"array", "hash-map"}}}
+			mockUseCase.upserted = &core.Delta{
+				Action:   core.ActionUpdate,
+				NewState: &core.Question{ID: 1, URL: "https://leetcode.com/problems/two-sum/"},
+			}
+
+			scanner := bufio.NewScanner(strings.NewReader(""))
+			err := handler.HandleUpsert(scanner, []string{"https://leetcode.com/problems/two-sum", "--note=", "--importance=1"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !slices.Equal(mockUseCase.upsertTags, tc.expected) {
+				t.Errorf("Expected tags %v, got %v", tc.expected, mockUseCase.upsertTags)
+			}
+		})
+	}
+}
+
+func TestHandler_HandleUpsert_FlagsKeepExistingTags(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.questions = []core.Question{{ID: 1, URL: "https://leetcode.com/problems/two-sum/", Tags: []string{"array", "hash-map"}}}
+	mockUseCase.upserted = &core.Delta{
+		Action:   core.ActionUpdate,
+		NewState: &core.Question{ID: 1, URL: "https://leetcode.com/problems/two-sum/"},
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	err := handler.HandleUpsert(scanner, []string{
+		"https://leetcode.com/problems/two-sum",
+		"--note=x",
+		"--familiarity=3",
+		"--memory=1",
+		"--importance=2",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(mockIO.readCalls) != 0 {
+		t.Errorf("Expected no prompts for a fully flagged add, got %v", mockIO.readCalls)
+	}
+	if !slices.Equal(mockUseCase.upsertTags, []string{"array", "hash-map"}) {
+		t.Errorf("Expected the existing tags to be kept, got %v", mockUseCase.upsertTags)
+	}
+}
+
+func TestHandler_HandleUpsert_InvalidFlags(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{"familiarity out of range", []string{"https://leetcode.com/problems/two-sum", "--familiarity=6"}},
+		{"memory out of range", []string{"https://leetcode.com/problems/two-sum", "--memory=0"}},
+		{"importance not a number", []string{"https://leetcode.com/problems/two-sum", "--importance=high"}},
+		{"invalid tag", []string{"https://leetcode.com/problems/two-sum", "--tags=a/b"}},
+		{"unknown flag", []string{"https://leetcode.com/problems/two-sum", "--priority=1"}},
+		{"minutes not positive", []string{"https://leetcode.com/problems/two-sum", "--minutes=0"}},
+		{"attempts not a number", []string{"https://leetcode.com/problems/two-sum", "--attempts=two"}},
+		{"first pass not yes or no", []string{"https://leetcode.com/problems/two-sum", "--first-pass=maybe"}},
+		{"first pass with retries", []string{"https://leetcode.com/problems/two-sum", "--first-pass=yes", "--attempts=2"}},
+		{"extra argument", []string{"https://leetcode.com/problems/two-sum", "extra"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, mockIO, mockUseCase := setupTestHandler(t)
+
+			scanner := bufio.NewScanner(strings.NewReader(""))
+			err := handler.HandleUpsert(scanner, tc.args)
+
+			var codedErr *errs.CodedError
+			if !errors.As(err, &codedErr) || codedErr.Kind != errs.ValidationErrorKind {
+				t.Errorf("Expected a validation error, got %v", err)
+			}
+			if len(mockIO.readCalls) != 0 {
+				t.Errorf("Expected validation to fail before prompting, got %v", mockIO.readCalls)
+			}
+			if len(mockUseCase.upsertCalls) != 0 {
+				t.Error("Expected no upsert on validation failure")
+			}
+		})
+	}
+}
+
 func TestHandler_HandleUpsert_NoMemoryPromptForVeryHardFamiliarity(t *testing.T) {
 	// Create mock IO with input for familiarity level 1 (VeryHard)
-	// Input: URL, note, familiarity (1), importance (2) - no memory input needed
-	mockIO := NewMockIOHandler("https://leetcode.com/problems/two-sum\nTest question\n1\n2\n")
+	// Input: URL, note, tags (none), familiarity (1), importance (2) - no memory input needed
+	mockIO := NewMockIOHandler("https://leetcode.com/problems/two-sum\nTest question\n\n1\n2\n")
 	mockUseCase := NewMockQuestionUseCase()
 	_, cfg := config.MockEnv(t)
 	logger.InitNop()
@@ -718,7 +1220,7 @@ func TestHandler_HandleUpsert_NoMemoryPromptForVeryHardFamiliarity(t *testing.T)
 	}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	handler.HandleUpsert(scanner, "")
+	handler.HandleUpsert(scanner, nil)
 
 	// Verify that success message was printed
 	found := false
@@ -744,8 +1246,8 @@ func TestHandler_HandleUpsert_NoMemoryPromptForVeryHardFamiliarity(t *testing.T)
 
 func TestHandler_HandleUpsert_NoMemoryPromptForHardFamiliarity(t *testing.T) {
 	// Create mock IO with input for familiarity level 2 (Hard)
-	// Input: URL, note, familiarity (2), importance (2) - no memory input needed
-	mockIO := NewMockIOHandler("https://leetcode.com/problems/two-sum\nTest question\n2\n2\n")
+	// Input: URL, note, tags (none), familiarity (2), importance (2) - no memory input needed
+	mockIO := NewMockIOHandler("https://leetcode.com/problems/two-sum\nTest question\n\n2\n2\n")
 	mockUseCase := NewMockQuestionUseCase()
 	_, cfg := config.MockEnv(t)
 	logger.InitNop()
@@ -768,7 +1270,7 @@ func TestHandler_HandleUpsert_NoMemoryPromptForHardFamiliarity(t *testing.T) {
 	}
 
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	handler.HandleUpsert(scanner, "")
+	handler.HandleUpsert(scanner, nil)
 
 	// Verify that success message was printed
 	found := false
@@ -792,32 +1294,124 @@ func TestHandler_HandleUpsert_NoMemoryPromptForHardFamiliarity(t *testing.T) {
 	}
 }
 
-func TestHandler_HandleDelete_Success(t *testing.T) {
-	// Create mock IO with proper input
-	mockIO := NewMockIOHandler("y\n")
+func TestHandler_HandleReview_NoDueQuestions(t *testing.T) {
+	handler, mockIO, _ := setupTestHandler(t)
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	handler.HandleReview(scanner)
+
+	if !strings.Contains(mockIO.output.String(), "No questions are due") {
+		t.Error("Expected message about no due questions")
+	}
+	if len(mockIO.readCalls) != 0 {
+		t.Errorf("Expected no prompts, got %d", len(mockIO.readCalls))
+	}
+}
+
+func TestHandler_HandleReview_Session(t *testing.T) {
+	// Input: skip first, grade second as Hard (no memory prompt), bury third,
+	// then grade the requeued first as Medium with Partial memory use
+	mockIO := NewMockIOHandler("s\n2\nb\n3\n2\n")
 	mockUseCase := NewMockQuestionUseCase()
 	_, cfg := config.MockEnv(t)
 	logger.InitNop()
 	handler := NewHandler(cfg, mockUseCase, mockIO, "test-version")
 
-	// Set up successful deletion
-	deletedQuestion := &core.Question{
-		ID:   1,
-		URL:  "https://leetcode.com/problems/test",
-		Note: "Test question",
+	mockUseCase.dueQuestions = []core.Question{
+		{ID: 1, URL: "https://leetcode.com/problems/first/", Note: "keep me"},
+		{ID: 2, URL: "https://leetcode.com/problems/second/"},
+		{ID: 3, URL: "https://leetcode.com/problems/third/"},
+	}
+	mockUseCase.upserted = &core.Delta{
+		Action:   core.ActionUpdate,
+		NewState: &core.Question{NextReview: testTime},
 	}
-	mockUseCase.deleted = deletedQuestion
 
-	// Simulate user confirmation
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	handler.HandleDelete(scanner, "1")
+	handler.HandleReview(scanner)
 
-	// Verify that deletion message was printed
-	found := false
-	for _, call := range mockIO.writeCalls {
-		if call == "PrintSuccess" {
-			found = true
-			break
+	expectedCalls := []string{
+		"https://leetcode.com/problems/second/",
+		"https://leetcode.com/problems/first/",
+	}
+	if len(mockUseCase.upsertCalls) != len(expectedCalls) {
+		t.Fatalf("Expected %d upserts, got %d: %v", len(expectedCalls), len(mockUseCase.upsertCalls), mockUseCase.upsertCalls)
+	}
+	for i, url := range expectedCalls {
+		if mockUseCase.upsertCalls[i] != url {
+			t.Errorf("Expected upsert %d to be %s, got %s", i, url, mockUseCase.upsertCalls[i])
+		}
+	}
+
+	output := mockIO.output.String()
+	if !strings.Contains(output, "Reviewed: 2") {
+		t.Error("Expected summary to report 2 reviewed questions")
+	}
+	if !strings.Contains(output, "Buried: 1") {
+		t.Error("Expected summary to report 1 buried question")
+	}
+	if mockUseCase.stateTarget != "3" || mockUseCase.buryDays != 1 || !strings.Contains(output, "Buried until 2024-06-20") {
+		t.Errorf("Expected the third question buried for a day, got target %q for %d days", mockUseCase.stateTarget, mockUseCase.buryDays)
+	}
+	if !strings.Contains(output, "Still due: 0") {
+		t.Error("Expected summary to report no remaining questions")
+	}
+}
+
+func TestHandler_HandleReview_QuitAndInvalidInput(t *testing.T) {
+	// Input: invalid familiarity is re-asked, then quit
+	mockIO := NewMockIOHandler("9\nq\n")
+	mockUseCase := NewMockQuestionUseCase()
+	_, cfg := config.MockEnv(t)
+	logger.InitNop()
+	handler := NewHandler(cfg, mockUseCase, mockIO, "test-version")
+
+	mockUseCase.dueQuestions = []core.Question{
+		{ID: 1, URL: "https://leetcode.com/problems/first/"},
+		{ID: 2, URL: "https://leetcode.com/problems/second/"},
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	handler.HandleReview(scanner)
+
+	if len(mockUseCase.upsertCalls) != 0 {
+		t.Errorf("Expected no upserts, got %v", mockUseCase.upsertCalls)
+	}
+	output := mockIO.output.String()
+	if !strings.Contains(output, errs.ErrInvalidFamiliarityLevel.Error()) {
+		t.Error("Expected invalid familiarity error to be printed")
+	}
+	if !strings.Contains(output, "Still due: 2") {
+		t.Error("Expected summary to report 2 remaining questions")
+	}
+}
+
+func TestHandler_HandleDelete_Success(t *testing.T) {
+	// Create mock IO with proper input
+	mockIO := NewMockIOHandler("y\n")
+	mockUseCase := NewMockQuestionUseCase()
+	_, cfg := config.MockEnv(t)
+	logger.InitNop()
+	handler := NewHandler(cfg, mockUseCase, mockIO, "test-version")
+
+	// Set up successful deletion
+	deletedQuestion := &core.Question{
+		ID:   1,
+		URL:  "https://leetcode.com/problems/test",
+		Note: "Test question",
+	}
+	mockUseCase.deleted = deletedQuestion
+
+	// Simulate user confirmation
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	handler.HandleDelete(scanner, "1")
+
+	// Verify that deletion message was printed
+	found := false
+	for _, call := range mockIO.writeCalls {
+		if call == "PrintSuccess" {
+			found = true
+			break
 		}
 	}
 	if !found {
@@ -831,7 +1425,9 @@ func TestHandler_HandleDelete_Cancelled(t *testing.T) {
 	// Simulate user cancellation
 	input := "n\n"
 	scanner := bufio.NewScanner(strings.NewReader(input))
-	handler.HandleDelete(scanner, "1")
+	if err := handler.HandleDelete(scanner, "1"); err != nil {
+		t.Errorf("Expected no error for a cancelled delete, got %v", err)
+	}
 
 	// Verify that cancellation message was printed
 	found := false
@@ -879,7 +1475,9 @@ func TestHandler_HandleDelete_UseCaseError(t *testing.T) {
 
 	// Simulate user confirmation
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	handler.HandleDelete(scanner, "999")
+	if err := handler.HandleDelete(scanner, "999"); err != errs.ErrQuestionNotFound {
+		t.Errorf("Expected ErrQuestionNotFound to be returned, got %v", err)
+	}
 
 	// Verify that error was printed
 	found := false
@@ -904,7 +1502,7 @@ func TestHandler_HandleUndo_Success(t *testing.T) {
 
 	// Simulate user confirmation
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	handler.HandleUndo(scanner)
+	handler.HandleUndo(scanner, nil)
 
 	// Verify that success message was printed
 	found := false
@@ -933,7 +1531,7 @@ func TestHandler_HandleUndo_Error(t *testing.T) {
 
 	// Simulate user confirmation
 	scanner := bufio.NewScanner(strings.NewReader(""))
-	handler.HandleUndo(scanner)
+	handler.HandleUndo(scanner, nil)
 
 	// Verify that error was printed
 	found := false
@@ -954,7 +1552,7 @@ func TestHandler_HandleUndo_Cancelled(t *testing.T) {
 	// Simulate user cancellation
 	input := "n\n"
 	scanner := bufio.NewScanner(strings.NewReader(input))
-	handler.HandleUndo(scanner)
+	handler.HandleUndo(scanner, nil)
 
 	// Verify that cancellation message was printed
 	found := false
@@ -969,6 +1567,387 @@ func TestHandler_HandleUndo_Cancelled(t *testing.T) {
 	}
 }
 
+// testHistory is a history of three actions on one question, most recent first
+func testHistory() []core.Delta {
+	oldState := &core.Question{ID: 1, URL: "https://leetcode.com/problems/test-question/", Familiarity: core.Hard}
+	newState := &core.Question{ID: 1, URL: "https://leetcode.com/problems/test-question/", Familiarity: core.Easy}
+	return []core.Delta{
+		{Action: core.ActionDelete, QuestionID: 1, OldState: newState, CreatedAt: testTime},
+		{Action: core.ActionUpdate, QuestionID: 1, OldState: oldState, NewState: newState, CreatedAt: testTime},
+		{Action: core.ActionAdd, QuestionID: 1, NewState: oldState, CreatedAt: testTime},
+	}
+}
+
+func TestHandler_HandleUndo_Args(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		undoCount int
+		wantExit  int
+	}{
+		{"default", nil, 1, errs.ExitOK},
+		{"count", []string{"2"}, 2, errs.ExitOK},
+		{"to entry", []string{"--to", "3"}, 2, errs.ExitOK},
+		{"to entry with equals", []string{"--to=2"}, 1, errs.ExitOK},
+		{"to latest entry", []string{"--to", "1"}, 0, errs.ExitOK},
+		{"count beyond history", []string{"4"}, 0, errs.ExitValidation},
+		{"entry beyond history", []string{"--to", "4"}, 0, errs.ExitValidation},
+		{"zero count", []string{"0"}, 0, errs.ExitValidation},
+		{"not a number", []string{"all"}, 0, errs.ExitValidation},
+		{"missing entry", []string{"--to"}, 0, errs.ExitValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, mockUseCase := setupTestHandler(t)
+			handler.IO = NewMockIOHandler("y")
+			mockUseCase.history = testHistory()
+
+			err := handler.HandleUndo(nil, tt.args)
+			if errs.ExitCode(err) != tt.wantExit {
+				t.Errorf("Expected exit code %d, got %v", tt.wantExit, err)
+			}
+			if mockUseCase.undoCount != tt.undoCount {
+				t.Errorf("Expected %d actions undone, got %d", tt.undoCount, mockUseCase.undoCount)
+			}
+		})
+	}
+}
+
+func TestHandler_HandleUndo_Preview(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	mockIO := NewMockIOHandler("n")
+	handler.IO = mockIO
+	mockUseCase.history = testHistory()
+
+	if err := handler.HandleUndo(nil, []string{"2"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := mockIO.output.String()
+	if !strings.Contains(output, "delete") || !strings.Contains(output, "Familiarity:") {
+		t.Errorf("Expected the two newest actions in the preview, got %q", output)
+	}
+	if !strings.Contains(output, "This reverts 1 update and restores 1 deleted question.") {
+		t.Errorf("Expected a summary of the changes, got %q", output)
+	}
+	if mockUseCase.undoCount != 0 {
+		t.Error("Expected nothing to be undone when cancelled")
+	}
+}
+
+func TestHandler_HandleUndo_PreviewResume(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	mockIO := NewMockIOHandler("n")
+	handler.IO = mockIO
+	oldState := &core.Question{ID: 1, URL: "https://leetcode.com/problems/two-sum/"}
+	mockUseCase.history = []core.Delta{{
+		Action: core.ActionResume,
+		Batch:  []core.Delta{{Action: core.ActionUpdate, QuestionID: 1, OldState: oldState, NewState: oldState}},
+	}}
+
+	handler.HandleUndo(nil, nil)
+
+	if output := mockIO.output.String(); !strings.Contains(output, "This reverts 1 update and pauses reviews again.") {
+		t.Errorf("Expected the undo to pause reviews again, got %q", output)
+	}
+}
+
+func TestHandler_HandlePause(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.pause = &core.Pause{Start: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)}
+
+	if err := handler.HandlePause(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output := mockIO.output.String(); !strings.Contains(output, "Reviews paused from 2024-06-10") {
+		t.Errorf("Expected the pause start, got %q", output)
+	}
+}
+
+func TestHandler_HandlePause_AlreadyPaused(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.shouldError = true
+	mockUseCase.errorToReturn = errs.ErrAlreadyPaused
+
+	if err := handler.HandlePause(); err != errs.ErrAlreadyPaused {
+		t.Errorf("Expected ErrAlreadyPaused, got %v", err)
+	}
+	if !slices.Contains(mockIO.writeCalls, "PrintError") {
+		t.Error("Expected the error to be printed")
+	}
+}
+
+func TestHandler_HandleResume(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.resume = &usecase.ResumeResult{Days: 7, Shifted: 3}
+
+	if err := handler.HandleResume(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output := mockIO.output.String(); !strings.Contains(output, "Moved the next review of 3 questions by 7 days") {
+		t.Errorf("Expected the moved reviews, got %q", output)
+	}
+}
+
+func TestHandler_HandleLeeches(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.leeches = []core.Question{
+		{ID: 4, URL: "https://leetcode.com/problems/two-sum", Lapses: 6, Leech: true, EaseFactor: 1.3, State: core.StateSuspended},
+	}
+
+	if err := handler.HandleLeeches(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := mockIO.output.String()
+	for _, expected := range []string{"Lapses", "two-sum", "suspended", "1.30"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got %q", expected, output)
+		}
+	}
+
+	mockIO.format = FormatJSON
+	if err := handler.HandleLeeches(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	doc, ok := mockIO.documents[0].(LeechesDocument)
+	if !ok || len(doc.Leeches) != 1 || doc.Leeches[0].Lapses != 6 || doc.Rows()[0][len(doc.Header())-1] != "suspended" {
+		t.Errorf("Expected a leeches document with the lapses and state, got %+v", mockIO.documents[0])
+	}
+}
+
+func TestHandler_HandleLeeches_Empty(t *testing.T) {
+	handler, mockIO, _ := setupTestHandler(t)
+
+	if err := handler.HandleLeeches(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(mockIO.output.String(), "No leeches") {
+		t.Errorf("Expected message about no leeches, got %q", mockIO.output.String())
+	}
+}
+
+func TestHandler_HandleLeeches_Clear(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.upserted = &core.Delta{Action: core.ActionUpdate, QuestionID: 4}
+
+	if err := handler.HandleLeeches([]string{"clear", "4"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mockUseCase.cleared != "4" || !strings.Contains(mockIO.output.String(), "Question 4 is no longer a leech") {
+		t.Errorf("Expected question 4 to be cleared, got %q and %q", mockUseCase.cleared, mockIO.output.String())
+	}
+
+	for _, args := range [][]string{{"clear"}, {"remove", "4"}} {
+		if err := handler.HandleLeeches(args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestHandler_HandleStateCommands(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+
+	if err := handler.HandleSuspend([]string{"https://leetcode.com/problems/two-sum/description/"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mockUseCase.stateTarget != "https://leetcode.com/problems/two-sum/" || mockUseCase.state != core.StateSuspended {
+		t.Errorf("Expected the normalized URL to be suspended, got %q and %q", mockUseCase.stateTarget, mockUseCase.state)
+	}
+	if !strings.Contains(mockIO.output.String(), "Question 1 is now suspended") || !strings.Contains(mockIO.output.String(), "make it active again") {
+		t.Errorf("Expected the new state and undo hint, got %q", mockIO.output.String())
+	}
+
+	handler.HandleArchive([]string{"3"})
+	if mockUseCase.stateTarget != "3" || mockUseCase.state != core.StateArchived {
+		t.Errorf("Expected question 3 to be archived, got %q and %q", mockUseCase.stateTarget, mockUseCase.state)
+	}
+	handler.HandleUnsuspend([]string{"3"})
+	if mockUseCase.state != core.StateActive {
+		t.Errorf("Expected question 3 to be active, got %q", mockUseCase.state)
+	}
+
+	for _, args := range [][]string{nil, {"3", "4"}} {
+		if err := handler.HandleSuspend(args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestHandler_HandleStart(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+
+	if err := handler.HandleStart([]string{"https://leetcode.com/problems/two-sum/description/"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mockUseCase.timerTarget != "https://leetcode.com/problems/two-sum/" {
+		t.Errorf("Expected the normalized URL, got %q", mockUseCase.timerTarget)
+	}
+	if output := mockIO.output.String(); !strings.Contains(output, "Run 'done' once it is solved") {
+		t.Errorf("Expected a hint to run done, got %q", output)
+	}
+
+	for _, args := range [][]string{nil, {"1", "2"}} {
+		if err := handler.HandleStart(args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestHandler_HandleDone_ExistingQuestion(t *testing.T) {
+	// Input: familiarity (4), memory (1), submissions (2)
+	mockIO := NewMockIOHandler("4\n1\n2\n")
+	mockUseCase := NewMockQuestionUseCase()
+	_, cfg := config.MockEnv(t)
+	logger.InitNop()
+	handler := NewHandler(cfg, mockUseCase, mockIO, "test-version")
+
+	url := "https://leetcode.com/problems/two-sum/"
+	question := &core.Question{ID: 1, URL: url, Note: "hash map", Tags: []string{"array"}, Importance: core.HighImportance}
+	mockUseCase.stopped = &usecase.StoppedTimer{Timer: core.Timer{URL: url, Start: testTime}, Minutes: 24, Question: question}
+	mockUseCase.upserted = &core.Delta{Action: core.ActionUpdate, QuestionID: 1, OldState: question, NewState: question, CreatedAt: testTime}
+
+	if err := handler.HandleDone(bufio.NewScanner(strings.NewReader("")), nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Note, tags and importance are kept, so only the review itself is prompted for
+	if mockUseCase.upsertNote != "hash map" || len(mockUseCase.upsertTags) != 1 || mockUseCase.upsertImp != core.HighImportance {
+		t.Errorf("Expected the question to keep its note, tags and importance, got %q, %v and %v",
+			mockUseCase.upsertNote, mockUseCase.upsertTags, mockUseCase.upsertImp)
+	}
+	if solve := mockUseCase.upsertSolve; solve.Minutes != 24 || solve.Attempts != 2 {
+		t.Errorf("Expected 24 timed minutes and 2 attempts, got %+v", solve)
+	}
+	output := mockIO.output.String()
+	for _, want := range []string{"Timer stopped after 24 minutes", "Minutes to solve: 24"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "Minutes to solve (optional)") {
+		t.Error("Expected the timed minutes not to be asked for")
+	}
+}
+
+func TestHandler_HandleDone_NewQuestionWithFlags(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	url := "https://leetcode.com/problems/3sum/"
+	mockUseCase.stopped = &usecase.StoppedTimer{Timer: core.Timer{URL: url, Start: testTime}, Minutes: 24}
+	mockUseCase.upserted = &core.Delta{Action: core.ActionAdd, QuestionID: 2, NewState: &core.Question{ID: 2, URL: url}, CreatedAt: testTime}
+
+	args := []string{"--note=two pointers", "--tags=array", "--familiarity=2", "--importance=3", "--attempts=3"}
+	if err := handler.HandleDone(bufio.NewScanner(strings.NewReader("")), args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mockUseCase.upsertCalls) != 1 || mockUseCase.upsertCalls[0] != url {
+		t.Errorf("Expected the timed question to be added, got %v", mockUseCase.upsertCalls)
+	}
+	if solve := mockUseCase.upsertSolve; solve.Minutes != 24 || solve.Attempts != 3 {
+		t.Errorf("Expected 24 timed minutes and 3 attempts, got %+v", solve)
+	}
+}
+
+func TestHandler_HandleDone_Errors(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+
+	if err := handler.HandleDone(bufio.NewScanner(strings.NewReader("")), nil); err != errs.ErrNoTimer {
+		t.Errorf("Expected ErrNoTimer, got %v", err)
+	}
+
+	// A URL is taken from the timer, and the timer keeps running when the arguments are wrong
+	mockUseCase.stopped = &usecase.StoppedTimer{Timer: core.Timer{URL: "https://leetcode.com/problems/3sum/"}, Minutes: 5}
+	for _, args := range [][]string{{"https://leetcode.com/problems/3sum"}, {"--minutes=0"}} {
+		if err := handler.HandleDone(bufio.NewScanner(strings.NewReader("")), args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+	if mockUseCase.stopped == nil {
+		t.Error("Expected the timer not to be stopped")
+	}
+}
+
+func TestHandler_HandleBury(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		days    int
+		until   time.Time
+		wantErr bool
+	}{
+		{"default", []string{"3"}, 1, time.Time{}, false},
+		{"days", []string{"3", "5"}, 5, time.Time{}, false},
+		{"date", []string{"3", "2024-06-20"}, 1, time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC), false},
+		{"bad length", []string{"3", "soon"}, 0, time.Time{}, true},
+		{"no target", nil, 0, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockIO, mockUseCase := setupTestHandler(t)
+
+			err := handler.HandleBury(tt.args)
+			if tt.wantErr {
+				if errs.ExitCode(err) != errs.ExitValidation {
+					t.Errorf("Expected a validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if mockUseCase.buryDays != tt.days || !mockUseCase.buryUntil.Equal(tt.until) {
+				t.Errorf("Expected burial for %d days until %v, got %d and %v", tt.days, tt.until, mockUseCase.buryDays, mockUseCase.buryUntil)
+			}
+			if !strings.Contains(mockIO.output.String(), "buried until 2024-06-20") {
+				t.Errorf("Expected the end of the burial, got %q", mockIO.output.String())
+			}
+		})
+	}
+}
+
+func TestHandler_HandleRedo(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		args        []string
+		redoHistory []core.Delta
+		redoCount   int
+		wantErr     error
+	}{
+		{"confirmed", "y", nil, testHistory(), 1, nil},
+		{"count", "y", []string{"3"}, testHistory(), 3, nil},
+		{"cancelled", "n", nil, testHistory(), 0, nil},
+		{"nothing to redo", "y", nil, nil, 0, errs.ErrNoActionsToRedo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, mockUseCase := setupTestHandler(t)
+			handler.IO = NewMockIOHandler(tt.input)
+			mockUseCase.redoHistory = tt.redoHistory
+
+			if err := handler.HandleRedo(nil, tt.args); err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if mockUseCase.redoCount != tt.redoCount {
+				t.Errorf("Expected %d actions redone, got %d", tt.redoCount, mockUseCase.redoCount)
+			}
+		})
+	}
+}
+
+func TestHandler_HandleRedo_InvalidArgs(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	mockUseCase.redoHistory = testHistory()
+
+	for _, args := range [][]string{{"0"}, {"all"}, {"1", "2"}, {"4"}} {
+		if err := handler.HandleRedo(nil, args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+}
+
 func TestHandler_ValidateFamiliarity(t *testing.T) {
 	handler, _, _ := setupTestHandler(t)
 
@@ -1114,6 +2093,12 @@ func TestHandler_ParseFilterArgs(t *testing.T) {
 		{[]string{"--familiarity=invalid"}, true},
 		{[]string{"--importance=invalid"}, true},
 		{[]string{"--review-count=invalid"}, true},
+		{[]string{"--tag=dp"}, false},
+		{[]string{"--no-tag=graph,bfs"}, false},
+		{[]string{"--tag=bad/tag"}, true},
+		{[]string{"--state=mastered"}, false},
+		{[]string{"--state="}, true},
+		{[]string{"--state=frozen"}, true},
 		{[]string{"--unknown=value"}, false}, // Should be ignored
 	}
 
@@ -1128,10 +2113,57 @@ func TestHandler_ParseFilterArgs(t *testing.T) {
 	}
 }
 
+func TestHandler_ParseFilterArgs_Tags(t *testing.T) {
+	handler, _, _ := setupTestHandler(t)
+
+	filter, err := handler.parseFilterArgs([]string{"--tag=DP", "--tag=graph,#bfs", "--no-tag=hard"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedTags := []string{"dp", "bfs", "graph"}
+	if strings.Join(filter.Tags, " ") != strings.Join(expectedTags, " ") {
+		t.Errorf("Expected tags %v, got %v", expectedTags, filter.Tags)
+	}
+	if len(filter.ExcludeTags) != 1 || filter.ExcludeTags[0] != "hard" {
+		t.Errorf("Expected excluded tags [hard], got %v", filter.ExcludeTags)
+	}
+}
+
+func TestHandler_HandleTags(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+
+	mockUseCase.tags = []usecase.TagSummary{
+		{Name: "dp", Total: 3, TotalDue: 1},
+		{Name: "graph", Total: 1, TotalDue: 0},
+	}
+
+	handler.HandleTags()
+
+	output := mockIO.output.String()
+	for _, expected := range []string{"dp", "graph", "Questions", "Due"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q", expected)
+		}
+	}
+}
+
+func TestHandler_HandleTags_Empty(t *testing.T) {
+	handler, mockIO, _ := setupTestHandler(t)
+
+	handler.HandleTags()
+
+	if !strings.Contains(mockIO.output.String(), "No tags yet") {
+		t.Error("Expected message about no tags")
+	}
+}
+
 func TestHandler_HandleUnknown(t *testing.T) {
 	handler, mockIO, _ := setupTestHandler(t)
 
-	handler.HandleUnknown("unknown_command")
+	if err := handler.HandleUnknown("unknown_command"); errs.ExitCode(err) != errs.ExitValidation {
+		t.Errorf("Expected a validation error for an unknown command, got %v", err)
+	}
 
 	// Verify that warning was printed
 	found := false
@@ -1391,6 +2423,18 @@ func TestHandler_GetChanges(t *testing.T) {
 			},
 			expected: []string{"Importance: 1 → 4", "Familiarity: 1 → 5"},
 		},
+		{
+			name:     "suspended",
+			oldState: &core.Question{},
+			newState: &core.Question{State: core.StateSuspended},
+			expected: []string{"State → suspended"},
+		},
+		{
+			name:     "buried",
+			oldState: &core.Question{},
+			newState: &core.Question{State: core.StateBuried, BuriedUntil: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)},
+			expected: []string{"Buried → 2024-06-20"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1434,6 +2478,124 @@ func TestHandler_HandleMigrate_Success(t *testing.T) {
 	}
 }
 
+func TestHandler_HandleDoctor(t *testing.T) {
+	issues := []usecase.Issue{
+		{Kind: usecase.IssueMaxID, Message: "max id", Repairable: true},
+		{Kind: usecase.IssueDuplicateURL, QuestionID: 2, Message: "duplicate", Repairable: false},
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		result  *usecase.CheckResult
+		wantErr error
+		repair  bool
+	}{
+		{"no problems", nil, &usecase.CheckResult{}, nil, false},
+		{"problems found", nil, &usecase.CheckResult{Issues: issues}, errs.ErrDataIssuesFound, false},
+		{"all repaired", []string{"--repair"}, &usecase.CheckResult{Issues: issues[:1], Repaired: 1, Backup: "20240615-120000"}, nil, true},
+		{"manual fix left", []string{"--repair"}, &usecase.CheckResult{Issues: issues, Repaired: 1}, errs.ErrDataIssuesFound, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockIO, mockUseCase := setupTestHandler(t)
+			mockUseCase.checkResult = tt.result
+
+			err := handler.HandleDoctor(tt.args)
+			if err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if mockUseCase.checkRepair != tt.repair {
+				t.Errorf("Expected repair=%t, got %t", tt.repair, mockUseCase.checkRepair)
+			}
+			if len(tt.result.Issues) == 0 && !slices.Contains(mockIO.writeCalls, "PrintSuccess") {
+				t.Error("Expected a success message when there are no problems")
+			}
+		})
+	}
+}
+
+func TestHandler_HandleDoctor_InvalidArgs(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	mockUseCase.checkResult = &usecase.CheckResult{Issues: []usecase.Issue{{Kind: usecase.IssueMaxID}}}
+
+	err := handler.HandleDoctor([]string{"--fix"})
+	if errs.ExitCode(err) != errs.ExitValidation {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+}
+
+func TestHandler_HandleBackup_List(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.backups = []backup.Snapshot{
+		{ID: "20240615-120000", CreatedAt: testTime, Reason: "automatic", Files: []backup.FileEntry{{Name: "questions", Size: 2048}}},
+	}
+
+	if err := handler.HandleBackup(nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	output := mockIO.output.String()
+	if !strings.Contains(output, "20240615-120000") || !strings.Contains(output, "2.0 KiB") || !strings.Contains(output, "automatic") {
+		t.Errorf("Expected the backup to be listed, got %q", output)
+	}
+}
+
+func TestHandler_HandleBackup_Create(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+
+	if err := handler.HandleBackup(nil, []string{"create", "before", "cleanup"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(mockUseCase.backups) != 1 || mockUseCase.backups[0].Reason != "before cleanup" {
+		t.Errorf("Expected a backup with the given reason, got %+v", mockUseCase.backups)
+	}
+	if !slices.Contains(mockIO.writeCalls, "PrintSuccess") {
+		t.Error("Expected a success message")
+	}
+}
+
+func TestHandler_HandleBackup_Restore(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		id         string
+		wantErr    error
+		restoredID string
+	}{
+		{"confirmed", "y", "20240615-120000", nil, "20240615-120000"},
+		{"cancelled", "n", "20240615-120000", nil, ""},
+		{"not found", "y", "20240101-000000", errs.ErrBackupNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, mockUseCase := setupTestHandler(t)
+			handler.IO = NewMockIOHandler(tt.input)
+			mockUseCase.backups = []backup.Snapshot{{ID: "20240615-120000", CreatedAt: testTime, Reason: "automatic"}}
+
+			scanner := bufio.NewScanner(strings.NewReader(tt.input + "\n"))
+			err := handler.HandleBackup(scanner, []string{"restore", tt.id})
+			if err != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if mockUseCase.restoredID != tt.restoredID {
+				t.Errorf("Expected restore of %q, got %q", tt.restoredID, mockUseCase.restoredID)
+			}
+		})
+	}
+}
+
+func TestHandler_HandleBackup_InvalidArgs(t *testing.T) {
+	handler, _, _ := setupTestHandler(t)
+
+	for _, args := range [][]string{{"restore"}, {"prune"}, {"list", "all"}} {
+		if err := handler.HandleBackup(nil, args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+}
+
 func TestHandler_HandleMigrate_Cancelled(t *testing.T) {
 	mockIO := NewMockIOHandler("n") // User cancels
 	mockUseCase := NewMockQuestionUseCase()
@@ -1515,6 +2677,62 @@ func TestHandler_HandleSetting_ShowSettings(t *testing.T) {
 	}
 }
 
+func TestHandler_HandleSetting_MapSetting(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+
+	scanner := bufio.NewScanner(strings.NewReader(""))
+	handler.HandleSetting(scanner, []string{})
+	if output := mockIO.output.String(); !strings.Contains(output, "BaseIntervals: low=8,medium=6,high=5,critical=4 days") {
+		t.Errorf("Expected the map setting as level=value pairs in level order, got %q", output)
+	}
+
+	if err := handler.HandleSetting(scanner, []string{"baseintervals", "medium=4,", "Critical=3"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	value, ok := mockUseCase.settingValue.(map[string]int)
+	if !ok || len(value) != 2 || value["medium"] != 4 || value["critical"] != 3 {
+		t.Errorf("Expected the levels of every argument, got %v", mockUseCase.settingValue)
+	}
+
+	if err := handler.HandleSetting(scanner, []string{"baseintervals", "urgent=1"}); errs.ExitCode(err) != errs.ExitValidation {
+		t.Errorf("Expected a validation error for an unknown level, got %v", err)
+	}
+}
+
+func TestHandler_HandleSetting_ResetAndDiff(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	scanner := bufio.NewScanner(strings.NewReader(""))
+
+	// The test configuration differs from the defaults
+	for _, diff := range handler.cfg.SettingDiffs() {
+		handler.cfg.ResetSettingValue(diff.Setting.Name)
+	}
+	handler.HandleSetting(scanner, []string{"diff"})
+	if output := mockIO.output.String(); !strings.Contains(output, "All settings are at their default values") {
+		t.Errorf("Expected no changed settings, got %q", output)
+	}
+
+	handler.cfg.PageSize = 20
+	handler.HandleSetting(scanner, []string{"diff"})
+	if output := mockIO.output.String(); !strings.Contains(output, "PageSize: 20 questions") || !strings.Contains(output, "default: 5 questions") {
+		t.Errorf("Expected PageSize with its default, got %q", output)
+	}
+
+	mockIO.format = FormatJSON
+	handler.HandleSetting(scanner, []string{"diff"})
+	doc, ok := mockIO.documents[len(mockIO.documents)-1].(SettingDiffsDocument)
+	if !ok || len(doc.Settings) != 1 || doc.Settings[0].Value != 20 || doc.Settings[0].Default != 5 {
+		t.Errorf("Expected a diff document with PageSize, got %+v", mockIO.documents)
+	}
+
+	if err := handler.HandleSetting(scanner, []string{"reset", "PageSize"}); err != nil || mockUseCase.settingName != "PageSize" {
+		t.Errorf("Expected PageSize to be reset, got %q and %v", mockUseCase.settingName, err)
+	}
+	if err := handler.HandleSetting(scanner, []string{"reset"}); errs.ExitCode(err) != errs.ExitValidation {
+		t.Errorf("Expected a validation error without a setting name, got %v", err)
+	}
+}
+
 func TestHandler_HandleSetting_InvalidUsage(t *testing.T) {
 	handler, mockIO, _ := setupTestHandler(t)
 
@@ -1583,3 +2801,366 @@ func TestHandler_ExtractQuestionNameFromURL(t *testing.T) {
 		})
 	}
 }
+
+// testSimulation is a simulation of two days, with one real and one hypothetical question
+func testSimulation() *usecase.SimulationResult {
+	days := []usecase.SimulatedDay{
+		{Date: testTime, Reviews: 0, Added: 1},
+		{Date: testTime.AddDate(0, 0, 1), Reviews: 3},
+	}
+	return &usecase.SimulationResult{
+		Days:  days,
+		Peaks: []usecase.SimulatedDay{days[1], days[0]},
+		Questions: []usecase.SimulatedQuestion{
+			{ID: 1, URL: "https://leetcode.com/problems/test-question/", Intervals: []int{6, 11, 20}},
+			{ID: 1, New: true, Intervals: []int{8}},
+		},
+	}
+}
+
+func TestHandler_HandleSimulate(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.simulation = testSimulation()
+
+	if err := handler.HandleSimulate([]string{"--days=60", "--familiarity=4", "--memory=2", "--add=20", "--per-day=5"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	opts := mockUseCase.simulateOpts
+	if opts.Days != 60 || opts.Familiarity == nil || *opts.Familiarity != core.Easy || opts.Memory != core.MemoryPartial ||
+		opts.NewQuestions != 20 || opts.NewPerDay != 5 {
+		t.Errorf("Unexpected options %+v", opts)
+	}
+
+	output := mockIO.output.String()
+	for _, want := range []string{
+		"Sat 2024-06-15 │█ 1 (1 new)",
+		"Sun 2024-06-16 │███ 3",
+		"Peak days: Sun 2024-06-16 (3), Sat 2024-06-15 (1)",
+		"Total: 4 questions over 2 days, 2.0 per day on average",
+		"6 → 11 → 20",
+		"new 1",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got %q", want, output)
+		}
+	}
+}
+
+func TestHandler_HandleSimulate_Defaults(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	mockUseCase.simulation = testSimulation()
+
+	if err := handler.HandleSimulate(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts := mockUseCase.simulateOpts; opts.Days != 30 || opts.Familiarity != nil || opts.NewQuestions != 0 {
+		t.Errorf("Expected 30 days at the current familiarity, got %+v", opts)
+	}
+}
+
+func TestHandler_HandleSimulate_InvalidArgs(t *testing.T) {
+	handler, _, _ := setupTestHandler(t)
+
+	for _, args := range [][]string{{"--days=0"}, {"--days=366"}, {"--familiarity=6"}, {"--add=-1"}, {"--per-day=0"}, {"60"}} {
+		if err := handler.HandleSimulate(args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestHandler_HandleSimulate_Structured(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockIO.format = FormatTSV
+	mockUseCase.simulation = testSimulation()
+
+	handler.HandleSimulate(nil)
+
+	if len(mockIO.documents) != 1 {
+		t.Fatalf("Expected one document, got %d", len(mockIO.documents))
+	}
+	doc := mockIO.documents[0].(SimulationDocument)
+	if rows := doc.Rows(); len(rows) != 2 || rows[1][0] != "2024-06-16" || rows[1][1] != "3" {
+		t.Errorf("Expected a row per day, got %v", rows)
+	}
+	if len(doc.Questions) != 2 || !doc.Questions[1].New || doc.Peaks[0].Reviews != 3 {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+}
+
+// testForecast is a forecast of the last three days of June and the first two of July
+func testForecast() *usecase.Forecast {
+	start := time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC)
+	days := make([]usecase.ForecastDay, 5)
+	for i, due := range []int{2, 0, 1, 0, 3} {
+		days[i] = usecase.ForecastDay{Date: start.AddDate(0, 0, i), Due: due}
+	}
+	return &usecase.Forecast{
+		Days:    days,
+		Peaks:   []usecase.ForecastDay{days[4], days[0], days[2]},
+		Overdue: 1,
+		Later:   4,
+		Total:   10,
+	}
+}
+
+func TestHandler_HandleForecast(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.forecast = testForecast()
+
+	if err := handler.HandleForecast(nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mockUseCase.forecastDays != 30 || mockUseCase.forecastBy != nil {
+		t.Errorf("Expected 30 days without filter, got %d days and %+v", mockUseCase.forecastDays, mockUseCase.forecastBy)
+	}
+
+	output := mockIO.output.String()
+	for _, want := range []string{
+		"Fri 2024-06-28 │██ 2 (1 overdue)",
+		"Sat 2024-06-29 │ 0",
+		"Tue 2024-07-02 │███ 3",
+		// Calendar grids of both months, Monday first
+		"June 2024\n  Mon  Tue  Wed  Thu  Fri  Sat  Sun\n                       28   29   30\n                        2    ·    1\n",
+		"July 2024\n  Mon  Tue  Wed  Thu  Fri  Sat  Sun\n    1    2\n    ·    3\n",
+		"Peak days: Tue 2024-07-02 (3), Fri 2024-06-28 (2), Sun 2024-06-30 (1)",
+		"Total: 6 questions due over 5 days, 1.2 per day on average",
+		"Later: 4 questions due after Tue 2024-07-02",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got %q", want, output)
+		}
+	}
+}
+
+func TestHandler_HandleForecast_WeeksAndFilter(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.forecast = testForecast()
+
+	if err := handler.HandleForecast([]string{"--days=90", "--weeks", "--importance=4", "--tag=graph,dp", "--no-tag=easy"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	filter := mockUseCase.forecastBy
+	if mockUseCase.forecastDays != 90 || filter == nil || *filter.Importance != core.CriticalImportance ||
+		len(filter.Tags) != 2 || len(filter.ExcludeTags) != 1 {
+		t.Errorf("Unexpected days %d and filter %+v", mockUseCase.forecastDays, filter)
+	}
+
+	output := mockIO.output.String()
+	for _, want := range []string{
+		"Counting only questions with importance 4, tagged dp and graph, not tagged easy.",
+		"Jun 28 – Jun 30 │███ 3",
+		"Jul 01 – Jul 02 │███ 3",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got %q", want, output)
+		}
+	}
+}
+
+func TestHandler_HandleForecast_InvalidArgs(t *testing.T) {
+	handler, _, _ := setupTestHandler(t)
+
+	for _, args := range [][]string{{"--days=0"}, {"--days=91"}, {"--importance=5"}, {"--tag=a/b"}, {"--due-only"}, {"30"}} {
+		if err := handler.HandleForecast(args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestHandler_HandleForecast_Structured(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockIO.format = FormatTSV
+	mockUseCase.forecast = testForecast()
+
+	handler.HandleForecast([]string{"--weeks"})
+
+	if len(mockIO.documents) != 1 {
+		t.Fatalf("Expected one document, got %d", len(mockIO.documents))
+	}
+	doc := mockIO.documents[0].(ForecastDocument)
+	if header := doc.Header(); header[0] != "start" {
+		t.Errorf("Expected a weekly header, got %v", header)
+	}
+	if rows := doc.Rows(); len(rows) != 2 || rows[1][0] != "2024-07-01" || rows[1][2] != "3" {
+		t.Errorf("Expected a row per week, got %v", rows)
+	}
+	if len(doc.Days) != 5 || doc.Overdue != 1 || doc.Later != 4 || doc.Peaks[0].Date != "2024-07-02" {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+}
+
+func testStats() *usecase.Stats {
+	stats := &usecase.Stats{
+		Questions:       5,
+		ByPlatform:      []usecase.PlatformCount{{Platform: core.PlatformLeetCode, Questions: 4}, {Platform: core.PlatformHackerRank, Questions: 1}},
+		AverageEase:     2.345,
+		AverageInterval: 12.25,
+		Reviews:         40,
+		RecentReviews:   14,
+		Repeated:        30,
+		Retained:        24,
+		CurrentStreak:   3,
+		LongestStreak:   9,
+	}
+	stats.ByFamiliarity[core.Medium], stats.ByFamiliarity[core.VeryEasy] = 3, 2
+	stats.ByImportance[core.HighImportance] = 5
+	for i := range 12 {
+		stats.AddedPerMonth = append(stats.AddedPerMonth, usecase.MonthCount{Month: time.Date(2023, time.Month(i+7), 1, 0, 0, 0, 0, time.UTC)})
+	}
+	stats.AddedPerMonth[11].Added = 5
+	return stats
+}
+
+func TestHandler_HandleStats(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.stats = testStats()
+
+	if err := handler.HandleStats(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := mockIO.output.String()
+	for _, want := range []string{
+		"Questions: 5",
+		"3 Decent    │███ 3",
+		"1 Struggled │ 0",
+		"3 High     │█████ 5",
+		"LeetCode   │████ 4",
+		"Average ease factor: 2.35",
+		"Average interval: 12.2 days",
+		"Last 28 days: 0.5 per day, 3.5 per week",
+		"Retention: 80% (24 of 30 reviews rated 3 or higher)",
+		"Streak: 3 days (longest: 9 days)",
+		"Jul 2023 │ 0",
+		"Jun 2024 │█████ 5",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got %q", want, output)
+		}
+	}
+}
+
+func TestHandler_HandleStats_Error(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	mockUseCase.shouldError = true
+	mockUseCase.errorToReturn = errs.ErrNoQuestionsAvailable
+
+	if err := handler.HandleStats(); err != errs.ErrNoQuestionsAvailable {
+		t.Errorf("Expected ErrNoQuestionsAvailable, got %v", err)
+	}
+}
+
+func TestHandler_HandleStats_Structured(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockIO.format = FormatTSV
+	mockUseCase.stats = testStats()
+
+	handler.HandleStats()
+
+	if len(mockIO.documents) != 1 {
+		t.Fatalf("Expected one document, got %d", len(mockIO.documents))
+	}
+	doc := mockIO.documents[0].(StatsDocument)
+	if len(doc.Familiarity) != 5 || doc.Familiarity[2] != (LevelCountView{Level: 3, Questions: 3}) || doc.Retention != 0.8 {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+	rows := doc.Rows()
+	if want := []string{"added", "2024-06", "5"}; !slices.Equal(rows[len(rows)-1], want) {
+		t.Errorf("Expected the last row %v, got %v", want, rows[len(rows)-1])
+	}
+	if !slices.ContainsFunc(rows, func(row []string) bool { return slices.Equal(row, []string{"platform", "hackerrank", "1"}) }) {
+		t.Errorf("Expected a row per platform, got %v", rows)
+	}
+}
+
+func testOptimization() *usecase.OptimizeResult {
+	return &usecase.OptimizeResult{
+		Options:       usecase.OptimizeOptions{Seed: 7, Rounds: 10},
+		Questions:     4,
+		Reviews:       40,
+		Recalled:      30,
+		CurrentError:  0.8,
+		ProposedError: 0.6,
+		Changes: []usecase.ParameterChange{
+			{Setting: "BaseIntervals", Level: "medium", Current: 6, Proposed: 4},
+			{Setting: "MemoryMultipliers", Level: "reasoned", Current: 1, Proposed: 0.85},
+		},
+	}
+}
+
+func TestHandler_HandleOptimize(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockUseCase.optimization = testOptimization()
+	mockIO.lines = []string{"y"}
+
+	if err := handler.HandleOptimize(bufio.NewScanner(strings.NewReader("")), []string{"--seed=7", "--rounds=10"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts := mockUseCase.optimizeOpts; opts.Seed != 7 || opts.Rounds != 10 {
+		t.Errorf("Unexpected options %+v", opts)
+	}
+
+	output := mockIO.output.String()
+	for _, want := range []string{
+		"Predicted 40 reviews of 4 questions; 75% were rated medium or easier.",
+		"0.8000 now, 0.6000 with the proposed settings (25.0% better)",
+		"MemoryMultipliers        reasoned            1       0.85",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the output, got %q", want, output)
+		}
+	}
+	if mockUseCase.applied != mockUseCase.optimization {
+		t.Error("Expected the proposal to be applied after confirmation")
+	}
+}
+
+func TestHandler_HandleOptimize_Declined(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	mockUseCase.optimization = testOptimization()
+
+	if err := handler.HandleOptimize(bufio.NewScanner(strings.NewReader("")), nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts := mockUseCase.optimizeOpts; opts.Seed != usecase.DefaultOptimizeSeed || opts.Rounds != usecase.DefaultOptimizeRounds {
+		t.Errorf("Expected the default seed and rounds, got %+v", opts)
+	}
+	if mockUseCase.applied != nil {
+		t.Error("Expected nothing to be applied without confirmation")
+	}
+}
+
+func TestHandler_HandleOptimize_InvalidArgs(t *testing.T) {
+	handler, _, _ := setupTestHandler(t)
+
+	for _, args := range [][]string{{"--seed=-1"}, {"--rounds=0"}, {"--apply"}} {
+		if err := handler.HandleOptimize(bufio.NewScanner(strings.NewReader("")), args); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestHandler_HandleOptimize_Structured(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	mockIO.format = FormatTSV
+	mockUseCase.optimization = testOptimization()
+	mockIO.lines = []string{"y"}
+
+	handler.HandleOptimize(bufio.NewScanner(strings.NewReader("")), nil)
+
+	if len(mockIO.documents) != 1 {
+		t.Fatalf("Expected one document, got %d", len(mockIO.documents))
+	}
+	doc := mockIO.documents[0].(OptimizeDocument)
+	if rows := doc.Rows(); len(rows) != 2 || rows[1][3] != "0.85" {
+		t.Errorf("Expected a row per change, got %v", rows)
+	}
+	if doc.Seed != 7 || doc.Reviews != 40 || doc.ProposedError != 0.6 {
+		t.Errorf("Unexpected document %+v", doc)
+	}
+	if mockUseCase.applied != nil {
+		t.Error("Expected structured output not to apply the proposal")
+	}
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/eannchen/leetsolv/core"
@@ -54,6 +56,8 @@ type IOHandler interface {
 	PrintError(err error)
 	PrintCancel(message string)
 	FormatTimeAgo(t time.Time) string
+	Format() OutputFormat
+	PrintDocument(doc Document)
 }
 
 type IOHandlerImpl struct {
@@ -70,6 +74,20 @@ func NewIOHandler(clock clock.Clock) *IOHandlerImpl {
 	}
 }
 
+func (ioh *IOHandlerImpl) Format() OutputFormat {
+	return FormatText
+}
+
+// PrintDocument renders a document as an aligned plain-text table
+func (ioh *IOHandlerImpl) PrintDocument(doc Document) {
+	tw := tabwriter.NewWriter(ioh.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(doc.Header(), "\t"))
+	for _, row := range doc.Rows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	tw.Flush()
+}
+
 func (ioh *IOHandlerImpl) Println(a ...interface{}) {
 	fmt.Fprintln(ioh.Writer, a...)
 }
@@ -103,6 +121,9 @@ func (ioh *IOHandlerImpl) PrintQuestionBrief(q *core.Question) {
 	} else {
 		ioh.Printf(" ↳ Note: %s\n", q.Note)
 	}
+	if len(q.Tags) > 0 {
+		ioh.Printf(" ↳ Tags: %s\n", strings.Join(q.Tags, ", "))
+	}
 }
 
 func (ioh *IOHandlerImpl) PrintQuestionDetail(question *core.Question) {
@@ -112,6 +133,9 @@ func (ioh *IOHandlerImpl) PrintQuestionDetail(question *core.Question) {
 	} else {
 		ioh.Printf(" ↳ Note: %s\n", question.Note)
 	}
+	if len(question.Tags) > 0 {
+		ioh.Printf(" ↳ Tags: %s\n", strings.Join(question.Tags, ", "))
+	}
 	ioh.Printf("   Familiarity: %d/%d\n", question.Familiarity+1, core.MaxFamiliarity)
 	ioh.Printf("   Importance: %d/%d\n", question.Importance+1, core.MaxImportance)
 	ioh.Printf("   Last Reviewed: %s\n", question.LastReviewed.Local().Format("2006-01-02"))
@@ -122,6 +146,23 @@ func (ioh *IOHandlerImpl) PrintQuestionDetail(question *core.Question) {
 	}
 	ioh.Printf("   Review Count: %d\n", question.ReviewCount)
 	ioh.Printf("   Ease Factor: %.2f\n", question.EaseFactor)
+	if question.Stability > 0 {
+		ioh.Printf("   Stability: %.1f days\n", question.Stability)
+		ioh.Printf("   Difficulty: %.1f/10\n", question.Difficulty)
+	}
+	if question.Leech {
+		ioh.PrintfColored(ColorWarning, "   Lapses: %d (leech)\n", question.Lapses)
+	} else if question.Lapses > 0 {
+		ioh.Printf("   Lapses: %d\n", question.Lapses)
+	}
+	if state := question.StateOn(ioh.Clock.Today()); state == core.StateBuried {
+		ioh.Printf("   State: buried until %s\n", question.BuriedUntil.Format(time.DateOnly))
+	} else if state != core.StateActive {
+		ioh.Printf("   State: %s\n", state)
+	}
+	if !question.LastSolve.IsZero() {
+		ioh.Printf("   Last Solve: %s\n", describeSolve(question.LastSolve))
+	}
 	ioh.Printf("   Created At: %s\n", question.CreatedAt.Local().Format("2006-01-02"))
 	ioh.Printf("\n")
 }
@@ -137,6 +178,9 @@ func (ioh *IOHandlerImpl) PrintQuestionUpsertDetail(delta *core.Delta) {
 	} else {
 		ioh.Printf(" ↳ Note: %s\n", newState.Note)
 	}
+	if len(newState.Tags) > 0 {
+		ioh.Printf(" ↳ Tags: %s\n", strings.Join(newState.Tags, ", "))
+	}
 
 	if oldState == nil {
 		ioh.Printf("   Familiarity: %d/%d\n", newState.Familiarity+1, core.MaxFamiliarity)
@@ -145,6 +189,9 @@ func (ioh *IOHandlerImpl) PrintQuestionUpsertDetail(delta *core.Delta) {
 		ioh.Printf("   Next Review: %s\n", newState.NextReview.Local().Format("2006-01-02"))
 		ioh.Printf("   Review Count: %d\n", newState.ReviewCount)
 		ioh.Printf("   Ease Factor: %.2f\n", newState.EaseFactor)
+		if !newState.LastSolve.IsZero() {
+			ioh.Printf("   Solve: %s\n", describeSolve(newState.LastSolve))
+		}
 		ioh.Printf("   Created At: %s\n", newState.CreatedAt.Local().Format("2006-01-02"))
 		ioh.Printf("\n")
 	} else {
@@ -178,12 +225,36 @@ func (ioh *IOHandlerImpl) PrintQuestionUpsertDetail(delta *core.Delta) {
 		} else {
 			ioh.Printf("   Ease Factor: %.2f\n", newState.EaseFactor)
 		}
+		if !newState.LastSolve.Equal(oldState.LastSolve) {
+			if oldState.LastSolve.IsZero() {
+				ioh.Printf("   Solve: %s\n", describeSolve(newState.LastSolve))
+			} else {
+				ioh.Printf("   Solve: %s (last: %s)\n", describeSolve(newState.LastSolve), describeSolve(oldState.LastSolve))
+			}
+		}
 		ioh.Printf("   Created At: %s\n", newState.CreatedAt.Local().Format("2006-01-02"))
 		ioh.Printf("\n")
 	}
 
 }
 
+// describeSolve lists the recorded parts of a solve, e.g. "25 min, 2 attempts"
+func describeSolve(solve core.Solve) string {
+	var parts []string
+	if solve.Minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d min", solve.Minutes))
+	}
+	switch {
+	case solve.Attempts > 0:
+		parts = append(parts, pluralize(solve.Attempts, "attempt"))
+	case solve.FirstPass != nil && *solve.FirstPass:
+		parts = append(parts, "passed on the first submission")
+	case solve.FirstPass != nil:
+		parts = append(parts, "failed the first submission")
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (ioh *IOHandlerImpl) PrintCancel(message string) {
 	ioh.PrintlnColored(ColorCancel, "[i] "+message)
 }
@@ -246,3 +317,89 @@ func (ioh *IOHandlerImpl) FormatTimeAgo(t time.Time) string {
 		return fmt.Sprintf("%d days ago", days)
 	}
 }
+
+// StructuredIOHandler renders command results as JSON or TSV documents on Out.
+// Prompts and human-readable messages go to the embedded handler's Writer (stderr by default)
+// so that Out only ever contains documents.
+type StructuredIOHandler struct {
+	*IOHandlerImpl
+	Out    io.Writer
+	format OutputFormat
+}
+
+func NewStructuredIOHandler(clock clock.Clock, format OutputFormat) *StructuredIOHandler {
+	return &StructuredIOHandler{
+		IOHandlerImpl: &IOHandlerImpl{
+			Reader: os.Stdin,
+			Writer: os.Stderr,
+			Clock:  clock,
+		},
+		Out:    os.Stdout,
+		format: format,
+	}
+}
+
+func (ioh *StructuredIOHandler) Format() OutputFormat {
+	return ioh.format
+}
+
+func (ioh *StructuredIOHandler) PrintDocument(doc Document) {
+	switch ioh.format {
+	case FormatTSV:
+		fmt.Fprintln(ioh.Out, strings.Join(doc.Header(), "\t"))
+		for _, row := range doc.Rows() {
+			fields := make([]string, len(row))
+			for i, field := range row {
+				fields[i] = tsvEscaper.Replace(field)
+			}
+			fmt.Fprintln(ioh.Out, strings.Join(fields, "\t"))
+		}
+	default:
+		ioh.writeJSON(doc)
+	}
+}
+
+// ErrorView is the JSON form of an error reported in structured output mode
+type ErrorView struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// PrintError reports errors on the message writer; in JSON mode as an {"error": ...} object
+func (ioh *StructuredIOHandler) PrintError(err error) {
+	if err == nil {
+		return
+	}
+	if ioh.format != FormatJSON {
+		ioh.IOHandlerImpl.PrintError(err)
+		return
+	}
+
+	view := ErrorView{Kind: "system", Message: err.Error()}
+	var codedErr *errs.CodedError
+	if errors.As(err, &codedErr) {
+		switch codedErr.Kind {
+		case errs.ValidationErrorKind:
+			view = ErrorView{Kind: "validation", Message: codedErr.UserMessage()}
+		case errs.BusinessErrorKind:
+			view = ErrorView{Kind: "business", Message: codedErr.UserMessage()}
+		}
+	}
+
+	encoder := json.NewEncoder(ioh.Writer)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(struct {
+		Error ErrorView `json:"error"`
+	}{view})
+}
+
+func (ioh *StructuredIOHandler) writeJSON(v any) {
+	encoder := json.NewEncoder(ioh.Out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		ioh.PrintError(errs.WrapInternalError(err, "Failed to encode JSON output"))
+	}
+}
+
+// tsvEscaper keeps every record on a single line with a fixed number of fields
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
@@ -435,3 +435,48 @@ func TestIOHandler_PrintQuestionUpsertDetail_UpdatedQuestion(t *testing.T) {
 		t.Errorf("Expected '→' for changes in output")
 	}
 }
+
+func TestStructuredIOHandler_PrintDocument(t *testing.T) {
+	doc := TagsDocument{Tags: []TagView{{Name: "dp", Total: 2, TotalDue: 1}, {Name: "a\tb", Total: 1}}}
+
+	t.Run("json", func(t *testing.T) {
+		var out, msg bytes.Buffer
+		ioh := &StructuredIOHandler{IOHandlerImpl: &IOHandlerImpl{Writer: &msg}, Out: &out, format: FormatJSON}
+
+		ioh.PrintDocument(doc)
+		ioh.Println("message")
+
+		if !strings.Contains(out.String(), `"name": "dp"`) || !strings.Contains(out.String(), `"total_due": 1`) {
+			t.Errorf("Expected JSON document, got %q", out.String())
+		}
+		if strings.Contains(out.String(), "message") || !strings.Contains(msg.String(), "message") {
+			t.Error("Expected messages to be kept out of the document output")
+		}
+	})
+
+	t.Run("tsv", func(t *testing.T) {
+		var out bytes.Buffer
+		ioh := &StructuredIOHandler{IOHandlerImpl: &IOHandlerImpl{Writer: &bytes.Buffer{}}, Out: &out, format: FormatTSV}
+
+		ioh.PrintDocument(doc)
+
+		expected := "name\ttotal\ttotal_due\ndp\t2\t1\na\\tb\t1\t0\n"
+		if out.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, out.String())
+		}
+	})
+}
+
+func TestStructuredIOHandler_PrintError(t *testing.T) {
+	var out, msg bytes.Buffer
+	ioh := &StructuredIOHandler{IOHandlerImpl: &IOHandlerImpl{Writer: &msg}, Out: &out, format: FormatJSON}
+
+	ioh.PrintError(errs.ErrQuestionNotFound)
+
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing on document output, got %q", out.String())
+	}
+	if !strings.Contains(msg.String(), `"kind": "business"`) {
+		t.Errorf("Expected business error kind, got %q", msg.String())
+	}
+}
@@ -0,0 +1,368 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+// FileFormat is the encoding of an import or export file
+type FileFormat string
+
+const (
+	FileFormatCSV  FileFormat = "csv"
+	FileFormatJSON FileFormat = "json"
+)
+
+// exportVersion is the version of the JSON export schema
+const exportVersion = 1
+
+// ExportDocument is the JSON schema of export files.
+// It holds the questions only; search indices are rebuilt on import.
+type ExportDocument struct {
+	Version   int            `json:"version"`
+	Questions []QuestionView `json:"questions"`
+}
+
+// csvHeader lists the CSV columns in export order. On import only url is required,
+// columns may come in any order, and unknown columns are ignored.
+var csvHeader = []string{"url", "note", "tags", "familiarity", "importance", "last_reviewed", "next_review", "review_count", "ease_factor", "stability", "difficulty", "lapses", "leech", "state", "buried_until", "solve_minutes", "first_pass", "attempts", "created_at"}
+
+// detectFileFormat picks the file format from the --as flag or the file extension
+func detectFileFormat(path, as string) (FileFormat, error) {
+	if as == "" {
+		as = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch format := FileFormat(strings.ToLower(as)); format {
+	case FileFormatCSV, FileFormatJSON:
+		return format, nil
+	}
+	return "", errs.WrapValidationError(fmt.Errorf("unknown file format for %q", path), "Cannot tell the file format; use a .csv or .json file or pass --as=csv|json")
+}
+
+func encodeExport(w io.Writer, format FileFormat, questions []core.Question) error {
+	views := newQuestionViews(questions)
+	if format == FileFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(ExportDocument{Version: exportVersion, Questions: views})
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, v := range views {
+		record := []string{
+			v.URL,
+			v.Note,
+			strings.Join(v.Tags, ","),
+			strconv.Itoa(v.Familiarity),
+			strconv.Itoa(v.Importance),
+			formatCSVTime(v.LastReviewed),
+			formatCSVTime(v.NextReview),
+			strconv.Itoa(v.ReviewCount),
+			formatCSVFloat(v.EaseFactor),
+			formatCSVFloat(v.Stability),
+			formatCSVFloat(v.Difficulty),
+			formatCSVInt(v.Lapses),
+			formatCSVBool(v.Leech),
+			v.State,
+			formatCSVTime(v.BuriedUntil),
+			formatCSVInt(v.LastSolve.Minutes),
+			formatCSVOptionalBool(v.LastSolve.FirstPass),
+			formatCSVInt(v.LastSolve.Attempts),
+			formatCSVTime(v.CreatedAt),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func decodeImport(data []byte, format FileFormat) ([]QuestionView, error) {
+	if format == FileFormatJSON {
+		return decodeJSONImport(data)
+	}
+	return decodeCSVImport(data)
+}
+
+// decodeJSONImport accepts an export document or a bare array of questions
+func decodeJSONImport(data []byte) ([]QuestionView, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var views []QuestionView
+		if err := json.Unmarshal(trimmed, &views); err != nil {
+			return nil, errs.WrapValidationError(err, fmt.Sprintf("Invalid JSON import file: %v", err))
+		}
+		return views, nil
+	}
+
+	var doc ExportDocument
+	if err := json.Unmarshal(trimmed, &doc); err != nil {
+		return nil, errs.WrapValidationError(err, fmt.Sprintf("Invalid JSON import file: %v", err))
+	}
+	if doc.Version > exportVersion {
+		return nil, errs.WrapValidationError(fmt.Errorf("unsupported export version %d", doc.Version),
+			fmt.Sprintf("The import file uses schema version %d; this version of leetsolv supports up to %d", doc.Version, exportVersion))
+	}
+	return doc.Questions, nil
+}
+
+func decodeCSVImport(data []byte) ([]QuestionView, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1 // spreadsheets often drop trailing empty cells
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errs.WrapValidationError(err, "The CSV import file needs a header line")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errs.WrapValidationError(errors.New("missing url column"), "The CSV import file needs a url column")
+	}
+
+	var views []QuestionView
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errs.WrapValidationError(err, fmt.Sprintf("Invalid CSV import file: %v", err))
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if field("url") == "" && strings.Join(record, "") == "" {
+			continue // blank line
+		}
+
+		view, err := parseCSVRecord(field)
+		if err != nil {
+			return nil, errs.WrapValidationError(err, fmt.Sprintf("Line %d: %v", line, err))
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+func parseCSVRecord(field func(name string) string) (QuestionView, error) {
+	var view QuestionView
+	var err error
+
+	view.URL = field("url")
+	view.Note = field("note")
+	if tags := field("tags"); tags != "" {
+		view.Tags = strings.Split(tags, ",")
+	}
+	if view.Familiarity, err = parseCSVInt(field("familiarity"), "familiarity"); err != nil {
+		return view, err
+	}
+	if view.Importance, err = parseCSVInt(field("importance"), "importance"); err != nil {
+		return view, err
+	}
+	if view.ReviewCount, err = parseCSVInt(field("review_count"), "review_count"); err != nil {
+		return view, err
+	}
+	if view.EaseFactor, err = parseCSVFloat(field("ease_factor"), "ease_factor"); err != nil {
+		return view, err
+	}
+	if view.Stability, err = parseCSVFloat(field("stability"), "stability"); err != nil {
+		return view, err
+	}
+	if view.Difficulty, err = parseCSVFloat(field("difficulty"), "difficulty"); err != nil {
+		return view, err
+	}
+	if view.Lapses, err = parseCSVInt(field("lapses"), "lapses"); err != nil {
+		return view, err
+	}
+	if view.Leech, err = parseCSVBool(field("leech"), "leech"); err != nil {
+		return view, err
+	}
+	view.State = field("state")
+	if view.BuriedUntil, err = parseCSVTime(field("buried_until"), "buried_until"); err != nil {
+		return view, err
+	}
+	if view.LastSolve.Minutes, err = parseCSVInt(field("solve_minutes"), "solve_minutes"); err != nil {
+		return view, err
+	}
+	if firstPass := field("first_pass"); firstPass != "" {
+		passed, err := parseCSVBool(firstPass, "first_pass")
+		if err != nil {
+			return view, err
+		}
+		view.LastSolve.FirstPass = &passed
+	}
+	if view.LastSolve.Attempts, err = parseCSVInt(field("attempts"), "attempts"); err != nil {
+		return view, err
+	}
+	if view.LastReviewed, err = parseCSVTime(field("last_reviewed"), "last_reviewed"); err != nil {
+		return view, err
+	}
+	if view.NextReview, err = parseCSVTime(field("next_review"), "next_review"); err != nil {
+		return view, err
+	}
+	if view.CreatedAt, err = parseCSVTime(field("created_at"), "created_at"); err != nil {
+		return view, err
+	}
+	return view, nil
+}
+
+// questionFromView converts an imported question, validating the 1-based levels and tags.
+// Missing levels default to medium familiarity and medium importance.
+func (h *HandlerImpl) questionFromView(view QuestionView, position int) (core.Question, error) {
+	q := core.Question{
+		URL:          strings.TrimSpace(view.URL),
+		Note:         strings.TrimSpace(view.Note),
+		Familiarity:  core.Medium,
+		Importance:   core.MediumImportance,
+		LastReviewed: view.LastReviewed,
+		NextReview:   view.NextReview,
+		ReviewCount:  view.ReviewCount,
+		EaseFactor:   view.EaseFactor,
+		Stability:    view.Stability,
+		Difficulty:   view.Difficulty,
+		Lapses:       view.Lapses,
+		Leech:        view.Leech,
+		BuriedUntil:  view.BuriedUntil,
+		LastSolve:    view.LastSolve,
+		CreatedAt:    view.CreatedAt,
+	}
+
+	if !q.LastSolve.Valid() {
+		return q, errs.WrapValidationError(fmt.Errorf("invalid last solve %+v", view.LastSolve),
+			fmt.Sprintf("Question %d: solve minutes and attempts must not be negative, and a first pass takes 1 attempt", position))
+	}
+
+	state, ok := core.ParseQuestionState(view.State)
+	if !ok {
+		return q, errs.WrapValidationError(fmt.Errorf("unknown state %q", view.State), fmt.Sprintf("Question %d: state must be active, suspended, buried or archived", position))
+	}
+	q.State = state
+
+	if view.Familiarity != 0 {
+		familiarity, err := h.validateFamiliarity(strconv.Itoa(view.Familiarity))
+		if err != nil {
+			return q, errs.WrapValidationError(err, fmt.Sprintf("Question %d: familiarity must be between 1 and 5", position))
+		}
+		q.Familiarity = familiarity
+	}
+	if view.Importance != 0 {
+		importance, err := h.validateImportance(strconv.Itoa(view.Importance))
+		if err != nil {
+			return q, errs.WrapValidationError(err, fmt.Sprintf("Question %d: importance must be between 1 and 4", position))
+		}
+		q.Importance = importance
+	}
+
+	tags, err := h.parseTags(strings.Join(view.Tags, ","))
+	if err != nil {
+		return q, errs.WrapValidationError(err, fmt.Sprintf("Question %d: tags may only contain letters, digits, '-', '_', '+' and '.'", position))
+	}
+	q.Tags = tags
+	return q, nil
+}
+
+func parseCSVInt(value, column string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a whole number, got %q", column, value)
+	}
+	return n, nil
+}
+
+func parseCSVFloat(value, column string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number, got %q", column, value)
+	}
+	return f, nil
+}
+
+func parseCSVBool(value, column string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", column, value)
+	}
+	return b, nil
+}
+
+// parseCSVTime accepts RFC 3339 timestamps and plain dates, which spreadsheets produce
+func parseCSVTime(value, column string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be a date (YYYY-MM-DD) or RFC 3339 timestamp, got %q", column, value)
+}
+
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatCSVFloat(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatCSVInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// formatCSVOptionalBool leaves the cell empty when the answer is not known
+func formatCSVOptionalBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+func formatCSVBool(b bool) string {
+	if !b {
+		return ""
+	}
+	return "true"
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/usecase"
+)
+
+func TestDetectFileFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		as       string
+		expected FileFormat
+		hasError bool
+	}{
+		{"deck.csv", "", FileFormatCSV, false},
+		{"deck.JSON", "", FileFormatJSON, false},
+		{"deck.txt", "csv", FileFormatCSV, false},
+		{"deck.txt", "", "", true},
+		{"deck.csv", "xml", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+"/"+tt.as, func(t *testing.T) {
+			format, err := detectFileFormat(tt.path, tt.as)
+			if tt.hasError {
+				if err == nil {
+					t.Error("Expected error for unknown format")
+				}
+				return
+			}
+			if err != nil || format != tt.expected {
+				t.Errorf("Expected %q, got %q (%v)", tt.expected, format, err)
+			}
+		})
+	}
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	handler, _, _ := setupTestHandler(t)
+	firstPass := false
+	questions := []core.Question{{
+		ID:           3,
+		URL:          "https://leetcode.com/problems/two-sum/",
+		Note:         "hash map, one pass",
+		Tags:         []string{"array", "hash"},
+		Familiarity:  core.Easy,
+		Importance:   core.HighImportance,
+		LastReviewed: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		NextReview:   time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC),
+		ReviewCount:  3,
+		EaseFactor:   2.1,
+		Lapses:       4,
+		Leech:        true,
+		State:        core.StateSuspended,
+		LastSolve:    core.Solve{Minutes: 35, FirstPass: &firstPass, Attempts: 2},
+		CreatedAt:    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	for _, format := range []FileFormat{FileFormatCSV, FileFormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encodeExport(&buf, format, questions); err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+			if strings.Contains(buf.String(), "trie") {
+				t.Error("Expected the export to contain questions only")
+			}
+
+			views, err := decodeImport(buf.Bytes(), format)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			if len(views) != 1 {
+				t.Fatalf("Expected 1 question, got %d", len(views))
+			}
+			got, err := handler.questionFromView(views[0], 1)
+			if err != nil {
+				t.Fatalf("Failed to convert: %v", err)
+			}
+
+			want := questions[0]
+			if got.URL != want.URL || got.Note != want.Note || !slices.Equal(got.Tags, want.Tags) ||
+				got.Familiarity != want.Familiarity || got.Importance != want.Importance ||
+				!got.LastReviewed.Equal(want.LastReviewed) || !got.NextReview.Equal(want.NextReview) ||
+				got.ReviewCount != want.ReviewCount || got.EaseFactor != want.EaseFactor ||
+				got.Lapses != want.Lapses || got.Leech != want.Leech || got.State != want.State ||
+				!got.LastSolve.Equal(want.LastSolve) {
+				t.Errorf("Round trip changed the question:\nwant %+v\ngot  %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeCSVImport_SpreadsheetInput(t *testing.T) {
+	handler, _, _ := setupTestHandler(t)
+	data := "Note,URL,Importance,Next_Review,Extra\n" +
+		"\"sliding window, two pointers\",https://leetcode.com/problems/3sum,4,2024-07-01,ignored\n" +
+		"\n" +
+		",https://leetcode.com/problems/two-sum\n"
+
+	views, err := decodeImport([]byte(data), FileFormatCSV)
+	if err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if len(views) != 2 {
+		t.Fatalf("Expected 2 questions, got %d", len(views))
+	}
+
+	q, err := handler.questionFromView(views[0], 1)
+	if err != nil {
+		t.Fatalf("Failed to convert: %v", err)
+	}
+	if q.Note != "sliding window, two pointers" || q.Importance != core.CriticalImportance || q.Familiarity != core.Medium {
+		t.Errorf("Unexpected question: %+v", q)
+	}
+	if !q.NextReview.Equal(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a plain date to be accepted, got %v", q.NextReview)
+	}
+}
+
+func TestDecodeImport_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format FileFormat
+	}{
+		{"missing url column", "note,tags\nx,y\n", FileFormatCSV},
+		{"bad number", "url,familiarity\nhttps://leetcode.com/problems/two-sum,high\n", FileFormatCSV},
+		{"bad date", "url,next_review\nhttps://leetcode.com/problems/two-sum,tomorrow\n", FileFormatCSV},
+		{"bad leech", "url,leech\nhttps://leetcode.com/problems/two-sum,often\n", FileFormatCSV},
+		{"bad first pass", "url,first_pass\nhttps://leetcode.com/problems/two-sum,maybe\n", FileFormatCSV},
+		{"malformed json", `{"questions": [`, FileFormatJSON},
+		{"newer schema", `{"version": 99, "questions": []}`, FileFormatJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeImport([]byte(tt.data), tt.format)
+			if errs.ExitCode(err) != errs.ExitValidation {
+				t.Errorf("Expected a validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHandler_HandleExport(t *testing.T) {
+	handler, _, mockUseCase := setupTestHandler(t)
+	mockUseCase.questions = []core.Question{{ID: 1, URL: "https://leetcode.com/problems/two-sum/"}}
+	path := filepath.Join(t.TempDir(), "deck.csv")
+
+	if err := handler.HandleExport(bufio.NewScanner(strings.NewReader("")), []string{path}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected export file to be written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), strings.Join(csvHeader, ",")+"\n") || !strings.Contains(string(data), "two-sum") {
+		t.Errorf("Unexpected export file:\n%s", data)
+	}
+}
+
+func TestHandler_HandleImport(t *testing.T) {
+	handler, mockIO, mockUseCase := setupTestHandler(t)
+	path := filepath.Join(t.TempDir(), "deck.json")
+	data := `[{"url": "https://leetcode.com/problems/two-sum", "tags": ["#Array"]}]`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write import file: %v", err)
+	}
+
+	err := handler.HandleImport(bufio.NewScanner(strings.NewReader("")), []string{path, "--on-conflict=merge", "--dry-run"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockUseCase.importPolicy != usecase.ImportMerge {
+		t.Errorf("Expected merge policy, got %q", mockUseCase.importPolicy)
+	}
+	if len(mockUseCase.imported) != 1 || !slices.Equal(mockUseCase.imported[0].Tags, []string{"array"}) {
+		t.Errorf("Expected one question with normalized tags, got %+v", mockUseCase.imported)
+	}
+	if !slices.Contains(mockIO.writeCalls, "PrintCancel") {
+		t.Error("Expected the dry run summary to be printed")
+	}
+}
+
+func TestHandler_HandleImport_InvalidArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown policy", []string{"deck.csv", "--on-conflict=replace"}},
+		{"unknown flag", []string{"deck.csv", "--force"}},
+		{"missing file", []string{filepath.Join(os.TempDir(), "leetsolv-missing-deck.csv")}},
+		{"unknown format", []string{"deck.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, mockUseCase := setupTestHandler(t)
+
+			err := handler.HandleImport(bufio.NewScanner(strings.NewReader("")), tt.args)
+			if errs.ExitCode(err) != errs.ExitValidation {
+				t.Errorf("Expected a validation error, got %v", err)
+			}
+			if mockUseCase.imported != nil {
+				t.Error("Expected nothing to be imported")
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+// Package backup implements the rotating data file backups for the leetsolv application.
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/eannchen/leetsolv/internal/clock"
+)
+
+// ErrSnapshotNotFound is returned when restoring a snapshot that does not exist
+var ErrSnapshotNotFound = errors.New("backup not found")
+
+const (
+	manifestName = "manifest.json"
+	idLayout     = "20060102-150405"
+)
+
+// File is a data file included in every snapshot
+type File struct {
+	Name string // Stable name inside the snapshot, e.g. "questions"
+	Path string // Location of the live file
+}
+
+// FileEntry records one file of a snapshot; files that did not exist have no hash
+type FileEntry struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256,omitempty"`
+	Size   int64  `json:"size"`
+}
+
+// Snapshot describes one backup in the backup directory
+type Snapshot struct {
+	ID        string      `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Reason    string      `json:"reason"`
+	Files     []FileEntry `json:"files"`
+}
+
+// Size returns the total size of the files in the snapshot
+func (s Snapshot) Size() int64 {
+	var size int64
+	for _, f := range s.Files {
+		size += f.Size
+	}
+	return size
+}
+
+// Retention returns how many snapshots to keep and the age after which they are deleted;
+// a maxAge of 0 keeps snapshots regardless of age. It is called on every prune, so that
+// changed settings apply right away.
+type Retention func() (keep int, maxAge time.Duration)
+
+// Manager creates, lists, restores and prunes snapshots of the data files.
+// Each snapshot is a directory named by its ID holding a copy of every file and a manifest.
+type Manager struct {
+	dir       string
+	files     []File
+	retention Retention
+	clock     clock.Clock
+
+	mu    sync.Mutex
+	armed bool
+}
+
+// NewManager creates a manager that snapshots files into dir and prunes them by retention
+func NewManager(dir string, files []File, retention Retention, clock clock.Clock) *Manager {
+	return &Manager{
+		dir:       dir,
+		files:     files,
+		retention: retention,
+		clock:     clock,
+		armed:     true,
+	}
+}
+
+// Tracks reports whether path is one of the backed up data files
+func (m *Manager) Tracks(path string) bool {
+	return slices.ContainsFunc(m.files, func(f File) bool { return filepath.Clean(f.Path) == filepath.Clean(path) })
+}
+
+// Arm makes the next BeforeWrite take a snapshot. Call it before each command.
+func (m *Manager) Arm() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.armed = true
+}
+
+// BeforeWrite takes a snapshot before the first write since the manager was armed,
+// so that a command writing several files is covered by a single snapshot.
+// No snapshot is taken when the data files match the newest snapshot.
+func (m *Manager) BeforeWrite() error {
+	m.mu.Lock()
+	armed := m.armed
+	m.armed = false
+	m.mu.Unlock()
+
+	if !armed {
+		return nil
+	}
+	if _, err := m.snapshot("automatic", true); err != nil {
+		return err
+	}
+	return m.prune("")
+}
+
+// Create takes a snapshot of the data files
+func (m *Manager) Create(reason string) (*Snapshot, error) {
+	snapshot, err := m.snapshot(reason, false)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, m.prune("")
+}
+
+// List returns the snapshots, newest first
+func (m *Manager) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		snapshot, err := m.readManifest(entry.Name())
+		if err != nil {
+			continue // Not a snapshot, or an incomplete one
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+	slices.SortFunc(snapshots, func(a, b Snapshot) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+	return snapshots, nil
+}
+
+// Restore replaces the data files with those of the snapshot. The current files are
+// snapshotted first, so a restore can itself be undone by restoring that snapshot.
+// It returns the restored snapshot and the one taken before restoring (nil when the
+// current files already match the newest snapshot, or do not exist).
+func (m *Manager) Restore(id string) (*Snapshot, *Snapshot, error) {
+	snapshot, err := m.readManifest(id)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrSnapshotNotFound
+		}
+		return nil, nil, err
+	}
+
+	before, err := m.snapshot("before restoring "+id, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, file := range m.files {
+		entry, ok := findEntry(snapshot.Files, file.Name)
+		if !ok || entry.SHA256 == "" {
+			// The file did not exist when the snapshot was taken
+			if err := os.Remove(file.Path); err != nil && !os.IsNotExist(err) {
+				return nil, nil, err
+			}
+			continue
+		}
+		if err := copyFile(filepath.Join(m.dir, id, file.Name+filepath.Ext(file.Path)), file.Path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The restored snapshot may be the oldest one; it is kept until the next prune
+	return snapshot, before, m.prune(id)
+}
+
+// snapshot copies the data files into a new snapshot directory.
+// With skipUnchanged set, nothing is written when the files match the newest snapshot
+// or when none of them exist yet.
+func (m *Manager) snapshot(reason string, skipUnchanged bool) (*Snapshot, error) {
+	entries := make([]FileEntry, 0, len(m.files))
+	for _, file := range m.files {
+		entry, err := hashFile(file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	existing, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	if skipUnchanged {
+		missing := !slices.ContainsFunc(entries, func(e FileEntry) bool { return e.SHA256 != "" })
+		if missing || (len(existing) > 0 && slices.Equal(existing[0].Files, entries)) {
+			return nil, nil
+		}
+	}
+
+	now := m.clock.Now()
+	snapshot := &Snapshot{ID: m.newID(now, existing), CreatedAt: now, Reason: reason, Files: entries}
+	snapshotDir := filepath.Join(m.dir, snapshot.ID)
+	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
+		return nil, err
+	}
+
+	for i, file := range m.files {
+		if entries[i].SHA256 == "" {
+			continue
+		}
+		if err := copyFile(file.Path, filepath.Join(snapshotDir, file.Name+filepath.Ext(file.Path))); err != nil {
+			os.RemoveAll(snapshotDir)
+			return nil, err
+		}
+	}
+
+	// The manifest is written last, so a snapshot without one is incomplete and ignored
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		os.RemoveAll(snapshotDir)
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(snapshotDir, manifestName), data, 0644); err != nil {
+		os.RemoveAll(snapshotDir)
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// prune deletes the snapshots beyond the retention count or age, except the newest one and keepID
+func (m *Manager) prune(keepID string) error {
+	snapshots, err := m.List()
+	if err != nil {
+		return err
+	}
+	keep, maxAge := m.retention()
+	now := m.clock.Now()
+	for i, snapshot := range snapshots {
+		if i == 0 || snapshot.ID == keepID {
+			continue
+		}
+		tooOld := maxAge > 0 && now.Sub(snapshot.CreatedAt) > maxAge
+		if i >= keep || tooOld {
+			if err := os.RemoveAll(filepath.Join(m.dir, snapshot.ID)); err != nil {
+				return fmt.Errorf("failed to remove old backup %s: %w", snapshot.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// newID names a snapshot after its creation time, adding a counter when that name is taken
+func (m *Manager) newID(now time.Time, existing []Snapshot) string {
+	base := now.Format(idLayout)
+	id := base
+	for n := 2; slices.ContainsFunc(existing, func(s Snapshot) bool { return s.ID == id }); n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	return id
+}
+
+func (m *Manager) readManifest(id string) (*Snapshot, error) {
+	if id == "" || id != filepath.Base(id) {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(filepath.Join(m.dir, id, manifestName))
+	if err != nil {
+		return nil, err
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// compareIDs orders IDs created in the same second by their counter
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func findEntry(entries []FileEntry, name string) (FileEntry, bool) {
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return FileEntry{}, false
+}
+
+func hashFile(file File) (FileEntry, error) {
+	entry := FileEntry{Name: file.Name}
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entry, nil
+		}
+		return entry, err
+	}
+	sum := sha256.Sum256(data)
+	entry.SHA256 = hex.EncodeToString(sum[:])
+	entry.Size = int64(len(data))
+	return entry, nil
+}
+
+// copyFile copies src to dst through a temporary file, so dst is never left half written
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(dst), "temp_*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), dst)
+}
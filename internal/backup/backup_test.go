@@ -0,0 +1,242 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/internal/clock"
+	"github.com/eannchen/leetsolv/internal/fileutil"
+)
+
+// Fixed test time for deterministic tests
+var testTime = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+// setupTestManager creates a manager for two data files in a temporary directory
+func setupTestManager(t *testing.T, keep int, maxAge time.Duration) (*Manager, *clock.MockClock, []File) {
+	dir := t.TempDir()
+	files := []File{
+		{Name: "questions", Path: filepath.Join(dir, "questions.json")},
+		{Name: "deltas", Path: filepath.Join(dir, "deltas.json")},
+	}
+	mockClock := clock.NewMockClock(testTime)
+	retention := func() (int, time.Duration) { return keep, maxAge }
+	return NewManager(filepath.Join(dir, "backups"), files, retention, mockClock), mockClock, files
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestManager_CreateAndList(t *testing.T) {
+	manager, mockClock, files := setupTestManager(t, 10, 0)
+	writeFile(t, files[0].Path, `{"max_id":1}`)
+
+	first, err := manager.Create("manual")
+	if err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	if first.ID != "20240615-120000" || first.Reason != "manual" || first.Size() != 12 {
+		t.Errorf("Unexpected snapshot %+v", first)
+	}
+	if first.Files[1].SHA256 != "" {
+		t.Error("Expected a missing file to have no hash")
+	}
+
+	// Snapshots in the same second get a counter
+	second, _ := manager.Create("manual")
+	mockClock.FixedTime = testTime.Add(time.Minute)
+	third, _ := manager.Create("manual")
+	if second.ID != "20240615-120000-2" || third.ID != "20240615-120100" {
+		t.Errorf("Unexpected IDs %s and %s", second.ID, third.ID)
+	}
+
+	// A directory without a manifest is an incomplete snapshot
+	if err := os.MkdirAll(filepath.Join(manager.dir, "20240615-130000"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := manager.List()
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(snapshots) != 3 || snapshots[0].ID != third.ID || snapshots[1].ID != second.ID || snapshots[2].ID != first.ID {
+		t.Errorf("Expected the snapshots newest first, got %+v", snapshots)
+	}
+}
+
+func TestManager_List_NoDirectory(t *testing.T) {
+	manager, _, _ := setupTestManager(t, 10, 0)
+
+	snapshots, err := manager.List()
+	if err != nil || len(snapshots) != 0 {
+		t.Errorf("Expected no snapshots and no error, got %v and %v", snapshots, err)
+	}
+}
+
+func TestManager_Prune(t *testing.T) {
+	manager, mockClock, files := setupTestManager(t, 3, 36*time.Hour)
+	writeFile(t, files[0].Path, "{}")
+
+	for day := range 4 {
+		mockClock.FixedTime = testTime.AddDate(0, 0, day)
+		if _, err := manager.Create("manual"); err != nil {
+			t.Fatalf("Failed to create snapshot: %v", err)
+		}
+	}
+
+	// The count limit drops the first one, and the age limit the one from 2 days ago
+	snapshots, _ := manager.List()
+	if len(snapshots) != 2 || snapshots[0].ID != "20240618-120000" || snapshots[1].ID != "20240617-120000" {
+		t.Errorf("Expected the 2 newest snapshots, got %+v", snapshots)
+	}
+
+	// The newest snapshot survives even when it is too old
+	mockClock.FixedTime = testTime.AddDate(0, 1, 0)
+	if err := manager.prune(""); err != nil {
+		t.Fatalf("Failed to prune: %v", err)
+	}
+	snapshots, _ = manager.List()
+	if len(snapshots) != 1 || snapshots[0].ID != "20240618-120000" {
+		t.Errorf("Expected only the newest snapshot, got %+v", snapshots)
+	}
+}
+
+func TestManager_BeforeWrite(t *testing.T) {
+	manager, _, files := setupTestManager(t, 10, 0)
+
+	// Nothing to back up before the first data file is written
+	if err := manager.BeforeWrite(); err != nil {
+		t.Fatalf("Failed before write: %v", err)
+	}
+	if snapshots, _ := manager.List(); len(snapshots) != 0 {
+		t.Fatalf("Expected no snapshot without data files, got %d", len(snapshots))
+	}
+
+	writeFile(t, files[0].Path, "v1")
+	manager.Arm()
+
+	// Several writes of one command share a snapshot
+	for range 2 {
+		if err := manager.BeforeWrite(); err != nil {
+			t.Fatalf("Failed before write: %v", err)
+		}
+	}
+	snapshots, _ := manager.List()
+	if len(snapshots) != 1 || snapshots[0].Reason != "automatic" {
+		t.Fatalf("Expected one automatic snapshot, got %+v", snapshots)
+	}
+
+	// Unchanged files are not snapshotted again
+	manager.Arm()
+	manager.BeforeWrite()
+	if snapshots, _ := manager.List(); len(snapshots) != 1 {
+		t.Errorf("Expected no snapshot of unchanged files, got %d", len(snapshots))
+	}
+
+	writeFile(t, files[0].Path, "v2")
+	manager.Arm()
+	manager.BeforeWrite()
+	if snapshots, _ := manager.List(); len(snapshots) != 2 {
+		t.Errorf("Expected a snapshot of the changed files, got %d", len(snapshots))
+	}
+}
+
+func TestManager_Restore(t *testing.T) {
+	manager, mockClock, files := setupTestManager(t, 2, 0)
+	writeFile(t, files[0].Path, "old questions")
+
+	// The deltas file did not exist yet, so restoring removes it
+	snapshot, _ := manager.Create("manual")
+	mockClock.FixedTime = testTime.Add(time.Minute)
+	manager.Create("manual")
+	writeFile(t, files[0].Path, "new questions")
+	writeFile(t, files[1].Path, "new deltas")
+	mockClock.FixedTime = testTime.Add(2 * time.Minute)
+
+	restored, before, err := manager.Restore(snapshot.ID)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if restored.ID != snapshot.ID || before == nil || before.Reason != "before restoring "+snapshot.ID {
+		t.Errorf("Unexpected restore result %+v and %+v", restored, before)
+	}
+	if got := readFile(t, files[0].Path); got != "old questions" {
+		t.Errorf("Expected the old questions, got %q", got)
+	}
+	if _, err := os.Stat(files[1].Path); !os.IsNotExist(err) {
+		t.Error("Expected the deltas file to be removed")
+	}
+
+	// The restored snapshot is kept even though it is beyond the retention count
+	snapshots, _ := manager.List()
+	if len(snapshots) != 3 || snapshots[0].ID != before.ID || snapshots[2].ID != snapshot.ID {
+		t.Errorf("Expected the restored snapshot to be kept, got %+v", snapshots)
+	}
+
+	// The restore can be undone
+	if _, _, err := manager.Restore(before.ID); err != nil {
+		t.Fatalf("Failed to undo restore: %v", err)
+	}
+	if got := readFile(t, files[1].Path); got != "new deltas" {
+		t.Errorf("Expected the new deltas back, got %q", got)
+	}
+}
+
+func TestManager_Restore_NotFound(t *testing.T) {
+	manager, _, _ := setupTestManager(t, 10, 0)
+
+	for _, id := range []string{"20240101-000000", "../backups", ""} {
+		if _, _, err := manager.Restore(id); err != ErrSnapshotNotFound {
+			t.Errorf("Expected ErrSnapshotNotFound for %q, got %v", id, err)
+		}
+	}
+}
+
+func TestFileUtil_SnapshotsTrackedFiles(t *testing.T) {
+	manager, _, files := setupTestManager(t, 10, 0)
+	writeFile(t, files[0].Path, `{"max_id":1}`)
+	file := NewFileUtil(fileutil.NewJSONFileUtil())
+
+	// Without a manager writes pass straight through
+	if err := file.Save(map[string]int{"max_id": 2}, files[0].Path); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if snapshots, _ := manager.List(); len(snapshots) != 0 {
+		t.Fatalf("Expected no snapshot before the manager is set, got %d", len(snapshots))
+	}
+
+	file.SetManager(manager)
+	untracked := filepath.Join(filepath.Dir(files[0].Path), "other.json")
+	if err := file.Save(map[string]int{}, untracked); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	if snapshots, _ := manager.List(); len(snapshots) != 0 {
+		t.Fatalf("Expected no snapshot for an untracked file, got %d", len(snapshots))
+	}
+
+	if err := file.Delete(files[0].Path); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	snapshots, _ := manager.List()
+	if len(snapshots) != 1 {
+		t.Fatalf("Expected a snapshot before the delete, got %d", len(snapshots))
+	}
+	backedUp := readFile(t, filepath.Join(manager.dir, snapshots[0].ID, "questions.json"))
+	if backedUp != "{\n  \"max_id\": 2\n}\n" {
+		t.Errorf("Expected the file as it was before the delete, got %q", backedUp)
+	}
+}
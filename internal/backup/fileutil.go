@@ -0,0 +1,48 @@
+package backup
+
+import (
+	"fmt"
+
+	"github.com/eannchen/leetsolv/internal/fileutil"
+)
+
+// FileUtil wraps a fileutil.FileUtil and takes a snapshot before a tracked data file is written
+type FileUtil struct {
+	fileutil.FileUtil
+	manager *Manager
+}
+
+// NewFileUtil wraps file. Writes pass straight through until a manager is set, because the
+// backup settings are only known once the configuration has been loaded through file.
+func NewFileUtil(file fileutil.FileUtil) *FileUtil {
+	return &FileUtil{FileUtil: file}
+}
+
+// SetManager starts taking snapshots with manager
+func (f *FileUtil) SetManager(manager *Manager) {
+	f.manager = manager
+}
+
+func (f *FileUtil) Save(data interface{}, filename string) error {
+	if err := f.beforeWrite(filename); err != nil {
+		return err
+	}
+	return f.FileUtil.Save(data, filename)
+}
+
+func (f *FileUtil) Delete(filename string) error {
+	if err := f.beforeWrite(filename); err != nil {
+		return err
+	}
+	return f.FileUtil.Delete(filename)
+}
+
+func (f *FileUtil) beforeWrite(filename string) error {
+	if f.manager == nil || !f.manager.Tracks(filename) {
+		return nil
+	}
+	if err := f.manager.BeforeWrite(); err != nil {
+		return fmt.Errorf("failed to back up data files before writing: %w", err)
+	}
+	return nil
+}
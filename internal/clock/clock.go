@@ -35,6 +35,37 @@ func (ClockImpl) AddDays(t time.Time, days int) time.Time {
 	return t.AddDate(0, 0, days)
 }
 
+// SimClock is a clock that only moves when told to, for replaying the schedule on future days.
+type SimClock struct {
+	now time.Time
+}
+
+// NewSimClock creates a SimClock starting at the given time.
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start.UTC()}
+}
+
+// AdvanceDays moves the clock forward by the given number of days.
+func (c *SimClock) AdvanceDays(days int) {
+	c.now = c.now.AddDate(0, 0, days)
+}
+
+func (c *SimClock) Now() time.Time {
+	return c.now
+}
+
+func (c *SimClock) Today() time.Time {
+	return time.Date(c.now.Year(), c.now.Month(), c.now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (c *SimClock) ToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (c *SimClock) AddDays(t time.Time, days int) time.Time {
+	return t.AddDate(0, 0, days)
+}
+
 // MockClock implements Clock for testing with a fixed time.
 type MockClock struct {
 	FixedTime time.Time
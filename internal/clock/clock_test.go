@@ -223,3 +223,17 @@ func TestMockClock_Deterministic(t *testing.T) {
 		}
 	}
 }
+
+func TestSimClock_AdvanceDays(t *testing.T) {
+	var _ Clock = NewSimClock(time.Now())
+
+	simClock := NewSimClock(time.Date(2024, 2, 28, 15, 30, 0, 0, time.FixedZone("UTC+8", 8*60*60)))
+	if want := time.Date(2024, 2, 28, 7, 30, 0, 0, time.UTC); !simClock.Now().Equal(want) || simClock.Now().Location() != time.UTC {
+		t.Errorf("Expected the start time in UTC, got %v", simClock.Now())
+	}
+
+	simClock.AdvanceDays(2)
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !simClock.Today().Equal(want) {
+		t.Errorf("Today() = %v after advancing over the leap day, want %v", simClock.Today(), want)
+	}
+}
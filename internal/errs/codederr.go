@@ -1,5 +1,7 @@
 package errs
 
+import "errors"
+
 type ErrorKind string
 
 const (
@@ -45,7 +47,13 @@ func (e *CodedError) Unwrap() error {
 	return e.Err
 }
 
+// WrapInternalError marks err as a system error. An error that already has another kind,
+// such as a business error raised by the storage, is returned unchanged so its message is kept.
 func WrapInternalError(err error, msg string) error {
+	var codedErr *CodedError
+	if errors.As(err, &codedErr) && codedErr.Kind != SystemErrorKind {
+		return err
+	}
 	return &CodedError{
 		Err:          err,
 		Kind:         SystemErrorKind,
@@ -68,3 +76,30 @@ func WrapBusinessError(err error, msg string) error {
 		UserMsg: msg,
 	}
 }
+
+// Process exit codes used in command line mode
+const (
+	ExitOK         = 0 // Command succeeded
+	ExitSystem     = 1 // System/infrastructure error, or an error without a kind
+	ExitValidation = 2 // Invalid input, flag or command
+	ExitBusiness   = 3 // Valid input rejected by business rules (e.g. question not found)
+)
+
+// ExitCode maps an error to the process exit code for its kind
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var codedErr *CodedError
+	if !errors.As(err, &codedErr) {
+		return ExitSystem
+	}
+	switch codedErr.Kind {
+	case ValidationErrorKind:
+		return ExitValidation
+	case BusinessErrorKind:
+		return ExitBusiness
+	default:
+		return ExitSystem
+	}
+}
@@ -2,6 +2,7 @@ package errs
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -263,3 +264,27 @@ func TestCodedError_UserMessage_AllBranches(t *testing.T) {
 		})
 	}
 }
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"nil error", nil, ExitOK},
+		{"validation error", ErrInvalidFamiliarityLevel, ExitValidation},
+		{"business error", ErrQuestionNotFound, ExitBusiness},
+		{"system error", WrapInternalError(errors.New("disk full"), "Failed to save"), ExitSystem},
+		{"wrapped business error", fmt.Errorf("undo: %w", ErrNoActionsToUndo), ExitBusiness},
+		{"business error wrapped as internal", WrapInternalError(ErrDataChanged, "Failed to save"), ExitBusiness},
+		{"plain error", errors.New("boom"), ExitSystem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.expected {
+				t.Errorf("ExitCode() = %d, expected %d", got, tt.expected)
+			}
+		})
+	}
+}
@@ -8,6 +8,15 @@ var (
 	ErrQuestionNotFound     = WrapBusinessError(errors.New("question not found"), "Question not found. Please check the ID or URL")
 	ErrNoQuestionsAvailable = WrapBusinessError(errors.New("no questions available"), "No questions available yet")
 	ErrNoActionsToUndo      = WrapBusinessError(errors.New("no actions to undo"), "No actions to undo")
+	ErrNoActionsToRedo      = WrapBusinessError(errors.New("no actions to redo"), "No actions to redo")
+	ErrDataIssuesFound      = WrapBusinessError(errors.New("data issues found"), "Data problems found. Run 'doctor --repair' to fix them")
+	ErrBackupNotFound       = WrapBusinessError(errors.New("backup not found"), "Backup not found. Run 'backup list' to see the available backups")
+	ErrDataChanged          = WrapBusinessError(errors.New("data file changed by another process"), "Another leetsolv session changed your data, so nothing was saved. The latest data is loaded now; please run the command again")
+	ErrAlreadyPaused        = WrapBusinessError(errors.New("reviews already paused"), "Reviews are already paused. Run 'resume' to continue them")
+	ErrNotPaused            = WrapBusinessError(errors.New("reviews not paused"), "Reviews are not paused")
+	ErrTimerRunning         = WrapBusinessError(errors.New("timer already running"), "A timer is already running. Run 'done' to finish it first")
+	ErrNoTimer              = WrapBusinessError(errors.New("no timer running"), "No timer is running. Run 'start <id|url>' to start one")
+	ErrNotALeech            = WrapBusinessError(errors.New("question is not a leech"), "The question is not a leech. Run 'leeches' to see them")
 )
 
 // Validation errors
@@ -19,6 +28,10 @@ var (
 	ErrInvalidImportanceLevel  = WrapValidationError(errors.New("invalid importance level"), "Please enter an importance level between 1 and 4")
 	ErrInvalidMemoryUseLevel   = WrapValidationError(errors.New("invalid memory use level"), "Please enter a memory use level between 1 and 3")
 	ErrInvalidReviewCount      = WrapValidationError(errors.New("invalid review count"), "Please enter a valid review count")
+	ErrInvalidState            = WrapValidationError(errors.New("invalid state"), "Please enter a state: active, suspended, buried or archived")
+	ErrInvalidSolve            = WrapValidationError(errors.New("invalid solve"), "Please enter positive minutes and attempts, and 1 attempt for a solve that passed on the first submission")
+	ErrInvalidTag              = WrapValidationError(errors.New("invalid tag"), "Tags may only contain letters, digits, '-', '_', '+' and '.'")
 	ErrUnsupportedPlatform     = WrapValidationError(errors.New("unsupported platform"), "Unsupported platform. Supported: LeetCode, HackerRank")
 	ErrInvalidProblemURLFormat = WrapValidationError(errors.New("invalid problem URL format"), "Invalid problem URL format")
+	ErrUnknownCommand          = WrapValidationError(errors.New("unknown command"), "Unknown command. Type 'help' or 'h' for more information")
 )
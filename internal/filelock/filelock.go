@@ -0,0 +1,42 @@
+// Package filelock implements advisory file locks shared between leetsolv processes.
+package filelock
+
+import "os"
+
+// Lock is a held lock on a lock file
+type Lock struct {
+	file *os.File
+}
+
+// Exclusive blocks until no other process holds a lock on path, then locks it.
+// The lock file is created if it does not exist.
+func Exclusive(path string) (*Lock, error) {
+	return acquire(path, true)
+}
+
+// Shared blocks until no other process holds an exclusive lock on path, then locks it.
+// Any number of processes may hold a shared lock at the same time.
+func Shared(path string) (*Lock, error) {
+	return acquire(path, false)
+}
+
+func acquire(path string, exclusive bool) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(file, exclusive); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Lock{file: file}, nil
+}
+
+// Unlock releases the lock. The lock file is left in place for the next process.
+func (l *Lock) Unlock() error {
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
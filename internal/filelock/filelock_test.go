@@ -0,0 +1,85 @@
+package filelock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// acquireAsync tries to take a lock in the background and reports when it has it
+func acquireAsync(t *testing.T, path string, exclusive bool) <-chan *Lock {
+	t.Helper()
+	acquired := make(chan *Lock, 1)
+	go func() {
+		lock, err := acquire(path, exclusive)
+		if err != nil {
+			t.Errorf("Failed to lock: %v", err)
+			close(acquired)
+			return
+		}
+		acquired <- lock
+	}()
+	return acquired
+}
+
+func TestExclusive_BlocksOtherLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	for _, exclusive := range []bool{true, false} {
+		lock, err := Exclusive(path)
+		if err != nil {
+			t.Fatalf("Failed to lock: %v", err)
+		}
+
+		acquired := acquireAsync(t, path, exclusive)
+		select {
+		case <-acquired:
+			t.Fatalf("Expected the lock (exclusive=%t) to wait for the exclusive lock", exclusive)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		if err := lock.Unlock(); err != nil {
+			t.Fatalf("Failed to unlock: %v", err)
+		}
+		select {
+		case other := <-acquired:
+			other.Unlock()
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected the lock (exclusive=%t) once the exclusive lock is released", exclusive)
+		}
+	}
+}
+
+func TestShared_AllowsOtherSharedLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	lock, err := Shared(path)
+	if err != nil {
+		t.Fatalf("Failed to lock: %v", err)
+	}
+
+	select {
+	case other := <-acquireAsync(t, path, false):
+		other.Unlock()
+	case <-time.After(5 * time.Second):
+		lock.Unlock()
+		t.Fatal("Expected a second shared lock right away")
+	}
+
+	acquired := acquireAsync(t, path, true)
+	select {
+	case <-acquired:
+		t.Fatal("Expected the exclusive lock to wait for the shared lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	lock.Unlock()
+	if other := <-acquired; other != nil {
+		other.Unlock()
+	}
+}
+
+func TestExclusive_MissingDirectory(t *testing.T) {
+	if _, err := Exclusive(filepath.Join(t.TempDir(), "missing", "test.lock")); err == nil {
+		t.Error("Expected an error when the lock file cannot be created")
+	}
+}
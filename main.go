@@ -14,7 +14,9 @@ import (
 	"github.com/eannchen/leetsolv/config"
 	"github.com/eannchen/leetsolv/core"
 	"github.com/eannchen/leetsolv/handler"
+	"github.com/eannchen/leetsolv/internal/backup"
 	"github.com/eannchen/leetsolv/internal/clock"
+	"github.com/eannchen/leetsolv/internal/errs"
 	"github.com/eannchen/leetsolv/internal/fileutil"
 	"github.com/eannchen/leetsolv/internal/logger"
 	"github.com/eannchen/leetsolv/storage"
@@ -31,7 +33,8 @@ var (
 func main() {
 	// Setup dependencies once
 	clock := clock.NewClock()
-	fileutil := fileutil.NewJSONFileUtil()
+	// Data files are backed up before they are written, once the backup settings are loaded
+	fileutil := backup.NewFileUtil(fileutil.NewJSONFileUtil())
 	cfg, err := config.NewConfig(fileutil)
 	if err != nil {
 		fmt.Println("Failed to load configuration:", err)
@@ -41,10 +44,30 @@ func main() {
 		fmt.Println("Failed to initialize logger:", err)
 		os.Exit(1)
 	}
-	storage := storage.NewFileStorage(cfg.QuestionsFile, cfg.DeltasFile, fileutil)
-	scheduler := core.NewSM2Scheduler(cfg, clock)
+	backups := backup.NewManager(cfg.BackupDir, []backup.File{
+		{Name: "questions", Path: cfg.QuestionsFile},
+		{Name: "deltas", Path: cfg.DeltasFile},
+		{Name: "redo", Path: cfg.RedoFile},
+		{Name: "reviews", Path: cfg.ReviewsFile},
+		{Name: "settings", Path: cfg.SettingsFile},
+	}, func() (int, time.Duration) {
+		return cfg.BackupKeep, time.Duration(cfg.BackupMaxAgeDays) * 24 * time.Hour
+	}, clock)
+	fileutil.SetManager(backups)
+	storage := storage.NewFileStorage(cfg.QuestionsFile, cfg.DeltasFile, cfg.RedoFile, cfg.ReviewsFile, fileutil, backups)
+	scheduler := core.NewSchedulerWithState(cfg, clock, core.DefaultRand{}, storage)
 	questionUseCase := usecase.NewQuestionUseCase(cfg, storage, scheduler, clock)
-	ioHandler := handler.NewIOHandler(clock)
+
+	// Global output flags may appear anywhere on the command line
+	var ioHandler handler.IOHandler = handler.NewIOHandler(clock)
+	format, args, err := handler.ExtractOutputFormat(os.Args[1:])
+	if err != nil {
+		ioHandler.PrintError(err)
+		os.Exit(errs.ExitCode(err))
+	}
+	if format != handler.FormatText {
+		ioHandler = handler.NewStructuredIOHandler(clock, format)
+	}
 	h := handler.NewHandler(cfg, questionUseCase, ioHandler, Version)
 
 	commandRegistry := command.NewCommandRegistry(h.HandleUnknown)
@@ -69,6 +92,10 @@ func main() {
 	commandRegistry.Register("upsert", upsertCommand)
 	commandRegistry.Register("add", upsertCommand)
 
+	reviewCommand := &command.ReviewCommand{Handler: h}
+	commandRegistry.Register("review", reviewCommand)
+	commandRegistry.Register("rev", reviewCommand)
+
 	deleteCommand := &command.DeleteCommand{Handler: h}
 	commandRegistry.Register("remove", deleteCommand)
 	commandRegistry.Register("rm", deleteCommand)
@@ -79,11 +106,23 @@ func main() {
 	commandRegistry.Register("undo", undoCommand)
 	commandRegistry.Register("back", undoCommand)
 
+	redoCommand := &command.RedoCommand{Handler: h}
+	commandRegistry.Register("redo", redoCommand)
+
 	historyCommand := &command.HistoryCommand{Handler: h}
 	commandRegistry.Register("history", historyCommand)
 	commandRegistry.Register("hist", historyCommand)
 	commandRegistry.Register("log", historyCommand)
 
+	tagsCommand := &command.TagsCommand{Handler: h}
+	commandRegistry.Register("tags", tagsCommand)
+
+	exportCommand := &command.ExportCommand{Handler: h}
+	commandRegistry.Register("export", exportCommand)
+
+	importCommand := &command.ImportCommand{Handler: h}
+	commandRegistry.Register("import", importCommand)
+
 	settingCommand := &command.SettingCommand{Handler: h}
 	commandRegistry.Register("setting", settingCommand)
 	commandRegistry.Register("config", settingCommand)
@@ -101,6 +140,57 @@ func main() {
 	migrateCommand := &command.MigrateCommand{Handler: h}
 	commandRegistry.Register("migrate", migrateCommand)
 
+	simulateCommand := &command.SimulateCommand{Handler: h}
+	commandRegistry.Register("simulate", simulateCommand)
+	commandRegistry.Register("sim", simulateCommand)
+
+	forecastCommand := &command.ForecastCommand{Handler: h}
+	commandRegistry.Register("forecast", forecastCommand)
+	commandRegistry.Register("fc", forecastCommand)
+
+	optimizeCommand := &command.OptimizeCommand{Handler: h}
+	commandRegistry.Register("optimize", optimizeCommand)
+	commandRegistry.Register("opt", optimizeCommand)
+
+	statsCommand := &command.StatsCommand{Handler: h}
+	commandRegistry.Register("stats", statsCommand)
+
+	leechesCommand := &command.LeechesCommand{Handler: h}
+	commandRegistry.Register("leeches", leechesCommand)
+	commandRegistry.Register("leech", leechesCommand)
+
+	suspendCommand := &command.SuspendCommand{Handler: h}
+	commandRegistry.Register("suspend", suspendCommand)
+
+	unsuspendCommand := &command.UnsuspendCommand{Handler: h}
+	commandRegistry.Register("unsuspend", unsuspendCommand)
+
+	buryCommand := &command.BuryCommand{Handler: h}
+	commandRegistry.Register("bury", buryCommand)
+
+	archiveCommand := &command.ArchiveCommand{Handler: h}
+	commandRegistry.Register("archive", archiveCommand)
+	commandRegistry.Register("mastered", archiveCommand)
+
+	startCommand := &command.StartCommand{Handler: h}
+	commandRegistry.Register("start", startCommand)
+
+	doneCommand := &command.DoneCommand{Handler: h}
+	commandRegistry.Register("done", doneCommand)
+
+	pauseCommand := &command.PauseCommand{Handler: h}
+	commandRegistry.Register("pause", pauseCommand)
+
+	resumeCommand := &command.ResumeCommand{Handler: h}
+	commandRegistry.Register("resume", resumeCommand)
+
+	backupCommand := &command.BackupCommand{Handler: h}
+	commandRegistry.Register("backup", backupCommand)
+
+	doctorCommand := &command.DoctorCommand{Handler: h}
+	commandRegistry.Register("doctor", doctorCommand)
+	commandRegistry.Register("fsck", doctorCommand)
+
 	resetCommand := &command.ResetCommand{Handler: h}
 	commandRegistry.Register("reset", resetCommand)
 
@@ -116,10 +206,10 @@ func main() {
 	scanner := bufio.NewScanner(os.Stdin)
 
 	// --- CLI argument mode ---
-	if len(os.Args) > 1 {
-		// Combine all arguments into a single command string
-		commandRegistry.Execute(scanner, os.Args[1], os.Args[2:])
-		os.Exit(0)
+	if len(args) > 0 {
+		// Errors are already reported by the handler; the exit code tells scripts what kind of error it was
+		_, err := commandRegistry.Execute(scanner, args[0], args[1:])
+		os.Exit(errs.ExitCode(err))
 	}
 
 	// --- Interactive mode ---
@@ -155,13 +245,23 @@ func main() {
 				continue
 			}
 
-			// Parse command and arguments
+			// Parse command and arguments; global output flags apply to this command only
 			parts := strings.Fields(input)
 			cmd := parts[0]
-			args := parts[1:]
+			format, args, err := handler.ExtractOutputFormat(parts[1:])
+			if err != nil {
+				ioHandler.PrintError(err)
+				continue
+			}
+			h.IO = ioHandler
+			if format != handler.FormatText {
+				h.IO = handler.NewStructuredIOHandler(clock, format)
+			}
 
-			// Execute command
-			if quit := commandRegistry.Execute(scanner, cmd, args); quit {
+			// Execute command; its first write takes a fresh backup
+			backups.Arm()
+			// Errors are already reported to the user by the handler
+			if quit, _ := commandRegistry.Execute(scanner, cmd, args); quit {
 				return
 			}
 		}
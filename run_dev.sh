@@ -5,16 +5,22 @@
 
 export LEETSOLV_QUESTIONS_FILE="questions.dev.json"
 export LEETSOLV_DELTAS_FILE="deltas.dev.json"
+export LEETSOLV_REDO_FILE="redo.dev.json"
+export LEETSOLV_REVIEWS_FILE="reviews.dev.json"
 export LEETSOLV_INFO_LOG_FILE="info.dev.log"
 export LEETSOLV_ERROR_LOG_FILE="error.dev.log"
 export LEETSOLV_SETTINGS_FILE="settings.dev.json"
+export LEETSOLV_BACKUP_DIR="backups.dev"
 
 echo "Running leetsolv in DEVELOPMENT mode with files:"
 echo "  Questions: $LEETSOLV_QUESTIONS_FILE"
 echo "  Deltas: $LEETSOLV_DELTAS_FILE"
+echo "  Redo: $LEETSOLV_REDO_FILE"
+echo "  Reviews: $LEETSOLV_REVIEWS_FILE"
 echo "  Info Log: $LEETSOLV_INFO_LOG_FILE"
 echo "  Error Log: $LEETSOLV_ERROR_LOG_FILE"
 echo "  Settings: $LEETSOLV_SETTINGS_FILE"
+echo "  Backups: $LEETSOLV_BACKUP_DIR"
 echo ""
 
 # Run the application with any provided arguments
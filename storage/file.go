@@ -2,53 +2,259 @@
 package storage
 
 import (
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"time"
+
 	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/backup"
 	"github.com/eannchen/leetsolv/internal/fileutil"
 	"github.com/eannchen/leetsolv/internal/search"
+	"github.com/eannchen/leetsolv/internal/tokenizer"
+	"github.com/eannchen/leetsolv/internal/urlparser"
 )
 
+// QuestionStoreVersion is the current format of the questions file.
+// Version 1 files (no version field) also persisted the search indices;
+// from version 2 on they are rebuilt from the questions on load.
+const QuestionStoreVersion = 2
+
 type Storage interface {
 	LoadQuestionStore() (*QuestionStore, error)
 	SaveQuestionStore(*QuestionStore) error
 	LoadDeltas() ([]core.Delta, error)
 	SaveDeltas([]core.Delta) error
+	LoadRedoDeltas() ([]core.Delta, error)
+	SaveRedoDeltas([]core.Delta) error
+	LoadReviewEvents() ([]core.ReviewEvent, error)
+	AppendReviewEvent(core.ReviewEvent) error
+	MarkReviewEvents(deltas []core.Delta, reverted bool) error
+	SaveReviewEvents([]core.ReviewEvent) error
 	DeleteAllData() error
+	CreateBackup(reason string) (*backup.Snapshot, error)
+	ListBackups() ([]backup.Snapshot, error)
+	RestoreBackup(id string) (restored *backup.Snapshot, before *backup.Snapshot, err error)
+	Lock() (unlock func(), err error)
 }
 
-func NewFileStorage(questionsFileName, deltasFileName string, file fileutil.FileUtil) *FileStorage {
+// NewFileStorage creates a storage for the given data files. Processes sharing the data files
+// coordinate through a lock file next to the questions file.
+func NewFileStorage(questionsFileName, deltasFileName, redoFileName, reviewsFileName string, file fileutil.FileUtil, backups *backup.Manager) *FileStorage {
 	return &FileStorage{
 		questionsFileName: questionsFileName,
 		deltasFileName:    deltasFileName,
+		redoFileName:      redoFileName,
+		reviewsFileName:   reviewsFileName,
+		lockFileName:      filepath.Join(filepath.Dir(questionsFileName), LockFileName),
 		file:              file,
+		backups:           backups,
 	}
 }
 
 type QuestionStore struct {
+	Version   int                    `json:"version"`
 	MaxID     int                    `json:"max_id"`
 	Questions map[int]*core.Question `json:"questions"`
-	URLIndex  map[string]int         `json:"url_index"`
-	URLTrie   *search.Trie           `json:"url_trie"`
-	NoteTrie  *search.Trie           `json:"note_trie"`
+	Pauses    []core.Pause           `json:"pauses,omitempty"` // Oldest first; only the last may be ongoing
+	Timer     *core.Timer            `json:"timer,omitempty"`  // Running solve timer, if any
+
+	// In-memory indices, rebuilt from Questions on load
+	URLIndex map[string]int   `json:"-"`
+	URLTrie  *search.Trie     `json:"-"`
+	NoteTrie *search.Trie     `json:"-"`
+	TagIndex map[string][]int `json:"-"`
+}
+
+// RebuildIndexes recreates the URL index, search tries and tag index from the questions
+func (s *QuestionStore) RebuildIndexes() {
+	s.URLIndex = make(map[string]int, len(s.Questions))
+	s.URLTrie = search.NewTrie(3)
+	s.NoteTrie = search.NewTrie(3)
+	s.TagIndex = make(map[string][]int)
+	// Index in ID order so that the result does not depend on map iteration
+	for _, id := range slices.Sorted(maps.Keys(s.Questions)) {
+		s.IndexQuestion(s.Questions[id])
+	}
+}
+
+// StaleIndexes returns the names of the in-memory indices that differ from a rebuild from the questions
+func (s *QuestionStore) StaleIndexes() []string {
+	fresh := &QuestionStore{Questions: s.Questions}
+	fresh.RebuildIndexes()
+
+	var stale []string
+	if !maps.Equal(s.URLIndex, fresh.URLIndex) {
+		stale = append(stale, "URL index")
+	}
+	if !reflect.DeepEqual(s.URLTrie, fresh.URLTrie) {
+		stale = append(stale, "URL search index")
+	}
+	if !reflect.DeepEqual(s.NoteTrie, fresh.NoteTrie) {
+		stale = append(stale, "note search index")
+	}
+	if !maps.EqualFunc(s.TagIndex, fresh.TagIndex, func(a, b []int) bool {
+		return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+	}) {
+		stale = append(stale, "tag index")
+	}
+	return stale
+}
+
+// IndexQuestion adds the question to the URL index, search tries and tag index
+func (s *QuestionStore) IndexQuestion(q *core.Question) {
+	s.URLIndex[q.URL] = q.ID
+	for _, word := range urlTokens(q.URL) {
+		s.URLTrie.Insert(word, q.ID)
+	}
+	for _, word := range tokenizer.Tokenize(q.Note) {
+		s.NoteTrie.Insert(word, q.ID)
+	}
+	s.IndexTags(q.ID, q.Tags)
+}
+
+// UnindexQuestion removes the question from everything IndexQuestion added it to
+func (s *QuestionStore) UnindexQuestion(q *core.Question) {
+	if s.URLIndex[q.URL] == q.ID {
+		delete(s.URLIndex, q.URL)
+	}
+	for _, word := range urlTokens(q.URL) {
+		s.URLTrie.Delete(word, q.ID)
+	}
+	for _, word := range tokenizer.Tokenize(q.Note) {
+		s.NoteTrie.Delete(word, q.ID)
+	}
+	s.UnindexTags(q.ID, q.Tags)
+}
+
+// urlTokens returns the searchable words of a question URL: those of its problem slug,
+// or of the whole URL when it cannot be parsed (e.g. a hand-edited file)
+func urlTokens(rawURL string) []string {
+	if parsed, err := urlparser.Parse(rawURL); err == nil {
+		return tokenizer.Tokenize(parsed.ProblemSlug)
+	}
+	return tokenizer.Tokenize(rawURL)
+}
+
+// IndexTags adds the question ID under each of its tags.
+func (s *QuestionStore) IndexTags(id int, tags []string) {
+	for _, tag := range tags {
+		if !slices.Contains(s.TagIndex[tag], id) {
+			s.TagIndex[tag] = append(s.TagIndex[tag], id)
+		}
+	}
+}
+
+// UnindexTags removes the question ID from each of its tags, dropping tags that become empty.
+func (s *QuestionStore) UnindexTags(id int, tags []string) {
+	for _, tag := range tags {
+		ids := slices.DeleteFunc(s.TagIndex[tag], func(indexed int) bool { return indexed == id })
+		if len(ids) == 0 {
+			delete(s.TagIndex, tag)
+		} else {
+			s.TagIndex[tag] = ids
+		}
+	}
+}
+
+// DueCounts returns how many questions are due on each of the days from start on, one count
+// per day, leaving out the question with excludeID. Questions due before start, and suspended and
+// archived questions, are not counted; buried questions count on the day their burial ends if later.
+func (s *QuestionStore) DueCounts(start time.Time, days int, excludeID int) []int {
+	counts := make([]int, days)
+	for id, q := range s.Questions {
+		if id == excludeID || !q.IsScheduled() {
+			continue
+		}
+		next := q.ReviewDate().UTC()
+		date := time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, time.UTC)
+		if offset := int(date.Sub(start).Hours() / 24); !date.Before(start) && offset < days {
+			counts[offset]++
+		}
+	}
+	return counts
+}
+
+// PausedSince returns the day reviews were paused on, if they are paused
+func (s *QuestionStore) PausedSince() (time.Time, bool) {
+	if len(s.Pauses) == 0 || !s.Pauses[len(s.Pauses)-1].End.IsZero() {
+		return time.Time{}, false
+	}
+	return s.Pauses[len(s.Pauses)-1].Start, true
 }
 
 type FileStorage struct {
 	questionsFileName  string
 	deltasFileName     string
+	redoFileName       string
+	reviewsFileName    string
+	lockFileName       string
 	file               fileutil.FileUtil
+	backups            *backup.Manager
 	questionStoreCache *QuestionStore
 	deltasCache        []core.Delta
+	redoCache          []core.Delta
+	reviewsCache       []core.ReviewEvent
+	locked             bool // Whether Lock holds the lock file
+
+	// Stamps of the files as this process last loaded or saved them. They outlive the
+	// cache, so a save can tell whether another process wrote the file in between.
+	questionsStamp fileStamp
+	deltasStamp    fileStamp
+	redoStamp      fileStamp
+	reviewsStamp   fileStamp
 }
 
 func (fs *FileStorage) LoadQuestionStore() (*QuestionStore, error) {
+	var store *QuestionStore
+	err := fs.withLock(false, func() (err error) {
+		store, err = fs.loadQuestionStore()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert a file from before the current format, dropping the persisted indices
+	if store.Version < QuestionStoreVersion && (store.MaxID > 0 || len(store.Questions) > 0) {
+		if err := fs.SaveQuestionStore(store); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// DueCounts implements core.ScheduleState over the stored questions. When they cannot be loaded,
+// every day counts as empty and the scheduler leaves the interval as it is.
+func (fs *FileStorage) DueCounts(start time.Time, days int, excludeID int) []int {
+	store, err := fs.LoadQuestionStore()
+	if err != nil {
+		return make([]int, days)
+	}
+	return store.DueCounts(start, days, excludeID)
+}
+
+// PausedSince implements core.ScheduleState over the stored pauses. When they cannot be loaded,
+// reviews count as not paused.
+func (fs *FileStorage) PausedSince() (time.Time, bool) {
+	store, err := fs.LoadQuestionStore()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return store.PausedSince()
+}
+
+func (fs *FileStorage) loadQuestionStore() (*QuestionStore, error) {
 	// Return from cache if available
-	if fs.questionStoreCache != nil {
+	if fs.questionStoreCache != nil && fs.isCurrent(fs.questionsFileName, &fs.questionsStamp) {
 		return fs.questionStoreCache, nil
 	}
 
 	// Load question store from file
 	var store QuestionStore
-	err := fs.file.Load(&store, fs.questionsFileName)
-	if err != nil {
+	if err := fs.loadFile(&store, fs.questionsFileName, &fs.questionsStamp); err != nil {
 		return nil, err
 	}
 
@@ -56,19 +262,9 @@ func (fs *FileStorage) LoadQuestionStore() (*QuestionStore, error) {
 	if store.Questions == nil {
 		store.Questions = make(map[int]*core.Question)
 	}
-	if store.URLIndex == nil {
-		store.URLIndex = make(map[string]int)
-	}
-	if store.URLTrie == nil {
-		store.URLTrie = search.NewTrie(3)
-	}
-	if store.NoteTrie == nil {
-		store.NoteTrie = search.NewTrie(3)
-	}
 
-	// Hydrate the trie nodes
-	store.URLTrie.Hydrate()
-	store.NoteTrie.Hydrate()
+	// Build the search indices in memory
+	store.RebuildIndexes()
 
 	// Update cache
 	fs.questionStoreCache = &store
@@ -77,69 +273,289 @@ func (fs *FileStorage) LoadQuestionStore() (*QuestionStore, error) {
 }
 
 func (fs *FileStorage) SaveQuestionStore(store *QuestionStore) error {
-	err := fs.file.Save(store, fs.questionsFileName)
-	if err != nil {
-		return err
-	}
+	return fs.withLock(true, func() error {
+		store.Version = QuestionStoreVersion
+		if err := fs.saveFile(store, fs.questionsFileName, &fs.questionsStamp); err != nil {
+			return err
+		}
 
-	// Update cache after successful save
-	fs.questionStoreCache = store
+		// Update cache after successful save
+		fs.questionStoreCache = store
 
-	return nil
+		return nil
+	})
 }
 
 func (fs *FileStorage) LoadDeltas() ([]core.Delta, error) {
-	// Return from cache if available
-	if fs.deltasCache != nil {
-		return fs.deltasCache, nil
+	var deltas []core.Delta
+	err := fs.withLock(false, func() error {
+		// Return from cache if available
+		if fs.deltasCache != nil && fs.isCurrent(fs.deltasFileName, &fs.deltasStamp) {
+			deltas = fs.deltasCache
+			return nil
+		}
+
+		// Load deltas from file
+		if err := fs.loadFile(&deltas, fs.deltasFileName, &fs.deltasStamp); err != nil {
+			return err
+		}
+
+		// Update cache
+		fs.deltasCache = deltas
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return deltas, nil
+}
 
-	// Load deltas from file
+func (fs *FileStorage) SaveDeltas(deltas []core.Delta) error {
+	return fs.withLock(true, func() error {
+		if err := fs.saveFile(deltas, fs.deltasFileName, &fs.deltasStamp); err != nil {
+			return err
+		}
+
+		// Update cache after successful save
+		fs.deltasCache = deltas
+
+		return nil
+	})
+}
+
+// LoadRedoDeltas returns the undone actions that can be redone, the next one to redo last
+func (fs *FileStorage) LoadRedoDeltas() ([]core.Delta, error) {
 	var deltas []core.Delta
-	err := fs.file.Load(&deltas, fs.deltasFileName)
+	err := fs.withLock(false, func() error {
+		// Return from cache if available
+		if fs.redoCache != nil && fs.isCurrent(fs.redoFileName, &fs.redoStamp) {
+			deltas = fs.redoCache
+			return nil
+		}
+
+		// Load redo deltas from file
+		if err := fs.loadFile(&deltas, fs.redoFileName, &fs.redoStamp); err != nil {
+			return err
+		}
+
+		// Update cache
+		fs.redoCache = deltas
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return deltas, nil
+}
 
-	// Update cache
-	fs.deltasCache = deltas
+func (fs *FileStorage) SaveRedoDeltas(deltas []core.Delta) error {
+	return fs.withLock(true, func() error {
+		if err := fs.saveFile(deltas, fs.redoFileName, &fs.redoStamp); err != nil {
+			return err
+		}
 
-	return deltas, nil
+		// Update cache after successful save
+		fs.redoCache = deltas
+
+		return nil
+	})
 }
 
-func (fs *FileStorage) SaveDeltas(deltas []core.Delta) error {
-	err := fs.file.Save(deltas, fs.deltasFileName)
-	if err != nil {
+func (fs *FileStorage) LoadReviewEvents() ([]core.ReviewEvent, error) {
+	var events []core.ReviewEvent
+	err := fs.withLock(false, func() (err error) {
+		events, err = fs.loadReviewEvents()
 		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (fs *FileStorage) loadReviewEvents() ([]core.ReviewEvent, error) {
+	// Return from cache if available
+	if fs.reviewsCache != nil && fs.isCurrent(fs.reviewsFileName, &fs.reviewsStamp) {
+		return fs.reviewsCache, nil
+	}
+
+	// Load review events from file
+	var events []core.ReviewEvent
+	if err := fs.loadFile(&events, fs.reviewsFileName, &fs.reviewsStamp); err != nil {
+		return nil, err
 	}
 
-	// Update cache after successful save
-	fs.deltasCache = deltas
+	// Update cache
+	fs.reviewsCache = events
+
+	return events, nil
+}
+
+// AppendReviewEvent adds an event to the end of the review log.
+// The review log is never truncated, so events appended by another process are kept.
+func (fs *FileStorage) AppendReviewEvent(event core.ReviewEvent) error {
+	return fs.withLock(true, func() error {
+		// Loading under the write lock picks up events other processes appended
+		events, err := fs.loadReviewEvents()
+		if err != nil {
+			return err
+		}
 
-	return nil
+		// Copy before appending so a failed save leaves the cache untouched
+		updated := make([]core.ReviewEvent, len(events), len(events)+1)
+		copy(updated, events)
+		updated = append(updated, event)
+
+		if err := fs.saveFile(updated, fs.reviewsFileName, &fs.reviewsStamp); err != nil {
+			return err
+		}
+
+		// Update cache after successful save
+		fs.reviewsCache = updated
+
+		return nil
+	})
+}
+
+// MarkReviewEvents marks the review events the deltas recorded as reverted, or restores them when
+// reverted is false. The deltas are taken in the order given: each marks the newest of its events
+// not yet marked and restores the oldest of its events marked, so events logged at the same time
+// follow their deltas through undo and redo. Deltas that recorded no review are skipped.
+func (fs *FileStorage) MarkReviewEvents(deltas []core.Delta, reverted bool) error {
+	return fs.withLock(true, func() error {
+		// Loading under the write lock picks up events other processes appended
+		events, err := fs.loadReviewEvents()
+		if err != nil {
+			return err
+		}
+
+		// Copy so a failed save leaves the cache untouched
+		updated := slices.Clone(events)
+		changed := false
+		for _, delta := range deltas {
+			recorded := func(event core.ReviewEvent) bool {
+				return event.Reverted != reverted && event.RecordedBy(delta)
+			}
+			i := slices.IndexFunc(updated, recorded)
+			if reverted {
+				i = lastIndexFunc(updated, recorded)
+			}
+			if i >= 0 {
+				updated[i].Reverted = reverted
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+
+		if err := fs.saveFile(updated, fs.reviewsFileName, &fs.reviewsStamp); err != nil {
+			return err
+		}
+
+		// Update cache after successful save
+		fs.reviewsCache = updated
+
+		return nil
+	})
+}
+
+// SaveReviewEvents replaces the whole review log. It is meant for rewriting the events already
+// logged, such as converting their timestamps, by a caller holding the lock from Lock.
+func (fs *FileStorage) SaveReviewEvents(events []core.ReviewEvent) error {
+	return fs.withLock(true, func() error {
+		if err := fs.saveFile(events, fs.reviewsFileName, &fs.reviewsStamp); err != nil {
+			return err
+		}
+
+		// Update cache after successful save
+		fs.reviewsCache = events
+
+		return nil
+	})
+}
+
+// lastIndexFunc returns the index of the last element satisfying f, or -1 if none do
+func lastIndexFunc[E any](s []E, f func(E) bool) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if f(s[i]) {
+			return i
+		}
+	}
+	return -1
 }
 
 // InvalidateCache clears the cache, forcing next load to read from file
 func (fs *FileStorage) InvalidateCache() {
 	fs.questionStoreCache = nil
 	fs.deltasCache = nil
+	fs.redoCache = nil
+	fs.reviewsCache = nil
 }
 
-// DeleteAllData deletes both questions and deltas files, and invalidates cache
-func (fs *FileStorage) DeleteAllData() error {
-	// Delete questions file
-	if err := fs.file.Delete(fs.questionsFileName); err != nil {
+// CreateBackup takes a snapshot of the data files
+func (fs *FileStorage) CreateBackup(reason string) (*backup.Snapshot, error) {
+	var snapshot *backup.Snapshot
+	err := fs.withLock(false, func() (err error) {
+		snapshot, err = fs.backups.Create(reason)
 		return err
-	}
+	})
+	return snapshot, err
+}
 
-	// Delete deltas file
-	if err := fs.file.Delete(fs.deltasFileName); err != nil {
-		return err
+// ListBackups returns the snapshots of the data files, newest first
+func (fs *FileStorage) ListBackups() ([]backup.Snapshot, error) {
+	return fs.backups.List()
+}
+
+// RestoreBackup replaces the data files with a snapshot and invalidates the cache.
+// The current files are snapshotted first; see backup.Manager.Restore.
+func (fs *FileStorage) RestoreBackup(id string) (restored *backup.Snapshot, before *backup.Snapshot, err error) {
+	err = fs.withLock(true, func() error {
+		restored, before, err = fs.backups.Restore(id)
+		if err != nil {
+			return err
+		}
+
+		// Invalidate cache
+		fs.InvalidateCache()
+
+		return fs.restamp()
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	return restored, before, nil
+}
 
-	// Invalidate cache
-	fs.questionStoreCache = nil
-	fs.deltasCache = nil
+// DeleteAllData deletes the questions, deltas, redo and review log files, and invalidates cache
+func (fs *FileStorage) DeleteAllData() error {
+	return fs.withLock(true, func() error {
+		// Delete questions file
+		if err := fs.file.Delete(fs.questionsFileName); err != nil {
+			return err
+		}
+
+		// Delete deltas file
+		if err := fs.file.Delete(fs.deltasFileName); err != nil {
+			return err
+		}
+
+		// Delete redo file
+		if err := fs.file.Delete(fs.redoFileName); err != nil {
+			return err
+		}
+
+		// Delete review log file
+		if err := fs.file.Delete(fs.reviewsFileName); err != nil {
+			return err
+		}
+
+		// Invalidate cache
+		fs.InvalidateCache()
 
-	return nil
+		return fs.restamp()
+	})
 }
@@ -8,6 +8,9 @@ import (
 
 	"github.com/eannchen/leetsolv/config"
 	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/backup"
+	"github.com/eannchen/leetsolv/internal/clock"
+	"github.com/eannchen/leetsolv/internal/errs"
 	"github.com/eannchen/leetsolv/internal/fileutil"
 	"github.com/eannchen/leetsolv/internal/search"
 )
@@ -19,7 +22,13 @@ var testTime = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
 func setupTestStorage(t *testing.T) (*FileStorage, *config.TestConfig) {
 	testConfig, _ := config.MockEnv(t)
 	fileUtil := fileutil.NewJSONFileUtil()
-	storage := NewFileStorage(testConfig.QuestionsFile, testConfig.DeltasFile, fileUtil)
+	backups := backup.NewManager(testConfig.BackupDir, []backup.File{
+		{Name: "questions", Path: testConfig.QuestionsFile},
+		{Name: "deltas", Path: testConfig.DeltasFile},
+		{Name: "redo", Path: testConfig.RedoFile},
+		{Name: "reviews", Path: testConfig.ReviewsFile},
+	}, func() (int, time.Duration) { return 20, 0 }, clock.NewMockClock(testTime))
+	storage := NewFileStorage(testConfig.QuestionsFile, testConfig.DeltasFile, testConfig.RedoFile, testConfig.ReviewsFile, fileUtil, backups)
 	return storage, testConfig
 }
 
@@ -67,6 +76,9 @@ func TestFileStorage_LoadQuestionStore_EmptyFile(t *testing.T) {
 	if store.NoteTrie == nil {
 		t.Error("Expected NoteTrie to be initialized")
 	}
+	if store.TagIndex == nil {
+		t.Error("Expected TagIndex to be initialized")
+	}
 }
 
 func TestFileStorage_SaveAndLoadQuestionStore(t *testing.T) {
@@ -337,7 +349,7 @@ func TestFileStorage_FilePermissionIssues(t *testing.T) {
 
 	// Test with a directory that doesn't exist (more reliable than read-only permissions)
 	nonExistentDir := "/non/existent/directory"
-	storageWithBadPath := NewFileStorage(nonExistentDir+"/questions.json", testConfig.DeltasFile, fileutil.NewJSONFileUtil())
+	storageWithBadPath := NewFileStorage(nonExistentDir+"/questions.json", testConfig.DeltasFile, testConfig.RedoFile, testConfig.ReviewsFile, fileutil.NewJSONFileUtil(), nil)
 
 	store := &QuestionStore{MaxID: 2}
 	err := storageWithBadPath.SaveQuestionStore(store)
@@ -813,6 +825,9 @@ func TestFileStorage_DeleteAllData(t *testing.T) {
 	if err := storage.SaveDeltas(deltas); err != nil {
 		t.Fatalf("Failed to save deltas: %v", err)
 	}
+	if err := storage.SaveRedoDeltas(deltas); err != nil {
+		t.Fatalf("Failed to save redo deltas: %v", err)
+	}
 
 	// Verify data exists
 	loadedStore, err := storage.LoadQuestionStore()
@@ -852,4 +867,501 @@ func TestFileStorage_DeleteAllData(t *testing.T) {
 	if len(loadedDeltas) != 0 {
 		t.Errorf("Expected 0 deltas after delete, got %d", len(loadedDeltas))
 	}
+
+	redoDeltas, err := storage.LoadRedoDeltas()
+	if err != nil {
+		t.Fatalf("Failed to load redo deltas after delete: %v", err)
+	}
+	if len(redoDeltas) != 0 {
+		t.Errorf("Expected 0 redo deltas after delete, got %d", len(redoDeltas))
+	}
+}
+
+func TestFileStorage_AppendAndLoadReviewEvents(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	// Test loading from empty file
+	events, err := storage.LoadReviewEvents()
+	if err != nil {
+		t.Fatalf("Expected no error loading empty review log, got %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected empty review log, got %d events", len(events))
+	}
+
+	// Append more events than MaxDelta would allow to verify nothing is truncated
+	for i := 0; i < 30; i++ {
+		event := core.ReviewEvent{
+			QuestionID:      1,
+			Familiarity:     core.Medium,
+			EaseFactorAfter: 2.0,
+			IntervalDays:    i + 1,
+			ReviewedAt:      testTime.AddDate(0, 0, i),
+		}
+		if err := storage.AppendReviewEvent(event); err != nil {
+			t.Fatalf("Failed to append review event: %v", err)
+		}
+	}
+
+	// Load from file, bypassing the cache
+	storage.InvalidateCache()
+	events, err = storage.LoadReviewEvents()
+	if err != nil {
+		t.Fatalf("Failed to load review events: %v", err)
+	}
+	if len(events) != 30 {
+		t.Fatalf("Expected 30 review events, got %d", len(events))
+	}
+	if events[0].IntervalDays != 1 || events[29].IntervalDays != 30 {
+		t.Errorf("Expected review events in append order, got first=%d last=%d", events[0].IntervalDays, events[29].IntervalDays)
+	}
+
+	// DeleteAllData should also remove the review log
+	if err := storage.DeleteAllData(); err != nil {
+		t.Fatalf("Failed to delete all data: %v", err)
+	}
+	events, err = storage.LoadReviewEvents()
+	if err != nil {
+		t.Fatalf("Failed to load review events after delete: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected 0 review events after delete, got %d", len(events))
+	}
+}
+
+func TestQuestionStore_IndexAndUnindexTags(t *testing.T) {
+	store := &QuestionStore{TagIndex: make(map[string][]int)}
+
+	store.IndexTags(1, []string{"dp", "graph"})
+	store.IndexTags(2, []string{"dp"})
+	store.IndexTags(2, []string{"dp"}) // indexing twice is a no-op
+
+	if got := store.TagIndex["dp"]; len(got) != 2 {
+		t.Errorf("Expected 2 questions tagged dp, got %v", got)
+	}
+
+	store.UnindexTags(1, []string{"dp", "graph"})
+
+	if got := store.TagIndex["dp"]; len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected only question 2 tagged dp, got %v", got)
+	}
+	if _, ok := store.TagIndex["graph"]; ok {
+		t.Error("Expected empty tag graph to be removed from the index")
+	}
+}
+
+func TestQuestionStore_IndexAndUnindexQuestion(t *testing.T) {
+	store := &QuestionStore{Questions: make(map[int]*core.Question)}
+	store.RebuildIndexes()
+
+	q := createTestQuestion(1, "https://leetcode.com/problems/two-sum/")
+	q.Tags = []string{"array"}
+	store.IndexQuestion(q)
+
+	if store.URLIndex[q.URL] != 1 || len(store.TagIndex["array"]) != 1 {
+		t.Fatalf("Expected the question to be indexed, got URL index %v and tag index %v", store.URLIndex, store.TagIndex)
+	}
+	if got := store.URLTrie.SearchPrefix("two"); len(got) != 1 {
+		t.Errorf("Expected the problem slug to be searchable, got %v", got)
+	}
+	if got := store.URLTrie.SearchPrefix("leetcode"); len(got) != 0 {
+		t.Errorf("Expected only the problem slug to be indexed, got %v", got)
+	}
+
+	store.UnindexQuestion(q)
+
+	if len(store.URLIndex) != 0 || len(store.TagIndex) != 0 {
+		t.Errorf("Expected empty indices, got URL index %v and tag index %v", store.URLIndex, store.TagIndex)
+	}
+	if got := store.URLTrie.SearchPrefix("two"); len(got) != 0 {
+		t.Errorf("Expected the slug to be removed from the URL trie, got %v", got)
+	}
+	if got := store.NoteTrie.SearchPrefix("test"); len(got) != 0 {
+		t.Errorf("Expected the note to be removed from the note trie, got %v", got)
+	}
+}
+
+func TestFileStorage_SaveQuestionStore_OmitsIndexes(t *testing.T) {
+	storage, testConfig := setupTestStorage(t)
+
+	store := &QuestionStore{
+		MaxID:     1,
+		Questions: map[int]*core.Question{1: createTestQuestion(1, "https://leetcode.com/problems/two-sum/")},
+	}
+	store.RebuildIndexes()
+	if err := storage.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Failed to save store: %v", err)
+	}
+
+	data, err := os.ReadFile(testConfig.QuestionsFile)
+	if err != nil {
+		t.Fatalf("Failed to read questions file: %v", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Failed to parse questions file: %v", err)
+	}
+	for _, key := range []string{"url_index", "url_trie", "note_trie", "tag_index"} {
+		if _, ok := raw[key]; ok {
+			t.Errorf("Expected %s not to be persisted", key)
+		}
+	}
+	if string(raw["version"]) != "2" {
+		t.Errorf("Expected version 2, got %s", raw["version"])
+	}
+
+	// The indices are rebuilt on load
+	storage.InvalidateCache()
+	loaded, err := storage.LoadQuestionStore()
+	if err != nil {
+		t.Fatalf("Failed to load store: %v", err)
+	}
+	if loaded.URLIndex["https://leetcode.com/problems/two-sum/"] != 1 {
+		t.Errorf("Expected the URL index to be rebuilt, got %v", loaded.URLIndex)
+	}
+	if got := loaded.URLTrie.SearchPrefix("sum"); len(got) != 1 {
+		t.Errorf("Expected the URL trie to be rebuilt, got %v", got)
+	}
+	if got := loaded.NoteTrie.SearchPrefix("question"); len(got) != 1 {
+		t.Errorf("Expected the note trie to be rebuilt, got %v", got)
+	}
+}
+
+func TestFileStorage_LoadQuestionStore_MigratesLegacyFile(t *testing.T) {
+	storage, testConfig := setupTestStorage(t)
+
+	// A version 1 file with persisted indices that no longer match the questions
+	legacy := `{
+		"max_id": 2,
+		"questions": {"2": {"id": 2, "url": "https://leetcode.com/problems/3sum/", "note": "two pointers", "tags": ["array"]}},
+		"url_index": {"https://leetcode.com/problems/two-sum/": 1},
+		"url_trie": {"root": {"children": {}}},
+		"note_trie": {"root": {"children": {}}},
+		"tag_index": {"hash": [1]}
+	}`
+	if err := os.WriteFile(testConfig.QuestionsFile, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy file: %v", err)
+	}
+
+	store, err := storage.LoadQuestionStore()
+	if err != nil {
+		t.Fatalf("Failed to load legacy file: %v", err)
+	}
+	if store.MaxID != 2 || len(store.Questions) != 1 {
+		t.Fatalf("Expected the questions to be kept, got MaxID %d and %d questions", store.MaxID, len(store.Questions))
+	}
+	if _, ok := store.URLIndex["https://leetcode.com/problems/two-sum/"]; ok || store.URLIndex["https://leetcode.com/problems/3sum/"] != 2 {
+		t.Errorf("Expected the URL index to be rebuilt from the questions, got %v", store.URLIndex)
+	}
+	if _, ok := store.TagIndex["hash"]; ok || len(store.TagIndex["array"]) != 1 {
+		t.Errorf("Expected the tag index to be rebuilt from the questions, got %v", store.TagIndex)
+	}
+	if got := store.NoteTrie.SearchPrefix("pointer"); len(got) != 1 {
+		t.Errorf("Expected the note trie to be rebuilt, got %v", got)
+	}
+
+	// The file is converted on load
+	data, err := os.ReadFile(testConfig.QuestionsFile)
+	if err != nil {
+		t.Fatalf("Failed to read questions file: %v", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Failed to parse questions file: %v", err)
+	}
+	if _, ok := raw["url_trie"]; ok || string(raw["version"]) != "2" {
+		t.Errorf("Expected the file to be converted to version 2, got:\n%s", data)
+	}
+}
+
+func TestFileStorage_CreateAndRestoreBackup(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	store := &QuestionStore{
+		MaxID:     1,
+		Questions: map[int]*core.Question{1: createTestQuestion(1, "https://leetcode.com/problems/two-sum/")},
+	}
+	store.RebuildIndexes()
+	if err := storage.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Failed to save store: %v", err)
+	}
+
+	snapshot, err := storage.CreateBackup("test")
+	if err != nil {
+		t.Fatalf("Failed to back up: %v", err)
+	}
+	if snapshot.ID != "20240615-120000" || snapshot.Reason != "test" || len(snapshot.Files) != 4 {
+		t.Fatalf("Unexpected snapshot %+v", snapshot)
+	}
+
+	// Change the data, then load it so the cache holds the changed store
+	if err := storage.SaveQuestionStore(&QuestionStore{}); err != nil {
+		t.Fatalf("Failed to save store: %v", err)
+	}
+	if store, _ := storage.LoadQuestionStore(); len(store.Questions) != 0 {
+		t.Fatalf("Expected no questions after saving an empty store, got %d", len(store.Questions))
+	}
+
+	restored, before, err := storage.RestoreBackup(snapshot.ID)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if restored.ID != snapshot.ID || before == nil || before.ID != "20240615-120000-2" {
+		t.Errorf("Expected to restore %s after a new snapshot, got %+v and %+v", snapshot.ID, restored, before)
+	}
+
+	store, err = storage.LoadQuestionStore()
+	if err != nil {
+		t.Fatalf("Failed to load restored store: %v", err)
+	}
+	if store.MaxID != 1 || len(store.Questions) != 1 || store.URLIndex["https://leetcode.com/problems/two-sum/"] != 1 {
+		t.Errorf("Expected the restored questions after the cache is invalidated, got %+v", store)
+	}
+
+	snapshots, _ := storage.ListBackups()
+	if len(snapshots) != 2 {
+		t.Errorf("Expected 2 snapshots, got %d", len(snapshots))
+	}
+}
+
+func TestQuestionStore_StaleIndexes(t *testing.T) {
+	store := &QuestionStore{Questions: map[int]*core.Question{1: createTestQuestion(1, "https://leetcode.com/problems/two-sum/")}}
+	store.RebuildIndexes()
+	if stale := store.StaleIndexes(); len(stale) != 0 {
+		t.Errorf("Expected freshly built indices to be current, got %v", stale)
+	}
+
+	store.URLIndex["https://leetcode.com/problems/3sum/"] = 2
+	store.NoteTrie.Insert("orphan", 2)
+	if stale := store.StaleIndexes(); len(stale) != 2 {
+		t.Errorf("Expected the URL index and note search index to be stale, got %v", stale)
+	}
+}
+
+func TestFileStorage_DueCounts(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+	store, _ := storage.LoadQuestionStore()
+	start := time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)
+	for id, nextReview := range []time.Time{
+		start.AddDate(0, 0, -1),   // Before the first day
+		start.Add(20 * time.Hour), // Late on the first day
+		start.AddDate(0, 0, 2),    // Third day
+		start.AddDate(0, 0, 2),    // Third day, left out
+		start.AddDate(0, 0, 3),    // After the last day
+		time.Date(2024, 6, 18, 1, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)), // Second day in UTC
+	} {
+		question := createTestQuestion(id+1, "")
+		question.NextReview = nextReview
+		store.Questions[id+1] = question
+	}
+	if err := storage.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Failed to save question store: %v", err)
+	}
+
+	counts := storage.DueCounts(start, 3, 4)
+	if len(counts) != 3 || counts[0] != 1 || counts[1] != 1 || counts[2] != 1 {
+		t.Errorf("Expected one question due on each day, got %v", counts)
+	}
+}
+
+func TestFileStorage_PausedSince(t *testing.T) {
+	first, second := setupSharedStorages(t)
+	store, _ := first.LoadQuestionStore()
+	start := time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)
+
+	if _, paused := first.PausedSince(); paused {
+		t.Error("Expected reviews not to be paused without pauses")
+	}
+
+	store.Pauses = []core.Pause{{Start: start.AddDate(0, 0, -10), End: start.AddDate(0, 0, -3)}, {Start: start}}
+	if err := first.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Failed to save question store: %v", err)
+	}
+	if since, paused := second.PausedSince(); !paused || !since.Equal(start) {
+		t.Errorf("Expected the saved pause to be ongoing since %v, got %v, %t", start, since, paused)
+	}
+
+	store.Pauses[1].End = start.AddDate(0, 0, 1)
+	if _, paused := store.PausedSince(); paused {
+		t.Error("Expected reviews not to be paused once the last pause ended")
+	}
+}
+
+func TestFileStorage_SavesTimer(t *testing.T) {
+	first, second := setupSharedStorages(t)
+	store, _ := first.LoadQuestionStore()
+	start := time.Date(2024, 6, 16, 9, 30, 0, 0, time.UTC)
+
+	store.Timer = &core.Timer{URL: "https://leetcode.com/problems/two-sum/", Start: start}
+	if err := first.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Failed to save question store: %v", err)
+	}
+	loaded, err := second.LoadQuestionStore()
+	if err != nil {
+		t.Fatalf("Failed to load question store: %v", err)
+	}
+	if loaded.Timer == nil || loaded.Timer.URL != store.Timer.URL || !loaded.Timer.Start.Equal(start) {
+		t.Errorf("Expected the timer started by the other process, got %+v", loaded.Timer)
+	}
+}
+
+// setupSharedStorages creates two storages on the same files, as two processes would
+func setupSharedStorages(t *testing.T) (*FileStorage, *FileStorage) {
+	first, testConfig := setupTestStorage(t)
+	second := NewFileStorage(testConfig.QuestionsFile, testConfig.DeltasFile, testConfig.RedoFile, testConfig.ReviewsFile, fileutil.NewJSONFileUtil(), nil)
+	return first, second
+}
+
+func TestFileStorage_ReloadsFileChangedByAnotherProcess(t *testing.T) {
+	first, second := setupSharedStorages(t)
+
+	if store, _ := first.LoadQuestionStore(); len(store.Questions) != 0 {
+		t.Fatalf("Expected an empty store, got %d questions", len(store.Questions))
+	}
+
+	store, _ := second.LoadQuestionStore()
+	store.Questions[1] = createTestQuestion(1, "https://leetcode.com/problems/two-sum/")
+	store.MaxID = 1
+	if err := second.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Failed to save store: %v", err)
+	}
+
+	store, err := first.LoadQuestionStore()
+	if err != nil {
+		t.Fatalf("Failed to load store: %v", err)
+	}
+	if len(store.Questions) != 1 || store.URLIndex["https://leetcode.com/problems/two-sum/"] != 1 {
+		t.Errorf("Expected the question saved by the other process, got %+v", store.Questions)
+	}
+}
+
+func TestFileStorage_SaveRejectsStaleData(t *testing.T) {
+	first, second := setupSharedStorages(t)
+
+	// Both processes load the same data, then the second one saves first
+	stale, _ := first.LoadQuestionStore()
+	store, _ := second.LoadQuestionStore()
+	store.Questions[1] = createTestQuestion(1, "https://leetcode.com/problems/two-sum/")
+	store.MaxID = 1
+	if err := second.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Failed to save store: %v", err)
+	}
+
+	stale.Questions[1] = createTestQuestion(1, "https://leetcode.com/problems/3sum/")
+	stale.MaxID = 1
+	if err := first.SaveQuestionStore(stale); err != errs.ErrDataChanged {
+		t.Fatalf("Expected ErrDataChanged, got %v", err)
+	}
+
+	// Nothing was overwritten, and the next load sees the other process's data
+	store, err := first.LoadQuestionStore()
+	if err != nil {
+		t.Fatalf("Failed to load store: %v", err)
+	}
+	if store.Questions[1].URL != "https://leetcode.com/problems/two-sum/" {
+		t.Errorf("Expected the question saved by the other process, got %s", store.Questions[1].URL)
+	}
+	if err := first.SaveQuestionStore(store); err != nil {
+		t.Errorf("Expected a save after reloading to succeed, got %v", err)
+	}
+}
+
+func TestFileStorage_LockHoldsOffOtherProcesses(t *testing.T) {
+	first, second := setupSharedStorages(t)
+
+	unlock, err := first.Lock()
+	if err != nil {
+		t.Fatalf("Failed to lock: %v", err)
+	}
+
+	// The other process waits for the lock before it can save
+	saved := make(chan error, 1)
+	go func() {
+		store, err := second.LoadQuestionStore()
+		if err == nil {
+			store.Questions[1] = createTestQuestion(1, "https://leetcode.com/problems/3sum/")
+			store.MaxID = 1
+			err = second.SaveQuestionStore(store)
+		}
+		saved <- err
+	}()
+
+	// Loads and saves under the lock do not wait for it, and cannot conflict
+	store, err := first.LoadQuestionStore()
+	if err != nil {
+		t.Fatalf("Failed to load store: %v", err)
+	}
+	store.Questions[1] = createTestQuestion(1, "https://leetcode.com/problems/two-sum/")
+	store.MaxID = 1
+	if err := first.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Expected the save under the lock to succeed, got %v", err)
+	}
+	if err := first.SaveDeltas([]core.Delta{{Action: core.ActionAdd, QuestionID: 1, NewState: store.Questions[1]}}); err != nil {
+		t.Fatalf("Expected the second save under the lock to succeed, got %v", err)
+	}
+
+	select {
+	case err := <-saved:
+		t.Fatalf("Expected the other process to wait for the lock, it finished with %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	// The other process loads only after the lock is released, so it sees the saved data
+	if err := <-saved; err != nil {
+		t.Fatalf("Expected the other process to save after the lock, got %v", err)
+	}
+}
+
+func TestFileStorage_MarkReviewEvents(t *testing.T) {
+	storage, _ := setupTestStorage(t)
+
+	// Two reviews of question 1 logged at the same time, and one of question 2
+	for _, id := range []int{1, 1, 2} {
+		if err := storage.AppendReviewEvent(core.ReviewEvent{QuestionID: id, ReviewedAt: testTime}); err != nil {
+			t.Fatalf("Failed to append review event: %v", err)
+		}
+	}
+	review := core.Delta{Action: core.ActionUpdate, QuestionID: 1, CreatedAt: testTime}
+	deletion := core.Delta{Action: core.ActionDelete, QuestionID: 2, CreatedAt: testTime}
+
+	if err := storage.MarkReviewEvents([]core.Delta{deletion, review}, true); err != nil {
+		t.Fatalf("Failed to mark review events: %v", err)
+	}
+	storage.InvalidateCache()
+	events, _ := storage.LoadReviewEvents()
+	if len(events) != 3 || events[0].Reverted || !events[1].Reverted || events[2].Reverted {
+		t.Fatalf("Expected only the newest review of question 1 reverted, got %+v", events)
+	}
+
+	if err := storage.MarkReviewEvents([]core.Delta{review}, true); err != nil {
+		t.Fatalf("Failed to mark review events: %v", err)
+	}
+	if err := storage.MarkReviewEvents([]core.Delta{review}, false); err != nil {
+		t.Fatalf("Failed to restore review events: %v", err)
+	}
+	events, _ = storage.LoadReviewEvents()
+	if events[0].Reverted || !events[1].Reverted {
+		t.Errorf("Expected the oldest reverted review restored first, got %+v", events)
+	}
+}
+
+func TestFileStorage_AppendReviewEvent_KeepsOtherProcessEvents(t *testing.T) {
+	first, second := setupSharedStorages(t)
+
+	first.LoadReviewEvents()
+	if err := second.AppendReviewEvent(core.ReviewEvent{QuestionID: 1, ReviewedAt: testTime}); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+	if err := first.AppendReviewEvent(core.ReviewEvent{QuestionID: 2, ReviewedAt: testTime}); err != nil {
+		t.Fatalf("Failed to append: %v", err)
+	}
+
+	events, err := second.LoadReviewEvents()
+	if err != nil {
+		t.Fatalf("Failed to load events: %v", err)
+	}
+	if len(events) != 2 || events[0].QuestionID != 1 || events[1].QuestionID != 2 {
+		t.Errorf("Expected the events of both processes, got %+v", events)
+	}
 }
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/filelock"
+)
+
+// LockFileName is the lock file, next to the questions file, that processes sharing the
+// data files coordinate through. Loads hold it shared and writes hold it exclusively.
+const LockFileName = ".leetsolv.lock"
+
+// fileStamp identifies the content of a data file
+type fileStamp struct {
+	known   bool // Set once the file has been loaded or saved
+	exists  bool
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// readStamp stamps the file as it is on disk
+func readStamp(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fileStamp{known: true}, nil
+	}
+	if err != nil {
+		return fileStamp{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{known: true, exists: true, modTime: info.ModTime(), size: info.Size(), hash: sha256.Sum256(data)}, nil
+}
+
+// changedOnDisk reports whether the file no longer has the content of stamp. The modification
+// time and size are compared first; the content is only hashed when they differ.
+func changedOnDisk(path string, stamp *fileStamp) (bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return stamp.exists, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if stamp.exists && info.ModTime().Equal(stamp.modTime) && info.Size() == stamp.size {
+		return false, nil
+	}
+
+	current, err := readStamp(path)
+	if err != nil {
+		return false, err
+	}
+	if stamp.exists && current.hash == stamp.hash {
+		// Rewritten with the same content; remember the new time so the next check is cheap
+		*stamp = current
+		return false, nil
+	}
+	return true, nil
+}
+
+// Lock holds the lock file exclusively until unlock is called, for a command that loads the data
+// files and then saves them: no other process can write in between, so the saves cannot conflict
+// and leave the command half done. Loads and saves until then run under this lock.
+func (fs *FileStorage) Lock() (unlock func(), err error) {
+	if fs.locked {
+		return func() {}, nil
+	}
+	lock, err := filelock.Exclusive(fs.lockFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock the data files: %w", err)
+	}
+	fs.locked = true
+	return func() {
+		fs.locked = false
+		lock.Unlock()
+	}, nil
+}
+
+// withLock runs fn holding the lock file, exclusively when exclusive is set. Under Lock, fn runs
+// right away.
+func (fs *FileStorage) withLock(exclusive bool, fn func() error) error {
+	if fs.locked {
+		return fn()
+	}
+	acquire := filelock.Shared
+	if exclusive {
+		acquire = filelock.Exclusive
+	}
+	lock, err := acquire(fs.lockFileName)
+	if err != nil {
+		return fmt.Errorf("failed to lock the data files: %w", err)
+	}
+	defer lock.Unlock()
+
+	return fn()
+}
+
+// isCurrent reports whether the file still has the content of stamp. When another process
+// changed it, the cache is invalidated so that every file is read again.
+func (fs *FileStorage) isCurrent(path string, stamp *fileStamp) bool {
+	if changed, err := changedOnDisk(path, stamp); err == nil && !changed {
+		return true
+	}
+	fs.InvalidateCache()
+	return false
+}
+
+// loadFile reads the file into data and stamps it. Call it holding the lock.
+func (fs *FileStorage) loadFile(data any, path string, stamp *fileStamp) error {
+	if err := fs.file.Load(data, path); err != nil {
+		return err
+	}
+	current, err := readStamp(path)
+	if err != nil {
+		return err
+	}
+	*stamp = current
+	return nil
+}
+
+// saveFile writes data to the file and stamps it. When another process changed the file since
+// this one loaded it, nothing is written: the cache is invalidated and errs.ErrDataChanged
+// returned, so the command can be run again on the latest data. Call it holding the exclusive lock.
+func (fs *FileStorage) saveFile(data any, path string, stamp *fileStamp) error {
+	if stamp.known {
+		changed, err := changedOnDisk(path, stamp)
+		if err != nil {
+			return err
+		}
+		if changed {
+			fs.InvalidateCache()
+			return errs.ErrDataChanged
+		}
+	}
+
+	if err := fs.file.Save(data, path); err != nil {
+		return err
+	}
+	current, err := readStamp(path)
+	if err != nil {
+		return err
+	}
+	*stamp = current
+	return nil
+}
+
+// restamp stamps every data file after this process replaced or deleted them
+func (fs *FileStorage) restamp() error {
+	for path, stamp := range map[string]*fileStamp{
+		fs.questionsFileName: &fs.questionsStamp,
+		fs.deltasFileName:    &fs.deltasStamp,
+		fs.redoFileName:      &fs.redoStamp,
+		fs.reviewsFileName:   &fs.reviewsStamp,
+	} {
+		current, err := readStamp(path)
+		if err != nil {
+			return err
+		}
+		*stamp = current
+	}
+	return nil
+}
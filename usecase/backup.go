@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/eannchen/leetsolv/internal/backup"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+)
+
+// CreateBackup takes a snapshot of the data files on request
+func (u *QuestionUseCaseImpl) CreateBackup(reason string) (*backup.Snapshot, error) {
+	logger.Infof("Creating backup: Reason=%s", reason)
+
+	snapshot, err := u.Storage.CreateBackup(reason)
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to create backup")
+	}
+	return snapshot, nil
+}
+
+// ListBackups returns the snapshots of the data files, newest first
+func (u *QuestionUseCaseImpl) ListBackups() ([]backup.Snapshot, error) {
+	snapshots, err := u.Storage.ListBackups()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to list backups")
+	}
+	return snapshots, nil
+}
+
+// RestoreBackup replaces the data files with a snapshot and reloads the restored settings. The
+// current files are snapshotted first; that snapshot is returned as before, or nil when they
+// match the newest snapshot.
+func (u *QuestionUseCaseImpl) RestoreBackup(id string) (*backup.Snapshot, *backup.Snapshot, error) {
+	logger.Infof("Restoring backup: ID=%s", id)
+
+	restored, before, err := u.Storage.RestoreBackup(id)
+	if err != nil {
+		if errors.Is(err, backup.ErrSnapshotNotFound) {
+			return nil, nil, errs.ErrBackupNotFound
+		}
+		return nil, nil, errs.WrapInternalError(err, "Failed to restore backup")
+	}
+
+	// The snapshot holds the settings file too; pick it up so a later save does not overwrite it
+	if err := u.cfg.Reload(); err != nil {
+		return nil, nil, errs.WrapInternalError(err, "Failed to reload restored settings")
+	}
+	return restored, before, nil
+}
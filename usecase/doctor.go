@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+	"github.com/eannchen/leetsolv/storage"
+)
+
+// IssueKind categorizes a data integrity problem
+type IssueKind string
+
+const (
+	IssueIDMismatch    IssueKind = "id-mismatch"    // Question stored under a key other than its ID
+	IssueMaxID         IssueKind = "max-id"         // MaxID below the highest question ID
+	IssueDuplicateURL  IssueKind = "duplicate-url"  // Two questions share a URL
+	IssueStaleIndex    IssueKind = "stale-index"    // In-memory index disagrees with the questions
+	IssueNonUTC        IssueKind = "non-utc"        // Timestamp stored in a local time zone
+	IssueEaseFactor    IssueKind = "ease-factor"    // Ease factor outside the scheduler bounds
+	IssueReviewOrder   IssueKind = "review-order"   // NextReview before LastReviewed
+	IssueState         IssueKind = "state"          // Lifecycle state that is not known
+	IssueDanglingDelta IssueKind = "dangling-delta" // History entry that undo could not apply
+)
+
+// Issue is a single data integrity problem
+type Issue struct {
+	Kind       IssueKind
+	QuestionID int // 0 when the issue is not about one question
+	Message    string
+	Repairable bool
+}
+
+// CheckResult is the outcome of a data integrity check
+type CheckResult struct {
+	Issues   []Issue
+	Repaired int    // Number of issues fixed; only set when repairing
+	Backup   string // ID of the backup taken before repairing
+}
+
+// Repairable returns the number of issues that a repair would fix
+func (r *CheckResult) Repairable() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Repairable {
+			count++
+		}
+	}
+	return count
+}
+
+// CheckData verifies the questions, their indices and the undo history against each other.
+// With repair set, it backs up the data files and then fixes every repairable issue.
+func (u *QuestionUseCaseImpl) CheckData(repair bool) (*CheckResult, error) {
+	logger.Infof("Checking data: Repair=%t", repair)
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	deltas, err := u.Storage.LoadDeltas()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load deltas")
+	}
+	events, err := u.Storage.LoadReviewEvents()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load review log")
+	}
+
+	result := &CheckResult{}
+	result.Issues, _ = checkStore(store, deltas, false)
+	eventIssues := checkReviewEvents(events, false)
+	result.Issues = append(result.Issues, eventIssues...)
+	if !repair || result.Repairable() == 0 {
+		return result, nil
+	}
+
+	snapshot, err := u.Storage.CreateBackup("before doctor --repair")
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to back up data files")
+	}
+	result.Backup = snapshot.ID
+
+	_, deltas = checkStore(store, deltas, true)
+	store.RebuildIndexes()
+	if err := u.Storage.SaveQuestionStore(store); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
+	}
+	if err := u.Storage.SaveDeltas(deltas); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save deltas")
+	}
+	if len(eventIssues) > 0 {
+		// The events are the cached slice, so convert a copy
+		events = slices.Clone(events)
+		checkReviewEvents(events, true)
+		if err := u.Storage.SaveReviewEvents(events); err != nil {
+			return nil, errs.WrapInternalError(err, "Failed to save review log")
+		}
+	}
+
+	result.Repaired = result.Repairable()
+	logger.Infof("Repaired %d data issues, backup: %s", result.Repaired, result.Backup)
+	return result, nil
+}
+
+// checkStore finds the integrity issues of the store and history. With fix set, it also repairs
+// them in place and returns the repaired history; the search indices must be rebuilt afterwards.
+func checkStore(store *storage.QuestionStore, deltas []core.Delta, fix bool) ([]Issue, []core.Delta) {
+	var issues []Issue
+	issues = append(issues, checkQuestions(store, fix)...)
+	issues = append(issues, checkPausesAndTimer(store, fix)...)
+	if stale := store.StaleIndexes(); len(stale) > 0 {
+		issues = append(issues, Issue{
+			Kind:       IssueStaleIndex,
+			Message:    fmt.Sprintf("Out of date with the questions: %v", stale),
+			Repairable: true,
+		})
+	}
+	deltaIssues, deltas := checkDeltas(store, deltas, fix)
+	return append(issues, deltaIssues...), deltas
+}
+
+func checkQuestions(store *storage.QuestionStore, fix bool) []Issue {
+	var issues []Issue
+	maxID := 0
+	urls := make(map[string]int)
+
+	for _, key := range slices.Sorted(maps.Keys(store.Questions)) {
+		q := store.Questions[key]
+		if q.ID != key {
+			issues = append(issues, Issue{IssueIDMismatch, key,
+				fmt.Sprintf("Question %d is stored with ID %d", key, q.ID), true})
+			if fix {
+				q.ID = key
+			}
+		}
+		maxID = max(maxID, key)
+
+		if first, ok := urls[q.URL]; ok {
+			issues = append(issues, Issue{IssueDuplicateURL, key,
+				fmt.Sprintf("Question %d has the same URL as question %d; delete one of them", key, first), false})
+		} else {
+			urls[q.URL] = key
+		}
+
+		if hasNonUTC(q) {
+			issues = append(issues, Issue{IssueNonUTC, key,
+				fmt.Sprintf("Question %d has timestamps that are not in UTC", key), true})
+			if fix {
+				toUTC(q)
+			}
+		}
+
+		// Questions scheduled by FSRS keep the ease factor of their last SM-2 review, so it is checked too
+		if clamped := core.ClampEaseFactor(q.EaseFactor); clamped != q.EaseFactor {
+			issues = append(issues, Issue{IssueEaseFactor, key,
+				fmt.Sprintf("Question %d has ease factor %.2f, outside the range the scheduler uses", key, q.EaseFactor), true})
+			if fix {
+				q.EaseFactor = clamped
+			}
+		}
+
+		if q.NextReview.Before(q.LastReviewed) {
+			issues = append(issues, Issue{IssueReviewOrder, key,
+				fmt.Sprintf("Question %d is due (%s) before it was last reviewed (%s)",
+					key, q.NextReview.Format(time.DateOnly), q.LastReviewed.Format(time.DateOnly)), true})
+			if fix {
+				// Make the question due right after its last review so it comes up again soon
+				q.NextReview = q.LastReviewed
+			}
+		}
+
+		if !slices.Contains(core.QuestionStates, q.State) {
+			issues = append(issues, Issue{IssueState, key,
+				fmt.Sprintf("Question %d has unknown state %q", key, q.State), true})
+			if fix {
+				// Make the question active so it is reviewed again
+				q.State, q.BuriedUntil = core.StateActive, time.Time{}
+			}
+		}
+	}
+
+	if store.MaxID < maxID {
+		issues = append(issues, Issue{IssueMaxID, 0,
+			fmt.Sprintf("Highest ID is %d but the next ID would be %d, which would overwrite a question", maxID, store.MaxID+1), true})
+		if fix {
+			store.MaxID = maxID
+		}
+	}
+	return issues
+}
+
+// checkPausesAndTimer finds the pauses and the running timer stored in a local time zone
+func checkPausesAndTimer(store *storage.QuestionStore, fix bool) []Issue {
+	var issues []Issue
+	for i := range store.Pauses {
+		if pause := &store.Pauses[i]; !isUTC(pause.Start) || !isUTC(pause.End) {
+			issues = append(issues, Issue{IssueNonUTC, 0,
+				fmt.Sprintf("Pause %d has timestamps that are not in UTC", i+1), true})
+			if fix {
+				pause.Start, pause.End = pause.Start.UTC(), pause.End.UTC()
+			}
+		}
+	}
+	if store.Timer != nil && !isUTC(store.Timer.Start) {
+		issues = append(issues, Issue{IssueNonUTC, 0, "Solve timer start is not in UTC", true})
+		if fix {
+			store.Timer.Start = store.Timer.Start.UTC()
+		}
+	}
+	return issues
+}
+
+// checkReviewEvents finds the review log events stored in a local time zone
+func checkReviewEvents(events []core.ReviewEvent, fix bool) []Issue {
+	var issues []Issue
+	for i := range events {
+		if !isUTC(events[i].ReviewedAt) {
+			issues = append(issues, Issue{IssueNonUTC, events[i].QuestionID,
+				fmt.Sprintf("Review log entry %d has a timestamp that is not in UTC", i+1), true})
+			if fix {
+				events[i].ReviewedAt = events[i].ReviewedAt.UTC()
+			}
+		}
+	}
+	return issues
+}
+
+// checkDeltas finds history entries that undo could not apply: entries missing the states
+// their action needs, and entries whose question is not in the state the history says.
+// Repairing drops every entry about an affected question, since undoing any of them is unsafe.
+func checkDeltas(store *storage.QuestionStore, deltas []core.Delta, fix bool) ([]Issue, []core.Delta) {
+	var issues []Issue
+	dangling := make(map[int]bool)
+	lastAction := make(map[int]core.ActionType)
+
+	for i := range deltas {
+		if hasNonUTCDelta(&deltas[i]) {
+			issues = append(issues, Issue{IssueNonUTC, deltas[i].QuestionID,
+				fmt.Sprintf("History entry %d has timestamps that are not in UTC", i+1), true})
+			if fix {
+				deltaToUTC(&deltas[i])
+			}
+		}
+
+		for _, step := range deltaSteps(deltas[i]) {
+			if !hasRequiredStates(step) {
+				issues = append(issues, Issue{IssueDanglingDelta, step.QuestionID,
+					fmt.Sprintf("History entry %d (%s question %d) is missing the question state", i+1, step.Action, step.QuestionID), true})
+				dangling[step.QuestionID] = true
+			}
+			lastAction[step.QuestionID] = step.Action
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(lastAction)) {
+		if dangling[id] {
+			continue
+		}
+		_, exists := store.Questions[id]
+		switch {
+		case lastAction[id] == core.ActionDelete && exists:
+			issues = append(issues, Issue{IssueDanglingDelta, id,
+				fmt.Sprintf("History says question %d was deleted, but it still exists", id), true})
+		case lastAction[id] != core.ActionDelete && !exists:
+			issues = append(issues, Issue{IssueDanglingDelta, id,
+				fmt.Sprintf("History refers to question %d, which no longer exists", id), true})
+		default:
+			continue
+		}
+		dangling[id] = true
+	}
+
+	if !fix || len(dangling) == 0 {
+		return issues, deltas
+	}
+
+	repaired := make([]core.Delta, 0, len(deltas))
+	for _, delta := range deltas {
+		if !delta.Action.IsBatch() {
+			if !dangling[delta.QuestionID] {
+				repaired = append(repaired, delta)
+			}
+			continue
+		}
+		delta.Batch = slices.DeleteFunc(slices.Clone(delta.Batch), func(step core.Delta) bool {
+			return dangling[step.QuestionID]
+		})
+		// An empty resume still reopens its pause when undone
+		if len(delta.Batch) > 0 || delta.Action == core.ActionResume {
+			repaired = append(repaired, delta)
+		}
+	}
+	return issues, repaired
+}
+
+// deltaSteps returns the single-question changes recorded by a history entry
+func deltaSteps(delta core.Delta) []core.Delta {
+	if delta.Action.IsBatch() {
+		return delta.Batch
+	}
+	return []core.Delta{delta}
+}
+
+// hasRequiredStates reports whether a delta has the states undo needs for its action
+func hasRequiredStates(delta core.Delta) bool {
+	switch delta.Action {
+	case core.ActionAdd:
+		return delta.NewState != nil
+	case core.ActionUpdate:
+		return delta.OldState != nil && delta.NewState != nil
+	case core.ActionDelete:
+		return delta.OldState != nil
+	}
+	return false
+}
+
+func isUTC(t time.Time) bool {
+	return t.Location() == time.UTC
+}
+
+func hasNonUTC(q *core.Question) bool {
+	return !isUTC(q.LastReviewed) || !isUTC(q.NextReview) || !isUTC(q.UpdatedAt) || !isUTC(q.CreatedAt) || !isUTC(q.BuriedUntil)
+}
+
+func toUTC(q *core.Question) {
+	q.LastReviewed = q.LastReviewed.UTC()
+	q.NextReview = q.NextReview.UTC()
+	q.UpdatedAt = q.UpdatedAt.UTC()
+	q.CreatedAt = q.CreatedAt.UTC()
+	q.BuriedUntil = q.BuriedUntil.UTC()
+}
+
+func hasNonUTCDelta(delta *core.Delta) bool {
+	if !isUTC(delta.CreatedAt) ||
+		(delta.OldState != nil && hasNonUTC(delta.OldState)) ||
+		(delta.NewState != nil && hasNonUTC(delta.NewState)) {
+		return true
+	}
+	return slices.ContainsFunc(delta.Batch, func(step core.Delta) bool { return hasNonUTCDelta(&step) })
+}
+
+func deltaToUTC(delta *core.Delta) {
+	delta.CreatedAt = delta.CreatedAt.UTC()
+	if delta.OldState != nil {
+		toUTC(delta.OldState)
+	}
+	if delta.NewState != nil {
+		toUTC(delta.NewState)
+	}
+	for i := range delta.Batch {
+		deltaToUTC(&delta.Batch[i])
+	}
+}
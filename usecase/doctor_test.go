@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+)
+
+// issueKinds returns the kinds of the issues in order
+func issueKinds(issues []Issue) []IssueKind {
+	kinds := make([]IssueKind, 0, len(issues))
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	return kinds
+}
+
+func addTestQuestions(t *testing.T, useCase *QuestionUseCaseImpl, urls ...string) {
+	t.Helper()
+	for _, url := range urls {
+		if _, err := useCase.UpsertQuestion(url, "note", []string{"array"}, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+			t.Fatalf("Failed to upsert question: %v", err)
+		}
+	}
+}
+
+func TestQuestionUseCase_CheckData_Clean(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/", "https://leetcode.com/problems/3sum/")
+	if _, err := useCase.DeleteQuestion("2"); err != nil {
+		t.Fatalf("Failed to delete question: %v", err)
+	}
+
+	result, err := useCase.CheckData(true)
+	if err != nil {
+		t.Fatalf("Failed to check data: %v", err)
+	}
+	if len(result.Issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", result.Issues)
+	}
+	if result.Backup != "" {
+		t.Error("Expected no backup when there is nothing to repair")
+	}
+}
+
+func TestQuestionUseCase_CheckData_QuestionIssues(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/", "https://leetcode.com/problems/3sum/", "https://leetcode.com/problems/4sum/")
+
+	// Copy the questions so that the states shared with the history stay intact, as when loaded from disk
+	store, _ := useCase.Storage.LoadQuestionStore()
+	for id, q := range store.Questions {
+		copied := *q
+		store.Questions[id] = &copied
+	}
+	store.MaxID = 1
+	store.Questions[1].EaseFactor = 9
+	store.Questions[1].CreatedAt = store.Questions[1].CreatedAt.In(time.FixedZone("UTC+8", 8*60*60))
+	store.Questions[2].NextReview = store.Questions[2].LastReviewed.AddDate(0, 0, -1)
+	store.Questions[2].State = "frozen"
+	store.Questions[3].URL = store.Questions[1].URL
+	store.RebuildIndexes()
+
+	result, err := useCase.CheckData(false)
+	if err != nil {
+		t.Fatalf("Failed to check data: %v", err)
+	}
+	want := []IssueKind{IssueNonUTC, IssueEaseFactor, IssueReviewOrder, IssueState, IssueDuplicateURL, IssueMaxID}
+	if got := issueKinds(result.Issues); !slices.Equal(got, want) {
+		t.Fatalf("Expected issues %v, got %v", want, got)
+	}
+	if result.Repairable() != 5 || result.Repaired != 0 {
+		t.Errorf("Expected 5 repairable issues and nothing repaired, got %d and %d", result.Repairable(), result.Repaired)
+	}
+	if store.MaxID != 1 {
+		t.Error("Expected a check without repair to change nothing")
+	}
+
+	result, err = useCase.CheckData(true)
+	if err != nil {
+		t.Fatalf("Failed to repair data: %v", err)
+	}
+	if result.Repaired != 5 || result.Backup == "" {
+		t.Errorf("Expected 5 repairs after a backup, got %d repairs and backup %q", result.Repaired, result.Backup)
+	}
+	if store.MaxID != 3 || store.Questions[1].EaseFactor != 2.6 || store.Questions[1].CreatedAt.Location() != time.UTC ||
+		store.Questions[2].NextReview.Before(store.Questions[2].LastReviewed) || store.Questions[2].State != core.StateActive {
+		t.Errorf("Expected the questions to be repaired, got MaxID %d and %+v", store.MaxID, store.Questions)
+	}
+
+	// Only the issue that needs a manual fix is left
+	result, _ = useCase.CheckData(false)
+	if got := issueKinds(result.Issues); !slices.Equal(got, []IssueKind{IssueDuplicateURL}) {
+		t.Errorf("Expected only the duplicate URL to remain, got %v", got)
+	}
+}
+
+func TestQuestionUseCase_CheckData_NonUTCPausesTimerAndReviews(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+
+	zone := time.FixedZone("UTC+8", 8*60*60)
+	store, _ := useCase.Storage.LoadQuestionStore()
+	store.Pauses = []core.Pause{{Start: testTime.In(zone), End: testTime.AddDate(0, 0, 1)}}
+	store.Timer = &core.Timer{URL: "https://leetcode.com/problems/3sum/", Start: testTime.In(zone)}
+	events, _ := useCase.Storage.LoadReviewEvents()
+	events = slices.Clone(events)
+	events[0].ReviewedAt = events[0].ReviewedAt.In(zone)
+	if err := useCase.Storage.SaveReviewEvents(events); err != nil {
+		t.Fatalf("Failed to save review log: %v", err)
+	}
+
+	result, err := useCase.CheckData(true)
+	if err != nil {
+		t.Fatalf("Failed to repair data: %v", err)
+	}
+	want := []IssueKind{IssueNonUTC, IssueNonUTC, IssueNonUTC}
+	if got := issueKinds(result.Issues); !slices.Equal(got, want) || result.Repaired != 3 {
+		t.Fatalf("Expected 3 repaired non-UTC issues, got %v and %d repairs", got, result.Repaired)
+	}
+
+	store, _ = useCase.Storage.LoadQuestionStore()
+	if !isUTC(store.Pauses[0].Start) || !isUTC(store.Timer.Start) {
+		t.Errorf("Expected the pause and timer in UTC, got %v and %v", store.Pauses[0].Start, store.Timer.Start)
+	}
+	events, _ = useCase.Storage.LoadReviewEvents()
+	if !isUTC(events[0].ReviewedAt) || !events[0].ReviewedAt.Equal(testTime) {
+		t.Errorf("Expected the review time converted to UTC, got %v", events[0].ReviewedAt)
+	}
+}
+
+func TestQuestionUseCase_CheckData_StaleIndex(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	store.NoteTrie.Insert("orphan", 7)
+	store.IndexTags(7, []string{"graph"})
+
+	result, err := useCase.CheckData(true)
+	if err != nil {
+		t.Fatalf("Failed to repair data: %v", err)
+	}
+	if got := issueKinds(result.Issues); !slices.Equal(got, []IssueKind{IssueStaleIndex}) {
+		t.Fatalf("Expected a stale index issue, got %v", got)
+	}
+
+	store, _ = useCase.Storage.LoadQuestionStore()
+	if ids := store.NoteTrie.SearchPrefix("orphan"); len(ids) != 0 {
+		t.Errorf("Expected the note index to be rebuilt, got %v", ids)
+	}
+	if _, ok := store.TagIndex["graph"]; ok {
+		t.Error("Expected the tag index to be rebuilt")
+	}
+}
+
+func TestQuestionUseCase_CheckData_DanglingDeltas(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/", "https://leetcode.com/problems/3sum/")
+	if _, err := useCase.ImportQuestions([]core.Question{
+		{URL: "https://leetcode.com/problems/4sum/"},
+		{URL: "https://leetcode.com/problems/3sum-closest/"},
+	}, ImportSkip, false); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	// Questions 2 and 4 disappear without the history knowing
+	store, _ := useCase.Storage.LoadQuestionStore()
+	delete(store.Questions, 2)
+	delete(store.Questions, 4)
+	store.RebuildIndexes()
+
+	result, err := useCase.CheckData(true)
+	if err != nil {
+		t.Fatalf("Failed to repair data: %v", err)
+	}
+	want := []IssueKind{IssueDanglingDelta, IssueDanglingDelta}
+	if got := issueKinds(result.Issues); !slices.Equal(got, want) {
+		t.Fatalf("Expected issues %v, got %v", want, got)
+	}
+
+	deltas, _ := useCase.Storage.LoadDeltas()
+	if len(deltas) != 2 || deltas[0].QuestionID != 1 || len(deltas[1].Batch) != 1 || deltas[1].Batch[0].QuestionID != 3 {
+		t.Fatalf("Expected the entries about questions 2 and 4 to be dropped, got %+v", deltas)
+	}
+
+	// The remaining history can still be undone
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo after repair: %v", err)
+	}
+	if _, ok := store.Questions[3]; ok {
+		t.Error("Expected undo to remove the imported question")
+	}
+}
+
+func TestQuestionUseCase_CheckData_MalformedDelta(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+
+	deltas, _ := useCase.Storage.LoadDeltas()
+	deltas = append(deltas, core.Delta{Action: core.ActionUpdate, QuestionID: 1, CreatedAt: testTime})
+	if err := useCase.Storage.SaveDeltas(deltas); err != nil {
+		t.Fatalf("Failed to save deltas: %v", err)
+	}
+
+	result, err := useCase.CheckData(true)
+	if err != nil {
+		t.Fatalf("Failed to repair data: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Kind != IssueDanglingDelta || result.Issues[0].QuestionID != 1 {
+		t.Fatalf("Expected one dangling delta issue for question 1, got %+v", result.Issues)
+	}
+
+	deltas, _ = useCase.Storage.LoadDeltas()
+	if len(deltas) != 0 {
+		t.Errorf("Expected every entry about question 1 to be dropped, got %d", len(deltas))
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+)
+
+// MaxForecastDays limits how far ahead a forecast looks
+const MaxForecastDays = 90
+
+// forecastPeaks is the number of busiest days a forecast reports
+const forecastPeaks = 3
+
+// ForecastDay is the number of questions that come due on one day
+type ForecastDay struct {
+	Date time.Time
+	Due  int
+}
+
+// ForecastWeek is the number of questions that come due in one calendar week, Monday to Sunday,
+// cut to the days of the forecast
+type ForecastWeek struct {
+	Start time.Time
+	End   time.Time
+	Due   int
+}
+
+// Forecast is how many questions come due on each of the coming days
+type Forecast struct {
+	Days    []ForecastDay // From today; today includes the overdue questions
+	Peaks   []ForecastDay // Busiest days, busiest first
+	Overdue int           // Questions that were due before today
+	Later   int           // Questions due after the last day
+	Total   int           // Questions that matched the filter
+}
+
+// DueInRange is the number of questions that come due during the forecast, overdue ones included
+func (f *Forecast) DueInRange() int {
+	return f.Total - f.Later
+}
+
+// Weeks groups the days of the forecast into calendar weeks
+func (f *Forecast) Weeks() []ForecastWeek {
+	var weeks []ForecastWeek
+	for _, day := range f.Days {
+		if len(weeks) == 0 || day.Date.Weekday() == time.Monday {
+			weeks = append(weeks, ForecastWeek{Start: day.Date})
+		}
+		week := &weeks[len(weeks)-1]
+		week.End = day.Date
+		week.Due += day.Due
+	}
+	return weeks
+}
+
+// ForecastDue counts the questions matching filter by the day they come due over the next days,
+// starting today. A nil filter counts every question that is not suspended or archived; buried
+// questions count on the day their burial ends, if they are due by then.
+func (u *QuestionUseCaseImpl) ForecastDue(days int, filter *core.SearchFilter) (*Forecast, error) {
+	logger.Infof("Forecasting due questions: Days=%d", days)
+
+	if days < 1 || days > MaxForecastDays {
+		return nil, errs.WrapValidationError(fmt.Errorf("invalid forecast days %d", days),
+			fmt.Sprintf("Please forecast between 1 and %d days", MaxForecastDays))
+	}
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+
+	today := u.Clock.Today()
+	forecast := &Forecast{Days: make([]ForecastDay, days)}
+	for i := range forecast.Days {
+		forecast.Days[i].Date = u.Clock.AddDays(today, i)
+	}
+
+	for _, q := range store.Questions {
+		if !q.IsScheduled() || filter != nil && !u.matchesFilter(*q, *filter) {
+			continue
+		}
+		forecast.Total++
+
+		nextReviewDate := u.Clock.ToDate(q.ReviewDate())
+		if nextReviewDate.Before(today) {
+			forecast.Overdue++
+			forecast.Days[0].Due++
+			continue
+		}
+		offset := int(nextReviewDate.Sub(today).Hours() / 24)
+		if offset >= days {
+			forecast.Later++
+			continue
+		}
+		forecast.Days[offset].Due++
+	}
+
+	forecast.Peaks = busiest(forecast.Days, func(day ForecastDay) int { return day.Due }, forecastPeaks)
+	return forecast, nil
+}
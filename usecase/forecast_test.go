@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+// storeQuestionDue stores a question that comes due the given number of days from the test time
+func storeQuestionDue(t *testing.T, useCase *QuestionUseCaseImpl, id int, days int, tags ...string) {
+	t.Helper()
+	store, _ := useCase.Storage.LoadQuestionStore()
+	question := createTestQuestion(id, fmt.Sprintf("https://leetcode.com/problems/q%d/", id))
+	question.NextReview = testTime.AddDate(0, 0, days)
+	question.Tags = tags
+	store.Questions[id] = question
+}
+
+func TestQuestionUseCase_ForecastDue(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	for id, days := range []int{-3, 0, 2, 2, 9, 40} {
+		storeQuestionDue(t, useCase, id+1, days)
+	}
+
+	forecast, err := useCase.ForecastDue(30, nil)
+	if err != nil {
+		t.Fatalf("Failed to forecast: %v", err)
+	}
+
+	if len(forecast.Days) != 30 || !forecast.Days[0].Date.Equal(useCase.Clock.Today()) {
+		t.Fatalf("Expected 30 days from today, got %d starting %v", len(forecast.Days), forecast.Days[0].Date)
+	}
+	if forecast.Days[0].Due != 2 || forecast.Days[2].Due != 2 || forecast.Days[9].Due != 1 {
+		t.Errorf("Expected 2, 2 and 1 questions due on days 0, 2 and 9, got %+v", forecast.Days[:10])
+	}
+	if forecast.Overdue != 1 || forecast.Later != 1 || forecast.Total != 6 || forecast.DueInRange() != 5 {
+		t.Errorf("Expected 1 overdue, 1 later and 6 in total, got %d, %d and %d", forecast.Overdue, forecast.Later, forecast.Total)
+	}
+	if len(forecast.Peaks) != 3 || !forecast.Peaks[0].Date.Equal(forecast.Days[0].Date) || !forecast.Peaks[1].Date.Equal(forecast.Days[2].Date) {
+		t.Errorf("Expected the busiest days earliest first among equals, got %+v", forecast.Peaks)
+	}
+}
+
+func TestQuestionUseCase_ForecastDue_Filter(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	storeQuestionDue(t, useCase, 1, 1, "graph")
+	storeQuestionDue(t, useCase, 2, 1, "dp")
+	storeQuestionDue(t, useCase, 3, 5, "graph", "dp")
+
+	forecast, err := useCase.ForecastDue(7, &core.SearchFilter{Tags: []string{"graph"}})
+	if err != nil {
+		t.Fatalf("Failed to forecast: %v", err)
+	}
+	if forecast.Total != 2 || forecast.Days[1].Due != 1 || forecast.Days[5].Due != 1 {
+		t.Errorf("Expected only the graph questions, got %+v", forecast)
+	}
+}
+
+func TestForecast_Weeks(t *testing.T) {
+	// The test time is a Saturday, so the first week has two days
+	_, useCase := setupTestEnvironment(t)
+	for id, days := range []int{0, 1, 2, 5, 8} {
+		storeQuestionDue(t, useCase, id+1, days)
+	}
+
+	forecast, err := useCase.ForecastDue(9, nil)
+	if err != nil {
+		t.Fatalf("Failed to forecast: %v", err)
+	}
+
+	weeks := forecast.Weeks()
+	if len(weeks) != 2 {
+		t.Fatalf("Expected 2 weeks, got %+v", weeks)
+	}
+	if weeks[0].Due != 2 || !weeks[0].End.Equal(forecast.Days[1].Date) {
+		t.Errorf("Expected 2 questions due up to Sunday, got %+v", weeks[0])
+	}
+	if weeks[1].Due != 3 || weeks[1].Start.Weekday() != time.Monday || !weeks[1].End.Equal(forecast.Days[8].Date) {
+		t.Errorf("Expected 3 questions due from Monday to Sunday, got %+v", weeks[1])
+	}
+}
+
+func TestQuestionUseCase_ForecastDue_InvalidDays(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	for _, days := range []int{0, MaxForecastDays + 1} {
+		if _, err := useCase.ForecastDue(days, nil); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %d days, got %v", days, err)
+		}
+	}
+}
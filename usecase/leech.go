@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"slices"
+
+	"github.com/eannchen/leetsolv/config"
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+)
+
+// ListLeeches returns the questions detected as leeches, most lapses first, then by ID.
+func (u *QuestionUseCaseImpl) ListLeeches() ([]core.Question, error) {
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+
+	var leeches []core.Question
+	for _, q := range store.Questions {
+		if q.Leech {
+			leeches = append(leeches, *q)
+		}
+	}
+
+	slices.SortFunc(leeches, func(a, b core.Question) int {
+		if a.Lapses != b.Lapses {
+			return b.Lapses - a.Lapses
+		}
+		return a.ID - b.ID
+	})
+	return leeches, nil
+}
+
+// ClearLeech gives a leech a fresh start: it is no longer a leech, its lapses are reset and the
+// leech action is reverted, unsuspending the question only if the leech action suspended it. The
+// change is recorded as an update so it can be undone.
+func (u *QuestionUseCaseImpl) ClearLeech(target string) (*core.Delta, error) {
+	logger.Infof("Clearing leech: Target=%s", target)
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	found, err := u.findQuestionByIDOrURL(store, target)
+	if err != nil {
+		return nil, err
+	}
+	if !found.Leech {
+		return nil, errs.ErrNotALeech
+	}
+
+	cleared := *found
+	cleared.Leech = false
+	cleared.Lapses = 0
+	cleared.Tags = slices.DeleteFunc(slices.Clone(found.Tags), func(tag string) bool { return tag == core.LeechTag })
+	if len(cleared.Tags) == 0 {
+		cleared.Tags = nil
+	}
+	// A question suspended by hand stays suspended
+	if cleared.State == core.StateSuspended && cleared.LeechSuspended {
+		cleared.State = core.StateActive
+	}
+	cleared.LeechSuspended = false
+	cleared.UpdatedAt = u.Clock.Now()
+
+	return u.saveUpdate(store, found, &cleared)
+}
+
+// applyLeechAction takes the configured leech action on a reviewed leech. The tag is put back on
+// every review of a leech, as an upsert replaces the tags, while a leech is suspended only as it
+// becomes one.
+func (u *QuestionUseCaseImpl) applyLeechAction(q *core.Question, newLeech bool) {
+	switch u.cfg.LeechAction {
+	case config.LeechActionTag:
+		if !q.HasTag(core.LeechTag) {
+			q.Tags = core.NormalizeTags(append(slices.Clone(q.Tags), core.LeechTag))
+		}
+	case config.LeechActionSuspend:
+		if newLeech {
+			q.State, q.LeechSuspended = core.StateSuspended, true
+		}
+	}
+}
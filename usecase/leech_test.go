@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eannchen/leetsolv/config"
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+// failReviews adds a question and then reviews it the given number of times, every review rated hard
+func failReviews(t *testing.T, useCase *QuestionUseCaseImpl, url string, reviews int) *core.Question {
+	t.Helper()
+	for i := 0; i <= reviews; i++ {
+		if _, err := useCase.UpsertQuestion(url, "note", nil, core.Hard, core.MediumImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+			t.Fatalf("Failed to upsert question: %v", err)
+		}
+	}
+	question, err := useCase.GetQuestion(url)
+	if err != nil {
+		t.Fatalf("Failed to get question: %v", err)
+	}
+	return question
+}
+
+func TestQuestionUseCase_UpsertQuestion_DetectsLeech(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	useCase.cfg.LeechThreshold = 4
+
+	// Adding a question is not a lapse, so three failed reviews leave it short of the threshold
+	question := failReviews(t, useCase, "https://leetcode.com/problems/two-sum", 3)
+	if question.Lapses != 3 || question.Leech {
+		t.Fatalf("Expected 3 lapses and no leech, got %d and %t", question.Lapses, question.Leech)
+	}
+
+	question = failReviews(t, useCase, "https://leetcode.com/problems/two-sum", 0)
+	if question.Lapses != 4 || !question.Leech || !question.HasTag(core.LeechTag) {
+		t.Fatalf("Expected a tagged leech after 4 lapses, got %+v", question)
+	}
+
+	// The tag is put back when a review replaces the tags
+	if _, err := useCase.UpsertQuestion(question.URL, "note", []string{"array"}, core.Easy, core.MediumImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+		t.Fatalf("Failed to upsert question: %v", err)
+	}
+	question, _ = useCase.GetQuestion(question.URL)
+	if question.Lapses != 4 || !question.HasTag(core.LeechTag) || !question.HasTag("array") {
+		t.Errorf("Expected the leech to keep its lapses and tag, got %+v", question)
+	}
+
+	leeches, err := useCase.ListLeeches()
+	if err != nil {
+		t.Fatalf("Failed to list leeches: %v", err)
+	}
+	if len(leeches) != 1 || leeches[0].ID != question.ID {
+		t.Errorf("Expected the question to be listed as a leech, got %+v", leeches)
+	}
+}
+
+func TestQuestionUseCase_LeechSuspended(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	useCase.cfg.LeechThreshold = 2
+	useCase.cfg.LeechAction = config.LeechActionSuspend
+
+	question := failReviews(t, useCase, "https://leetcode.com/problems/two-sum", 2)
+	if question.State != core.StateSuspended || question.HasTag(core.LeechTag) {
+		t.Fatalf("Expected an untagged suspended leech, got %+v", question)
+	}
+
+	// Suspended questions are never due, however overdue they are
+	store, _ := useCase.Storage.LoadQuestionStore()
+	store.Questions[question.ID].NextReview = testTime.AddDate(0, 0, -5)
+	due, _ := useCase.ListDueQuestions()
+	summary, _ := useCase.ListQuestionsSummary()
+	if len(due) != 0 || summary.TotalDue != 0 || summary.Total != 1 {
+		t.Errorf("Expected a suspended question not to be due, got %d and %+v", len(due), summary)
+	}
+
+	delta, err := useCase.ClearLeech(question.URL)
+	if err != nil {
+		t.Fatalf("Failed to clear leech: %v", err)
+	}
+	if delta.Action != core.ActionUpdate || delta.NewState.Leech || delta.NewState.Lapses != 0 || delta.NewState.State != core.StateActive {
+		t.Errorf("Expected an update to an active question with no lapses, got %+v", delta.NewState)
+	}
+	if due, _ := useCase.ListDueQuestions(); len(due) != 1 {
+		t.Errorf("Expected the cleared question to be due, got %d", len(due))
+	}
+
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	question, _ = useCase.GetQuestion(question.URL)
+	if !question.Leech || question.Lapses != 2 || question.State != core.StateSuspended {
+		t.Errorf("Expected undo to suspend the leech again, got %+v", question)
+	}
+}
+
+func TestQuestionUseCase_ClearLeech_KeepsManualSuspension(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	useCase.cfg.LeechThreshold = 2
+
+	question := failReviews(t, useCase, "https://leetcode.com/problems/two-sum", 2)
+	if _, err := useCase.SetQuestionState(question.URL, core.StateSuspended); err != nil {
+		t.Fatalf("Failed to suspend question: %v", err)
+	}
+
+	delta, err := useCase.ClearLeech(question.URL)
+	if err != nil {
+		t.Fatalf("Failed to clear leech: %v", err)
+	}
+	if delta.NewState.Leech || delta.NewState.State != core.StateSuspended {
+		t.Errorf("Expected the leech cleared and the question kept suspended, got %+v", delta.NewState)
+	}
+}
+
+func TestQuestionUseCase_ListLeeches_Order(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	for id, lapses := range []int{2, 0, 5, 2} {
+		storeQuestionDue(t, useCase, id+1, 1)
+		store, _ := useCase.Storage.LoadQuestionStore()
+		store.Questions[id+1].Lapses = lapses
+		store.Questions[id+1].Leech = lapses > 0
+	}
+
+	leeches, err := useCase.ListLeeches()
+	if err != nil {
+		t.Fatalf("Failed to list leeches: %v", err)
+	}
+	var ids []int
+	for _, q := range leeches {
+		ids = append(ids, q.ID)
+	}
+	if len(ids) != 3 || ids[0] != 3 || ids[1] != 1 || ids[2] != 4 {
+		t.Errorf("Expected leeches 3, 1 and 4, most lapses first, got %v", ids)
+	}
+}
+
+func TestQuestionUseCase_ClearLeech_NotALeech(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	storeQuestionDue(t, useCase, 1, 1)
+
+	if _, err := useCase.ClearLeech("1"); !errors.Is(err, errs.ErrNotALeech) {
+		t.Errorf("Expected ErrNotALeech, got %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package usecase
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/eannchen/leetsolv/config"
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/clock"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+)
+
+// Optimization limits and defaults
+const (
+	DefaultOptimizeSeed   = 1
+	DefaultOptimizeRounds = 50
+	MaxOptimizeRounds     = 500
+
+	// minOptimizeReviews is the number of predicted reviews an optimization needs
+	minOptimizeReviews = 20
+
+	// optimizeRetention is the chance of recalling a question on the day it is scheduled for
+	// that the fitted settings aim at
+	optimizeRetention = 0.9
+
+	// minOptimizeStep is the smallest step the search takes for a decimal setting
+	minOptimizeStep = 0.01
+
+	// optimizeDriftCost is what the search adds to the error for each setting level squared
+	// initial steps away from its current value, so that a change has to pay for itself
+	optimizeDriftCost = 0.001
+)
+
+// OptimizeOptions control the search for better SM-2 settings
+type OptimizeOptions struct {
+	Seed   uint64 // Same seed and data give the same result
+	Rounds int    // Most passes over the settings
+}
+
+// ParameterChange is a proposed change to one level of a map setting
+type ParameterChange struct {
+	Setting  string
+	Level    string
+	Current  float64
+	Proposed float64
+}
+
+// OptimizeResult is how well the SM-2 settings predict the review log, and the settings that
+// predict it best
+type OptimizeResult struct {
+	Options       OptimizeOptions
+	Questions     int     // Questions with reviews that could be predicted
+	Reviews       int     // Reviews whose outcome was predicted
+	Recalled      int     // Predicted reviews rated medium or easier
+	CurrentError  float64 // Mean log loss of the current settings
+	ProposedError float64 // Mean log loss of the proposed settings
+	Changes       []ParameterChange
+	Settings      map[string]any // Proposed value of each changed setting, by setting name
+}
+
+// Improvement is the share by which the proposed settings lower the prediction error
+func (r *OptimizeResult) Improvement() float64 {
+	if r.CurrentError == 0 {
+		return 0
+	}
+	return (r.CurrentError - r.ProposedError) / r.CurrentError
+}
+
+// tunable is a level of a map setting the optimizer may change
+type tunable struct {
+	setting string
+	level   string
+	step    float64
+	integer bool
+}
+
+// tunableSettings are the SM-2 map settings the optimizer fits, with their initial steps
+var tunableSettings = []struct {
+	name    string
+	levels  []string
+	step    float64
+	integer bool
+}{
+	{"BaseIntervals", config.ImportanceLevels, 1, true},
+	{"NewQuestionBonus", config.FamiliarityLevels, 1, true},
+	{"MemoryMultipliers", config.MemoryLevels, 0.1, false},
+	{"StartEaseFactors", config.ImportanceLevels, 0.1, false},
+	{"ImportanceEaseBonus", config.ImportanceLevels, 0.05, false},
+	{"FamiliarityEasePenalty", config.FamiliarityLevels, 0.05, false},
+	{"MemoryEasePenalty", config.MemoryLevels, 0.05, false},
+}
+
+// Optimize measures how well the current SM-2 settings predict the outcomes in the review log
+// and searches for settings that predict them better. Each question's reviews are replayed
+// through the scheduler; a review is predicted to be recalled with a chance that falls from
+// optimizeRetention on the scheduled day the longer it came after the interval, and counts as
+// recalled when rated medium or easier. The search changes one level of one setting at a time
+// in an order drawn from the seed. Nothing is saved.
+func (u *QuestionUseCaseImpl) Optimize(opts OptimizeOptions) (*OptimizeResult, error) {
+	logger.Infof("Optimizing SM-2 settings: Seed=%d, Rounds=%d", opts.Seed, opts.Rounds)
+
+	if opts.Rounds < 1 || opts.Rounds > MaxOptimizeRounds {
+		return nil, errs.WrapValidationError(fmt.Errorf("invalid optimize rounds %d", opts.Rounds),
+			fmt.Sprintf("Please optimize with between 1 and %d rounds", MaxOptimizeRounds))
+	}
+
+	events, err := u.loadReviewEvents()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load review log")
+	}
+	histories := u.reviewHistories(events)
+
+	params := make([]tunable, 0)
+	values := make([]float64, 0)
+	for _, setting := range tunableSettings {
+		current, err := u.cfg.GetSettingValue(setting.name)
+		if err != nil {
+			return nil, errs.WrapInternalError(err, "Failed to read settings")
+		}
+		for _, level := range setting.levels {
+			params = append(params, tunable{setting: setting.name, level: level, step: setting.step, integer: setting.integer})
+			values = append(values, levelValue(current, level))
+		}
+	}
+
+	result := &OptimizeResult{Options: opts}
+	fit, err := u.replayReviews(histories, params, values)
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to replay the review log")
+	}
+	if fit.reviews < minOptimizeReviews {
+		return nil, errs.WrapBusinessError(fmt.Errorf("%d reviews to predict", fit.reviews),
+			fmt.Sprintf("Not enough review history to optimize: %d of the %d repeat reviews needed", fit.reviews, minOptimizeReviews))
+	}
+	result.Questions, result.Reviews, result.Recalled = fit.questions, fit.reviews, fit.recalled
+	result.CurrentError = fit.loss
+
+	best, bestLoss := u.searchSettings(histories, params, values, fit.loss, opts)
+	result.ProposedError = bestLoss
+	if bestLoss >= fit.loss {
+		result.ProposedError = fit.loss
+		return result, nil
+	}
+
+	result.Settings = make(map[string]any)
+	for i, param := range params {
+		if best[i] == values[i] {
+			continue
+		}
+		result.Changes = append(result.Changes, ParameterChange{Setting: param.setting, Level: param.level, Current: values[i], Proposed: best[i]})
+		result.Settings[param.setting] = settingMap(params, best, param.setting)
+	}
+	return result, nil
+}
+
+// ApplyOptimization saves the settings an optimization proposes
+func (u *QuestionUseCaseImpl) ApplyOptimization(result *OptimizeResult) error {
+	logger.Infof("Applying optimized settings: %d changes", len(result.Changes))
+
+	// Check every setting on a copy first, so that either all of them change or none
+	candidate := *u.cfg
+	names := slices.Sorted(maps.Keys(result.Settings))
+	for _, name := range names {
+		if err := candidate.SetSettingValue(name, result.Settings[name]); err != nil {
+			return wrapSettingError(err, name)
+		}
+	}
+	for _, name := range names {
+		if err := u.cfg.SetSettingValue(name, result.Settings[name]); err != nil {
+			return wrapSettingError(err, name)
+		}
+	}
+
+	if err := u.cfg.Save(); err != nil {
+		return errs.WrapInternalError(err, "Failed to save settings")
+	}
+	return nil
+}
+
+// searchSettings moves one setting level at a time by its step while that lowers the error
+// and drift cost, halving the steps of decimal settings when a whole round finds nothing better.
+// It returns the best values and their error.
+func (u *QuestionUseCaseImpl) searchSettings(histories [][]core.ReviewEvent, params []tunable, values []float64, loss float64, opts OptimizeOptions) ([]float64, float64) {
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	best, bestLoss := slices.Clone(values), loss
+	steps := make([]float64, len(params))
+	for i, param := range params {
+		steps[i] = param.step
+	}
+	drift := func(candidate []float64) float64 {
+		cost := 0.0
+		for i, param := range params {
+			cost += optimizeDriftCost * math.Pow((candidate[i]-values[i])/param.step, 2)
+		}
+		return cost
+	}
+	objective := loss
+
+	for range opts.Rounds {
+		improved := false
+		for _, i := range rng.Perm(len(params)) {
+			directions := []float64{1, -1}
+			if rng.IntN(2) == 0 {
+				directions = []float64{-1, 1}
+			}
+			for _, direction := range directions {
+				candidate := slices.Clone(best)
+				candidate[i] = math.Round((best[i]+direction*steps[i])*1000) / 1000
+				fit, err := u.replayReviews(histories, params, candidate)
+				if err != nil || fit.loss+drift(candidate) >= objective-1e-9 {
+					continue
+				}
+				best, bestLoss, objective, improved = candidate, fit.loss, fit.loss+drift(candidate), true
+				break
+			}
+		}
+		if improved {
+			continue
+		}
+
+		refined := false
+		for i, param := range params {
+			if !param.integer && steps[i]/2 >= minOptimizeStep {
+				steps[i] /= 2
+				refined = true
+			}
+		}
+		if !refined {
+			break
+		}
+	}
+	return best, bestLoss
+}
+
+// reviewFit is how well a set of settings predicts the review log
+type reviewFit struct {
+	questions int
+	reviews   int
+	recalled  int
+	loss      float64
+}
+
+// replayReviews replays every question's reviews with the given setting values and measures
+// how well the intervals they produce predict the outcome of the review after each. A replay
+// starts from the first logged review; when that was not the question's first review, the
+// interval it got is taken from the log.
+func (u *QuestionUseCaseImpl) replayReviews(histories [][]core.ReviewEvent, params []tunable, values []float64) (reviewFit, error) {
+	candidate := *u.cfg
+	for _, setting := range tunableSettings {
+		if err := candidate.SetSettingValue(setting.name, settingMap(params, values, setting.name)); err != nil {
+			return reviewFit{}, err
+		}
+	}
+
+	var fit reviewFit
+	if len(histories) == 0 {
+		return fit, nil
+	}
+	simClock := clock.NewSimClock(u.Clock.ToDate(histories[0][0].ReviewedAt))
+	scheduler := core.NewSM2SchedulerWithRand(&candidate, simClock, core.FixedRand{Value: 1})
+	for _, history := range histories {
+		q := &core.Question{ID: history[0].QuestionID}
+		predicted := false
+		for i, event := range history {
+			today := u.Clock.ToDate(event.ReviewedAt)
+			simClock.AdvanceDays(int(today.Sub(simClock.Today()).Hours() / 24))
+			q.Familiarity, q.Importance = event.Familiarity, event.Importance
+
+			switch {
+			case i > 0:
+				scheduler.Schedule(q, event.MemoryUse)
+			case event.EaseFactorBefore == 0:
+				scheduler.ScheduleNewQuestion(q, event.MemoryUse)
+			default:
+				q.EaseFactor, q.ReviewCount = event.EaseFactorAfter, 1
+				q.LastReviewed, q.NextReview = today, simClock.AddDays(today, event.IntervalDays)
+			}
+
+			if i+1 == len(history) {
+				break
+			}
+			next := history[i+1]
+			elapsed := u.Clock.ToDate(next.ReviewedAt).Sub(today).Hours() / 24
+			interval := q.NextReview.Sub(today).Hours() / 24
+			if elapsed < 1 || interval < 1 {
+				// A rating changed on the same day says nothing about forgetting
+				continue
+			}
+
+			recall := math.Pow(optimizeRetention, elapsed/interval)
+			recall = min(max(recall, 1e-6), 1-1e-6)
+			if next.Familiarity >= core.Medium {
+				fit.loss -= math.Log(recall)
+				fit.recalled++
+			} else {
+				fit.loss -= math.Log(1 - recall)
+			}
+			fit.reviews++
+			predicted = true
+		}
+		if predicted {
+			fit.questions++
+		}
+	}
+
+	if fit.reviews > 0 {
+		fit.loss /= float64(fit.reviews)
+	}
+	return fit, nil
+}
+
+// reviewHistories groups the review log by question, each in review order, ordered by question ID
+func (u *QuestionUseCaseImpl) reviewHistories(events []core.ReviewEvent) [][]core.ReviewEvent {
+	byQuestion := make(map[int][]core.ReviewEvent)
+	for _, event := range events {
+		byQuestion[event.QuestionID] = append(byQuestion[event.QuestionID], event)
+	}
+
+	histories := make([][]core.ReviewEvent, 0, len(byQuestion))
+	for _, id := range slices.Sorted(maps.Keys(byQuestion)) {
+		history := byQuestion[id]
+		slices.SortStableFunc(history, func(a, b core.ReviewEvent) int { return a.ReviewedAt.Compare(b.ReviewedAt) })
+		histories = append(histories, history)
+	}
+	return histories
+}
+
+// levelValue reads one level of a map setting as a float
+func levelValue(value any, level string) float64 {
+	switch values := value.(type) {
+	case map[string]int:
+		return float64(values[level])
+	case map[string]float64:
+		return values[level]
+	}
+	return 0
+}
+
+// settingMap builds the value of a map setting from the values of its levels
+func settingMap(params []tunable, values []float64, setting string) any {
+	ints, floats := map[string]int{}, map[string]float64{}
+	for i, param := range params {
+		if param.setting != setting {
+			continue
+		}
+		if param.integer {
+			ints[param.level] = int(values[i])
+		} else {
+			floats[param.level] = values[i]
+		}
+	}
+	if len(ints) > 0 {
+		return ints
+	}
+	return floats
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+// logReviews logs a review of each question every gap days, the first as the question is added,
+// ending before the test time; every review is rated with the given familiarity
+func logReviews(t *testing.T, useCase *QuestionUseCaseImpl, questions, reviews, gap int, familiarity core.Familiarity) {
+	t.Helper()
+	for id := 1; id <= questions; id++ {
+		for i := range reviews {
+			event := core.ReviewEvent{
+				QuestionID:  id,
+				Familiarity: familiarity,
+				MemoryUse:   core.MemoryReasoned,
+				Importance:  core.MediumImportance,
+				ReviewedAt:  testTime.AddDate(0, 0, -gap*(reviews-i)),
+			}
+			if i > 0 {
+				event.EaseFactorBefore = 2.0
+			}
+			if err := useCase.Storage.AppendReviewEvent(event); err != nil {
+				t.Fatalf("Failed to log review: %v", err)
+			}
+		}
+	}
+}
+
+func TestQuestionUseCase_Optimize_LongerIntervalsForGoodRecall(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	// Every question is still rated easy after a month, far past its first intervals
+	logReviews(t, useCase, 10, 4, 30, core.Easy)
+
+	opts := OptimizeOptions{Seed: DefaultOptimizeSeed, Rounds: DefaultOptimizeRounds}
+	result, err := useCase.Optimize(opts)
+	if err != nil {
+		t.Fatalf("Failed to optimize: %v", err)
+	}
+
+	if result.Questions != 10 || result.Reviews != 30 || result.Recalled != 30 {
+		t.Errorf("Expected 30 predicted reviews of 10 questions, all recalled, got %+v", result)
+	}
+	if result.ProposedError >= result.CurrentError || result.Improvement() <= 0 {
+		t.Fatalf("Expected the proposed settings to predict better, got %v and %v", result.CurrentError, result.ProposedError)
+	}
+
+	base := slices.IndexFunc(result.Changes, func(c ParameterChange) bool { return c.Setting == "BaseIntervals" && c.Level == "medium" })
+	if base < 0 || result.Changes[base].Proposed <= result.Changes[base].Current {
+		t.Errorf("Expected a longer base interval for medium importance, got %+v", result.Changes)
+	}
+	for _, change := range result.Changes {
+		if _, ok := result.Settings[change.Setting]; !ok {
+			t.Errorf("Expected the proposed value of %s, got %v", change.Setting, result.Settings)
+		}
+	}
+
+	// Nothing is saved until the result is applied
+	if useCase.cfg.BaseIntervals["medium"] != int(result.Changes[base].Current) {
+		t.Errorf("Expected the settings to be unchanged, got %v", useCase.cfg.BaseIntervals)
+	}
+
+	again, _ := useCase.Optimize(opts)
+	if !slices.Equal(again.Changes, result.Changes) || again.ProposedError != result.ProposedError {
+		t.Errorf("Expected the same seed to give the same proposal, got %+v and %+v", again.Changes, result.Changes)
+	}
+
+	if err := useCase.ApplyOptimization(result); err != nil {
+		t.Fatalf("Failed to apply the proposal: %v", err)
+	}
+	if useCase.cfg.BaseIntervals["medium"] != int(result.Changes[base].Proposed) {
+		t.Errorf("Expected the proposed base interval, got %v", useCase.cfg.BaseIntervals)
+	}
+	if applied, _ := useCase.Optimize(opts); applied.CurrentError != result.ProposedError {
+		t.Errorf("Expected the applied settings to have the proposed error %v, got %v", result.ProposedError, applied.CurrentError)
+	}
+}
+
+func TestQuestionUseCase_Optimize_NotEnoughHistory(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	logReviews(t, useCase, 3, 3, 10, core.Medium)
+
+	_, err := useCase.Optimize(OptimizeOptions{Seed: 1, Rounds: 10})
+	if errs.ExitCode(err) != errs.ExitBusiness {
+		t.Errorf("Expected a business error for 6 predicted reviews, got %v", err)
+	}
+}
+
+func TestQuestionUseCase_Optimize_InvalidRounds(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	for _, rounds := range []int{0, MaxOptimizeRounds + 1} {
+		if _, err := useCase.Optimize(OptimizeOptions{Rounds: rounds}); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %d rounds, got %v", rounds, err)
+		}
+	}
+}
+
+func TestQuestionUseCase_ApplyOptimization_AllOrNothing(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	err := useCase.ApplyOptimization(&OptimizeResult{Settings: map[string]any{
+		"BaseIntervals":    map[string]int{"medium": 9},
+		"StartEaseFactors": map[string]float64{"low": 9.0},
+	}})
+	if errs.ExitCode(err) != errs.ExitValidation {
+		t.Errorf("Expected a validation error for an invalid start ease factor, got %v", err)
+	}
+	if useCase.cfg.BaseIntervals["medium"] == 9 {
+		t.Error("Expected no setting to change when one is invalid")
+	}
+}
+
+func TestQuestionUseCase_ApplyOptimization_SchedulesWithNewSettings(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	err := useCase.ApplyOptimization(&OptimizeResult{Settings: map[string]any{
+		"BaseIntervals": map[string]int{"medium": 3},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to apply the proposal: %v", err)
+	}
+
+	// The scheduler the use case was created with picks up the applied settings
+	question := &core.Question{Importance: core.MediumImportance, Familiarity: core.VeryHard}
+	useCase.Scheduler.ScheduleNewQuestion(question, core.MemoryReasoned)
+	if days := int(question.NextReview.Sub(question.LastReviewed).Hours() / 24); days != 3 {
+		t.Errorf("Expected the applied 3 day first interval, got %d", days)
+	}
+}
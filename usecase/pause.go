@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+	"github.com/eannchen/leetsolv/storage"
+)
+
+// ResumeResult is the outcome of resuming reviews
+type ResumeResult struct {
+	Pause   core.Pause // The pause that ended
+	Days    int        // Days the next reviews moved by
+	Shifted int        // Questions whose next review moved
+}
+
+// PauseReviews starts a pause from today. While it lasts, the paused days do not count as overdue.
+func (u *QuestionUseCaseImpl) PauseReviews() (*core.Pause, error) {
+	logger.Infof("Pausing reviews")
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	if _, paused := store.PausedSince(); paused {
+		return nil, errs.ErrAlreadyPaused
+	}
+
+	store.Pauses = append(store.Pauses, core.Pause{Start: u.Clock.Today()})
+	if err := u.Storage.SaveQuestionStore(store); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
+	}
+
+	// An undone resume could otherwise be redone into the new pause
+	u.clearRedoHistory()
+	return &store.Pauses[len(store.Pauses)-1], nil
+}
+
+// ResumeReviews ends the ongoing pause and moves the next review of every active or buried
+// question by the paused days, so the pause leaves no backlog behind. Questions added or reviewed
+// after the day the pause began keep their schedule. The moves are recorded as a single delta so
+// one undo reverts them and pauses reviews again.
+func (u *QuestionUseCaseImpl) ResumeReviews() (*ResumeResult, error) {
+	logger.Infof("Resuming reviews")
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	since, paused := store.PausedSince()
+	if !paused {
+		return nil, errs.ErrNotPaused
+	}
+
+	today := u.Clock.Today()
+	pause := &store.Pauses[len(store.Pauses)-1]
+	days := int(today.Sub(u.Clock.ToDate(since)).Hours() / 24)
+
+	// A pause resumed on the day it began moves nothing and is not worth keeping
+	if days <= 0 {
+		result := &ResumeResult{Pause: *pause}
+		store.Pauses = store.Pauses[:len(store.Pauses)-1]
+		if err := u.Storage.SaveQuestionStore(store); err != nil {
+			return nil, errs.WrapInternalError(err, "Failed to save question store")
+		}
+		return result, nil
+	}
+
+	deltas, err := u.Storage.LoadDeltas()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load deltas")
+	}
+
+	now := u.Clock.Now()
+	var batch []core.Delta
+	for _, q := range store.Questions {
+		// Reviewed since the pause began, so already scheduled from that review
+		if u.Clock.ToDate(q.LastReviewed).After(since) {
+			continue
+		}
+		// Suspended and archived questions come up for no review the pause could have held up
+		if !q.IsScheduled() {
+			continue
+		}
+		newState := *q
+		newState.NextReview = u.Clock.AddDays(q.NextReview, days)
+		newState.UpdatedAt = now
+		store.Questions[q.ID] = &newState
+
+		batch = append(batch, core.Delta{
+			Action:     core.ActionUpdate,
+			QuestionID: q.ID,
+			OldState:   q,
+			NewState:   &newState,
+			CreatedAt:  now,
+		})
+	}
+	pause.End = today
+
+	if err := u.Storage.SaveQuestionStore(store); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
+	}
+
+	// Recorded even when nothing moved, so that undo pauses reviews again
+	deltas = u.appendDelta(deltas, core.Delta{
+		Action:    core.ActionResume,
+		Batch:     batch,
+		CreatedAt: now,
+	})
+	u.saveNewHistory(deltas)
+	return &ResumeResult{Pause: *pause, Days: days, Shifted: len(batch)}, nil
+}
+
+// undoResume reverts the moved next reviews and reopens the pause the resume ended
+func (u *QuestionUseCaseImpl) undoResume(store *storage.QuestionStore, delta core.Delta) error {
+	if len(store.Pauses) == 0 {
+		return errors.New("cannot undo resume with no pause recorded")
+	}
+	if err := u.undoBatch(store, delta); err != nil {
+		return err
+	}
+	store.Pauses[len(store.Pauses)-1].End = time.Time{}
+	return nil
+}
+
+// checkPauseReplay verifies that a resume can be undone, or redone: undo needs the pause it ended
+// to be the last one, and redo needs that pause to be ongoing again.
+func checkPauseReplay(store *storage.QuestionStore, paused bool, undo bool) error {
+	switch {
+	case undo && len(store.Pauses) == 0:
+		return errors.New("cannot undo resume: no pause is recorded")
+	case undo && paused:
+		return errors.New("cannot undo resume: reviews have been paused again since")
+	case !undo && !paused:
+		return errors.New("cannot redo resume: reviews are not paused")
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/clock"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+// advanceDays moves the test clock forward
+func advanceDays(useCase *QuestionUseCaseImpl, days int) {
+	mockClock := useCase.Clock.(*clock.MockClock)
+	mockClock.FixedTime = mockClock.FixedTime.AddDate(0, 0, days)
+}
+
+func TestQuestionUseCase_PauseResume(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+	store, _ := useCase.Storage.LoadQuestionStore()
+	before := store.Questions[1].NextReview
+
+	if _, err := useCase.PauseReviews(); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	advanceDays(useCase, 7)
+
+	// Added during the pause, so already scheduled from the day it was added
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/3sum/")
+	added := store.Questions[2].NextReview
+
+	result, err := useCase.ResumeReviews()
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	if result.Days != 7 || result.Shifted != 1 || !result.Pause.End.Equal(useCase.Clock.Today()) {
+		t.Errorf("Expected 1 question moved by 7 days and the pause ended today, got %+v", result)
+	}
+	if got := store.Questions[1].NextReview; !got.Equal(before.AddDate(0, 0, 7)) {
+		t.Errorf("Expected the next review moved from %v by 7 days, got %v", before, got)
+	}
+	if got := store.Questions[2].NextReview; !got.Equal(added) {
+		t.Errorf("Expected the question added during the pause to keep its schedule, got %v", got)
+	}
+	if _, paused := store.PausedSince(); paused {
+		t.Error("Expected reviews not to be paused")
+	}
+
+	history, _ := useCase.GetHistory()
+	if len(history) != 3 || history[0].Action != core.ActionResume || len(history[0].Batch) != 1 {
+		t.Errorf("Expected the resume recorded as one batch, got %v", deltaActions(history))
+	}
+}
+
+func TestQuestionUseCase_PauseResume_OnlyScheduledQuestions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/", "https://leetcode.com/problems/3sum/",
+		"https://leetcode.com/problems/4sum/", "https://leetcode.com/problems/3sum-closest/")
+	if _, err := useCase.SetQuestionState("2", core.StateSuspended); err != nil {
+		t.Fatalf("Failed to suspend: %v", err)
+	}
+	if _, err := useCase.SetQuestionState("3", core.StateArchived); err != nil {
+		t.Fatalf("Failed to archive: %v", err)
+	}
+	if _, err := useCase.BuryQuestion("4", 3, time.Time{}); err != nil {
+		t.Fatalf("Failed to bury: %v", err)
+	}
+	store, _ := useCase.Storage.LoadQuestionStore()
+	before := make(map[int]time.Time)
+	for id, q := range store.Questions {
+		before[id] = q.NextReview
+	}
+
+	if _, err := useCase.PauseReviews(); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	advanceDays(useCase, 7)
+	result, err := useCase.ResumeReviews()
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+
+	if result.Shifted != 2 {
+		t.Errorf("Expected the active and the buried question moved, got %d", result.Shifted)
+	}
+	for id, moved := range map[int]bool{1: true, 2: false, 3: false, 4: true} {
+		want := before[id]
+		if moved {
+			want = want.AddDate(0, 0, 7)
+		}
+		if got := store.Questions[id].NextReview; !got.Equal(want) {
+			t.Errorf("Expected question %d due on %v, got %v", id, want, got)
+		}
+	}
+}
+
+func TestQuestionUseCase_PauseResume_Errors(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	if _, err := useCase.ResumeReviews(); err != errs.ErrNotPaused {
+		t.Errorf("Expected ErrNotPaused, got %v", err)
+	}
+	if _, err := useCase.PauseReviews(); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	if _, err := useCase.PauseReviews(); err != errs.ErrAlreadyPaused {
+		t.Errorf("Expected ErrAlreadyPaused, got %v", err)
+	}
+}
+
+func TestQuestionUseCase_Resume_SameDay(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+
+	if _, err := useCase.PauseReviews(); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	result, err := useCase.ResumeReviews()
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	if result.Days != 0 || len(store.Pauses) != 0 {
+		t.Errorf("Expected the empty pause to be dropped, got %+v and %+v", result, store.Pauses)
+	}
+	if history, _ := useCase.GetHistory(); len(history) != 1 {
+		t.Errorf("Expected no resume in the history, got %v", deltaActions(history))
+	}
+}
+
+func TestQuestionUseCase_UndoRedo_Resume(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+	store, _ := useCase.Storage.LoadQuestionStore()
+	before := store.Questions[1].NextReview
+
+	if _, err := useCase.PauseReviews(); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	advanceDays(useCase, 5)
+	if _, err := useCase.ResumeReviews(); err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo resume: %v", err)
+	}
+	if !store.Questions[1].NextReview.Equal(before) {
+		t.Errorf("Expected the next review back on %v, got %v", before, store.Questions[1].NextReview)
+	}
+	if since, paused := store.PausedSince(); !paused || !since.Equal(testTime.Truncate(24*time.Hour)) {
+		t.Errorf("Expected reviews paused since the test day again, got %v, %t", since, paused)
+	}
+
+	if _, err := useCase.Redo(1); err != nil {
+		t.Fatalf("Failed to redo resume: %v", err)
+	}
+	if !store.Questions[1].NextReview.Equal(before.AddDate(0, 0, 5)) {
+		t.Errorf("Expected the next review moved by 5 days again, got %v", store.Questions[1].NextReview)
+	}
+	if _, paused := store.PausedSince(); paused || !store.Pauses[0].End.Equal(useCase.Clock.Today()) {
+		t.Errorf("Expected the pause to end today again, got %+v", store.Pauses)
+	}
+}
+
+func TestQuestionUseCase_UndoResume_PausedAgain(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+	if _, err := useCase.PauseReviews(); err != nil {
+		t.Fatalf("Failed to pause: %v", err)
+	}
+	advanceDays(useCase, 3)
+	if _, err := useCase.ResumeReviews(); err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	if _, err := useCase.PauseReviews(); err != nil {
+		t.Fatalf("Failed to pause again: %v", err)
+	}
+
+	if _, err := useCase.Undo(1); errs.ExitCode(err) != errs.ExitBusiness {
+		t.Errorf("Expected a business error undoing a resume during a later pause, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+	"github.com/eannchen/leetsolv/storage"
+)
+
+// Redo reapplies the most recently undone actions in the order they were first made, and returns
+// them in that order. The reapplied actions move back to the history.
+func (u *QuestionUseCaseImpl) Redo(count int) ([]core.Delta, error) {
+	logger.Infof("Redoing actions: Count=%d", count)
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	redoDeltas, err := u.Storage.LoadRedoDeltas()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load redo deltas")
+	}
+	if len(redoDeltas) == 0 {
+		return nil, errs.ErrNoActionsToRedo
+	}
+	if count < 1 || count > len(redoDeltas) {
+		return nil, errs.WrapBusinessError(fmt.Errorf("cannot redo %d of %d actions", count, len(redoDeltas)),
+			fmt.Sprintf("Only %d actions can be redone", len(redoDeltas)))
+	}
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	deltas, err := u.Storage.LoadDeltas()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load deltas")
+	}
+
+	// The next action to redo is the last one undone
+	redone := slices.Clone(redoDeltas[len(redoDeltas)-count:])
+	slices.Reverse(redone)
+
+	if err := checkReplay(store, redone, false); err != nil {
+		return nil, errs.WrapBusinessError(err, "The undone actions no longer match your questions, so they cannot be redone")
+	}
+	for _, delta := range redone {
+		redoDelta(store, delta)
+	}
+
+	// Save the updated questions
+	if err := u.Storage.SaveQuestionStore(store); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
+	}
+
+	// Move the deltas back to the history only after successful redo
+	for _, delta := range redone {
+		deltas = u.appendDelta(deltas, delta)
+	}
+	if err := u.Storage.SaveDeltas(deltas); err != nil {
+		logger.Errorf("Failed to save deltas: %v", err)
+	}
+	if err := u.Storage.SaveRedoDeltas(redoDeltas[:len(redoDeltas)-count]); err != nil {
+		logger.Errorf("Failed to save redo deltas: %v", err)
+	}
+
+	// Restore the reviews the undo left out of the review log
+	if err := u.Storage.MarkReviewEvents(redone, false); err != nil {
+		logger.Errorf("Failed to restore review events: %v", err)
+	}
+
+	return redone, nil
+}
+
+// GetRedoHistory returns the actions that can be redone, the next one to redo first
+func (u *QuestionUseCaseImpl) GetRedoHistory() ([]core.Delta, error) {
+	redoDeltas, err := u.Storage.LoadRedoDeltas()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load redo deltas")
+	}
+
+	// Copy the deltas to avoid modifying the cache
+	reversed := slices.Clone(redoDeltas)
+	slices.Reverse(reversed)
+	return reversed, nil
+}
+
+// redoDelta applies an action again; checkReplay must have accepted it
+func redoDelta(store *storage.QuestionStore, delta core.Delta) {
+	switch delta.Action {
+	case core.ActionAdd:
+		store.Questions[delta.QuestionID] = delta.NewState
+		store.IndexQuestion(delta.NewState)
+		store.MaxID = max(store.MaxID, delta.QuestionID)
+	case core.ActionUpdate:
+		store.UnindexQuestion(store.Questions[delta.QuestionID])
+		store.Questions[delta.QuestionID] = delta.NewState
+		store.IndexQuestion(delta.NewState)
+	case core.ActionDelete:
+		store.UnindexQuestion(store.Questions[delta.QuestionID])
+		delete(store.Questions, delta.QuestionID)
+	case core.ActionImport:
+		for _, step := range delta.Batch {
+			redoDelta(store, step)
+		}
+	case core.ActionResume:
+		for _, step := range delta.Batch {
+			redoDelta(store, step)
+		}
+		// The pause ended on the day of the resume
+		at := delta.CreatedAt.UTC()
+		store.Pauses[len(store.Pauses)-1].End = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// checkReplay verifies that the deltas can be undone, or redone, one after the other: every step
+// needs the states of its action, and its question must be exactly as the action left it, or as
+// it found it, so that no change made outside the history is lost. Resumes also need reviews to
+// be paused, or not, as they were.
+func checkReplay(store *storage.QuestionStore, deltas []core.Delta, undo bool) error {
+	verb := "redo"
+	if undo {
+		verb = "undo"
+	}
+
+	// The state of a question, nil once removed, and whether reviews are paused, after the steps
+	// checked so far
+	states := make(map[int]*core.Question)
+	_, paused := store.PausedSince()
+	for _, delta := range deltas {
+		if delta.Action == core.ActionResume {
+			if err := checkPauseReplay(store, paused, undo); err != nil {
+				return err
+			}
+			paused = undo
+		}
+
+		steps := slices.Clone(deltaSteps(delta))
+		if undo {
+			slices.Reverse(steps)
+		}
+
+		for _, step := range steps {
+			if !hasRequiredStates(step) {
+				return fmt.Errorf("cannot %s %s of question %d: the history entry is missing the question state", verb, step.Action, step.QuestionID)
+			}
+
+			current, ok := states[step.QuestionID]
+			if !ok {
+				current = store.Questions[step.QuestionID]
+			}
+			before, after := step.OldState, step.NewState
+			if step.Action == core.ActionAdd {
+				before = nil
+			}
+			if step.Action == core.ActionDelete {
+				after = nil
+			}
+			if undo {
+				before, after = after, before
+			}
+			if !sameState(current, before) {
+				return fmt.Errorf("cannot %s %s of question %d: the question has changed since", verb, step.Action, step.QuestionID)
+			}
+			states[step.QuestionID] = after
+		}
+	}
+	return nil
+}
+
+// sameState reports whether a question is in the expected state; nil stands for no question
+func sameState(current, expected *core.Question) bool {
+	if current == nil || expected == nil {
+		return current == nil && expected == nil
+	}
+	return current.Equal(expected)
+}
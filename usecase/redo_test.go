@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+// deltaActions returns the actions of the deltas in order
+func deltaActions(deltas []core.Delta) []core.ActionType {
+	actions := make([]core.ActionType, 0, len(deltas))
+	for _, delta := range deltas {
+		actions = append(actions, delta.Action)
+	}
+	return actions
+}
+
+func TestQuestionUseCase_UndoRedo_SeveralActions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/", "https://leetcode.com/problems/3sum/", "https://leetcode.com/problems/4sum/")
+	if _, err := useCase.UpsertQuestion("https://leetcode.com/problems/two-sum/", "updated", nil, core.Easy, core.HighImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+		t.Fatalf("Failed to update question: %v", err)
+	}
+	if _, err := useCase.DeleteQuestion("2"); err != nil {
+		t.Fatalf("Failed to delete question: %v", err)
+	}
+
+	undone, err := useCase.Undo(3)
+	if err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if got := deltaActions(undone); len(got) != 3 || got[0] != core.ActionDelete || got[1] != core.ActionUpdate || got[2] != core.ActionAdd {
+		t.Fatalf("Expected the undone actions newest first, got %v", got)
+	}
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	if _, ok := store.Questions[3]; ok || store.Questions[1].Note != "note" || store.Questions[2] == nil {
+		t.Errorf("Expected question 3 removed, question 1 reverted and question 2 restored, got %+v", store.Questions)
+	}
+	if _, ok := store.URLIndex["https://leetcode.com/problems/4sum/"]; ok {
+		t.Error("Expected the removed question to leave the URL index")
+	}
+	if history, _ := useCase.GetHistory(); len(history) != 2 {
+		t.Errorf("Expected 2 actions left in the history, got %d", len(history))
+	}
+
+	// The next action to redo is the oldest of the undone ones
+	redoHistory, _ := useCase.GetRedoHistory()
+	if got := deltaActions(redoHistory); len(got) != 3 || got[0] != core.ActionAdd || got[2] != core.ActionDelete {
+		t.Fatalf("Expected the redo history oldest first, got %v", got)
+	}
+
+	redone, err := useCase.Redo(2)
+	if err != nil {
+		t.Fatalf("Failed to redo: %v", err)
+	}
+	if got := deltaActions(redone); len(got) != 2 || got[0] != core.ActionAdd || got[1] != core.ActionUpdate {
+		t.Fatalf("Expected the redone actions in their original order, got %v", got)
+	}
+	if store.Questions[3] == nil || store.Questions[1].Note != "updated" || store.URLIndex["https://leetcode.com/problems/4sum/"] != 3 {
+		t.Errorf("Expected question 3 back and question 1 updated again, got %+v", store.Questions)
+	}
+	if history, _ := useCase.GetHistory(); len(history) != 4 || history[0].Action != core.ActionUpdate {
+		t.Errorf("Expected the redone actions back in the history, got %v", deltaActions(history))
+	}
+
+	// A new action cannot be followed by the actions undone before it
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/3sum-closest/")
+	if redoHistory, _ := useCase.GetRedoHistory(); len(redoHistory) != 0 {
+		t.Errorf("Expected a new action to clear the redo history, got %d", len(redoHistory))
+	}
+}
+
+func TestQuestionUseCase_UndoRedo_ReviewLog(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+	if _, err := useCase.UpsertQuestion("https://leetcode.com/problems/two-sum/", "updated", nil, core.Easy, core.HighImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+		t.Fatalf("Failed to update question: %v", err)
+	}
+
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	// The undone review stays in the log, marked reverted, and is left out of stats
+	events, _ := useCase.Storage.LoadReviewEvents()
+	if len(events) != 2 || events[0].Reverted || !events[1].Reverted {
+		t.Fatalf("Expected the undone review marked reverted, got %+v", events)
+	}
+	if stats, err := useCase.GetStats(); err != nil || stats.Reviews != 1 {
+		t.Errorf("Expected 1 review in the stats, got %+v, %v", stats, err)
+	}
+
+	if _, err := useCase.Redo(1); err != nil {
+		t.Fatalf("Failed to redo: %v", err)
+	}
+	if stats, err := useCase.GetStats(); err != nil || stats.Reviews != 2 {
+		t.Errorf("Expected the redone review back in the stats, got %+v, %v", stats, err)
+	}
+
+	if _, err := useCase.Undo(2); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if active, _ := useCase.loadReviewEvents(); len(active) != 0 {
+		t.Errorf("Expected no reviews left after undoing both, got %+v", active)
+	}
+}
+
+func TestQuestionUseCase_UndoRedo_Import(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+	if _, err := useCase.ImportQuestions([]core.Question{
+		{URL: "https://leetcode.com/problems/two-sum/", Note: "imported"},
+		{URL: "https://leetcode.com/problems/3sum/"},
+	}, ImportOverwrite, false); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo import: %v", err)
+	}
+	if _, err := useCase.Redo(1); err != nil {
+		t.Fatalf("Failed to redo import: %v", err)
+	}
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	if len(store.Questions) != 2 || store.Questions[1].Note != "imported" || store.MaxID != 2 {
+		t.Errorf("Expected the import to be applied again, got MaxID %d and %+v", store.MaxID, store.Questions)
+	}
+	if ids := store.NoteTrie.SearchPrefix("imported"); len(ids) != 1 {
+		t.Errorf("Expected the imported note to be indexed, got %v", ids)
+	}
+}
+
+func TestQuestionUseCase_Undo_MoreThanHistory(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+
+	_, err := useCase.Undo(2)
+	var codedErr *errs.CodedError
+	if !errors.As(err, &codedErr) || codedErr.Kind != errs.BusinessErrorKind {
+		t.Fatalf("Expected a business error, got %v", err)
+	}
+	if _, err := useCase.GetQuestion("1"); err != nil {
+		t.Error("Expected nothing to be undone")
+	}
+}
+
+func TestQuestionUseCase_Undo_ChecksEveryActionFirst(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/", "https://leetcode.com/problems/3sum/")
+
+	// Question 1 disappears without the history knowing, so its add cannot be undone
+	store, _ := useCase.Storage.LoadQuestionStore()
+	delete(store.Questions, 1)
+
+	if _, err := useCase.Undo(2); err == nil {
+		t.Fatal("Expected an error when the history does not match the questions")
+	}
+	if _, ok := store.Questions[2]; !ok {
+		t.Error("Expected the newer action to stay applied")
+	}
+	if history, _ := useCase.GetHistory(); len(history) != 2 {
+		t.Errorf("Expected the history to be unchanged, got %d actions", len(history))
+	}
+}
+
+func TestQuestionUseCase_Undo_QuestionEditedOutsideHistory(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+	if _, err := useCase.UpsertQuestion("https://leetcode.com/problems/two-sum/", "updated", nil, core.Easy, core.HighImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+		t.Fatalf("Failed to update question: %v", err)
+	}
+
+	// The note changes without the history knowing, as if the files were edited by hand
+	store, _ := useCase.Storage.LoadQuestionStore()
+	edited := *store.Questions[1]
+	edited.Note = "edited by hand"
+	store.Questions[1] = &edited
+
+	if _, err := useCase.Undo(1); err == nil {
+		t.Fatal("Expected an error when undoing over a change made outside the history")
+	}
+	if store.Questions[1].Note != "edited by hand" {
+		t.Errorf("Expected the outside change to be kept, got %q", store.Questions[1].Note)
+	}
+}
+
+func TestQuestionUseCase_Redo_QuestionChanged(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+
+	// Another question took the ID, as if the files were edited by hand
+	store, _ := useCase.Storage.LoadQuestionStore()
+	store.Questions[1] = createTestQuestion(1, "https://leetcode.com/problems/3sum/")
+
+	if _, err := useCase.Redo(1); err == nil {
+		t.Fatal("Expected an error when redoing over another question")
+	}
+	if store.Questions[1].URL != "https://leetcode.com/problems/3sum/" {
+		t.Error("Expected the other question to be kept")
+	}
+	if redoHistory, _ := useCase.GetRedoHistory(); len(redoHistory) != 1 {
+		t.Errorf("Expected the action to stay in the redo history, got %d", len(redoHistory))
+	}
+}
+
+func TestQuestionUseCase_Redo_NoActions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	if _, err := useCase.Redo(1); err != errs.ErrNoActionsToRedo {
+		t.Errorf("Expected ErrNoActionsToRedo, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/clock"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+)
+
+// MaxSimulationDays limits how far ahead a simulation runs
+const MaxSimulationDays = 365
+
+// simulationPeaks is the number of busiest days a simulation reports
+const simulationPeaks = 3
+
+// SimulationOptions are the assumptions a schedule simulation runs under
+type SimulationOptions struct {
+	Days         int
+	Familiarity  *core.Familiarity // Outcome of every review; nil repeats each question's current familiarity
+	Memory       core.MemoryUse    // Memory use of every review
+	NewQuestions int               // Hypothetical questions added during the simulation
+	NewPerDay    int               // Hypothetical questions added per day; 0 adds them all on the first day
+}
+
+// SimulatedDay is the workload of one simulated day
+type SimulatedDay struct {
+	Date    time.Time
+	Reviews int // Due questions reviewed
+	Added   int // Hypothetical questions added
+}
+
+// Load is the number of questions worked on during the day
+func (d SimulatedDay) Load() int {
+	return d.Reviews + d.Added
+}
+
+// SimulatedQuestion is how the intervals of one question grow during a simulation
+type SimulatedQuestion struct {
+	ID        int // Numbered from 1 among the hypothetical questions when New is set
+	URL       string
+	New       bool
+	Intervals []int // Days until the next review after each simulated review, in order
+}
+
+// SimulationResult is the outcome of a schedule simulation
+type SimulationResult struct {
+	Options   SimulationOptions
+	Days      []SimulatedDay
+	Peaks     []SimulatedDay      // Busiest days, busiest first
+	Questions []SimulatedQuestion // Questions reviewed or added during the simulation
+}
+
+// TotalLoad is the number of questions worked on over the whole simulation
+func (r *SimulationResult) TotalLoad() int {
+	total := 0
+	for _, day := range r.Days {
+		total += day.Load()
+	}
+	return total
+}
+
+// simulatedCard is a question under simulation and the intervals it went through
+type simulatedCard struct {
+	question *core.Question
+	result   SimulatedQuestion
+}
+
+// Simulate replays the schedule forward from today, reviewing every question on the day it
+// is due with the assumed outcome; suspended and archived questions are left out. It works
+// on copies of the questions and changes no data.
+func (u *QuestionUseCaseImpl) Simulate(opts SimulationOptions) (*SimulationResult, error) {
+	logger.Infof("Simulating schedule: Days=%d, NewQuestions=%d, NewPerDay=%d", opts.Days, opts.NewQuestions, opts.NewPerDay)
+
+	if opts.Days < 1 || opts.Days > MaxSimulationDays || opts.NewQuestions < 0 || opts.NewPerDay < 0 {
+		return nil, errs.WrapValidationError(fmt.Errorf("invalid simulation options %+v", opts),
+			fmt.Sprintf("Please simulate between 1 and %d days", MaxSimulationDays))
+	}
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	if len(store.Questions) == 0 && opts.NewQuestions == 0 {
+		return nil, errs.ErrNoQuestionsAvailable
+	}
+
+	// The simulated clock drives a scheduler of its own; a fixed Rand keeps the
+	// randomized intervals on their unshifted value, so the same data gives the same result.
+	// Load balancing reads the simulated schedule rather than the stored one.
+	schedule := &simulatedSchedule{cards: make([]*simulatedCard, 0, len(store.Questions)+opts.NewQuestions)}
+	schedule.pausedSince, schedule.paused = store.PausedSince()
+	simClock := clock.NewSimClock(u.Clock.Today())
+	scheduler := core.NewSchedulerWithState(u.cfg, simClock, core.FixedRand{Value: 1}, schedule)
+
+	for _, id := range slices.Sorted(maps.Keys(store.Questions)) {
+		if !store.Questions[id].IsScheduled() {
+			continue
+		}
+		copied := *store.Questions[id]
+		// A buried question comes due no earlier than the end of its burial
+		copied.NextReview = copied.ReviewDate()
+		schedule.cards = append(schedule.cards, &simulatedCard{
+			question: &copied,
+			result:   SimulatedQuestion{ID: id, URL: copied.URL},
+		})
+	}
+
+	result := &SimulationResult{Options: opts, Days: make([]SimulatedDay, 0, opts.Days)}
+	remaining := opts.NewQuestions
+	for range opts.Days {
+		today := simClock.Today()
+		day := SimulatedDay{Date: today}
+
+		// Review what is due before adding, as the new questions are not due yet
+		for _, card := range schedule.cards {
+			if simClock.ToDate(card.question.NextReview).After(today) {
+				continue
+			}
+			if opts.Familiarity != nil {
+				card.question.Familiarity = *opts.Familiarity
+			}
+			scheduler.Schedule(card.question, opts.Memory)
+			card.recordInterval(today)
+			day.Reviews++
+		}
+
+		added := remaining
+		if opts.NewPerDay > 0 {
+			added = min(remaining, opts.NewPerDay)
+		}
+		for range added {
+			familiarity := core.Medium
+			if opts.Familiarity != nil {
+				familiarity = *opts.Familiarity
+			}
+			number := opts.NewQuestions - remaining + 1
+			card := &simulatedCard{
+				// A negative ID keeps hypothetical questions apart from stored ones
+				question: &core.Question{ID: -number, Familiarity: familiarity, Importance: core.MediumImportance},
+				result:   SimulatedQuestion{ID: number, New: true},
+			}
+			scheduler.ScheduleNewQuestion(card.question, opts.Memory)
+			card.recordInterval(today)
+			schedule.cards = append(schedule.cards, card)
+			remaining--
+		}
+		day.Added = added
+
+		result.Days = append(result.Days, day)
+		simClock.AdvanceDays(1)
+	}
+
+	for _, card := range schedule.cards {
+		if len(card.result.Intervals) > 0 {
+			result.Questions = append(result.Questions, card.result)
+		}
+	}
+	result.Peaks = busiest(result.Days, SimulatedDay.Load, simulationPeaks)
+	return result, nil
+}
+
+// simulatedSchedule is the schedule state of a simulation: the questions under simulation and
+// the pause, if any, that the simulation started in
+type simulatedSchedule struct {
+	cards       []*simulatedCard
+	pausedSince time.Time
+	paused      bool
+}
+
+// DueCounts implements core.ScheduleState over the questions under simulation
+func (s *simulatedSchedule) DueCounts(start time.Time, days int, excludeID int) []int {
+	counts := make([]int, days)
+	for _, card := range s.cards {
+		if card.question.ID == excludeID {
+			continue
+		}
+		next := card.question.NextReview.UTC()
+		date := time.Date(next.Year(), next.Month(), next.Day(), 0, 0, 0, 0, time.UTC)
+		if offset := int(date.Sub(start).Hours() / 24); !date.Before(start) && offset < days {
+			counts[offset]++
+		}
+	}
+	return counts
+}
+
+// PausedSince implements core.ScheduleState with the pause the simulation started in
+func (s *simulatedSchedule) PausedSince() (time.Time, bool) {
+	return s.pausedSince, s.paused
+}
+
+func (c *simulatedCard) recordInterval(today time.Time) {
+	days := int(c.question.NextReview.Sub(today).Hours() / 24)
+	c.result.Intervals = append(c.result.Intervals, days)
+}
+
+// busiest returns up to n days with any load, busiest first and earliest first among equals
+func busiest[Day any](days []Day, load func(Day) int, n int) []Day {
+	busy := slices.DeleteFunc(slices.Clone(days), func(day Day) bool { return load(day) == 0 })
+	slices.SortStableFunc(busy, func(a, b Day) int { return cmp.Compare(load(b), load(a)) })
+	return busy[:min(n, len(busy))]
+}
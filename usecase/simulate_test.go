@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+func TestQuestionUseCase_Simulate_ReviewsDueQuestions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/", "https://leetcode.com/problems/3sum/")
+	store, _ := useCase.Storage.LoadQuestionStore()
+	nextReview := store.Questions[1].NextReview
+	dueDay := int(nextReview.Sub(useCase.Clock.Today()).Hours() / 24)
+
+	familiarity := core.Medium
+	result, err := useCase.Simulate(SimulationOptions{Days: 30, Familiarity: &familiarity})
+	if err != nil {
+		t.Fatalf("Failed to simulate: %v", err)
+	}
+
+	if len(result.Days) != 30 || !result.Days[0].Date.Equal(useCase.Clock.Today()) {
+		t.Fatalf("Expected 30 days from today, got %d starting %v", len(result.Days), result.Days[0].Date)
+	}
+	if result.Days[dueDay].Reviews != 2 {
+		t.Errorf("Expected both questions reviewed on day %d, got %d", dueDay, result.Days[dueDay].Reviews)
+	}
+	if len(result.Peaks) == 0 || !result.Peaks[0].Date.Equal(result.Days[dueDay].Date) {
+		t.Errorf("Expected the first review day to be the busiest, got %+v", result.Peaks)
+	}
+
+	if len(result.Questions) != 2 {
+		t.Fatalf("Expected both questions in the result, got %d", len(result.Questions))
+	}
+	intervals := result.Questions[0].Intervals
+	if len(intervals) < 2 || intervals[1] <= intervals[0] || intervals[0] <= dueDay {
+		t.Errorf("Expected growing intervals, got %v", intervals)
+	}
+	if result.TotalLoad() != 2*len(intervals) {
+		t.Errorf("Expected a load of %d, got %d", 2*len(intervals), result.TotalLoad())
+	}
+
+	// The stored questions are left as they were
+	if store.Questions[1].ReviewCount != 1 || !store.Questions[1].NextReview.Equal(nextReview) || store.Questions[1].Familiarity != core.Medium {
+		t.Errorf("Expected the stored question to be unchanged, got %+v", store.Questions[1])
+	}
+}
+
+func TestQuestionUseCase_Simulate_NewQuestions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	result, err := useCase.Simulate(SimulationOptions{Days: 10, NewQuestions: 5, NewPerDay: 2})
+	if err != nil {
+		t.Fatalf("Failed to simulate: %v", err)
+	}
+
+	added := []int{result.Days[0].Added, result.Days[1].Added, result.Days[2].Added, result.Days[3].Added}
+	if added[0] != 2 || added[1] != 2 || added[2] != 1 || added[3] != 0 {
+		t.Errorf("Expected 2, 2 and 1 questions added on the first days, got %v", added)
+	}
+	if len(result.Questions) != 5 || !result.Questions[4].New || result.Questions[4].ID != 5 {
+		t.Errorf("Expected the 5 hypothetical questions numbered in order, got %+v", result.Questions)
+	}
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	if len(store.Questions) != 0 {
+		t.Errorf("Expected no question to be saved, got %d", len(store.Questions))
+	}
+}
+
+func TestQuestionUseCase_Simulate_InvalidOptions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	if _, err := useCase.Simulate(SimulationOptions{Days: 30}); err != errs.ErrNoQuestionsAvailable {
+		t.Errorf("Expected ErrNoQuestionsAvailable, got %v", err)
+	}
+	for _, days := range []int{0, MaxSimulationDays + 1} {
+		if _, err := useCase.Simulate(SimulationOptions{Days: days, NewQuestions: 1}); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for %d days, got %v", days, err)
+		}
+	}
+}
+
+func TestQuestionUseCase_Simulate_LoadBalance(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	useCase.cfg.LoadBalance = true
+	useCase.cfg.DailyReviewCap = 2
+
+	result, err := useCase.Simulate(SimulationOptions{Days: 60, NewQuestions: 6})
+	if err != nil {
+		t.Fatalf("Failed to simulate: %v", err)
+	}
+
+	// Questions added together would otherwise come due together
+	for _, day := range result.Days[1:] {
+		if day.Reviews > 2 {
+			t.Errorf("Expected at most 2 reviews a day, got %d on %v", day.Reviews, day.Date)
+		}
+	}
+	if first := result.Questions[0].Intervals[0]; first == result.Questions[5].Intervals[0] && first == result.Questions[2].Intervals[0] {
+		t.Errorf("Expected the first reviews spread over several days, got %v", result.Questions)
+	}
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+	"github.com/eannchen/leetsolv/storage"
+)
+
+// SetQuestionState makes a question active, suspended or archived. The change is recorded as an
+// update so it can be undone.
+func (u *QuestionUseCaseImpl) SetQuestionState(target string, state core.QuestionState) (*core.Delta, error) {
+	logger.Infof("Setting question state: Target=%s, State=%s", target, state)
+
+	if state == core.StateBuried {
+		return nil, errs.WrapValidationError(errors.New("burial without an end"), "Please bury a question with 'bury'")
+	}
+	return u.changeState(target, state, time.Time{})
+}
+
+// BuryQuestion leaves a question out of the due lists until the day until, or for the given
+// number of days from today when until is zero. Burying a buried question moves the day its
+// burial ends. The change is recorded as an update so it can be undone.
+func (u *QuestionUseCaseImpl) BuryQuestion(target string, days int, until time.Time) (*core.Delta, error) {
+	logger.Infof("Burying question: Target=%s, Days=%d, Until=%s", target, days, until.Format(time.DateOnly))
+
+	today := u.Clock.Today()
+	if until.IsZero() {
+		until = u.Clock.AddDays(today, days)
+	}
+	until = u.Clock.ToDate(until)
+	if !until.After(today) {
+		return nil, errs.WrapValidationError(fmt.Errorf("burial until %s is not after today", until.Format(time.DateOnly)),
+			"Please bury the question until a day after today")
+	}
+	return u.changeState(target, core.StateBuried, until)
+}
+
+// changeState moves a question to a lifecycle state; buriedUntil is the day a burial ends
+func (u *QuestionUseCaseImpl) changeState(target string, state core.QuestionState, buriedUntil time.Time) (*core.Delta, error) {
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	found, err := u.findQuestionByIDOrURL(store, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// A burial that is over counts as active, while another burial moves the day it ends
+	if current := found.StateOn(u.Clock.Today()); current == state && state != core.StateBuried {
+		return nil, errs.WrapBusinessError(fmt.Errorf("question %d is already %s", found.ID, state),
+			fmt.Sprintf("Question %d is already %s", found.ID, state))
+	}
+
+	updated := *found
+	updated.State = state
+	updated.LeechSuspended = false // The state is set by hand from now on
+	updated.BuriedUntil = buriedUntil
+	updated.UpdatedAt = u.Clock.Now()
+
+	return u.saveUpdate(store, found, &updated)
+}
+
+// saveUpdate replaces a question with its updated state outside of a review and records the
+// change as an update delta
+func (u *QuestionUseCaseImpl) saveUpdate(store *storage.QuestionStore, old, updated *core.Question) (*core.Delta, error) {
+	deltas, err := u.Storage.LoadDeltas()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load deltas")
+	}
+
+	store.Questions[old.ID] = updated
+	store.UnindexQuestion(old)
+	store.IndexQuestion(updated)
+
+	delta := &core.Delta{
+		Action:     core.ActionUpdate,
+		QuestionID: old.ID,
+		OldState:   old,
+		NewState:   updated,
+		CreatedAt:  u.Clock.Now(),
+	}
+	deltas = u.appendDelta(deltas, *delta)
+
+	if err := u.Storage.SaveQuestionStore(store); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
+	}
+	u.saveNewHistory(deltas)
+	return delta, nil
+}
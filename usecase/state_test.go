@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+func TestQuestionUseCase_SetQuestionState(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	storeQuestionDue(t, useCase, 1, -2)
+	storeQuestionDue(t, useCase, 2, 0)
+
+	delta, err := useCase.SetQuestionState("1", core.StateSuspended)
+	if err != nil {
+		t.Fatalf("Failed to suspend: %v", err)
+	}
+	if delta.Action != core.ActionUpdate || delta.OldState.State != core.StateActive || delta.NewState.State != core.StateSuspended {
+		t.Errorf("Expected an update from active to suspended, got %+v", delta)
+	}
+	if _, err := useCase.SetQuestionState("2", core.StateArchived); err != nil {
+		t.Fatalf("Failed to archive: %v", err)
+	}
+
+	summary, _ := useCase.ListQuestionsSummary()
+	due, _ := useCase.ListDueQuestions()
+	if summary.TotalDue != 0 || len(due) != 0 {
+		t.Errorf("Expected suspended and archived questions not to be due, got %d and %d", summary.TotalDue, len(due))
+	}
+
+	if _, err := useCase.SetQuestionState("1", core.StateSuspended); errs.ExitCode(err) != errs.ExitBusiness {
+		t.Errorf("Expected a business error for suspending twice, got %v", err)
+	}
+	if _, err := useCase.SetQuestionState("1", core.StateBuried); errs.ExitCode(err) != errs.ExitValidation {
+		t.Errorf("Expected a validation error for a burial without an end, got %v", err)
+	}
+
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo: %v", err)
+	}
+	if summary, _ := useCase.ListQuestionsSummary(); summary.TotalDue != 1 {
+		t.Errorf("Expected the unarchived question to be due, got %d", summary.TotalDue)
+	}
+}
+
+func TestQuestionUseCase_BuryQuestion(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	storeQuestionDue(t, useCase, 1, 0)
+	today := useCase.Clock.Today()
+
+	delta, err := useCase.BuryQuestion("1", 2, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to bury: %v", err)
+	}
+	if !delta.NewState.BuriedUntil.Equal(today.AddDate(0, 0, 2)) {
+		t.Errorf("Expected a burial of 2 days, got %v", delta.NewState.BuriedUntil)
+	}
+	if summary, _ := useCase.ListQuestionsSummary(); summary.TotalDue != 0 {
+		t.Errorf("Expected a buried question not to be due, got %d", summary.TotalDue)
+	}
+
+	// Burying again moves the end of the burial
+	until := today.AddDate(0, 0, 5)
+	if delta, err = useCase.BuryQuestion("1", 0, until); err != nil || !delta.NewState.BuriedUntil.Equal(until) {
+		t.Fatalf("Expected the burial to end on %v, got %+v and %v", until, delta, err)
+	}
+
+	for _, days := range []int{0, -1} {
+		if _, err := useCase.BuryQuestion("1", days, time.Time{}); errs.ExitCode(err) != errs.ExitValidation {
+			t.Errorf("Expected a validation error for a burial of %d days, got %v", days, err)
+		}
+	}
+
+	// The burial is over on its last day
+	store, _ := useCase.Storage.LoadQuestionStore()
+	store.Questions[1].BuriedUntil = today
+	if due, _ := useCase.ListDueQuestions(); len(due) != 1 {
+		t.Errorf("Expected the question to be due after its burial, got %d", len(due))
+	}
+	if _, err := useCase.SetQuestionState("1", core.StateActive); errs.ExitCode(err) != errs.ExitBusiness {
+		t.Errorf("Expected a business error for unburying a question whose burial is over, got %v", err)
+	}
+}
+
+func TestQuestionUseCase_ReviewEndsBurial(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	url := "https://leetcode.com/problems/two-sum"
+	if _, err := useCase.UpsertQuestion(url, "note", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+		t.Fatalf("Failed to add question: %v", err)
+	}
+	if _, err := useCase.BuryQuestion(url, 3, time.Time{}); err != nil {
+		t.Fatalf("Failed to bury: %v", err)
+	}
+
+	delta, err := useCase.UpsertQuestion(url, "note", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
+	if err != nil {
+		t.Fatalf("Failed to review: %v", err)
+	}
+	if delta.NewState.State != core.StateActive || !delta.NewState.BuriedUntil.IsZero() {
+		t.Errorf("Expected a review to end the burial, got %+v", delta.NewState)
+	}
+}
+
+func TestQuestionUseCase_SearchQuestions_State(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	for id := 1; id <= 3; id++ {
+		storeQuestionDue(t, useCase, id, 0)
+	}
+	useCase.SetQuestionState("1", core.StateArchived)
+	useCase.BuryQuestion("2", 1, time.Time{})
+
+	tests := []struct {
+		state core.QuestionState
+		want  []int
+	}{
+		{core.StateArchived, []int{1}},
+		{core.StateBuried, []int{2}},
+		{core.StateActive, []int{3}},
+		{core.StateSuspended, nil},
+	}
+	for _, tt := range tests {
+		state := tt.state
+		results, err := useCase.SearchQuestions(nil, &core.SearchFilter{State: &state})
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		var ids []int
+		for _, q := range results {
+			ids = append(ids, q.ID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("Expected questions %v to be %s, got %v", tt.want, tt.state, ids)
+		}
+	}
+}
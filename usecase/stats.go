@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+	"github.com/eannchen/leetsolv/internal/urlparser"
+)
+
+// StatsWindowDays is the number of recent days the review rate is taken over
+const StatsWindowDays = 28
+
+// statsMonths is the number of months, the current one included, that added questions are counted for
+const statsMonths = 12
+
+// PlatformCount is the number of questions from one platform
+type PlatformCount struct {
+	Platform  core.Platform
+	Questions int
+}
+
+// MonthCount is the number of questions added in one month
+type MonthCount struct {
+	Month time.Time // First day of the month
+	Added int
+}
+
+// Stats is an overview of the questions and their review history
+type Stats struct {
+	Questions       int
+	ByFamiliarity   [core.MaxFamiliarity]int
+	ByImportance    [core.MaxImportance]int
+	ByPlatform      []PlatformCount // Most questions first
+	AverageEase     float64
+	AverageInterval float64 // Days from the last review to the next, over the reviewed questions
+	Reviews         int     // Reviews in the review log
+	RecentReviews   int     // Reviews in the last StatsWindowDays days, today included
+	Repeated        int     // Reviews of questions already tracked, leaving out the first rating of a new question
+	Retained        int     // Repeated reviews rated medium or better
+	CurrentStreak   int     // Days in a row with a review, up to today, or up to yesterday before the first review of today
+	LongestStreak   int
+	AddedPerMonth   []MonthCount // Oldest first
+}
+
+// ReviewsPerDay is the average number of reviews a day over the last StatsWindowDays days
+func (s *Stats) ReviewsPerDay() float64 {
+	return float64(s.RecentReviews) / StatsWindowDays
+}
+
+// ReviewsPerWeek is the average number of reviews a week over the last StatsWindowDays days
+func (s *Stats) ReviewsPerWeek() float64 {
+	return s.ReviewsPerDay() * 7
+}
+
+// Retention is the share of repeated reviews rated medium or better, or 0 without any
+func (s *Stats) Retention() float64 {
+	if s.Repeated == 0 {
+		return 0
+	}
+	return float64(s.Retained) / float64(s.Repeated)
+}
+
+// GetStats summarizes the questions and the review log. The review log is used rather than the
+// history, as the history is limited to the last maxDelta actions.
+func (u *QuestionUseCaseImpl) GetStats() (*Stats, error) {
+	logger.Infof("Computing stats")
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	if len(store.Questions) == 0 {
+		return nil, errs.ErrNoQuestionsAvailable
+	}
+	events, err := u.loadReviewEvents()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load review log")
+	}
+
+	today := u.Clock.Today()
+	stats := &Stats{Questions: len(store.Questions)}
+	u.countQuestions(stats, store.Questions, today)
+
+	firstDay := u.Clock.AddDays(today, -(StatsWindowDays - 1))
+	reviewDays := make(map[time.Time]bool)
+	for _, event := range events {
+		day := u.Clock.ToDate(event.ReviewedAt)
+		reviewDays[day] = true
+		stats.Reviews++
+		if !day.Before(firstDay) && !day.After(today) {
+			stats.RecentReviews++
+		}
+		if event.EaseFactorBefore == 0 {
+			continue
+		}
+		stats.Repeated++
+		if !core.IsLapse(event.Familiarity) {
+			stats.Retained++
+		}
+	}
+	stats.CurrentStreak, stats.LongestStreak = u.streaks(reviewDays, today)
+	return stats, nil
+}
+
+// countQuestions fills in the distributions, averages and monthly additions of the questions
+func (u *QuestionUseCaseImpl) countQuestions(stats *Stats, questions map[int]*core.Question, today time.Time) {
+	thisMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	stats.AddedPerMonth = make([]MonthCount, statsMonths)
+	for i := range stats.AddedPerMonth {
+		stats.AddedPerMonth[i].Month = thisMonth.AddDate(0, i-statsMonths+1, 0)
+	}
+
+	platforms := make(map[core.Platform]int)
+	var easeSum, intervalSum float64
+	var intervals int
+	for _, q := range questions {
+		// Levels out of range are left to doctor
+		if q.Familiarity >= core.VeryHard && q.Familiarity <= core.VeryEasy {
+			stats.ByFamiliarity[q.Familiarity]++
+		}
+		if q.Importance >= core.LowImportance && q.Importance <= core.CriticalImportance {
+			stats.ByImportance[q.Importance]++
+		}
+		easeSum += q.EaseFactor
+
+		// A URL that no platform recognizes still counts, as from another platform
+		if parsed, err := urlparser.Parse(q.URL); err == nil {
+			platforms[parsed.Platform]++
+		} else {
+			platforms[core.Platform("other")]++
+		}
+
+		if !q.LastReviewed.IsZero() && !q.NextReview.IsZero() {
+			intervalSum += q.NextReview.Sub(q.LastReviewed).Hours() / 24
+			intervals++
+		}
+
+		created := u.Clock.ToDate(q.CreatedAt)
+		month := time.Date(created.Year(), created.Month(), 1, 0, 0, 0, 0, today.Location())
+		if i := monthsBetween(stats.AddedPerMonth[0].Month, month); i >= 0 && i < statsMonths {
+			stats.AddedPerMonth[i].Added++
+		}
+	}
+
+	stats.AverageEase = easeSum / float64(len(questions))
+	if intervals > 0 {
+		stats.AverageInterval = intervalSum / float64(intervals)
+	}
+	for platform, count := range platforms {
+		stats.ByPlatform = append(stats.ByPlatform, PlatformCount{Platform: platform, Questions: count})
+	}
+	slices.SortFunc(stats.ByPlatform, func(a, b PlatformCount) int {
+		if a.Questions != b.Questions {
+			return b.Questions - a.Questions
+		}
+		return cmp.Compare(a.Platform, b.Platform)
+	})
+}
+
+// streaks returns the days in a row with a review up to today, or up to yesterday when there is
+// no review today yet, and the longest run of such days
+func (u *QuestionUseCaseImpl) streaks(reviewDays map[time.Time]bool, today time.Time) (current, longest int) {
+	days := make([]time.Time, 0, len(reviewDays))
+	for day := range reviewDays {
+		days = append(days, day)
+	}
+	slices.SortFunc(days, time.Time.Compare)
+
+	run := 0
+	for i, day := range days {
+		if i > 0 && u.Clock.AddDays(days[i-1], 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	start := today
+	if !reviewDays[today] {
+		start = u.Clock.AddDays(today, -1)
+	}
+	for day := start; reviewDays[day]; day = u.Clock.AddDays(day, -1) {
+		current++
+	}
+	return current, longest
+}
+
+// monthsBetween is the number of calendar months from one first day of a month to another
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
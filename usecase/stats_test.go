@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+// logReviewOn appends a review of question 1 to the review log, days before the test day
+func logReviewOn(t *testing.T, useCase *QuestionUseCaseImpl, daysAgo int, familiarity core.Familiarity, repeated bool) {
+	t.Helper()
+	event := core.ReviewEvent{QuestionID: 1, Familiarity: familiarity, ReviewedAt: testTime.AddDate(0, 0, -daysAgo)}
+	if repeated {
+		event.EaseFactorBefore = 2.0
+	}
+	if err := useCase.Storage.AppendReviewEvent(event); err != nil {
+		t.Fatalf("Failed to log review: %v", err)
+	}
+}
+
+func TestQuestionUseCase_GetStats(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	store, _ := useCase.Storage.LoadQuestionStore()
+	for id := 1; id <= 3; id++ {
+		storeQuestionDue(t, useCase, id, id)
+	}
+	store.Questions[2].Familiarity, store.Questions[2].EaseFactor = core.VeryEasy, 1.5
+	store.Questions[3].Importance, store.Questions[3].URL = core.CriticalImportance, "https://www.hackerrank.com/challenges/solve-me-first/problem"
+	store.Questions[3].CreatedAt = testTime.AddDate(0, -2, 0)
+	store.Questions[1].CreatedAt = testTime.AddDate(-2, 0, 0)
+
+	// A streak of 3 days up to yesterday, and an older one of 4 days
+	for _, daysAgo := range []int{1, 2, 3, 10, 11, 12, 13, 40} {
+		logReviewOn(t, useCase, daysAgo, core.Easy, daysAgo != 40)
+	}
+	logReviewOn(t, useCase, 2, core.Hard, true)
+
+	stats, err := useCase.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+
+	if stats.Questions != 3 || stats.ByFamiliarity[core.Medium] != 2 || stats.ByFamiliarity[core.VeryEasy] != 1 {
+		t.Errorf("Expected 2 medium and 1 very easy question, got %v", stats.ByFamiliarity)
+	}
+	if stats.ByImportance[core.MediumImportance] != 2 || stats.ByImportance[core.CriticalImportance] != 1 {
+		t.Errorf("Expected 2 medium and 1 critical question, got %v", stats.ByImportance)
+	}
+	want := []PlatformCount{{core.PlatformLeetCode, 2}, {core.PlatformHackerRank, 1}}
+	if len(stats.ByPlatform) != 2 || stats.ByPlatform[0] != want[0] || stats.ByPlatform[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, stats.ByPlatform)
+	}
+	if math.Abs(stats.AverageEase-6.5/3) > 1e-9 || math.Abs(stats.AverageInterval-2) > 1e-9 {
+		t.Errorf("Expected an average ease of 2.17 and interval of 2 days, got %.2f and %.2f", stats.AverageEase, stats.AverageInterval)
+	}
+
+	if stats.Reviews != 9 || stats.RecentReviews != 8 || stats.Repeated != 8 || stats.Retained != 7 {
+		t.Errorf("Expected 9 reviews, 8 recent, 8 repeated and 7 retained, got %+v", stats)
+	}
+	if got := stats.ReviewsPerWeek(); math.Abs(got-2) > 1e-9 {
+		t.Errorf("Expected 2 reviews per week, got %.2f", got)
+	}
+	if got := stats.Retention(); math.Abs(got-7.0/8) > 1e-9 {
+		t.Errorf("Expected a retention of 7/8, got %.2f", got)
+	}
+	if stats.CurrentStreak != 3 || stats.LongestStreak != 4 {
+		t.Errorf("Expected a current streak of 3 and longest of 4 days, got %d and %d", stats.CurrentStreak, stats.LongestStreak)
+	}
+
+	months := stats.AddedPerMonth
+	if len(months) != 12 || !months[11].Month.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Expected 12 months up to June 2024, got %+v", months)
+	}
+	// The question added two years ago is older than the months counted
+	if months[11].Added != 1 || months[9].Added != 1 || months[10].Added != 0 {
+		t.Errorf("Expected 1 question added in June and 1 in April, got %+v", months)
+	}
+}
+
+func TestQuestionUseCase_GetStats_StreakIncludesToday(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	storeQuestionDue(t, useCase, 1, 1)
+	for _, daysAgo := range []int{0, 0, 1} {
+		logReviewOn(t, useCase, daysAgo, core.Medium, false)
+	}
+
+	stats, err := useCase.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats.CurrentStreak != 2 || stats.LongestStreak != 2 || stats.Repeated != 0 || stats.Retention() != 0 {
+		t.Errorf("Expected a 2 day streak and no repeated reviews, got %+v", stats)
+	}
+}
+
+func TestQuestionUseCase_GetStats_NoQuestions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	if _, err := useCase.GetStats(); err != errs.ErrNoQuestionsAvailable {
+		t.Errorf("Expected ErrNoQuestionsAvailable, got %v", err)
+	}
+}
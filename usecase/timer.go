@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"strconv"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+)
+
+// StoppedTimer is a solve timer that was stopped
+type StoppedTimer struct {
+	Timer    core.Timer
+	Minutes  int            // Time the timer ran, rounded to whole minutes
+	Question *core.Question // The timed question; nil when it has not been added yet
+}
+
+// StartTimer starts a solve timer on a question, given by ID or normalized URL. A URL need not
+// belong to a question yet, so a timer can run while a new question is solved for the first time.
+// The timer is kept with the questions, so it outlives the process that started it.
+func (u *QuestionUseCaseImpl) StartTimer(target string) (*core.Timer, error) {
+	logger.Infof("Starting timer: Target=%s", target)
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	if store.Timer != nil {
+		return nil, errs.ErrTimerRunning
+	}
+
+	url := target
+	if _, err := strconv.Atoi(target); err == nil {
+		found, err := u.findQuestionByIDOrURL(store, target)
+		if err != nil {
+			return nil, err
+		}
+		url = found.URL
+	}
+
+	store.Timer = &core.Timer{URL: url, Start: u.Clock.Now()}
+	if err := u.Storage.SaveQuestionStore(store); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
+	}
+	return store.Timer, nil
+}
+
+// StopTimer stops the running solve timer and returns how long it ran
+func (u *QuestionUseCaseImpl) StopTimer() (*StoppedTimer, error) {
+	logger.Infof("Stopping timer")
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	if store.Timer == nil {
+		return nil, errs.ErrNoTimer
+	}
+
+	timer := *store.Timer
+	stopped := &StoppedTimer{Timer: timer, Minutes: timer.Minutes(u.Clock.Now())}
+	if id, ok := store.URLIndex[timer.URL]; ok {
+		copied := *store.Questions[id]
+		stopped.Question = &copied
+	}
+
+	store.Timer = nil
+	if err := u.Storage.SaveQuestionStore(store); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
+	}
+	return stopped, nil
+}
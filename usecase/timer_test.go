@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/internal/clock"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+func TestQuestionUseCase_StartStopTimer(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	addTestQuestions(t, useCase, "https://leetcode.com/problems/two-sum/")
+	store, _ := useCase.Storage.LoadQuestionStore()
+	url := store.Questions[1].URL
+
+	timer, err := useCase.StartTimer("1")
+	if err != nil {
+		t.Fatalf("Failed to start timer: %v", err)
+	}
+	if timer.URL != url || !timer.Start.Equal(useCase.Clock.Now()) {
+		t.Errorf("Expected a timer on %s from now, got %+v", url, timer)
+	}
+	if _, err := useCase.StartTimer("1"); err != errs.ErrTimerRunning {
+		t.Errorf("Expected ErrTimerRunning, got %v", err)
+	}
+
+	mockClock := useCase.Clock.(*clock.MockClock)
+	mockClock.FixedTime = mockClock.FixedTime.Add(23*time.Minute + 40*time.Second)
+
+	stopped, err := useCase.StopTimer()
+	if err != nil {
+		t.Fatalf("Failed to stop timer: %v", err)
+	}
+	if stopped.Minutes != 24 || stopped.Question == nil || stopped.Question.ID != 1 {
+		t.Errorf("Expected 24 minutes on question 1, got %+v", stopped)
+	}
+	if _, err := useCase.StopTimer(); err != errs.ErrNoTimer {
+		t.Errorf("Expected ErrNoTimer, got %v", err)
+	}
+}
+
+func TestQuestionUseCase_StartTimer_NewQuestion(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	url := "https://leetcode.com/problems/3sum/"
+
+	if _, err := useCase.StartTimer("7"); err != errs.ErrNoQuestionsAvailable {
+		t.Errorf("Expected ErrNoQuestionsAvailable for an ID, got %v", err)
+	}
+	if _, err := useCase.StartTimer(url); err != nil {
+		t.Fatalf("Failed to start timer: %v", err)
+	}
+
+	stopped, err := useCase.StopTimer()
+	if err != nil {
+		t.Fatalf("Failed to stop timer: %v", err)
+	}
+	if stopped.Timer.URL != url || stopped.Question != nil || stopped.Minutes != 1 {
+		t.Errorf("Expected an at least one minute timer on a new question, got %+v", stopped)
+	}
+}
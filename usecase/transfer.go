@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/eannchen/leetsolv/config"
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+	"github.com/eannchen/leetsolv/internal/logger"
+	"github.com/eannchen/leetsolv/internal/urlparser"
+	"github.com/eannchen/leetsolv/storage"
+)
+
+// ImportPolicy decides what happens to an imported question whose URL is already tracked
+type ImportPolicy string
+
+const (
+	ImportSkip      ImportPolicy = "skip"      // Keep the existing question as it is
+	ImportOverwrite ImportPolicy = "overwrite" // Replace the existing question with the imported one
+	ImportMerge     ImportPolicy = "merge"     // Combine notes and tags, keep the more recently reviewed state
+)
+
+// ParseImportPolicy validates a conflict policy name
+func ParseImportPolicy(value string) (ImportPolicy, error) {
+	switch policy := ImportPolicy(value); policy {
+	case ImportSkip, ImportOverwrite, ImportMerge:
+		return policy, nil
+	}
+	return "", errs.WrapValidationError(fmt.Errorf("unknown import policy %q", value), "Conflict policy must be skip, overwrite or merge")
+}
+
+// ImportOutcome is what an import does with a single question
+type ImportOutcome string
+
+const (
+	ImportAdded   ImportOutcome = "add"
+	ImportUpdated ImportOutcome = "update"
+	ImportSkipped ImportOutcome = "skip"
+)
+
+// ImportItem describes the outcome for one imported question
+type ImportItem struct {
+	URL     string
+	Outcome ImportOutcome
+}
+
+// ImportResult summarizes an import; for a dry run it describes what would happen
+type ImportResult struct {
+	Items   []ImportItem
+	Added   int
+	Updated int
+	Skipped int
+	DryRun  bool
+}
+
+// importStep is a planned change to a single question
+type importStep struct {
+	oldState *core.Question // nil for a new question
+	newState *core.Question
+}
+
+// ExportQuestions returns every question ordered by ID
+func (u *QuestionUseCaseImpl) ExportQuestions() ([]core.Question, error) {
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+
+	questions := make([]core.Question, 0, len(store.Questions))
+	for _, q := range store.Questions {
+		questions = append(questions, *q)
+	}
+	sort.Slice(questions, func(i, j int) bool {
+		return questions[i].ID < questions[j].ID
+	})
+	return questions, nil
+}
+
+// ImportQuestions adds or updates questions in bulk. IDs of the imported questions are ignored;
+// questions are matched on their normalized URL. The import is all-or-nothing: any invalid
+// question aborts it before anything is saved. Applied changes are recorded as a single delta
+// so that one undo reverts the whole import.
+func (u *QuestionUseCaseImpl) ImportQuestions(questions []core.Question, policy ImportPolicy, dryRun bool) (*ImportResult, error) {
+	logger.Infof("Importing questions: Count=%d, Policy=%s, DryRun=%t", len(questions), policy, dryRun)
+
+	if _, err := ParseImportPolicy(string(policy)); err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, errs.WrapValidationError(errors.New("empty import"), "The import file contains no questions")
+	}
+
+	imported, err := u.normalizeImport(questions)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+
+	result := &ImportResult{DryRun: dryRun}
+	var steps []importStep
+	for i := range imported {
+		step, outcome := u.planImport(store, &imported[i], policy)
+		result.Items = append(result.Items, ImportItem{URL: imported[i].URL, Outcome: outcome})
+		switch outcome {
+		case ImportAdded:
+			result.Added++
+		case ImportUpdated:
+			result.Updated++
+		case ImportSkipped:
+			result.Skipped++
+			continue
+		}
+		steps = append(steps, step)
+	}
+
+	if dryRun || len(steps) == 0 {
+		return result, nil
+	}
+
+	deltas, err := u.Storage.LoadDeltas()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load deltas")
+	}
+
+	batch := make([]core.Delta, 0, len(steps))
+	for _, step := range steps {
+		batch = append(batch, u.applyImportStep(store, step))
+	}
+
+	if err := u.Storage.SaveQuestionStore(store); err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
+	}
+
+	deltas = u.appendDelta(deltas, core.Delta{
+		Action:    core.ActionImport,
+		Batch:     batch,
+		CreatedAt: u.Clock.Now(),
+	})
+	u.saveNewHistory(deltas)
+	return result, nil
+}
+
+// normalizeImport validates the imported questions, including their review state, and normalizes
+// their URLs, tags and timestamps. Positions in error messages are 1-based to match the order of
+// the import file.
+func (u *QuestionUseCaseImpl) normalizeImport(questions []core.Question) ([]core.Question, error) {
+	normalized := make([]core.Question, len(questions))
+	seen := make(map[string]int, len(questions))
+
+	for i, q := range questions {
+		parsed, err := urlparser.Parse(q.URL)
+		if err != nil {
+			return nil, errs.WrapValidationError(err, fmt.Sprintf("Question %d: invalid URL %q", i+1, q.URL))
+		}
+		if q.Familiarity < core.VeryHard || q.Familiarity > core.VeryEasy {
+			return nil, errs.WrapValidationError(errs.ErrInvalidFamiliarityLevel, fmt.Sprintf("Question %d: familiarity must be between 1 and 5", i+1))
+		}
+		if q.Importance < core.LowImportance || q.Importance > core.CriticalImportance {
+			return nil, errs.WrapValidationError(errs.ErrInvalidImportanceLevel, fmt.Sprintf("Question %d: importance must be between 1 and 4", i+1))
+		}
+		if q.EaseFactor != 0 && core.ClampEaseFactor(q.EaseFactor) != q.EaseFactor {
+			return nil, errs.WrapValidationError(errors.New("ease factor out of range"), fmt.Sprintf("Question %d: ease factor must be between %.1f and %.1f", i+1, config.MinEaseFactor, config.MaxEaseFactor))
+		}
+		if hasReviewState(&q) && q.NextReview.Before(q.LastReviewed) {
+			return nil, errs.WrapValidationError(errors.New("next review before last review"), fmt.Sprintf("Question %d: next review must not be before the last review", i+1))
+		}
+		if q.ReviewCount < 0 || q.Lapses < 0 {
+			return nil, errs.WrapValidationError(errors.New("negative count"), fmt.Sprintf("Question %d: review count and lapses must not be negative", i+1))
+		}
+		if !slices.Contains(core.QuestionStates, q.State) {
+			return nil, errs.WrapValidationError(fmt.Errorf("unknown state %q", q.State), fmt.Sprintf("Question %d: state must be active, suspended, buried or archived", i+1))
+		}
+		if first, ok := seen[parsed.NormalizedURL]; ok {
+			return nil, errs.WrapValidationError(errors.New("duplicate URL"), fmt.Sprintf("Question %d: same URL as question %d (%s)", i+1, first, parsed.NormalizedURL))
+		}
+		seen[parsed.NormalizedURL] = i + 1
+
+		q.URL = parsed.NormalizedURL
+		q.Tags = core.NormalizeTags(q.Tags)
+		toUTC(&q)
+		normalized[i] = q
+	}
+	return normalized, nil
+}
+
+// planImport decides what to do with one imported question without touching the store
+func (u *QuestionUseCaseImpl) planImport(store *storage.QuestionStore, imported *core.Question, policy ImportPolicy) (importStep, ImportOutcome) {
+	now := u.Clock.Now()
+
+	id, exists := store.URLIndex[imported.URL]
+	existing := store.Questions[id]
+	if !exists || existing == nil {
+		newState := *imported
+		newState.ID = 0 // assigned when applied
+		if !hasReviewState(&newState) {
+			u.Scheduler.ScheduleNewQuestion(&newState, core.MemoryReasoned)
+		} else {
+			u.fillReviewState(&newState)
+		}
+		if newState.CreatedAt.IsZero() {
+			newState.CreatedAt = now
+		}
+		newState.UpdatedAt = now
+		return importStep{newState: &newState}, ImportAdded
+	}
+
+	if policy == ImportSkip {
+		return importStep{}, ImportSkipped
+	}
+
+	newState := *existing
+	switch policy {
+	case ImportOverwrite:
+		newState.Note = imported.Note
+		newState.Tags = imported.Tags
+		newState.Familiarity = imported.Familiarity
+		newState.Importance = imported.Importance
+		// Without review data in the file, the existing schedule stays in place
+		if hasReviewState(imported) {
+			copyReviewState(&newState, imported)
+			u.fillReviewState(&newState)
+		}
+	case ImportMerge:
+		if newState.Note == "" {
+			newState.Note = imported.Note
+		}
+		newState.Tags = core.NormalizeTags(append(slices.Clone(existing.Tags), imported.Tags...))
+		if hasReviewState(imported) && imported.LastReviewed.After(existing.LastReviewed) {
+			newState.Familiarity = imported.Familiarity
+			newState.Importance = imported.Importance
+			copyReviewState(&newState, imported)
+			u.fillReviewState(&newState)
+		}
+	}
+
+	// newState is a copy of existing, so only the imported values can tell them apart
+	if existing.Equal(&newState) {
+		return importStep{}, ImportSkipped
+	}
+	newState.UpdatedAt = now
+	return importStep{oldState: existing, newState: &newState}, ImportUpdated
+}
+
+// applyImportStep writes a planned change into the store and its indices
+func (u *QuestionUseCaseImpl) applyImportStep(store *storage.QuestionStore, step importStep) core.Delta {
+	newState := step.newState
+
+	if step.oldState == nil {
+		store.MaxID++
+		newState.ID = store.MaxID
+		store.Questions[newState.ID] = newState
+		store.IndexQuestion(newState)
+
+		return core.Delta{
+			Action:     core.ActionAdd,
+			QuestionID: newState.ID,
+			NewState:   newState,
+			CreatedAt:  u.Clock.Now(),
+		}
+	}
+
+	oldState := step.oldState
+	store.Questions[newState.ID] = newState
+	store.UnindexQuestion(oldState)
+	store.IndexQuestion(newState)
+
+	return core.Delta{
+		Action:     core.ActionUpdate,
+		QuestionID: newState.ID,
+		OldState:   oldState,
+		NewState:   newState,
+		CreatedAt:  u.Clock.Now(),
+	}
+}
+
+// hasReviewState reports whether an imported question carries its own schedule
+func hasReviewState(q *core.Question) bool {
+	return !q.LastReviewed.IsZero() && !q.NextReview.IsZero()
+}
+
+func copyReviewState(dst, src *core.Question) {
+	dst.LastReviewed = src.LastReviewed
+	dst.NextReview = src.NextReview
+	dst.ReviewCount = src.ReviewCount
+	dst.EaseFactor = src.EaseFactor
+	dst.Stability = src.Stability
+	dst.Difficulty = src.Difficulty
+	dst.Lapses = src.Lapses
+	dst.Leech = src.Leech
+	dst.State = src.State
+	dst.LeechSuspended = src.LeechSuspended
+	dst.BuriedUntil = src.BuriedUntil
+	dst.LastSolve = src.LastSolve
+}
+
+// fillReviewState gives an imported schedule the values a scheduled question always has
+func (u *QuestionUseCaseImpl) fillReviewState(q *core.Question) {
+	if q.ReviewCount < 1 {
+		q.ReviewCount = 1
+	}
+	if q.EaseFactor <= 0 {
+		// Use the starting ease factor the scheduler gives a new question of this importance
+		scheduled := *q
+		u.Scheduler.ScheduleNewQuestion(&scheduled, core.MemoryReasoned)
+		q.EaseFactor = scheduled.EaseFactor
+	}
+}
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/errs"
+)
+
+func TestQuestionUseCase_ExportQuestions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	for _, url := range []string{"https://leetcode.com/problems/two-sum", "https://leetcode.com/problems/3sum"} {
+		if _, err := useCase.UpsertQuestion(url, "", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+			t.Fatalf("Failed to upsert question: %v", err)
+		}
+	}
+
+	questions, err := useCase.ExportQuestions()
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if len(questions) != 2 || questions[0].ID != 1 || questions[1].ID != 2 {
+		t.Errorf("Expected 2 questions ordered by ID, got %+v", questions)
+	}
+}
+
+func TestQuestionUseCase_ImportQuestions_Add(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	scheduled := core.Question{
+		URL:          "https://leetcode.com/problems/3sum/description/",
+		Familiarity:  core.Easy,
+		Importance:   core.HighImportance,
+		LastReviewed: testTime.AddDate(0, 0, -10),
+		NextReview:   testTime.AddDate(0, 0, 5),
+		ReviewCount:  4,
+		EaseFactor:   2.2,
+	}
+	unscheduled := core.Question{
+		URL:         "https://leetcode.com/problems/two-sum",
+		Note:        "hash map",
+		Tags:        []string{"#Array", "hash"},
+		Familiarity: core.Medium,
+		Importance:  core.MediumImportance,
+	}
+
+	result, err := useCase.ImportQuestions([]core.Question{scheduled, unscheduled}, ImportSkip, false)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if result.Added != 2 || result.Updated != 0 || result.Skipped != 0 {
+		t.Errorf("Expected 2 added, got %+v", result)
+	}
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	id, ok := store.URLIndex["https://leetcode.com/problems/3sum/"]
+	if !ok {
+		t.Fatal("Expected the imported URL to be normalized and indexed")
+	}
+	if q := store.Questions[id]; q.ReviewCount != 4 || !q.NextReview.Equal(scheduled.NextReview) {
+		t.Errorf("Expected the imported schedule to be kept, got %+v", q)
+	}
+
+	q := store.Questions[store.URLIndex["https://leetcode.com/problems/two-sum/"]]
+	if q.ReviewCount != 1 || q.NextReview.IsZero() || q.EaseFactor == 0 {
+		t.Errorf("Expected a question without review data to be scheduled as new, got %+v", q)
+	}
+	if !slices.Equal(q.Tags, []string{"array", "hash"}) || !slices.Contains(store.TagIndex["array"], q.ID) {
+		t.Errorf("Expected normalized and indexed tags, got %v", q.Tags)
+	}
+
+	found, err := useCase.SearchQuestions([]string{"hash"}, nil)
+	if err != nil || len(found) != 1 {
+		t.Errorf("Expected the imported note to be searchable, got %d results (%v)", len(found), err)
+	}
+}
+
+func TestQuestionUseCase_ImportQuestions_ConflictPolicies(t *testing.T) {
+	url := "https://leetcode.com/problems/two-sum/"
+	incoming := core.Question{
+		URL:          url,
+		Note:         "imported note",
+		Tags:         []string{"array"},
+		Familiarity:  core.VeryEasy,
+		Importance:   core.CriticalImportance,
+		LastReviewed: testTime,
+		NextReview:   testTime.AddDate(0, 0, 30),
+		ReviewCount:  9,
+		EaseFactor:   2.5,
+	}
+
+	tests := []struct {
+		name        string
+		policy      ImportPolicy
+		outcome     ImportOutcome
+		note        string
+		tags        []string
+		familiarity core.Familiarity
+	}{
+		{"skip keeps the existing question", ImportSkip, ImportSkipped, "existing note", []string{"hash"}, core.Hard},
+		{"overwrite replaces it", ImportOverwrite, ImportUpdated, "imported note", []string{"array"}, core.VeryEasy},
+		{"merge combines tags and takes the newer review", ImportMerge, ImportUpdated, "existing note", []string{"array", "hash"}, core.VeryEasy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, useCase := setupTestEnvironment(t)
+			if _, err := useCase.UpsertQuestion(url, "existing note", []string{"hash"}, core.Hard, core.MediumImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+				t.Fatalf("Failed to upsert question: %v", err)
+			}
+			// Backdate the existing review so that the imported one is more recent
+			store, _ := useCase.Storage.LoadQuestionStore()
+			store.Questions[1].LastReviewed = testTime.AddDate(0, 0, -3)
+
+			result, err := useCase.ImportQuestions([]core.Question{incoming}, tt.policy, false)
+			if err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+			if result.Items[0].Outcome != tt.outcome {
+				t.Errorf("Expected outcome %s, got %s", tt.outcome, result.Items[0].Outcome)
+			}
+
+			q := store.Questions[1]
+			if q.Note != tt.note || !slices.Equal(q.Tags, tt.tags) || q.Familiarity != tt.familiarity {
+				t.Errorf("Expected note %q, tags %v, familiarity %d; got %q, %v, %d", tt.note, tt.tags, tt.familiarity, q.Note, q.Tags, q.Familiarity)
+			}
+			if len(store.Questions) != 1 {
+				t.Errorf("Expected no new question, got %d questions", len(store.Questions))
+			}
+		})
+	}
+}
+
+func TestQuestionUseCase_ImportQuestions_DryRun(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	result, err := useCase.ImportQuestions([]core.Question{{URL: "https://leetcode.com/problems/two-sum"}}, ImportSkip, true)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if !result.DryRun || result.Added != 1 {
+		t.Errorf("Expected a dry run reporting 1 addition, got %+v", result)
+	}
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	deltas, _ := useCase.Storage.LoadDeltas()
+	if len(store.Questions) != 0 || len(deltas) != 0 {
+		t.Errorf("Expected a dry run to change nothing, got %d questions and %d deltas", len(store.Questions), len(deltas))
+	}
+}
+
+func TestQuestionUseCase_ImportQuestions_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		questions []core.Question
+		policy    ImportPolicy
+	}{
+		{"empty import", nil, ImportSkip},
+		{"unknown policy", []core.Question{{URL: "https://leetcode.com/problems/two-sum"}}, ImportPolicy("replace")},
+		{"invalid URL", []core.Question{{URL: "https://leetcode.com/problems/two-sum"}, {URL: "not a url"}}, ImportSkip},
+		{"duplicate URL", []core.Question{{URL: "https://leetcode.com/problems/two-sum"}, {URL: "https://leetcode.com/problems/two-sum/description/"}}, ImportSkip},
+		{"invalid familiarity", []core.Question{{URL: "https://leetcode.com/problems/two-sum", Familiarity: 7}}, ImportSkip},
+		{"ease factor out of range", []core.Question{{URL: "https://leetcode.com/problems/two-sum", Familiarity: core.Medium, Importance: core.LowImportance, EaseFactor: 5}}, ImportSkip},
+		{"next review before last review", []core.Question{{URL: "https://leetcode.com/problems/two-sum", Familiarity: core.Medium, Importance: core.LowImportance,
+			LastReviewed: testTime, NextReview: testTime.AddDate(0, 0, -1)}}, ImportSkip},
+		{"negative review count", []core.Question{{URL: "https://leetcode.com/problems/two-sum", Familiarity: core.Medium, Importance: core.LowImportance, ReviewCount: -1}}, ImportSkip},
+		{"negative lapses", []core.Question{{URL: "https://leetcode.com/problems/two-sum", Familiarity: core.Medium, Importance: core.LowImportance, Lapses: -2}}, ImportSkip},
+		{"unknown state", []core.Question{{URL: "https://leetcode.com/problems/two-sum", Familiarity: core.Medium, Importance: core.LowImportance, State: "frozen"}}, ImportSkip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, useCase := setupTestEnvironment(t)
+
+			_, err := useCase.ImportQuestions(tt.questions, tt.policy, false)
+			var codedErr *errs.CodedError
+			if !errors.As(err, &codedErr) || codedErr.Kind != errs.ValidationErrorKind {
+				t.Fatalf("Expected a validation error, got %v", err)
+			}
+
+			store, _ := useCase.Storage.LoadQuestionStore()
+			if len(store.Questions) != 0 {
+				t.Errorf("Expected nothing to be imported, got %d questions", len(store.Questions))
+			}
+		})
+	}
+}
+
+func TestQuestionUseCase_ImportQuestions_ConvertsTimestampsToUTC(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	zone := time.FixedZone("UTC+8", 8*60*60)
+
+	if _, err := useCase.ImportQuestions([]core.Question{{
+		URL:          "https://leetcode.com/problems/two-sum",
+		Familiarity:  core.Medium,
+		Importance:   core.MediumImportance,
+		LastReviewed: testTime.AddDate(0, 0, -3).In(zone),
+		NextReview:   testTime.AddDate(0, 0, 4).In(zone),
+		CreatedAt:    testTime.AddDate(0, -1, 0).In(zone),
+	}}, ImportSkip, false); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	q := store.Questions[1]
+	if hasNonUTC(q) {
+		t.Errorf("Expected the imported timestamps in UTC, got %v, %v and %v", q.LastReviewed, q.NextReview, q.CreatedAt)
+	}
+	if !q.NextReview.Equal(testTime.AddDate(0, 0, 4)) {
+		t.Errorf("Expected the same instant after the conversion, got %v", q.NextReview)
+	}
+}
+
+func TestQuestionUseCase_ImportQuestions_UndoRevertsWholeImport(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	if _, err := useCase.UpsertQuestion("https://leetcode.com/problems/two-sum/", "before", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+		t.Fatalf("Failed to upsert question: %v", err)
+	}
+
+	_, err := useCase.ImportQuestions([]core.Question{
+		{URL: "https://leetcode.com/problems/two-sum/", Note: "after"},
+		{URL: "https://leetcode.com/problems/3sum/"},
+		{URL: "https://leetcode.com/problems/4sum/"},
+	}, ImportOverwrite, false)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	deltas, _ := useCase.Storage.LoadDeltas()
+	if len(deltas) != 2 || deltas[1].Action != core.ActionImport || len(deltas[1].Batch) != 3 {
+		t.Fatalf("Expected one import delta with 3 changes after the add, got %+v", deltas)
+	}
+
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo import: %v", err)
+	}
+
+	store, _ := useCase.Storage.LoadQuestionStore()
+	if len(store.Questions) != 1 || store.Questions[1].Note != "before" {
+		t.Errorf("Expected only the original question to remain unchanged, got %d questions", len(store.Questions))
+	}
+	if _, ok := store.URLIndex["https://leetcode.com/problems/3sum/"]; ok {
+		t.Error("Expected imported URLs to be removed from the URL index")
+	}
+	deltas, _ = useCase.Storage.LoadDeltas()
+	if len(deltas) != 1 {
+		t.Errorf("Expected only the add delta to remain, got %d", len(deltas))
+	}
+}
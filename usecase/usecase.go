@@ -4,17 +4,19 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/eannchen/leetsolv/config"
 	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/backup"
 	"github.com/eannchen/leetsolv/internal/clock"
 	"github.com/eannchen/leetsolv/internal/errs"
 	"github.com/eannchen/leetsolv/internal/logger"
 	"github.com/eannchen/leetsolv/internal/rank"
-	"github.com/eannchen/leetsolv/internal/tokenizer"
 	"github.com/eannchen/leetsolv/internal/urlparser"
 	"github.com/eannchen/leetsolv/storage"
 )
@@ -22,16 +24,40 @@ import (
 // QuestionUseCase defines the interface for question use cases
 type QuestionUseCase interface {
 	ListQuestionsSummary() (QuestionsSummary, error)
+	ListDueQuestions() ([]core.Question, error)
+	ListTags() ([]TagSummary, error)
 	ListQuestionsOrderByDesc() ([]core.Question, error)
 	GetQuestion(target string) (*core.Question, error)
 	SearchQuestions(queries []string, filter *core.SearchFilter) ([]core.Question, error)
-	UpsertQuestion(url, note string, familiarity core.Familiarity, importance core.Importance, memory core.MemoryUse) (*core.Delta, error)
+	UpsertQuestion(url, note string, tags []string, familiarity core.Familiarity, importance core.Importance, memory core.MemoryUse, solve core.Solve) (*core.Delta, error)
 	DeleteQuestion(target string) (*core.Question, error)
-	Undo() error
+	Undo(count int) ([]core.Delta, error)
+	Redo(count int) ([]core.Delta, error)
 	GetHistory() ([]core.Delta, error)
+	GetRedoHistory() ([]core.Delta, error)
+	ExportQuestions() ([]core.Question, error)
+	ImportQuestions(questions []core.Question, policy ImportPolicy, dryRun bool) (*ImportResult, error)
 	GetSettings() error
 	UpdateSetting(settingName string, value interface{}) error
+	ResetSetting(settingName string) error
 	MigrateToUTC() (int, int, error)
+	CheckData(repair bool) (*CheckResult, error)
+	Simulate(opts SimulationOptions) (*SimulationResult, error)
+	ForecastDue(days int, filter *core.SearchFilter) (*Forecast, error)
+	Optimize(opts OptimizeOptions) (*OptimizeResult, error)
+	ApplyOptimization(result *OptimizeResult) error
+	GetStats() (*Stats, error)
+	ListLeeches() ([]core.Question, error)
+	ClearLeech(target string) (*core.Delta, error)
+	SetQuestionState(target string, state core.QuestionState) (*core.Delta, error)
+	BuryQuestion(target string, days int, until time.Time) (*core.Delta, error)
+	StartTimer(target string) (*core.Timer, error)
+	StopTimer() (*StoppedTimer, error)
+	PauseReviews() (*core.Pause, error)
+	ResumeReviews() (*ResumeResult, error)
+	CreateBackup(reason string) (*backup.Snapshot, error)
+	ListBackups() ([]backup.Snapshot, error)
+	RestoreBackup(id string) (restored *backup.Snapshot, before *backup.Snapshot, err error)
 	ResetData() (questionsCount int, deltasCount int, err error)
 }
 
@@ -58,7 +84,8 @@ type QuestionsSummary struct {
 	TotalDue      int             // Total count of due questions
 	TopUpcoming   []core.Question // Top-K upcoming questions (by NextReview, then score)
 	TotalUpcoming int             // Total count of upcoming (within 1 day)
-	Total         int             // Total number of questions in the store
+	Total         int             // Total number of questions in the store, suspended, buried and archived ones included
+	PausedSince   time.Time       // Day reviews were paused on; zero when they are not paused
 }
 
 func (u *QuestionUseCaseImpl) ListQuestionsSummary() (QuestionsSummary, error) {
@@ -69,6 +96,7 @@ func (u *QuestionUseCaseImpl) ListQuestionsSummary() (QuestionsSummary, error) {
 
 	today := u.Clock.Today()
 	oneDayLater := u.Clock.AddDays(today, 1)
+	pausedSince, _ := store.PausedSince()
 
 	var dueTotal int
 	dueHeap := rank.NewTopKMinHeap(u.cfg.TopKDue)
@@ -77,17 +105,20 @@ func (u *QuestionUseCaseImpl) ListQuestionsSummary() (QuestionsSummary, error) {
 	upcomingHeap := rank.NewTopKMinHeap(u.cfg.TopKUpcoming)
 
 	for _, q := range store.Questions {
-		nextReviewDate := u.Clock.ToDate(q.NextReview)
+		if !q.IsScheduled() {
+			continue
+		}
+		nextReviewDate := u.Clock.ToDate(q.ReviewDate())
 		if !nextReviewDate.After(today) {
 			dueHeap.Push(rank.HeapItem{
 				Item:  q,
-				Score: u.Scheduler.CalculatePriorityScore(q),
+				Score: u.Scheduler.CalculatePriorityScore(q, pausedSince),
 			})
 			dueTotal++
 		} else if !nextReviewDate.After(oneDayLater) {
 			upcomingHeap.Push(rank.HeapItem{
 				Item:  q,
-				Score: u.Scheduler.CalculatePriorityScore(q),
+				Score: u.Scheduler.CalculatePriorityScore(q, pausedSince),
 			})
 			upcomingTotal++
 		}
@@ -115,9 +146,84 @@ func (u *QuestionUseCaseImpl) ListQuestionsSummary() (QuestionsSummary, error) {
 		TopUpcoming:   upcoming,
 		TotalUpcoming: upcomingTotal,
 		Total:         total,
+		PausedSince:   pausedSince,
 	}, nil
 }
 
+// ListDueQuestions returns every due question that is not suspended, buried or archived, highest
+// priority score first.
+// It uses the same ordering as the due list of ListQuestionsSummary without the top-K limit.
+func (u *QuestionUseCaseImpl) ListDueQuestions() ([]core.Question, error) {
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+
+	today := u.Clock.Today()
+	pausedSince, _ := store.PausedSince()
+
+	var due []core.Question
+	scores := make(map[int]float64)
+	for _, q := range store.Questions {
+		if !q.IsScheduled() || u.Clock.ToDate(q.ReviewDate()).After(today) {
+			continue
+		}
+		due = append(due, *q)
+		scores[q.ID] = u.Scheduler.CalculatePriorityScore(q, pausedSince)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if scores[due[i].ID] != scores[due[j].ID] {
+			return scores[due[i].ID] > scores[due[j].ID]
+		}
+		return due[i].ID < due[j].ID
+	})
+	return due, nil
+}
+
+// TagSummary describes how many questions carry a tag and how many of them are due
+type TagSummary struct {
+	Name     string
+	Total    int
+	TotalDue int
+}
+
+// ListTags returns every tag in use, most used first, then by name.
+func (u *QuestionUseCaseImpl) ListTags() ([]TagSummary, error) {
+	store, err := u.Storage.LoadQuestionStore()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+
+	today := u.Clock.Today()
+
+	tags := make([]TagSummary, 0, len(store.TagIndex))
+	for name, ids := range store.TagIndex {
+		summary := TagSummary{Name: name}
+		for _, id := range ids {
+			q, ok := store.Questions[id]
+			if !ok {
+				continue
+			}
+			summary.Total++
+			if q.IsScheduled() && !u.Clock.ToDate(q.ReviewDate()).After(today) {
+				summary.TotalDue++
+			}
+		}
+		if summary.Total > 0 {
+			tags = append(tags, summary)
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Total != tags[j].Total {
+			return tags[i].Total > tags[j].Total
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
 func (u *QuestionUseCaseImpl) ListQuestionsOrderByDesc() ([]core.Question, error) {
 	store, err := u.Storage.LoadQuestionStore()
 	if err != nil {
@@ -212,16 +318,45 @@ func (u *QuestionUseCaseImpl) matchesFilter(question core.Question, filter core.
 	}
 
 	// Filter by due date
-	if filter.DueOnly && question.NextReview.After(u.Clock.Now()) {
+	if filter.DueOnly && (!question.IsScheduled() || question.ReviewDate().After(u.Clock.Now())) {
 		return false
 	}
 
+	// Filter by lifecycle state, as it is today
+	if filter.State != nil && question.StateOn(u.Clock.Today()) != *filter.State {
+		return false
+	}
+
+	// Filter by tags: all included tags are required, any excluded tag rejects
+	for _, tag := range filter.Tags {
+		if !question.HasTag(tag) {
+			return false
+		}
+	}
+	for _, tag := range filter.ExcludeTags {
+		if question.HasTag(tag) {
+			return false
+		}
+	}
+
 	return true
 }
 
-func (u *QuestionUseCaseImpl) UpsertQuestion(url, note string, familiarity core.Familiarity, importance core.Importance, memory core.MemoryUse) (*core.Delta, error) {
+func (u *QuestionUseCaseImpl) UpsertQuestion(url, note string, tags []string, familiarity core.Familiarity, importance core.Importance, memory core.MemoryUse, solve core.Solve) (*core.Delta, error) {
 	logger.Infof("Upserting question: URL=%s, Familiarity=%d, Importance=%d", url, familiarity, importance)
 
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if !solve.Valid() {
+		return nil, errs.ErrInvalidSolve
+	}
+	solve = solve.Normalized()
+	tags = core.NormalizeTags(tags)
+
 	store, err := u.Storage.LoadQuestionStore()
 	if err != nil {
 		return nil, errs.WrapInternalError(err, "Failed to load question store")
@@ -245,28 +380,42 @@ func (u *QuestionUseCaseImpl) UpsertQuestion(url, note string, familiarity core.
 	if foundQuestion != nil {
 		// Update existing question
 		newState = &core.Question{
-			ID:           foundQuestion.ID,
-			URL:          url,
-			Note:         note,
-			Familiarity:  familiarity,
-			Importance:   importance,
-			LastReviewed: foundQuestion.LastReviewed,
-			NextReview:   foundQuestion.NextReview,
-			ReviewCount:  foundQuestion.ReviewCount,
-			EaseFactor:   foundQuestion.EaseFactor,
-			UpdatedAt:    u.Clock.Now(),
-			CreatedAt:    foundQuestion.CreatedAt,
+			ID:             foundQuestion.ID,
+			URL:            url,
+			Note:           note,
+			Tags:           tags,
+			Familiarity:    familiarity,
+			Importance:     importance,
+			LastReviewed:   foundQuestion.LastReviewed,
+			NextReview:     foundQuestion.NextReview,
+			ReviewCount:    foundQuestion.ReviewCount,
+			EaseFactor:     foundQuestion.EaseFactor,
+			Stability:      foundQuestion.Stability,
+			Difficulty:     foundQuestion.Difficulty,
+			Lapses:         foundQuestion.Lapses,
+			Leech:          foundQuestion.Leech,
+			State:          foundQuestion.State,
+			LeechSuspended: foundQuestion.LeechSuspended,
+			BuriedUntil:    foundQuestion.BuriedUntil,
+			LastSolve:      foundQuestion.LastSolve,
+			UpdatedAt:      u.Clock.Now(),
+			CreatedAt:      foundQuestion.CreatedAt,
+		}
+		// A review ends a burial
+		if newState.State == core.StateBuried {
+			newState.State, newState.BuriedUntil = core.StateActive, time.Time{}
 		}
 		u.Scheduler.Schedule(newState, memory)
+		core.ApplySolve(u.cfg, newState, solve)
+		newLeech := core.TrackLapse(u.cfg, newState, foundQuestion.EaseFactor)
+		if newState.Leech {
+			u.applyLeechAction(newState, newLeech)
+		}
 		store.Questions[foundQuestion.ID] = newState
 
-		// Update the note indices for search
-		for _, word := range tokenizer.Tokenize(foundQuestion.Note) {
-			store.NoteTrie.Delete(word, foundQuestion.ID)
-		}
-		for _, word := range tokenizer.Tokenize(newState.Note) {
-			store.NoteTrie.Insert(word, newState.ID)
-		}
+		// Update the indices for search
+		store.UnindexQuestion(foundQuestion)
+		store.IndexQuestion(newState)
 
 		// Create a delta for the update
 		delta = &core.Delta{
@@ -279,31 +428,26 @@ func (u *QuestionUseCaseImpl) UpsertQuestion(url, note string, familiarity core.
 		deltas = u.appendDelta(deltas, *delta)
 	} else {
 		// Create a new question
+		if _, err := u.extractProblemSlug(url); err != nil {
+			return nil, err
+		}
 		store.MaxID++
 		newState = &core.Question{
 			ID:          store.MaxID,
 			URL:         url,
 			Note:        note,
+			Tags:        tags,
 			Familiarity: familiarity,
 			Importance:  importance,
 			UpdatedAt:   u.Clock.Now(),
 			CreatedAt:   u.Clock.Now(),
 		}
 		newState = u.Scheduler.ScheduleNewQuestion(newState, memory)
+		core.ApplySolve(u.cfg, newState, solve)
 		store.Questions[store.MaxID] = newState
-		store.URLIndex[url] = store.MaxID
 
-		// Create the URL and note indices for search
-		questionName, err := u.extractProblemSlug(newState.URL)
-		if err != nil {
-			return nil, err
-		}
-		for _, word := range tokenizer.Tokenize(questionName) {
-			store.URLTrie.Insert(word, newState.ID)
-		}
-		for _, word := range tokenizer.Tokenize(newState.Note) {
-			store.NoteTrie.Insert(word, newState.ID)
-		}
+		// Create the indices for search
+		store.IndexQuestion(newState)
 
 		// Create a delta for the new question
 		delta = &core.Delta{
@@ -319,15 +463,53 @@ func (u *QuestionUseCaseImpl) UpsertQuestion(url, note string, familiarity core.
 	if err := u.Storage.SaveQuestionStore(store); err != nil {
 		return nil, errs.WrapInternalError(err, "Failed to save question store")
 	}
-	if err := u.Storage.SaveDeltas(deltas); err != nil {
-		logger.Errorf("Failed to save deltas: %v", err)
+	u.saveNewHistory(deltas)
+	if err := u.Storage.AppendReviewEvent(u.newReviewEvent(delta, memory, solve)); err != nil {
+		logger.Errorf("Failed to append review event: %v", err)
 	}
 	return delta, nil
 }
 
+// loadReviewEvents returns the review log without the reviews that were undone
+func (u *QuestionUseCaseImpl) loadReviewEvents() ([]core.ReviewEvent, error) {
+	events, err := u.Storage.LoadReviewEvents()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(slices.Clone(events), func(event core.ReviewEvent) bool { return event.Reverted }), nil
+}
+
+// newReviewEvent builds the review log entry for an upsert delta
+func (u *QuestionUseCaseImpl) newReviewEvent(delta *core.Delta, memory core.MemoryUse, solve core.Solve) core.ReviewEvent {
+	newState := delta.NewState
+
+	var easeFactorBefore float64
+	if delta.OldState != nil {
+		easeFactorBefore = delta.OldState.EaseFactor
+	}
+
+	return core.ReviewEvent{
+		QuestionID:       newState.ID,
+		Familiarity:      newState.Familiarity,
+		MemoryUse:        memory,
+		Importance:       newState.Importance,
+		EaseFactorBefore: easeFactorBefore,
+		EaseFactorAfter:  newState.EaseFactor,
+		IntervalDays:     int(newState.NextReview.Sub(newState.LastReviewed).Hours() / 24),
+		Solve:            solve,
+		ReviewedAt:       delta.CreatedAt,
+	}
+}
+
 func (u *QuestionUseCaseImpl) DeleteQuestion(target string) (*core.Question, error) {
 	logger.Infof("Deleting question: Target=%s", target)
 
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	store, err := u.Storage.LoadQuestionStore()
 	if err != nil {
 		return nil, errs.WrapInternalError(err, "Failed to load question store")
@@ -344,15 +526,9 @@ func (u *QuestionUseCaseImpl) DeleteQuestion(target string) (*core.Question, err
 
 	// Delete the question from the store
 	delete(store.Questions, deletedQuestion.ID)
-	delete(store.URLIndex, deletedQuestion.URL)
 
-	// Delete the question from the URL and note indices
-	for _, word := range tokenizer.Tokenize(deletedQuestion.URL) {
-		store.URLTrie.Delete(word, deletedQuestion.ID)
-	}
-	for _, word := range tokenizer.Tokenize(deletedQuestion.Note) {
-		store.NoteTrie.Delete(word, deletedQuestion.ID)
-	}
+	// Delete the question from the indices for search
+	store.UnindexQuestion(deletedQuestion)
 
 	if err := u.Storage.SaveQuestionStore(store); err != nil {
 		return nil, errs.WrapInternalError(err, "Failed to save question store")
@@ -366,57 +542,95 @@ func (u *QuestionUseCaseImpl) DeleteQuestion(target string) (*core.Question, err
 		NewState:   nil,
 		CreatedAt:  u.Clock.Now(),
 	})
-	if err := u.Storage.SaveDeltas(deltas); err != nil {
-		logger.Errorf("Failed to save deltas: %v", err)
-	}
+	u.saveNewHistory(deltas)
 	return deletedQuestion, nil
 }
 
-func (u *QuestionUseCaseImpl) Undo() error {
-	logger.Infof("Undoing last action")
+// Undo reverts the most recent actions, newest first, and returns them in that order.
+// The reverted actions move to the redo history.
+func (u *QuestionUseCaseImpl) Undo(count int) ([]core.Delta, error) {
+	logger.Infof("Undoing actions: Count=%d", count)
+
+	unlock, err := u.lockData()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
 	deltas, err := u.Storage.LoadDeltas()
 	if err != nil {
-		return errs.WrapInternalError(err, "Failed to load deltas")
+		return nil, errs.WrapInternalError(err, "Failed to load deltas")
 	}
 	if len(deltas) == 0 {
-		return errs.ErrNoActionsToUndo
+		return nil, errs.ErrNoActionsToUndo
+	}
+	if count < 1 || count > len(deltas) {
+		return nil, errs.WrapBusinessError(fmt.Errorf("cannot undo %d of %d actions", count, len(deltas)),
+			fmt.Sprintf("Only %d actions can be undone", len(deltas)))
 	}
 
 	store, err := u.Storage.LoadQuestionStore()
 	if err != nil {
-		return errs.WrapInternalError(err, "Failed to load question store")
+		return nil, errs.WrapInternalError(err, "Failed to load question store")
+	}
+	redoDeltas, err := u.Storage.LoadRedoDeltas()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to load redo deltas")
 	}
 
-	// Get the last delta
-	lastDelta := deltas[len(deltas)-1]
+	// Newest first, the order they are reverted in
+	undone := slices.Clone(deltas[len(deltas)-count:])
+	slices.Reverse(undone)
 
-	var deltaError error
-	switch lastDelta.Action {
-	case core.ActionAdd:
-		deltaError = u.undoAdd(store, lastDelta)
-	case core.ActionUpdate:
-		deltaError = u.undoUpdate(store, lastDelta)
-	case core.ActionDelete:
-		deltaError = u.undoDelete(store, lastDelta)
+	// Check every action before reverting any, so a damaged entry leaves the questions untouched
+	if err := checkReplay(store, undone, true); err != nil {
+		return nil, errs.WrapBusinessError(err, "The history no longer matches your questions. Run 'doctor' to check your data")
 	}
-
-	if deltaError != nil {
-		return errs.WrapInternalError(deltaError, "Failed to undo last action")
+	for _, delta := range undone {
+		if err := u.undoDelta(store, delta); err != nil {
+			return nil, errs.WrapInternalError(err, "Failed to undo")
+		}
 	}
 
 	// Save the updated questions
 	if err := u.Storage.SaveQuestionStore(store); err != nil {
-		return errs.WrapInternalError(err, "Failed to save question store")
+		return nil, errs.WrapInternalError(err, "Failed to save question store")
 	}
 
-	// Remove the last delta only after successful undo
-	deltas = deltas[:len(deltas)-1]
+	// Move the deltas to the redo history only after successful undo
+	deltas = deltas[:len(deltas)-count]
 	if err := u.Storage.SaveDeltas(deltas); err != nil {
 		logger.Errorf("Failed to save deltas: %v", err)
 	}
+	for _, delta := range undone {
+		redoDeltas = u.appendDelta(redoDeltas, delta)
+	}
+	if err := u.Storage.SaveRedoDeltas(redoDeltas); err != nil {
+		logger.Errorf("Failed to save redo deltas: %v", err)
+	}
 
-	return nil
+	// Leave the undone reviews out of the review log until they are redone
+	if err := u.Storage.MarkReviewEvents(undone, true); err != nil {
+		logger.Errorf("Failed to mark review events reverted: %v", err)
+	}
+
+	return undone, nil
+}
+
+func (u *QuestionUseCaseImpl) undoDelta(store *storage.QuestionStore, delta core.Delta) error {
+	switch delta.Action {
+	case core.ActionAdd:
+		return u.undoAdd(store, delta)
+	case core.ActionUpdate:
+		return u.undoUpdate(store, delta)
+	case core.ActionDelete:
+		return u.undoDelete(store, delta)
+	case core.ActionImport:
+		return u.undoBatch(store, delta)
+	case core.ActionResume:
+		return u.undoResume(store, delta)
+	}
+	return fmt.Errorf("unexpected %s action", delta.Action)
 }
 
 func (u *QuestionUseCaseImpl) undoAdd(store *storage.QuestionStore, delta core.Delta) error {
@@ -425,13 +639,7 @@ func (u *QuestionUseCaseImpl) undoAdd(store *storage.QuestionStore, delta core.D
 	}
 
 	delete(store.Questions, delta.NewState.ID)
-	delete(store.URLIndex, delta.NewState.URL)
-	for _, word := range tokenizer.Tokenize(delta.NewState.URL) {
-		store.URLTrie.Delete(word, delta.NewState.ID)
-	}
-	for _, word := range tokenizer.Tokenize(delta.NewState.Note) {
-		store.NoteTrie.Delete(word, delta.NewState.ID)
-	}
+	store.UnindexQuestion(delta.NewState)
 	return nil
 }
 
@@ -443,20 +651,9 @@ func (u *QuestionUseCaseImpl) undoUpdate(store *storage.QuestionStore, delta cor
 	// Restore the previous state of the question
 	store.Questions[delta.QuestionID] = delta.OldState
 
-	// Delete the current state of the question from the trie
-	for _, word := range tokenizer.Tokenize(delta.NewState.URL) {
-		store.URLTrie.Delete(word, delta.NewState.ID)
-	}
-	for _, word := range tokenizer.Tokenize(delta.NewState.Note) {
-		store.NoteTrie.Delete(word, delta.NewState.ID)
-	}
-	// Restore the previous state of the question to the trie
-	for _, word := range tokenizer.Tokenize(delta.OldState.URL) {
-		store.URLTrie.Insert(word, delta.OldState.ID)
-	}
-	for _, word := range tokenizer.Tokenize(delta.OldState.Note) {
-		store.NoteTrie.Insert(word, delta.OldState.ID)
-	}
+	// Replace the current state of the question in the indices with the previous one
+	store.UnindexQuestion(delta.NewState)
+	store.IndexQuestion(delta.OldState)
 	return nil
 }
 
@@ -467,14 +664,27 @@ func (u *QuestionUseCaseImpl) undoDelete(store *storage.QuestionStore, delta cor
 
 	// Restore the previous state of the question
 	store.Questions[delta.QuestionID] = delta.OldState
-	store.URLIndex[delta.OldState.URL] = delta.QuestionID
 
-	// Restore the previous state of the question to the trie
-	for _, word := range tokenizer.Tokenize(delta.OldState.URL) {
-		store.URLTrie.Insert(word, delta.OldState.ID)
-	}
-	for _, word := range tokenizer.Tokenize(delta.OldState.Note) {
-		store.NoteTrie.Insert(word, delta.OldState.ID)
+	// Restore the previous state of the question to the indices
+	store.IndexQuestion(delta.OldState)
+	return nil
+}
+
+// undoBatch reverts the steps of a batch delta in reverse order
+func (u *QuestionUseCaseImpl) undoBatch(store *storage.QuestionStore, delta core.Delta) error {
+	for i := len(delta.Batch) - 1; i >= 0; i-- {
+		var err error
+		switch step := delta.Batch[i]; step.Action {
+		case core.ActionAdd:
+			err = u.undoAdd(store, step)
+		case core.ActionUpdate:
+			err = u.undoUpdate(store, step)
+		default:
+			err = fmt.Errorf("unexpected %s action in %s", step.Action, delta.Action)
+		}
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -499,6 +709,39 @@ func (u *QuestionUseCaseImpl) GetHistory() ([]core.Delta, error) {
 	return reversedDeltas, nil
 }
 
+// lockData holds the data files for the whole of a command that writes them; see storage.Lock
+func (u *QuestionUseCaseImpl) lockData() (unlock func(), err error) {
+	unlock, err = u.Storage.Lock()
+	if err != nil {
+		return nil, errs.WrapInternalError(err, "Failed to lock the data files")
+	}
+	return unlock, nil
+}
+
+// saveNewHistory saves the history after a new action. A new action starts a new line of
+// history, so the actions undone before it can no longer be redone.
+func (u *QuestionUseCaseImpl) saveNewHistory(deltas []core.Delta) {
+	if err := u.Storage.SaveDeltas(deltas); err != nil {
+		logger.Errorf("Failed to save deltas: %v", err)
+	}
+	u.clearRedoHistory()
+}
+
+// clearRedoHistory drops the undone actions, as they can no longer be redone
+func (u *QuestionUseCaseImpl) clearRedoHistory() {
+	redoDeltas, err := u.Storage.LoadRedoDeltas()
+	if err != nil {
+		logger.Errorf("Failed to load redo deltas: %v", err)
+		return
+	}
+	if len(redoDeltas) == 0 {
+		return
+	}
+	if err := u.Storage.SaveRedoDeltas(nil); err != nil {
+		logger.Errorf("Failed to clear redo deltas: %v", err)
+	}
+}
+
 func (u *QuestionUseCaseImpl) appendDelta(deltas []core.Delta, delta core.Delta) []core.Delta {
 	deltas = append(deltas, delta)
 
@@ -557,7 +800,7 @@ func (u *QuestionUseCaseImpl) GetSettings() error {
 func (u *QuestionUseCaseImpl) UpdateSetting(settingName string, value any) error {
 	// Use the registry-based approach
 	if err := u.cfg.SetSettingValue(settingName, value); err != nil {
-		return errs.WrapValidationError(err, fmt.Sprintf("Unknown setting: %s", settingName))
+		return wrapSettingError(err, settingName)
 	}
 
 	// Save the configuration
@@ -568,11 +811,39 @@ func (u *QuestionUseCaseImpl) UpdateSetting(settingName string, value any) error
 	return nil
 }
 
-// MigrateToUTC converts all timestamps in questions and deltas to UTC.
-// This is needed for users upgrading from versions that stored local timezone.
+// ResetSetting puts a setting back to its default value and saves the configuration
+func (u *QuestionUseCaseImpl) ResetSetting(settingName string) error {
+	if err := u.cfg.ResetSettingValue(settingName); err != nil {
+		return wrapSettingError(err, settingName)
+	}
+
+	if err := u.cfg.Save(); err != nil {
+		return errs.WrapInternalError(err, "Failed to save settings")
+	}
+
+	return nil
+}
+
+// wrapSettingError wraps an error from changing a setting; the error names what is wrong with
+// the value, unless the setting itself does not exist
+func wrapSettingError(err error, settingName string) error {
+	if errors.Is(err, config.ErrUnknownSetting) {
+		return errs.WrapValidationError(err, fmt.Sprintf("Unknown setting: %s", settingName))
+	}
+	return errs.WrapValidationError(err, "")
+}
+
+// MigrateToUTC converts all timestamps in questions, pauses, the solve timer, deltas and the
+// review log to UTC. This is needed for users upgrading from versions that stored local timezone.
 // Returns the number of questions and deltas migrated.
 func (u *QuestionUseCaseImpl) MigrateToUTC() (int, int, error) {
-	// Migrate questions
+	unlock, err := u.lockData()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+
+	// Migrate questions, pauses and the timer
 	store, err := u.Storage.LoadQuestionStore()
 	if err != nil {
 		return 0, 0, errs.WrapInternalError(err, "Failed to load questions")
@@ -580,12 +851,10 @@ func (u *QuestionUseCaseImpl) MigrateToUTC() (int, int, error) {
 
 	questionsCount := 0
 	for _, q := range store.Questions {
-		q.LastReviewed = q.LastReviewed.UTC()
-		q.NextReview = q.NextReview.UTC()
-		q.UpdatedAt = q.UpdatedAt.UTC()
-		q.CreatedAt = q.CreatedAt.UTC()
+		toUTC(q)
 		questionsCount++
 	}
+	checkPausesAndTimer(store, true)
 
 	if err := u.Storage.SaveQuestionStore(store); err != nil {
 		return 0, 0, errs.WrapInternalError(err, "Failed to save questions")
@@ -599,19 +868,7 @@ func (u *QuestionUseCaseImpl) MigrateToUTC() (int, int, error) {
 
 	deltasCount := 0
 	for i := range deltas {
-		deltas[i].CreatedAt = deltas[i].CreatedAt.UTC()
-		if deltas[i].OldState != nil {
-			deltas[i].OldState.LastReviewed = deltas[i].OldState.LastReviewed.UTC()
-			deltas[i].OldState.NextReview = deltas[i].OldState.NextReview.UTC()
-			deltas[i].OldState.UpdatedAt = deltas[i].OldState.UpdatedAt.UTC()
-			deltas[i].OldState.CreatedAt = deltas[i].OldState.CreatedAt.UTC()
-		}
-		if deltas[i].NewState != nil {
-			deltas[i].NewState.LastReviewed = deltas[i].NewState.LastReviewed.UTC()
-			deltas[i].NewState.NextReview = deltas[i].NewState.NextReview.UTC()
-			deltas[i].NewState.UpdatedAt = deltas[i].NewState.UpdatedAt.UTC()
-			deltas[i].NewState.CreatedAt = deltas[i].NewState.CreatedAt.UTC()
-		}
+		deltaToUTC(&deltas[i])
 		deltasCount++
 	}
 
@@ -619,6 +876,17 @@ func (u *QuestionUseCaseImpl) MigrateToUTC() (int, int, error) {
 		return questionsCount, 0, errs.WrapInternalError(err, "Failed to save deltas")
 	}
 
+	// Migrate the review log; the loaded events are the cached slice, so convert a copy
+	events, err := u.Storage.LoadReviewEvents()
+	if err != nil {
+		return questionsCount, deltasCount, errs.WrapInternalError(err, "Failed to load review log")
+	}
+	if events = slices.Clone(events); len(checkReviewEvents(events, true)) > 0 {
+		if err := u.Storage.SaveReviewEvents(events); err != nil {
+			return questionsCount, deltasCount, errs.WrapInternalError(err, "Failed to save review log")
+		}
+	}
+
 	return questionsCount, deltasCount, nil
 }
 
@@ -626,6 +894,12 @@ func (u *QuestionUseCaseImpl) MigrateToUTC() (int, int, error) {
 func (u *QuestionUseCaseImpl) ResetData() (int, int, error) {
 	logger.Infof("Resetting all data")
 
+	unlock, err := u.lockData()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+
 	// Get counts before deletion
 	store, err := u.Storage.LoadQuestionStore()
 	if err != nil {
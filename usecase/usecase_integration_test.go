@@ -6,6 +6,7 @@ import (
 
 	"github.com/eannchen/leetsolv/config"
 	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/backup"
 	"github.com/eannchen/leetsolv/internal/clock"
 	"github.com/eannchen/leetsolv/internal/logger"
 	"github.com/eannchen/leetsolv/storage"
@@ -18,7 +19,8 @@ var integrationTestTime = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
 func setupIntegrationTest(t *testing.T) (*QuestionUseCaseImpl, *config.TestConfig) {
 	testConfig, cfg := config.MockEnv(t)
 	mockClock := clock.NewMockClock(integrationTestTime)
-	storage := storage.NewFileStorage(testConfig.QuestionsFile, testConfig.DeltasFile, &config.MockFileUtil{})
+	backups := backup.NewManager(testConfig.BackupDir, nil, testRetention, mockClock)
+	storage := storage.NewFileStorage(testConfig.QuestionsFile, testConfig.DeltasFile, testConfig.RedoFile, testConfig.ReviewsFile, &config.MockFileUtil{}, backups)
 	// Use fixed random for deterministic tests
 	scheduler := core.NewSM2SchedulerWithRand(cfg, mockClock, core.FixedRand{Value: 1})
 	logger.InitNop()
@@ -36,7 +38,7 @@ func TestQuestionUseCase_Integration_UpsertAndGet(t *testing.T) {
 	importance := core.MediumImportance
 	memory := core.MemoryReasoned
 
-	delta, err := useCase.UpsertQuestion(url, note, familiarity, importance, memory)
+	delta, err := useCase.UpsertQuestion(url, note, nil, familiarity, importance, memory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to upsert question: %v", err)
 	}
@@ -89,7 +91,7 @@ func TestQuestionUseCase_Integration_UpdateQuestion(t *testing.T) {
 	importance := core.MediumImportance
 	memory := core.MemoryReasoned
 
-	_, err := useCase.UpsertQuestion(url, note, familiarity, importance, memory)
+	_, err := useCase.UpsertQuestion(url, note, nil, familiarity, importance, memory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to create initial question: %v", err)
 	}
@@ -100,7 +102,7 @@ func TestQuestionUseCase_Integration_UpdateQuestion(t *testing.T) {
 	updatedImportance := core.HighImportance
 	updatedMemory := core.MemoryPartial
 
-	updatedDelta, err := useCase.UpsertQuestion(url, updatedNote, updatedFamiliarity, updatedImportance, updatedMemory)
+	updatedDelta, err := useCase.UpsertQuestion(url, updatedNote, nil, updatedFamiliarity, updatedImportance, updatedMemory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to update question: %v", err)
 	}
@@ -135,7 +137,7 @@ func TestQuestionUseCase_Integration_DeleteAndUndo(t *testing.T) {
 	importance := core.MediumImportance
 	memory := core.MemoryReasoned
 
-	delta, err := useCase.UpsertQuestion(url, note, familiarity, importance, memory)
+	delta, err := useCase.UpsertQuestion(url, note, nil, familiarity, importance, memory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to create question: %v", err)
 	}
@@ -157,7 +159,7 @@ func TestQuestionUseCase_Integration_DeleteAndUndo(t *testing.T) {
 	}
 
 	// Undo the deletion
-	err = useCase.Undo()
+	_, err = useCase.Undo(1)
 	if err != nil {
 		t.Fatalf("Failed to undo deletion: %v", err)
 	}
@@ -250,7 +252,7 @@ func TestQuestionUseCase_Integration_ErrorHandling(t *testing.T) {
 	}
 
 	// Test undo when no actions available
-	err = useCase.Undo()
+	_, err = useCase.Undo(1)
 	if err == nil {
 		t.Error("Expected error when undoing with no actions")
 	}
@@ -1,13 +1,19 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/eannchen/leetsolv/config"
 	"github.com/eannchen/leetsolv/core"
+	"github.com/eannchen/leetsolv/internal/backup"
 	"github.com/eannchen/leetsolv/internal/clock"
+	"github.com/eannchen/leetsolv/internal/errs"
 	"github.com/eannchen/leetsolv/internal/logger"
 	"github.com/eannchen/leetsolv/internal/search"
 	"github.com/eannchen/leetsolv/storage"
@@ -16,6 +22,9 @@ import (
 // Fixed test time for deterministic tests
 var testTime = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
 
+// testRetention keeps the backups taken by tests regardless of age
+func testRetention() (int, time.Duration) { return 20, 0 }
+
 // setupTestEnvironment creates a test environment with temporary files
 func setupTestEnvironment(t *testing.T) (*config.TestConfig, *QuestionUseCaseImpl) {
 	// Create test configuration with temporary files
@@ -25,7 +34,8 @@ func setupTestEnvironment(t *testing.T) (*config.TestConfig, *QuestionUseCaseImp
 	mockClock := clock.NewMockClock(testTime)
 
 	// Create storage with test files
-	storage := storage.NewFileStorage(testConfig.QuestionsFile, testConfig.DeltasFile, &config.MockFileUtil{})
+	backups := backup.NewManager(testConfig.BackupDir, nil, testRetention, mockClock)
+	storage := storage.NewFileStorage(testConfig.QuestionsFile, testConfig.DeltasFile, testConfig.RedoFile, testConfig.ReviewsFile, &config.MockFileUtil{}, backups)
 
 	// Create scheduler with fixed random for deterministic tests
 	scheduler := core.NewSM2SchedulerWithRand(cfg, mockClock, core.FixedRand{Value: 1})
@@ -67,7 +77,7 @@ func TestQuestionUseCase_UpsertQuestion(t *testing.T) {
 	memory := core.MemoryReasoned
 
 	// Test upserting a new question
-	delta, err := useCase.UpsertQuestion(url, note, familiarity, importance, memory)
+	delta, err := useCase.UpsertQuestion(url, note, nil, familiarity, importance, memory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to upsert question: %v", err)
 	}
@@ -105,7 +115,7 @@ func TestQuestionUseCase_UpsertQuestion(t *testing.T) {
 	updatedImportance := core.HighImportance
 	updatedMemory := core.MemoryPartial
 
-	updatedDelta, err := useCase.UpsertQuestion(url, updatedNote, updatedFamiliarity, updatedImportance, updatedMemory)
+	updatedDelta, err := useCase.UpsertQuestion(url, updatedNote, nil, updatedFamiliarity, updatedImportance, updatedMemory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to update question: %v", err)
 	}
@@ -124,6 +134,101 @@ func TestQuestionUseCase_UpsertQuestion(t *testing.T) {
 	}
 }
 
+func TestQuestionUseCase_UpsertQuestion_AppendsReviewEvents(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	url := "https://leetcode.com/problems/two-sum/"
+
+	addDelta, err := useCase.UpsertQuestion(url, "note", nil, core.Hard, core.HighImportance, core.MemoryReasoned, core.Solve{})
+	if err != nil {
+		t.Fatalf("Failed to add question: %v", err)
+	}
+	updateDelta, err := useCase.UpsertQuestion(url, "note", nil, core.Easy, core.HighImportance, core.MemoryPartial, core.Solve{})
+	if err != nil {
+		t.Fatalf("Failed to update question: %v", err)
+	}
+
+	events, err := useCase.Storage.LoadReviewEvents()
+	if err != nil {
+		t.Fatalf("Failed to load review events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 review events, got %d", len(events))
+	}
+
+	first := events[0]
+	if first.QuestionID != addDelta.NewState.ID || first.Familiarity != core.Hard || first.Importance != core.HighImportance {
+		t.Errorf("Unexpected first review event: %+v", first)
+	}
+	if first.EaseFactorBefore != 0 {
+		t.Errorf("Expected no ease factor before the first review, got %.2f", first.EaseFactorBefore)
+	}
+	if first.EaseFactorAfter != addDelta.NewState.EaseFactor {
+		t.Errorf("Expected ease factor after %.2f, got %.2f", addDelta.NewState.EaseFactor, first.EaseFactorAfter)
+	}
+
+	second := events[1]
+	if second.MemoryUse != core.MemoryPartial {
+		t.Errorf("Expected memory use %d, got %d", core.MemoryPartial, second.MemoryUse)
+	}
+	if second.EaseFactorBefore != addDelta.NewState.EaseFactor || second.EaseFactorAfter != updateDelta.NewState.EaseFactor {
+		t.Errorf("Expected ease factor %.2f → %.2f, got %.2f → %.2f",
+			addDelta.NewState.EaseFactor, updateDelta.NewState.EaseFactor, second.EaseFactorBefore, second.EaseFactorAfter)
+	}
+	expectedInterval := int(updateDelta.NewState.NextReview.Sub(updateDelta.NewState.LastReviewed).Hours() / 24)
+	if second.IntervalDays != expectedInterval {
+		t.Errorf("Expected interval %d days, got %d", expectedInterval, second.IntervalDays)
+	}
+	if !second.ReviewedAt.Equal(testTime) {
+		t.Errorf("Expected review time %v, got %v", testTime, second.ReviewedAt)
+	}
+}
+
+func TestQuestionUseCase_UpsertQuestion_RecordsSolve(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	useCase.cfg.SlowerSolvePenalty = 0.1
+	useCase.cfg.RetryPenalty = 0
+	url := "https://leetcode.com/problems/two-sum/"
+
+	// Two questions reviewed alike, except that the last solve of one is slower than the one before
+	faster := "https://leetcode.com/problems/3sum/"
+	for _, minutes := range []int{20, 15} {
+		for _, target := range []string{url, faster} {
+			if _, err := useCase.UpsertQuestion(target, "note", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{Minutes: minutes}); err != nil {
+				t.Fatalf("Failed to upsert question: %v", err)
+			}
+		}
+	}
+	slower, err := useCase.UpsertQuestion(url, "note", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{Minutes: 30, Attempts: 2})
+	if err != nil {
+		t.Fatalf("Failed to review question: %v", err)
+	}
+	quicker, _ := useCase.UpsertQuestion(faster, "note", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{Minutes: 10})
+	if slower.NewState.LastSolve.Minutes != 30 || slower.OldState.LastSolve.Minutes != 15 {
+		t.Errorf("Expected the last solve to go from 15 to 30 minutes, got %+v", slower)
+	}
+	if diff := quicker.NewState.EaseFactor - slower.NewState.EaseFactor; math.Abs(diff-0.1) > 1e-9 {
+		t.Errorf("Expected the slower solve to lose 0.1 more ease, got %.2f", diff)
+	}
+
+	// A review without a solve keeps the last one
+	kept, _ := useCase.UpsertQuestion(url, "note", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
+	if kept.NewState.LastSolve.Minutes != 30 {
+		t.Errorf("Expected the last solve to be kept, got %+v", kept.NewState.LastSolve)
+	}
+
+	events, _ := useCase.Storage.LoadReviewEvents()
+	if len(events) != 7 || events[4].Solve.Minutes != 30 || events[4].Solve.Attempts != 2 || !events[6].Solve.IsZero() {
+		t.Errorf("Expected each review event to hold its own solve, got %+v", events)
+	}
+
+	yes := true
+	if _, err := useCase.UpsertQuestion(url, "note", nil, core.Medium, core.MediumImportance, core.MemoryReasoned,
+		core.Solve{FirstPass: &yes, Attempts: 2}); errs.ExitCode(err) != errs.ExitValidation {
+		t.Errorf("Expected a validation error for a first pass with 2 attempts, got %v", err)
+	}
+}
+
 func TestQuestionUseCase_GetQuestion(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
@@ -212,6 +317,32 @@ func TestQuestionUseCase_DeleteQuestion(t *testing.T) {
 	}
 }
 
+func TestQuestionUseCase_Undo_DeleteAction_KeepsSearchIndexConsistent(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	if _, err := useCase.UpsertQuestion("https://leetcode.com/problems/two-sum/", "hash map", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{}); err != nil {
+		t.Fatalf("Failed to upsert question: %v", err)
+	}
+	if _, err := useCase.DeleteQuestion("1"); err != nil {
+		t.Fatalf("Failed to delete question: %v", err)
+	}
+	if _, err := useCase.Undo(1); err != nil {
+		t.Fatalf("Failed to undo delete: %v", err)
+	}
+
+	// The restored question is indexed exactly as it was when added: by its problem slug only
+	store, err := useCase.Storage.LoadQuestionStore()
+	if err != nil {
+		t.Fatalf("Failed to load question store: %v", err)
+	}
+	if ids := store.URLTrie.SearchPrefix("two"); len(ids) != 1 {
+		t.Errorf("Expected the restored question to be found by its slug, got %v", ids)
+	}
+	if ids := store.URLTrie.SearchPrefix("leetcode"); len(ids) != 0 {
+		t.Errorf("Expected the host not to be indexed, got %v", ids)
+	}
+}
+
 func TestQuestionUseCase_ListQuestionsSummary(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
@@ -275,18 +406,123 @@ func TestQuestionUseCase_ListQuestionsSummary(t *testing.T) {
 	}
 }
 
+func TestQuestionUseCase_ListDueQuestions(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	yesterday := testTime.AddDate(0, 0, -1)
+
+	lowPriority := createTestQuestion(1, "https://leetcode.com/problems/low")
+	lowPriority.NextReview = yesterday
+	lowPriority.Importance = core.LowImportance
+
+	highPriority := createTestQuestion(2, "https://leetcode.com/problems/high")
+	highPriority.NextReview = yesterday
+	highPriority.Importance = core.CriticalImportance
+
+	upcoming := createTestQuestion(3, "https://leetcode.com/problems/upcoming")
+
+	store := &storage.QuestionStore{
+		Questions: map[int]*core.Question{1: lowPriority, 2: highPriority, 3: upcoming},
+		URLIndex:  map[string]int{},
+		MaxID:     3,
+		URLTrie:   search.NewTrie(3),
+		NoteTrie:  search.NewTrie(3),
+	}
+	if err := useCase.Storage.SaveQuestionStore(store); err != nil {
+		t.Fatalf("Failed to save test data: %v", err)
+	}
+
+	// The due list is not limited by TopKDue
+	useCase.cfg.TopKDue = 1
+
+	due, err := useCase.ListDueQuestions()
+	if err != nil {
+		t.Fatalf("Failed to list due questions: %v", err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("Expected 2 due questions, got %d", len(due))
+	}
+	if due[0].ID != 2 || due[1].ID != 1 {
+		t.Errorf("Expected due questions ordered by priority [2 1], got [%d %d]", due[0].ID, due[1].ID)
+	}
+}
+
+func TestQuestionUseCase_Tags(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/climbing-stairs", "", []string{"DP", "#easy"}, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
+	if err != nil {
+		t.Fatalf("Failed to add first question: %v", err)
+	}
+	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/word-ladder", "", []string{"graph", "bfs"}, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
+	if err != nil {
+		t.Fatalf("Failed to add second question: %v", err)
+	}
+	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/coin-change", "", []string{"dp"}, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
+	if err != nil {
+		t.Fatalf("Failed to add third question: %v", err)
+	}
+
+	t.Run("filters by included and excluded tags", func(t *testing.T) {
+		results, err := useCase.SearchQuestions(nil, &core.SearchFilter{Tags: []string{"dp"}, ExcludeTags: []string{"easy"}})
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		if len(results) != 1 || results[0].URL != "https://leetcode.com/problems/coin-change" {
+			t.Errorf("Expected only coin-change, got %v", results)
+		}
+	})
+
+	t.Run("lists tags with counts", func(t *testing.T) {
+		tags, err := useCase.ListTags()
+		if err != nil {
+			t.Fatalf("Failed to list tags: %v", err)
+		}
+		if len(tags) != 4 {
+			t.Fatalf("Expected 4 tags, got %d: %v", len(tags), tags)
+		}
+		if tags[0].Name != "dp" || tags[0].Total != 2 {
+			t.Errorf("Expected dp with 2 questions first, got %+v", tags[0])
+		}
+	})
+
+	t.Run("updating and undoing keeps the tag index in sync", func(t *testing.T) {
+		_, err := useCase.UpsertQuestion("https://leetcode.com/problems/coin-change", "", []string{"knapsack"}, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
+		if err != nil {
+			t.Fatalf("Failed to update question: %v", err)
+		}
+
+		store, _ := useCase.Storage.LoadQuestionStore()
+		if len(store.TagIndex["dp"]) != 1 || len(store.TagIndex["knapsack"]) != 1 {
+			t.Errorf("Expected dp and knapsack to have 1 question each, got %v", store.TagIndex)
+		}
+
+		if _, err := useCase.Undo(1); err != nil {
+			t.Fatalf("Failed to undo: %v", err)
+		}
+
+		store, _ = useCase.Storage.LoadQuestionStore()
+		if len(store.TagIndex["dp"]) != 2 {
+			t.Errorf("Expected dp to have 2 questions after undo, got %v", store.TagIndex["dp"])
+		}
+		if _, ok := store.TagIndex["knapsack"]; ok {
+			t.Error("Expected knapsack to be removed from the index after undo")
+		}
+	})
+}
+
 // NEW TESTS FOR BETTER BUG DETECTION
 
 func TestQuestionUseCase_SearchQuestions_WithQueries(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
 	// Add questions using the proper method to populate tries
-	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/two-sum", "Find two numbers that add up to target", core.Medium, core.MediumImportance, core.MemoryReasoned)
+	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/two-sum", "Find two numbers that add up to target", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add first question: %v", err)
 	}
 
-	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/add-two-numbers", "Add two linked lists representing numbers", core.Medium, core.MediumImportance, core.MemoryReasoned)
+	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/add-two-numbers", "Add two linked lists representing numbers", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add second question: %v", err)
 	}
@@ -325,12 +561,12 @@ func TestQuestionUseCase_SearchQuestions_WithFilters(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
 	// Add questions using the proper method to populate tries
-	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/test1", "Test question 1", core.Easy, core.HighImportance, core.MemoryReasoned)
+	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/test1", "Test question 1", nil, core.Easy, core.HighImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add first question: %v", err)
 	}
 
-	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/test2", "Test question 2", core.Hard, core.LowImportance, core.MemoryReasoned)
+	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/test2", "Test question 2", nil, core.Hard, core.LowImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add second question: %v", err)
 	}
@@ -356,12 +592,12 @@ func TestQuestionUseCase_SearchQuestions_EmptyQuery(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
 	// Add questions using the proper method
-	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/test1", "Test question 1", core.Medium, core.MediumImportance, core.MemoryReasoned)
+	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/test1", "Test question 1", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add first question: %v", err)
 	}
 
-	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/test2", "Test question 2", core.Medium, core.MediumImportance, core.MemoryReasoned)
+	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/test2", "Test question 2", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add second question: %v", err)
 	}
@@ -387,7 +623,7 @@ func TestQuestionUseCase_Undo_AddAction(t *testing.T) {
 	importance := core.MediumImportance
 	memory := core.MemoryReasoned
 
-	_, err := useCase.UpsertQuestion(url, note, familiarity, importance, memory)
+	_, err := useCase.UpsertQuestion(url, note, nil, familiarity, importance, memory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add question: %v", err)
 	}
@@ -399,7 +635,7 @@ func TestQuestionUseCase_Undo_AddAction(t *testing.T) {
 	}
 
 	// Undo the add action
-	err = useCase.Undo()
+	_, err = useCase.Undo(1)
 	if err != nil {
 		t.Fatalf("Failed to undo add action: %v", err)
 	}
@@ -421,7 +657,7 @@ func TestQuestionUseCase_Undo_UpdateAction(t *testing.T) {
 	importance := core.MediumImportance
 	memory := core.MemoryReasoned
 
-	_, err := useCase.UpsertQuestion(url, note, familiarity, importance, memory)
+	_, err := useCase.UpsertQuestion(url, note, nil, familiarity, importance, memory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add question: %v", err)
 	}
@@ -432,7 +668,7 @@ func TestQuestionUseCase_Undo_UpdateAction(t *testing.T) {
 	updatedImportance := core.HighImportance
 	updatedMemory := core.MemoryPartial
 
-	_, err = useCase.UpsertQuestion(url, updatedNote, updatedFamiliarity, updatedImportance, updatedMemory)
+	_, err = useCase.UpsertQuestion(url, updatedNote, nil, updatedFamiliarity, updatedImportance, updatedMemory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to update question: %v", err)
 	}
@@ -448,7 +684,7 @@ func TestQuestionUseCase_Undo_UpdateAction(t *testing.T) {
 	}
 
 	// Undo the update action
-	err = useCase.Undo()
+	_, err = useCase.Undo(1)
 	if err != nil {
 		t.Fatalf("Failed to undo update action: %v", err)
 	}
@@ -474,7 +710,7 @@ func TestQuestionUseCase_Undo_DeleteAction(t *testing.T) {
 	importance := core.MediumImportance
 	memory := core.MemoryReasoned
 
-	delta, err := useCase.UpsertQuestion(url, note, familiarity, importance, memory)
+	delta, err := useCase.UpsertQuestion(url, note, nil, familiarity, importance, memory, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add question: %v", err)
 	}
@@ -496,7 +732,7 @@ func TestQuestionUseCase_Undo_DeleteAction(t *testing.T) {
 	}
 
 	// Undo the delete action
-	err = useCase.Undo()
+	_, err = useCase.Undo(1)
 	if err != nil {
 		t.Fatalf("Failed to undo delete action: %v", err)
 	}
@@ -516,7 +752,7 @@ func TestQuestionUseCase_Undo_NoActions(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
 	// Try to undo when no actions are available
-	err := useCase.Undo()
+	_, err := useCase.Undo(1)
 	if err == nil {
 		t.Error("Expected error when undoing with no actions")
 	}
@@ -546,7 +782,7 @@ func TestQuestionUseCase_InvalidURL(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
 	// Try to upsert with invalid URL
-	_, err := useCase.UpsertQuestion("invalid-url", "test", core.Medium, core.MediumImportance, core.MemoryReasoned)
+	_, err := useCase.UpsertQuestion("invalid-url", "test", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 	if err == nil {
 		t.Error("Expected error when upserting with invalid URL")
 	}
@@ -558,7 +794,7 @@ func TestQuestionUseCase_LargeDataSet(t *testing.T) {
 	// Add many questions with unique URLs
 	for i := 0; i < 10; i++ {
 		url := fmt.Sprintf("https://leetcode.com/problems/test%d", i)
-		_, err := useCase.UpsertQuestion(url, "test", core.Medium, core.MediumImportance, core.MemoryReasoned)
+		_, err := useCase.UpsertQuestion(url, "test", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 		if err != nil {
 			t.Fatalf("Failed to add question %d: %v", i, err)
 		}
@@ -599,7 +835,7 @@ func TestQuestionUseCase_SchedulerIntegration(t *testing.T) {
 
 	for i, familiarity := range familiarityLevels {
 		url := fmt.Sprintf("https://leetcode.com/problems/test%d", i)
-		delta, err := useCase.UpsertQuestion(url, "test", familiarity, core.MediumImportance, core.MemoryReasoned)
+		delta, err := useCase.UpsertQuestion(url, "test", nil, familiarity, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 		if err != nil {
 			t.Fatalf("Failed to add question with familiarity %d: %v", familiarity, err)
 		}
@@ -618,6 +854,34 @@ func TestQuestionUseCase_SchedulerIntegration(t *testing.T) {
 	}
 }
 
+func TestQuestionUseCase_FSRSSchedulerKeepsMemoryState(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+	useCase.Scheduler = core.NewFSRSSchedulerWithRand(useCase.cfg, useCase.Clock, core.FixedRand{Value: 1})
+
+	url := "https://leetcode.com/problems/two-sum"
+	added, err := useCase.UpsertQuestion(url, "test", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
+	if err != nil {
+		t.Fatalf("Failed to add question: %v", err)
+	}
+	if added.NewState.Stability <= 0 {
+		t.Fatalf("Expected new question to have FSRS stability, got %.4f", added.NewState.Stability)
+	}
+
+	updated, err := useCase.UpsertQuestion(url, "test", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
+	if err != nil {
+		t.Fatalf("Failed to update question: %v", err)
+	}
+
+	// A same-day review leaves stability unchanged, so it must equal the stored value
+	// rather than one re-derived from the SM-2 interval
+	if updated.NewState.Stability != added.NewState.Stability {
+		t.Errorf("Expected stability to carry over as %.4f, got %.4f", added.NewState.Stability, updated.NewState.Stability)
+	}
+	if updated.NewState.Difficulty <= 0 {
+		t.Errorf("Expected difficulty to carry over, got %.4f", updated.NewState.Difficulty)
+	}
+}
+
 func TestQuestionUseCase_GetHistory(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
@@ -631,7 +895,7 @@ func TestQuestionUseCase_GetHistory(t *testing.T) {
 	}
 
 	// Add a question
-	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/test1", "note1", core.Medium, core.MediumImportance, core.MemoryReasoned)
+	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/test1", "note1", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add question: %v", err)
 	}
@@ -649,7 +913,7 @@ func TestQuestionUseCase_GetHistory(t *testing.T) {
 	}
 
 	// Update the question
-	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/test1", "note2", core.Easy, core.HighImportance, core.MemoryPartial)
+	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/test1", "note2", nil, core.Easy, core.HighImportance, core.MemoryPartial, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to update question: %v", err)
 	}
@@ -671,12 +935,12 @@ func TestQuestionUseCase_SearchQuestions_WithImportanceFilter(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
 	// Add questions with different importance levels
-	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/low", "low importance", core.Medium, core.LowImportance, core.MemoryReasoned)
+	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/low", "low importance", nil, core.Medium, core.LowImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add low importance question: %v", err)
 	}
 
-	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/high", "high importance", core.Medium, core.HighImportance, core.MemoryReasoned)
+	_, err = useCase.UpsertQuestion("https://leetcode.com/problems/high", "high importance", nil, core.Medium, core.HighImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add high importance question: %v", err)
 	}
@@ -701,7 +965,7 @@ func TestQuestionUseCase_SearchQuestions_WithDueOnlyFilter(t *testing.T) {
 	_, useCase := setupTestEnvironment(t)
 
 	// Add a question (will be scheduled in the future, so not due)
-	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/future", "future question", core.Medium, core.MediumImportance, core.MemoryReasoned)
+	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/future", "future question", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add question: %v", err)
 	}
@@ -734,7 +998,7 @@ func TestQuestionUseCase_ListQuestionsOrderByDesc_Ordering(t *testing.T) {
 	// Add questions in order
 	for i := 0; i < 5; i++ {
 		url := fmt.Sprintf("https://leetcode.com/problems/test%d", i)
-		_, err := useCase.UpsertQuestion(url, "test", core.Medium, core.MediumImportance, core.MemoryReasoned)
+		_, err := useCase.UpsertQuestion(url, "test", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 		if err != nil {
 			t.Fatalf("Failed to add question: %v", err)
 		}
@@ -790,6 +1054,31 @@ func TestQuestionUseCase_UpdateSetting(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for unknown setting")
 	}
+
+	// An invalid value is reported as such rather than as an unknown setting
+	var codedErr *errs.CodedError
+	err = useCase.UpdateSetting("pagesize", 0)
+	if !errors.As(err, &codedErr) || strings.Contains(codedErr.UserMessage(), "Unknown setting") {
+		t.Errorf("Expected the reason the value is invalid, got %v", err)
+	}
+}
+
+func TestQuestionUseCase_ResetSetting(t *testing.T) {
+	_, useCase := setupTestEnvironment(t)
+
+	if err := useCase.UpdateSetting("importanceweight", 3.0); err != nil {
+		t.Fatalf("Failed to update setting: %v", err)
+	}
+	if err := useCase.ResetSetting("ImportanceWeight"); err != nil {
+		t.Fatalf("Failed to reset setting: %v", err)
+	}
+	if useCase.cfg.ImportanceWeight != 1.5 {
+		t.Errorf("Expected ImportanceWeight back to 1.5, got %v", useCase.cfg.ImportanceWeight)
+	}
+
+	if err := useCase.ResetSetting("unknownsetting"); errs.ExitCode(err) != errs.ExitValidation {
+		t.Errorf("Expected a validation error for an unknown setting, got %v", err)
+	}
 }
 
 func TestQuestionUseCase_MigrateToUTC(t *testing.T) {
@@ -797,7 +1086,7 @@ func TestQuestionUseCase_MigrateToUTC(t *testing.T) {
 
 	// Create a question with non-UTC times
 	localTime := time.Date(2024, 6, 15, 12, 0, 0, 0, time.FixedZone("EST", -5*60*60))
-	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/test1", "test", core.Medium, core.MediumImportance, core.MemoryReasoned)
+	_, err := useCase.UpsertQuestion("https://leetcode.com/problems/test1", "test", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 	if err != nil {
 		t.Fatalf("Failed to add question: %v", err)
 	}
@@ -811,10 +1100,22 @@ func TestQuestionUseCase_MigrateToUTC(t *testing.T) {
 		q.LastReviewed = localTime
 		q.NextReview = localTime.Add(24 * time.Hour)
 		q.CreatedAt = localTime
+		q.BuriedUntil = localTime.Add(48 * time.Hour)
 	}
+	store.Pauses = []core.Pause{{Start: localTime, End: localTime.Add(24 * time.Hour)}}
+	store.Timer = &core.Timer{URL: "https://leetcode.com/problems/test2", Start: localTime}
 	if err := useCase.Storage.SaveQuestionStore(store); err != nil {
 		t.Fatalf("Failed to save store: %v", err)
 	}
+	events, err := useCase.Storage.LoadReviewEvents()
+	if err != nil {
+		t.Fatalf("Failed to load review log: %v", err)
+	}
+	events = slices.Clone(events)
+	events[0].ReviewedAt = localTime
+	if err := useCase.Storage.SaveReviewEvents(events); err != nil {
+		t.Fatalf("Failed to save review log: %v", err)
+	}
 
 	// Migrate to UTC
 	questionsCount, deltasCount, err := useCase.MigrateToUTC()
@@ -841,6 +1142,31 @@ func TestQuestionUseCase_MigrateToUTC(t *testing.T) {
 		if q.CreatedAt.Location() != time.UTC {
 			t.Errorf("Expected CreatedAt to be UTC, got %v", q.CreatedAt.Location())
 		}
+		if q.BuriedUntil.Location() != time.UTC {
+			t.Errorf("Expected BuriedUntil to be UTC, got %v", q.BuriedUntil.Location())
+		}
+	}
+	if store.Pauses[0].Start.Location() != time.UTC || store.Pauses[0].End.Location() != time.UTC {
+		t.Errorf("Expected the pause to be UTC, got %+v", store.Pauses[0])
+	}
+	if store.Timer.Start.Location() != time.UTC {
+		t.Errorf("Expected the timer start to be UTC, got %v", store.Timer.Start.Location())
+	}
+	events, err = useCase.Storage.LoadReviewEvents()
+	if err != nil {
+		t.Fatalf("Failed to reload review log: %v", err)
+	}
+	if events[0].ReviewedAt.Location() != time.UTC {
+		t.Errorf("Expected ReviewedAt to be UTC, got %v", events[0].ReviewedAt.Location())
+	}
+
+	// Nothing is left for doctor to report
+	result, err := useCase.CheckData(false)
+	if err != nil {
+		t.Fatalf("Failed to check data: %v", err)
+	}
+	if len(result.Issues) != 0 {
+		t.Errorf("Expected no issues after migrating, got %+v", result.Issues)
 	}
 }
 
@@ -850,7 +1176,7 @@ func TestQuestionUseCase_ResetData(t *testing.T) {
 	// Add some data first
 	for i := 0; i < 3; i++ {
 		url := fmt.Sprintf("https://leetcode.com/problems/test%d", i)
-		_, err := useCase.UpsertQuestion(url, "test", core.Medium, core.MediumImportance, core.MemoryReasoned)
+		_, err := useCase.UpsertQuestion(url, "test", nil, core.Medium, core.MediumImportance, core.MemoryReasoned, core.Solve{})
 		if err != nil {
 			t.Fatalf("Failed to add question: %v", err)
 		}